@@ -11,6 +11,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -21,7 +23,9 @@ import (
 	"catalog-api/internal/catalog"
 	httpapi "catalog-api/internal/http"
 	"catalog-api/internal/identity"
+	"catalog-api/internal/storage/memory"
 	"catalog-api/internal/storage/postgres"
+	"catalog-api/internal/webhook"
 	"catalog-api/internal/ws"
 	"catalog-api/pkg/config"
 	"catalog-api/pkg/crypto"
@@ -35,8 +39,10 @@ import (
 // App encapsula las dependencias principales de la aplicacion.
 type App struct {
 	DB       *pgxpool.Pool
+	Replica  *pgxpool.Pool // nil sin DATABASE_REPLICA_URL
 	Router   *http.Server
 	WSHub    *ws.Hub
+	Mailer   identity.VerificationSender
 	HTTPPort string
 	Logr     *slog.Logger
 }
@@ -49,22 +55,53 @@ func bootstrap(ctx context.Context, cfg config.Config, logr *slog.Logger) (*App,
 		return nil, err
 	}
 
+	replicaPool, err := initReplicaDB(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	wsHub := ws.NewHub(cfg.WSAllowedOrigins, logr)
 
-	verificationSender := initVerificationSender(cfg, logr)
+	verificationSender, err := initVerificationSender(cfg, logr)
+	if err != nil {
+		return nil, err
+	}
 	jwtProvider := buildJWTProvider(cfg)
-	idService, catService, err := initServices(dbPool, verificationSender, jwtProvider)
+	catalogRepo := initCatalogRepository(cfg, dbPool, replicaPool, logr)
+	idService, catService, err := initServices(cfg, dbPool, catalogRepo, verificationSender, jwtProvider, logr)
 	if err != nil {
 		return nil, err
 	}
 	seedAdmin(ctx, idService, cfg, logr)
 
-	router := initHTTPServer(cfg, wsHub, jwtProvider, idService, catService)
+	// el janitor vive mientras ctx; se detiene con el cancel del shutdown.
+	janitor := identity.NewCodeJanitor(postgres.NewIdentityRepository(dbPool), cfg.VerificationCleanupInterval, logr)
+	go janitor.Run(ctx)
+	historyJanitor := catalog.NewHistoryJanitor(catalogRepo, cfg.ProductHistoryRetention, cfg.ProductHistoryKeep, cfg.ProductHistoryPruneInterval, logr)
+	go historyJanitor.Run(ctx)
+
+	webhookRepo := postgres.NewWebhookRepository(dbPool)
+	dispatcher := webhook.NewDispatcher(webhookRepo, webhookRepo, webhook.DispatcherConfig{
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		Backoff:      cfg.WebhookRetryBackoff,
+		Timeout:      cfg.WebhookTimeout,
+		PollInterval: cfg.WebhookPollInterval,
+	}, logr)
+	go dispatcher.Run(ctx)
+	webhookService := webhook.NewService(webhook.ServiceDeps{
+		Repo:     webhookRepo,
+		Events:   httpapi.CatalogEventNames(),
+		OnChange: dispatcher.InvalidateSubscriptions,
+	})
+
+	router := initHTTPServer(cfg, wsHub, jwtProvider, idService, catService, webhookService, dispatcher, verificationSender, logr)
 
 	return &App{
 		DB:       dbPool,
+		Replica:  replicaPool,
 		Router:   router,
 		WSHub:    wsHub,
+		Mailer:   verificationSender,
 		HTTPPort: cfg.HTTPPort,
 		Logr:     logr,
 	}, nil
@@ -90,6 +127,9 @@ func main() {
 		os.Exit(1)
 	}
 	defer app.DB.Close()
+	if app.Replica != nil {
+		defer app.Replica.Close()
+	}
 
 	if app.WSHub != nil {
 		go app.WSHub.Run(ctx)
@@ -111,6 +151,10 @@ func main() {
 	if err := app.Router.Shutdown(shutdownCtx); err != nil {
 		logr.Error("graceful shutdown failed", "error", err)
 	}
+	// cierra las conexiones SMTP que el pool mantiene abiertas.
+	if closer, ok := app.Mailer.(io.Closer); ok {
+		_ = closer.Close()
+	}
 	cancel()
 }
 
@@ -123,40 +167,118 @@ func initDB(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
 	return pgxpool.New(ctx, cfg.DatabaseURL)
 }
 
-func initVerificationSender(cfg config.Config, logr *slog.Logger) identity.VerificationSender {
-	if smtpSender := mailer.NewMailVerificationSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From, cfg.SMTP.SkipTLS); smtpSender != nil {
-		return smtpSender
+// initReplicaDB abre el pool de la replica de lectura; sin URL devuelve nil.
+func initReplicaDB(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
+	if cfg.DatabaseReplicaURL == "" || cfg.Storage == config.StorageMemory {
+		return nil, nil
+	}
+	return pgxpool.New(ctx, cfg.DatabaseReplicaURL)
+}
+
+func initVerificationSender(cfg config.Config, logr *slog.Logger) (identity.VerificationSender, error) {
+	opts := []mailer.Option{
+		mailer.WithMessageIDDomain(cfg.SMTP.MessageIDDomain),
+		mailer.WithPool(cfg.SMTP.PoolSize, cfg.SMTP.PoolIdleTimeout),
+	}
+	if cfg.SMTP.CABundle != "" {
+		pool, err := mailer.LoadCABundle(cfg.SMTP.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, mailer.WithRootCAs(pool))
+	}
+	if cfg.SMTP.SkipTLS {
+		logr.Warn("SMTP TLS disabled; use SMTP_TLS_SKIP_VERIFY only in development")
+	}
+	if smtpSender := mailer.NewMailVerificationSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From, cfg.SMTP.SkipTLS, opts...); smtpSender != nil {
+		return smtpSender, nil
+	}
+	if cfg.RequireVerificationSender {
+		return nil, errors.New("SMTP is not configured and REQUIRE_VERIFICATION_SENDER is set")
 	}
 	logr.Warn("SMTP not configured; falling back to noop verification sender")
-	return &mailer.NoopVerificationSender{Logr: logr}
+	if cfg.DevExposeVerificationCodes {
+		logr.Warn("DEV_EXPOSE_VERIFICATION_CODES enabled: verification codes are logged at debug level; never use in production")
+	}
+	return &mailer.NoopVerificationSender{Logr: logr, ExposeCodes: cfg.DevExposeVerificationCodes}, nil
 }
 
 func buildJWTProvider(cfg config.Config) crypto.JWTProvider {
 	return crypto.JWTProvider{
-		Secret: cfg.JWTSecret,
-		Issuer: cfg.JWTIssuer,
-		TTL:    cfg.JWTTTL,
+		Secret:  cfg.JWTSecret,
+		Issuer:  cfg.JWTIssuer,
+		TTL:     cfg.JWTTTL,
+		RoleTTL: cfg.JWTRoleTTLs,
 	}
 }
 
-func initServices(dbPool *pgxpool.Pool, verificationSender identity.VerificationSender, jwtProvider crypto.JWTProvider) (identity.Service, catalog.Service, error) {
+// catalogRepository agrupa los contratos que cumple cada backend de catalogo.
+type catalogRepository interface {
+	catalog.CategoryRepository
+	catalog.ProductRepository
+	catalog.ProductHistoryPruner
+	catalog.PriceChangeRepository
+}
+
+func initCatalogRepository(cfg config.Config, dbPool, replicaPool *pgxpool.Pool, logr *slog.Logger) catalogRepository {
+	if cfg.Storage == config.StorageMemory {
+		logr.Warn("using in-memory catalog storage; data is lost on restart")
+		return memory.NewCatalogRepository()
+	}
+	options := []postgres.CatalogOption{postgres.WithIDCollisionRetries(cfg.IDCollisionRetries, logr)}
+	if replicaPool != nil {
+		logr.Info("catalog reads routed to read replica")
+		options = append(options, postgres.WithReadReplica(replicaPool))
+	}
+	return postgres.NewCatalogRepository(dbPool, options...)
+}
+
+func initServices(cfg config.Config, dbPool *pgxpool.Pool, catalogRepo catalogRepository, verificationSender identity.VerificationSender, jwtProvider crypto.JWTProvider, logr *slog.Logger) (identity.Service, catalog.Service, error) {
 	identityRepo := postgres.NewIdentityRepository(dbPool)
-	catalogRepo := postgres.NewCatalogRepository(dbPool)
 
-	codeGenerator := crypto.RandomDigitsGenerator{Length: 6}
+	var codeGenerator identity.VerificationCodeGenerator = crypto.RandomDigitsGenerator{Length: cfg.VerificationCode.Length}
+	if cfg.VerificationCode.Format == config.CodeFormatAlphanumeric {
+		codeGenerator = crypto.AlphaNumericGenerator{Length: cfg.VerificationCode.Length, Alphabet: cfg.VerificationCode.Alphabet}
+	}
 
-	idService := identity.NewService(identity.ServiceDeps{
-		UserRepo:                 identityRepo,
-		RoleRepo:                 identityRepo,
-		PasswordHasher:           crypto.BcryptHasher{},
-		VerificationSender:       verificationSender,
-		VerificationCodeProvider: codeGenerator,
-		TokenProvider:            jwtProvider,
-	})
+	deps := identity.ServiceDeps{
+		UserRepo:                  identityRepo,
+		RoleRepo:                  identityRepo,
+		PasswordHasher:            crypto.BcryptHasher{},
+		VerificationSender:        verificationSender,
+		VerificationCodeProvider:  codeGenerator,
+		TokenProvider:             jwtProvider,
+		SessionRepo:               identityRepo,
+		SessionTTL:                cfg.RefreshTokenTTL,
+		RememberMeTTL:             cfg.RememberMeTTL,
+		UsernameLogin:             cfg.UsernameLogin,
+		RequireVerificationSender: cfg.RequireVerificationSender,
+	}
+	if cfg.NewDeviceAlerts {
+		notifier, ok := verificationSender.(identity.LoginNotifier)
+		if ok {
+			deps.DeviceRepo = identityRepo
+			deps.LoginNotifier = notifier
+		} else {
+			logr.Warn("NEW_DEVICE_ALERTS enabled but the configured sender cannot send login notifications")
+		}
+	}
+	idService := identity.NewService(deps)
 
 	catService, err := catalog.NewService(catalog.ServiceDeps{
-		CategoryRepo: catalogRepo,
-		ProductRepo:  catalogRepo,
+		CategoryRepo:       catalogRepo,
+		ProductRepo:        catalogRepo,
+		DefaultCurrency:    cfg.DefaultCurrency,
+		LowStockThreshold:  cfg.LowStockThreshold,
+		SearchDefaultLimit: cfg.SearchDefaultLimit,
+		// el repo siempre se pasa: con umbral cero igual se pueden revisar solicitudes previas.
+		PriceApprovalThreshold:  cfg.PriceApprovalThreshold,
+		PriceChangeRepo:         catalogRepo,
+		DescriptionPolicy:       catalog.DescriptionPolicy(cfg.ProductDescriptionPolicy),
+		DefaultProductPrice:     cfg.ProductDefaultPrice,
+		DefaultProductStock:     cfg.ProductDefaultStock,
+		PublishRequiresCategory: cfg.PublishRequiresCategory,
+		LowStockAlertThreshold:  cfg.LowStockAlertThreshold,
 	})
 	if err != nil {
 		return nil, nil, err
@@ -166,30 +288,107 @@ func initServices(dbPool *pgxpool.Pool, verificationSender identity.Verification
 }
 
 func seedAdmin(ctx context.Context, idService identity.Service, cfg config.Config, logr *slog.Logger) {
-	if err := idService.SeedAdmin(ctx, identity.AdminSeedInput{
-		Email:    cfg.AdminSeed.Email,
-		Password: cfg.AdminSeed.Password,
-		FullName: cfg.AdminSeed.FullName,
-	}); err != nil {
-		logr.Warn("admin seed skipped", "error", err)
+	// SeedAdmin es idempotente: los admins existentes se saltean.
+	for _, seed := range cfg.AdminSeeds {
+		if err := idService.SeedAdmin(ctx, identity.AdminSeedInput{
+			Email:    seed.Email,
+			Password: seed.Password,
+			FullName: seed.FullName,
+		}); err != nil {
+			logr.Warn("admin seed skipped", "email", seed.Email, "error", err)
+		}
+	}
+}
+
+// sameSiteMode traduce el valor ya validado de AUTH_COOKIE_SAMESITE.
+func sameSiteMode(v string) http.SameSite {
+	switch v {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
 	}
 }
 
-func initHTTPServer(cfg config.Config, wsHub *ws.Hub, jwtProvider crypto.JWTProvider, idService identity.Service, catService catalog.Service) *http.Server {
-	eventEmitter := httpapi.NewSocketEmitter(wsHub)
+func initHTTPServer(cfg config.Config, wsHub *ws.Hub, jwtProvider crypto.JWTProvider, idService identity.Service, catService catalog.Service, webhookService webhook.Service, dispatcher *webhook.Dispatcher, sender identity.VerificationSender, logr *slog.Logger) *http.Server {
+	eventEmitter := httpapi.NewMultiEmitter(httpapi.NewSocketEmitter(wsHub), httpapi.NewWebhookEmitter(dispatcher))
 	catalogHandler := httpapi.NewCatalogHandler(catService, eventEmitter)
-	identityHandler := httpapi.NewIdentityHandler(idService)
+	// la cookie dura lo que el token mas largo posible; cada JWT controla su propia expiracion.
+	identityHandler := httpapi.NewIdentityHandler(idService, httpapi.WithAuthCookie(httpapi.AuthCookieConfig{
+		Always:   cfg.AuthCookie.Always,
+		Secure:   cfg.AuthCookie.Secure,
+		SameSite: sameSiteMode(cfg.AuthCookie.SameSite),
+		Domain:   cfg.AuthCookie.Domain,
+		MaxAge:   cfg.LongestJWTTTL(),
+	}))
+
+	var schemas *httpapi.SchemaValidator
+	if cfg.JSONSchemaValidation {
+		// los schemas van embebidos, asi que un error aca es un schema roto en el build.
+		v, err := httpapi.NewSchemaValidator()
+		if err != nil {
+			logr.Error("JSON_SCHEMA_VALIDATION disabled: invalid embedded schema", "error", err)
+		} else {
+			schemas = v
+		}
+	}
 
+	if cfg.DebugErrors {
+		logr.Warn("DEBUG_ERRORS enabled: 500 responses include internal error details; never use in production")
+	}
+
+	var smtpTestHandler *httpapi.SMTPTestHandler
+	if testSender, ok := sender.(httpapi.TestEmailSender); ok {
+		smtpTestHandler = httpapi.NewSMTPTestHandler(testSender)
+	}
+
+	cors := httpapi.CORSConfig{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}
 	routerFactory := &httpapi.RouterFactory{
-		CatalogHandler:  catalogHandler,
-		IdentityHandler: identityHandler,
-		WSHub:           wsHub,
-		TokenValidator:  httpapi.JWTValidatorAdapter{Provider: jwtProvider},
+		CatalogHandler:          catalogHandler,
+		WebhookHandler:          httpapi.NewWebhookHandler(webhookService),
+		StatsHandler:            httpapi.NewStatsHandler(catService, idService, cfg.AdminStatsCacheTTL),
+		SMTPTestHandler:         smtpTestHandler,
+		IdentityHandler:         identityHandler,
+		WSHub:                   wsHub,
+		TokenValidator:          httpapi.JWTValidatorAdapter{Provider: jwtProvider},
+		WSAllowAnonymous:        cfg.WSAllowAnonymous,
+		StrictJSON:              cfg.StrictJSON,
+		UUIDParams:              cfg.IDFormat != config.IDFormatFree,
+		Schemas:                 schemas,
+		LocaleHeader:            cfg.LocaleHeader,
+		DefaultLocale:           cfg.DefaultLocale,
+		Int64AsString:           cfg.JSONInt64AsString,
+		Compression:             cfg.Compression,
+		CompressionMinSize:      cfg.CompressionMinSize,
+		CacheControl:            cfg.CacheControl,
+		Storefront:              cfg.Storefront,
+		GlobalRateLimit:         cfg.GlobalRateLimit,
+		GlobalRateBurst:         cfg.GlobalRateBurst,
+		LoginRatePerMin:         cfg.LoginRatePerMin,
+		LoginBurst:              cfg.LoginBurst,
+		RegistrationRatePerHour: cfg.RegistrationRatePerHour,
+		RegistrationBurst:       cfg.RegistrationBurst,
+		DebugErrors:             cfg.DebugErrors,
+		CORS:                    cors,
+		AllowedContentTypes:     cfg.AllowedContentTypes,
+		MaxPageOffset:           cfg.MaxPageOffset,
+		SlowRequestThreshold:    cfg.SlowRequestThreshold,
+		Logr:                    logr,
 	}
 
 	router := routerFactory.Build()
-	return &http.Server{
-		Addr:    ":" + cfg.HTTPPort,
-		Handler: router,
-	}
+	return httpapi.NewServer(httpapi.ServerConfig{
+		Addr:              ":" + cfg.HTTPPort,
+		ReadTimeout:       cfg.HTTPServer.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPServer.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPServer.WriteTimeout,
+		IdleTimeout:       cfg.HTTPServer.IdleTimeout,
+		KeepAlive:         cfg.HTTPServer.KeepAlive,
+	}, router)
 }
@@ -0,0 +1,39 @@
+package catalog
+
+import "strings"
+
+// NormalizeBarcode valida un EAN-13 o UPC-A (con digito verificador) y lo devuelve
+// como EAN-13: un UPC-A de 12 digitos es el mismo codigo con un 0 adelante, asi que
+// ambas formas colisionan en el indice unico. Vacio significa sin codigo.
+func NormalizeBarcode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "", nil
+	}
+	if len(code) == 12 {
+		code = "0" + code
+	}
+	if len(code) != 13 {
+		return "", ErrInvalidBarcode
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		d := code[i]
+		if d < '0' || d > '9' {
+			return "", ErrInvalidBarcode
+		}
+		if i == 12 {
+			break
+		}
+		// EAN-13 pondera con 1 las posiciones pares y con 3 las impares (desde 0).
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+	if check := (10 - sum%10) % 10; int(code[12]-'0') != check {
+		return "", ErrInvalidBarcode
+	}
+	return code, nil
+}
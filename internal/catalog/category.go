@@ -6,7 +6,12 @@ import "time"
 type Category struct {
 	ID          string
 	Name        string
+	Slug        string // derivado del nombre al crear; estable ante renombres
 	Description string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	// DisplayOrder es la posicion elegida por un admin (1..n); 0 significa sin orden
+	// explicito y esas categorias se listan despues, por nombre.
+	DisplayOrder int
+	Snippet      string // fragmento resaltado, solo en busquedas con highlight
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
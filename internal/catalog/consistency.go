@@ -0,0 +1,17 @@
+package catalog
+
+import "context"
+
+type primaryReadKey struct{}
+
+// WithPrimaryRead pide que las lecturas hechas con ctx vayan al primario aunque
+// haya replica: sirve para leer-modificar-escribir sin depender del lag.
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+// PrimaryRead indica si ctx fue marcado con WithPrimaryRead.
+func PrimaryRead(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryReadKey{}).(bool)
+	return v
+}
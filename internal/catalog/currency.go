@@ -0,0 +1,23 @@
+package catalog
+
+import (
+	"strings"
+
+	"golang.org/x/text/currency"
+)
+
+// DefaultCurrency se usa cuando no se configura una moneda por defecto.
+const DefaultCurrency = "USD"
+
+// NormalizeCurrency valida un codigo ISO 4217 y lo devuelve en mayusculas.
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return "", ErrInvalidCurrency
+	}
+	return unit.String(), nil
+}
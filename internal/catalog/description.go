@@ -0,0 +1,51 @@
+package catalog
+
+import "github.com/microcosm-cc/bluemonday"
+
+// DescriptionPolicy define como se sanea la descripcion de un producto al guardarla.
+type DescriptionPolicy string
+
+const (
+	// DescriptionPlain elimina todo el HTML y escapa el texto restante (default).
+	DescriptionPlain DescriptionPolicy = "plain"
+	// DescriptionBasic conserva formato basico (negrita, listas, enlaces) y quita el resto.
+	DescriptionBasic DescriptionPolicy = "basic"
+	// DescriptionRaw guarda la descripcion tal cual; solo para clientes que no la renderizan como HTML.
+	DescriptionRaw DescriptionPolicy = "raw"
+)
+
+// Valid indica si la politica es una de las soportadas.
+func (p DescriptionPolicy) Valid() bool {
+	switch p {
+	case DescriptionPlain, DescriptionBasic, DescriptionRaw:
+		return true
+	}
+	return false
+}
+
+var (
+	plainDescription = bluemonday.StrictPolicy()
+	basicDescription = basicDescriptionPolicy()
+)
+
+func basicDescriptionPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "b", "strong", "i", "em", "u", "ul", "ol", "li")
+	p.AllowStandardURLs()
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	return p
+}
+
+// SanitizeDescription aplica la politica a una descripcion; scripts y atributos de
+// eventos nunca sobreviven salvo con DescriptionRaw.
+func SanitizeDescription(policy DescriptionPolicy, description string) string {
+	switch policy {
+	case DescriptionRaw:
+		return description
+	case DescriptionBasic:
+		return basicDescription.Sanitize(description)
+	default:
+		return plainDescription.Sanitize(description)
+	}
+}
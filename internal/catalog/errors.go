@@ -1,13 +1,89 @@
 package catalog
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrNotImplemented          = errors.New("not implemented")
 	ErrRepositoryNotConfigured = errors.New("repository not configured")
 	ErrInvalidCategory         = errors.New("invalid category")
 	ErrInvalidCategoryID       = errors.New("invalid category id")
+	ErrInvalidSlug             = errors.New("invalid slug")
+	ErrInvalidCategoryOrder    = errors.New("category order must list each id once")
 	ErrInvalidProduct          = errors.New("invalid product")
 	ErrInvalidProductID        = errors.New("invalid product id")
 	ErrInvalidSearchKind       = errors.New("invalid search kind")
+	ErrInvalidCurrency         = errors.New("invalid currency")
+	ErrInvalidBarcode          = errors.New("barcode must be a valid EAN-13 or UPC-A")
+	ErrDuplicateBarcode        = errors.New("barcode already assigned to another product")
+	ErrInvalidStockFilter      = errors.New("stock filter must be out, in or low")
+	ErrInvalidPriceRange       = errors.New("min_price and max_price must be non-negative and min_price must not exceed max_price")
+	ErrInvalidStockAdjustment  = errors.New("invalid stock adjustment")
+	ErrInsufficientStock       = errors.New("stock cannot go negative")
+	ErrCategoryConflict        = errors.New("category name already exists")
+	ErrCategoryNotFound        = errors.New("category not found")
+	ErrProductNotFound         = errors.New("product not found")
+	ErrProductHistoryNotFound  = errors.New("product history not found")
+	ErrProductIncomplete       = errors.New("product is missing required fields to publish")
+	ErrPriceChangePending      = errors.New("price change pending approval")
+	ErrPriceChangeNotFound     = errors.New("price change request not found")
+	ErrPriceChangeDecided      = errors.New("price change request already decided")
+	ErrPriceChangeStale        = errors.New("product price changed since the request was created")
+	ErrInvalidPriceChangeState = errors.New("price change status must be pending, approved or rejected")
 )
+
+// BulkCategoryError indica que item del lote hizo fallar la operacion completa.
+type BulkCategoryError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkCategoryError) Error() string {
+	return fmt.Sprintf("category at index %d: %v", e.Index, e.Err)
+}
+
+func (e *BulkCategoryError) Unwrap() error {
+	return e.Err
+}
+
+// BulkProductError lista cada producto que hizo rechazar el lote; no se inserto ninguno.
+type BulkProductError struct {
+	Items []BulkItemError
+}
+
+// BulkItemError es el error de un item del lote, por su posicion.
+type BulkItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkProductError) Error() string {
+	first := e.Items[0]
+	return fmt.Sprintf("%d invalid products in batch, first at index %d: %v", len(e.Items), first.Index, first.Err)
+}
+
+// Unwrap expone los errores de todos los items para errors.Is.
+func (e *BulkProductError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Items))
+	for _, item := range e.Items {
+		errs = append(errs, item.Err)
+	}
+	return errs
+}
+
+// BulkStockError indica que ajuste del lote hizo revertir todo el ajuste masivo.
+type BulkStockError struct {
+	Index     int
+	ProductID string
+	Err       error
+}
+
+func (e *BulkStockError) Error() string {
+	return fmt.Sprintf("stock adjustment at index %d (product %s): %v", e.Index, e.ProductID, e.Err)
+}
+
+func (e *BulkStockError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,66 @@
+package catalog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DefaultHistoryKeep es la cantidad minima de entradas por producto que sobreviven a la poda.
+const DefaultHistoryKeep = 10
+
+// ProductHistoryPruner borra historial viejo respetando un piso por producto.
+type ProductHistoryPruner interface {
+	// PruneProductHistory elimina entradas anteriores a olderThan salvo las keep mas
+	// recientes de cada producto; devuelve la cantidad borrada.
+	PruneProductHistory(ctx context.Context, olderThan time.Time, keep int) (int64, error)
+}
+
+// HistoryJanitor poda periodicamente product_history segun la retencion configurada.
+type HistoryJanitor struct {
+	pruner    ProductHistoryPruner
+	retention time.Duration
+	keep      int
+	interval  time.Duration
+	logr      *slog.Logger
+}
+
+// NewHistoryJanitor construye un janitor; retention o interval <= 0 lo dejan deshabilitado.
+// keep negativo se trata como 0.
+func NewHistoryJanitor(pruner ProductHistoryPruner, retention time.Duration, keep int, interval time.Duration, logr *slog.Logger) *HistoryJanitor {
+	if logr == nil {
+		logr = slog.Default()
+	}
+	if keep < 0 {
+		keep = 0
+	}
+	return &HistoryJanitor{pruner: pruner, retention: retention, keep: keep, interval: interval, logr: logr}
+}
+
+// Run bloquea hasta que ctx se cancele, podando en cada tick.
+func (j *HistoryJanitor) Run(ctx context.Context) {
+	if j.pruner == nil || j.retention <= 0 || j.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep ejecuta una pasada de poda y devuelve la cantidad de entradas borradas.
+func (j *HistoryJanitor) Sweep(ctx context.Context) int64 {
+	removed, err := j.pruner.PruneProductHistory(ctx, time.Now().Add(-j.retention), j.keep)
+	if err != nil {
+		j.logr.Warn("product history prune failed", "error", err)
+		return 0
+	}
+	j.logr.Info("product history pruned", "count", removed, "retention", j.retention, "keep", j.keep)
+	return removed
+}
@@ -0,0 +1,41 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubPruner struct {
+	removed   int64
+	err       error
+	olderThan time.Time
+	keep      int
+}
+
+func (s *stubPruner) PruneProductHistory(ctx context.Context, olderThan time.Time, keep int) (int64, error) {
+	s.olderThan = olderThan
+	s.keep = keep
+	return s.removed, s.err
+}
+
+func TestHistoryJanitor_SweepUsesRetentionAndKeep(t *testing.T) {
+	pruner := &stubPruner{removed: 7}
+	j := NewHistoryJanitor(pruner, 24*time.Hour, 3, time.Hour, nil)
+	before := time.Now().Add(-24 * time.Hour)
+	if got := j.Sweep(context.Background()); got != 7 {
+		t.Fatalf("expected 7 removed, got %d", got)
+	}
+	if pruner.keep != 3 {
+		t.Fatalf("expected keep 3, got %d", pruner.keep)
+	}
+	if pruner.olderThan.Before(before) || pruner.olderThan.After(time.Now().Add(-24*time.Hour)) {
+		t.Fatalf("unexpected cutoff %v", pruner.olderThan)
+	}
+
+	pruner.err = errors.New("db down")
+	if got := j.Sweep(context.Background()); got != 0 {
+		t.Fatalf("expected 0 on error, got %d", got)
+	}
+}
@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"context"
+	"time"
+)
+
+// PriceChangeStatus es el estado de una solicitud de cambio de precio.
+type PriceChangeStatus string
+
+const (
+	PriceChangePending  PriceChangeStatus = "pending"
+	PriceChangeApproved PriceChangeStatus = "approved"
+	PriceChangeRejected PriceChangeStatus = "rejected"
+)
+
+// Valid indica si el estado es uno de los soportados.
+func (s PriceChangeStatus) Valid() bool {
+	switch s {
+	case PriceChangePending, PriceChangeApproved, PriceChangeRejected:
+		return true
+	}
+	return false
+}
+
+// PriceChangeRequest es un cambio de precio que supero el umbral de aprobacion y
+// espera la decision de un admin.
+type PriceChangeRequest struct {
+	ID        string
+	ProductID string
+	OldPrice  int64
+	NewPrice  int64
+	Status    PriceChangeStatus
+	CreatedAt time.Time
+	DecidedAt time.Time // cero mientras esta pendiente
+}
+
+// PriceChangeRepository persiste las solicitudes de cambio de precio.
+type PriceChangeRepository interface {
+	// RequestPriceChange guarda p (con su precio actual) y crea req pendiente, todo o nada:
+	// si la solicitud no se puede crear, los demas campos tampoco se aplican.
+	RequestPriceChange(ctx context.Context, p Product, req PriceChangeRequest) (PriceChangeRequest, Product, error)
+	// ListPriceChangeRequests filtra por estado (vacio devuelve todas), mas recientes primero.
+	ListPriceChangeRequests(ctx context.Context, status PriceChangeStatus) ([]PriceChangeRequest, error)
+	// ApprovePriceChangeRequest aplica NewPrice, registra el historial y marca la solicitud,
+	// todo o nada. Una solicitud ya decidida devuelve ErrPriceChangeDecided y una cuyo
+	// OldPrice ya no es el precio vigente, ErrPriceChangeStale.
+	ApprovePriceChangeRequest(ctx context.Context, id string) (PriceChangeRequest, Product, error)
+	RejectPriceChangeRequest(ctx context.Context, id string) (PriceChangeRequest, error)
+}
+
+// PriceChangePendingError indica que UpdateProduct guardo el resto de los cambios pero
+// dejo el precio pendiente de aprobacion; Product tiene el precio vigente.
+type PriceChangePendingError struct {
+	Product Product
+	Request PriceChangeRequest
+}
+
+func (e *PriceChangePendingError) Error() string {
+	return ErrPriceChangePending.Error()
+}
+
+func (e *PriceChangePendingError) Unwrap() error {
+	return ErrPriceChangePending
+}
+
+// exceedsPriceThreshold indica si pasar de oldPrice a newPrice supera thresholdPercent.
+// Desde un precio cero cualquier cambio lo supera.
+func exceedsPriceThreshold(oldPrice, newPrice int64, thresholdPercent float64) bool {
+	if thresholdPercent <= 0 || oldPrice == newPrice {
+		return false
+	}
+	if oldPrice == 0 {
+		return true
+	}
+	diff := newPrice - oldPrice
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff)*100 > thresholdPercent*float64(oldPrice)
+}
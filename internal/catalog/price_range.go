@@ -0,0 +1,18 @@
+package catalog
+
+// ValidPriceRange indica si MinPrice/MaxPrice forman un rango usable.
+func (f ProductFilter) ValidPriceRange() bool {
+	if f.MinPrice != nil && *f.MinPrice < 0 || f.MaxPrice != nil && *f.MaxPrice < 0 {
+		return false
+	}
+	return f.MinPrice == nil || f.MaxPrice == nil || *f.MinPrice <= *f.MaxPrice
+}
+
+// MatchesPrice aplica el rango de precio en memoria con la misma semantica que el SQL:
+// ambos extremos son inclusivos.
+func (f ProductFilter) MatchesPrice(price int64) bool {
+	if f.MinPrice != nil && price < *f.MinPrice {
+		return false
+	}
+	return f.MaxPrice == nil || price <= *f.MaxPrice
+}
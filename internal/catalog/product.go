@@ -6,11 +6,20 @@ import "time"
 type Product struct {
 	ID          string
 	Name        string
+	Slug        string // canonico; los anteriores quedan en el historial de slugs
 	Description string
-	Price       int64 // almacenado en la unidad monetaria mas pequena
+	Price       int64  // almacenado en la unidad monetaria mas pequena
+	Currency    string // ISO 4217; vacio hereda la moneda por defecto
+	Barcode     string // EAN-13 normalizado (ver NormalizeBarcode); vacio si no tiene
 	Stock       int64
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	Draft       bool       // borrador: no se lista en la vitrina hasta publicarse
+	Snippet     string     // fragmento resaltado, solo en busquedas con highlight
+	Categories  []Category // solo en GetProductDetail; los listados no las cargan
+	// LowStockCrossed solo lo marca UpdateProduct: el stock bajo de arriba del umbral
+	// de alerta a igual o menor; no se persiste.
+	LowStockCrossed bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 // ProductHistory captura los cambios historicos de precio/stock.
@@ -0,0 +1,75 @@
+package catalog
+
+import "strings"
+
+// Tipos de busqueda aceptados por Search.
+const (
+	SearchKindProduct  = "product"
+	SearchKindCategory = "category"
+)
+
+// Campos y direcciones de ordenamiento que entienden los repositorios.
+const (
+	SortByName      = "name"
+	SortByPrice     = "price"
+	SortByStock     = "stock"
+	SortByCreatedAt = "created_at"
+	// SortByRelevance ordena por coincidencia con el query: nombre exacto, prefijo y luego
+	// contenido. Es el default de productos cuando hay query y no se pide otro orden.
+	SortByRelevance = "relevance"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Ordenamiento aplicado cuando el campo pedido no esta soportado o viene vacio.
+const (
+	DefaultProductSort     = SortByCreatedAt
+	DefaultProductSortDir  = SortDesc
+	DefaultCategorySort    = SortByName
+	DefaultCategorySortDir = SortAsc
+)
+
+// SearchKinds lista los tipos de busqueda soportados.
+func SearchKinds() []string {
+	return []string{SearchKindProduct, SearchKindCategory}
+}
+
+// ProductSortFields lista los campos por los que se pueden ordenar productos.
+func ProductSortFields() []string {
+	return []string{SortByCreatedAt, SortByName, SortByPrice, SortByStock, SortByRelevance}
+}
+
+// SortsByRelevance indica si el listado debe ordenarse por relevancia: solo con query
+// de texto, y cuando no se pidio un orden explicito (o se pidio relevance).
+func (f ProductFilter) SortsByRelevance() bool {
+	if strings.TrimSpace(f.Query) == "" {
+		return false
+	}
+	return f.SortBy == "" || f.SortBy == SortByRelevance
+}
+
+// NameRelevance puntua name contra query como lo hace el SQL: 0 nombre exacto,
+// 1 prefijo, 2 lo contiene y 3 solo coincide por descripcion. Menor es mas relevante.
+func NameRelevance(name, query string) int {
+	name, query = strings.ToLower(name), strings.ToLower(strings.TrimSpace(query))
+	switch {
+	case name == query:
+		return 0
+	case strings.HasPrefix(name, query):
+		return 1
+	case strings.Contains(name, query):
+		return 2
+	}
+	return 3
+}
+
+// CategorySortFields lista los campos por los que se pueden ordenar categorias.
+func CategorySortFields() []string {
+	return []string{SortByName, SortByCreatedAt}
+}
+
+// StockFilters lista los valores no vacios aceptados por ProductFilter.Stock.
+func StockFilters() []StockFilter {
+	return []StockFilter{StockOut, StockIn, StockLow}
+}
@@ -7,11 +7,19 @@ import (
 
 // CategoryRepository define contratos de persistencia para categorias.
 type CategoryRepository interface {
-	ListCategories(ctx context.Context) ([]Category, error)
+	ListCategories(ctx context.Context, filter CategoryFilter) ([]Category, error)
+	CountCategories(ctx context.Context) (int64, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (Category, error)
+	// CreateCategory agrega un sufijo numerico al slug si ya esta tomado.
 	CreateCategory(ctx context.Context, cat Category) (Category, error)
+	// BulkCreateCategories inserta todas las categorias o ninguna.
+	BulkCreateCategories(ctx context.Context, cats []Category) ([]Category, error)
 	UpdateCategory(ctx context.Context, cat Category) (Category, error)
 	DeleteCategory(ctx context.Context, id string) error
+	// ReorderCategories asigna rangos 1..n segun ids y deja en 0 al resto, todo o nada.
+	ReorderCategories(ctx context.Context, ids []string) error
 	SearchCategories(ctx context.Context, filter SearchFilter) ([]Category, int64, error)
+	CategoryExists(ctx context.Context, id string) (bool, error)
 }
 
 // ProductRepository define contratos de persistencia para productos.
@@ -19,30 +27,85 @@ type ProductRepository interface {
 	ListProducts(ctx context.Context, filter ProductFilter) ([]Product, error)
 	CountProducts(ctx context.Context, filter ProductFilter) (int64, error)
 	GetProduct(ctx context.Context, id string) (Product, error)
+	// GetProductDetail es GetProduct con Categories cargadas (ordenadas por nombre).
+	GetProductDetail(ctx context.Context, id string) (Product, error)
+	// GetProductBySlug resuelve slugs actuales e historicos; si p.Slug difiere del
+	// pedido, el slug buscado es uno viejo.
+	GetProductBySlug(ctx context.Context, slug string) (Product, error)
+	// GetProductByBarcode busca por el EAN-13 normalizado.
+	GetProductByBarcode(ctx context.Context, barcode string) (Product, error)
+	ProductExists(ctx context.Context, id string) (bool, error)
 	CreateProduct(ctx context.Context, p Product) (Product, error)
+	// CreateProducts inserta todo el lote o nada; un barcode ya tomado devuelve *BulkProductError.
+	CreateProducts(ctx context.Context, products []Product) ([]Product, error)
+	// CreateProduct y UpdateProduct devuelven ErrDuplicateBarcode si otro producto ya
+	// tiene p.Barcode. UpdateProduct guarda el slug anterior en el historial cuando p.Slug cambia.
 	UpdateProduct(ctx context.Context, p Product) (Product, error)
+	// DeleteProduct hace soft-delete; el producto borrado no se lee pero conserva su historial.
 	DeleteProduct(ctx context.Context, id string) error
+	// RestoreProduct deshace el soft-delete; restored es false si el producto no estaba borrado.
+	RestoreProduct(ctx context.Context, id string) (p Product, restored bool, err error)
+	// PublishProduct quita la marca de borrador; published es false si ya estaba publicado.
+	PublishProduct(ctx context.Context, id string) (p Product, published bool, err error)
+	// BulkAdjustStock aplica todos los ajustes y su historial o ninguno; un producto
+	// inexistente o con stock resultante negativo devuelve *BulkStockError.
+	BulkAdjustStock(ctx context.Context, adjustments []StockAdjustment) ([]Product, error)
 	ListProductHistory(ctx context.Context, id string, filter ProductHistoryFilter) ([]ProductHistory, error)
+	// GetLatestProductHistory devuelve ErrProductHistoryNotFound si el producto no tiene cambios.
+	GetLatestProductHistory(ctx context.Context, id string) (ProductHistory, error)
 	AssignProductCategory(ctx context.Context, productID, categoryID string) error
+	// UnassignProductCategory quita la relacion; removed es false si no existia.
+	UnassignProductCategory(ctx context.Context, productID, categoryID string) (removed bool, err error)
+	// ListProductCategories devuelve ErrProductNotFound si el producto no existe.
+	ListProductCategories(ctx context.Context, productID string) ([]Category, error)
+}
+
+// CategoryFilter soporta paginacion opcional; Limit 0 devuelve todas las categorias.
+type CategoryFilter struct {
+	Limit  int
+	Offset int
 }
 
 // ProductFilter soporta paginacion y futuros filtros.
 type ProductFilter struct {
-	Query   string
-	Limit   int
-	Offset  int
-	SortBy  string
-	SortDir string
+	Query     string
+	Limit     int
+	Offset    int
+	SortBy    string
+	SortDir   string
+	Highlight bool
+	Stock     StockFilter
+	// LowStockThreshold lo completa el servicio; solo aplica con Stock=low.
+	LowStockThreshold int
+	// CategoryID limita a productos asignados a esa categoria; vacio no filtra.
+	CategoryID string
+	// ExcludeDrafts oculta los borradores (listados de vitrina).
+	ExcludeDrafts bool
+	// MinPrice y MaxPrice acotan el precio (inclusivos); nil no filtra.
+	MinPrice *int64
+	MaxPrice *int64
+	// SkipCount evita el COUNT(*): el servicio pide una fila extra y el total
+	// devuelto solo es exacto en la ultima pagina (alcanza para derivar has_more).
+	SkipCount bool
 }
 
 // SearchFilter supports combined search for products or categories.
 type SearchFilter struct {
-	Kind    string // "product" o "category"
+	Kind    string // SearchKindProduct o SearchKindCategory
 	Query   string
 	Limit   int
 	Offset  int
 	SortBy  string
 	SortDir string
+	// Highlight pide un fragmento resaltado por resultado; tiene costo de CPU.
+	Highlight bool
+	// Stock filtra productos por disponibilidad; no aplica a categorias.
+	Stock StockFilter
+	// ExcludeDrafts oculta productos borrador; no aplica a categorias.
+	ExcludeDrafts bool
+	// MinPrice y MaxPrice acotan el precio de productos; no aplican a categorias.
+	MinPrice *int64
+	MaxPrice *int64
 }
 
 // ProductHistoryFilter filtra consultas de historial.
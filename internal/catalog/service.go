@@ -1,21 +1,46 @@
 package catalog
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 // Service expone casos de uso del catalogo.
 type Service interface {
-	ListCategories(ctx context.Context) ([]Category, error)
+	ListCategories(ctx context.Context, filter CategoryFilter) ([]Category, int64, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (Category, error)
 	CreateCategory(ctx context.Context, input CreateCategoryInput) (Category, error)
+	BulkCreateCategories(ctx context.Context, inputs []CreateCategoryInput) ([]Category, error)
 	UpdateCategory(ctx context.Context, input UpdateCategoryInput) (Category, error)
 	DeleteCategory(ctx context.Context, id string) error
+	ReorderCategories(ctx context.Context, ids []string) error
+	CategoryExists(ctx context.Context, id string) (bool, error)
 	ListProducts(ctx context.Context, filter ProductFilter) ([]Product, int64, error)
 	GetProduct(ctx context.Context, id string) (Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (Product, error)
+	GetProductByBarcode(ctx context.Context, barcode string) (Product, error)
+	ProductExists(ctx context.Context, id string) (bool, error)
 	CreateProduct(ctx context.Context, input CreateProductInput) (Product, error)
+	// CreateProducts valida todo el lote antes de insertar; los items invalidos vuelven
+	// juntos en *BulkProductError.
+	CreateProducts(ctx context.Context, inputs []CreateProductInput) ([]Product, error)
 	UpdateProduct(ctx context.Context, input UpdateProductInput) (Product, error)
 	DeleteProduct(ctx context.Context, id string) error
+	RestoreProduct(ctx context.Context, id string) (Product, bool, error)
+	PublishProduct(ctx context.Context, id string) (Product, bool, error)
+	BulkAdjustStock(ctx context.Context, adjustments []StockAdjustment) ([]Product, error)
 	Search(ctx context.Context, filter SearchFilter) (SearchResult, error)
 	GetProductHistory(ctx context.Context, id string, filter ProductHistoryFilter) ([]ProductHistory, error)
+	GetLatestProductHistory(ctx context.Context, id string) (ProductHistory, error)
 	AssignProductCategory(ctx context.Context, productID, categoryID string) error
+	// UnassignProductCategory es idempotente; removed es false si no estaban relacionados.
+	UnassignProductCategory(ctx context.Context, productID, categoryID string) (removed bool, err error)
+	ListProductCategories(ctx context.Context, productID string) ([]Category, error)
+	Stats(ctx context.Context) (Stats, error)
+	ListPriceChangeRequests(ctx context.Context, status PriceChangeStatus) ([]PriceChangeRequest, error)
+	ApprovePriceChange(ctx context.Context, id string) (PriceChangeRequest, Product, error)
+	RejectPriceChange(ctx context.Context, id string) (PriceChangeRequest, error)
 }
 
 // CreateCategoryInput encapsula campos de creacion.
@@ -25,11 +50,16 @@ type CreateCategoryInput struct {
 }
 
 // CreateProductInput encapsula campos para crear producto.
+// Price y Stock nil toman DefaultProductPrice/DefaultProductStock de ServiceDeps,
+// asi se puede crear un borrador solo con nombre.
 type CreateProductInput struct {
 	Name        string
 	Description string
-	Price       int64
-	Stock       int64
+	Price       *int64
+	Currency    string
+	Barcode     string // opcional, EAN-13 o UPC-A
+	Stock       *int64
+	Draft       bool // se publica luego con PublishProduct
 }
 
 // UpdateCategoryInput encapsula campos de actualizacion de categoria.
@@ -40,12 +70,16 @@ type UpdateCategoryInput struct {
 }
 
 // UpdateProductInput encapsula campos de actualizacion de producto.
+// Los campos nil conservan el valor actual, asi un cliente que omite price o
+// stock no los pisa con cero.
 type UpdateProductInput struct {
 	ID          string
-	Name        string
-	Description string
-	Price       int64
-	Stock       int64
+	Name        *string
+	Description *string
+	Price       *int64
+	Currency    *string
+	Barcode     *string // "" quita el codigo
+	Stock       *int64
 }
 
 // SearchResult envuelve las respuestas de busqueda.
@@ -53,14 +87,50 @@ type SearchResult struct {
 	Products   []Product
 	Categories []Category
 	Total      int64
+	// Limit y Offset son los efectivos, despues de aplicar defaults y tope.
+	Limit  int
+	Offset int
+}
+
+// Stats resume el tamano del catalogo para el panel de administracion.
+type Stats struct {
+	Products           int64
+	OutOfStockProducts int64
+	Categories         int64
 }
 
 // ServiceDeps cablea las dependencias en el servicio de catalogo.
 type ServiceDeps struct {
-	CategoryRepo CategoryRepository
-	ProductRepo  ProductRepository
+	CategoryRepo    CategoryRepository
+	ProductRepo     ProductRepository
+	DefaultCurrency string // vacio usa DefaultCurrency
+	// LowStockThreshold es el limite de ?stock=low; cero usa DefaultLowStockThreshold.
+	LowStockThreshold int
+	// SearchDefaultLimit es el limite de Search cuando no se pide uno; cero usa DefaultPageLimit.
+	// Suele ser menor que el del listado porque alimenta autocompletados.
+	SearchDefaultLimit int
+	// PriceApprovalThreshold es el porcentaje de cambio de precio a partir del cual
+	// UpdateProduct crea una solicitud pendiente en PriceChangeRepo; cero deshabilita.
+	PriceApprovalThreshold float64
+	PriceChangeRepo        PriceChangeRepository
+	// DescriptionPolicy sanea la descripcion de productos al crear/editar; vacio usa DescriptionPlain.
+	DescriptionPolicy DescriptionPolicy
+	// DefaultProductPrice y DefaultProductStock se usan al crear un producto sin price/stock.
+	DefaultProductPrice int64
+	DefaultProductStock int64
+	// PublishRequiresCategory exige al menos una categoria para publicar un borrador.
+	PublishRequiresCategory bool
+	// LowStockAlertThreshold marca Product.LowStockCrossed cuando UpdateProduct deja el
+	// stock en o por debajo de este valor viniendo de arriba; cero deshabilita.
+	LowStockAlertThreshold int64
 }
 
+// Limites de paginacion compartidos con la capa HTTP.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
 type service struct {
 	deps ServiceDeps
 }
@@ -70,11 +140,64 @@ func NewService(deps ServiceDeps) (Service, error) {
 	if deps.CategoryRepo == nil || deps.ProductRepo == nil {
 		return nil, ErrRepositoryNotConfigured
 	}
+	if deps.DefaultCurrency == "" {
+		deps.DefaultCurrency = DefaultCurrency
+	}
+	code, err := NormalizeCurrency(deps.DefaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	deps.DefaultCurrency = code
+	if deps.LowStockThreshold <= 0 {
+		deps.LowStockThreshold = DefaultLowStockThreshold
+	}
+	if deps.SearchDefaultLimit == 0 {
+		deps.SearchDefaultLimit = DefaultPageLimit
+	}
+	if deps.SearchDefaultLimit < 0 || deps.SearchDefaultLimit > MaxPageLimit {
+		return nil, fmt.Errorf("search default limit must be between 1 and %d, got %d", MaxPageLimit, deps.SearchDefaultLimit)
+	}
+	if deps.PriceApprovalThreshold < 0 {
+		return nil, fmt.Errorf("price approval threshold must not be negative, got %v", deps.PriceApprovalThreshold)
+	}
+	if deps.PriceApprovalThreshold > 0 && deps.PriceChangeRepo == nil {
+		return nil, fmt.Errorf("price approval threshold requires a price change repository")
+	}
+	if deps.DescriptionPolicy == "" {
+		deps.DescriptionPolicy = DescriptionPlain
+	}
+	if !deps.DescriptionPolicy.Valid() {
+		return nil, fmt.Errorf("unknown product description policy %q", deps.DescriptionPolicy)
+	}
+	if deps.DefaultProductPrice < 0 || deps.DefaultProductStock < 0 {
+		return nil, fmt.Errorf("default product price and stock must not be negative")
+	}
+	if deps.LowStockAlertThreshold < 0 {
+		return nil, fmt.Errorf("low stock alert threshold must not be negative, got %d", deps.LowStockAlertThreshold)
+	}
 	return &service{deps: deps}, nil
 }
 
-func (s *service) ListCategories(ctx context.Context) ([]Category, error) {
-	return s.deps.CategoryRepo.ListCategories(ctx)
+func (s *service) ListCategories(ctx context.Context, filter CategoryFilter) ([]Category, int64, error) {
+	if filter.Limit < 0 {
+		filter.Limit = 0
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	items, err := s.deps.CategoryRepo.ListCategories(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	// sin paginar el total es el propio largo; evitamos el COUNT extra.
+	if filter.Limit == 0 && filter.Offset == 0 {
+		return items, int64(len(items)), nil
+	}
+	total, err := s.deps.CategoryRepo.CountCategories(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
 }
 
 func (s *service) CreateCategory(ctx context.Context, input CreateCategoryInput) (Category, error) {
@@ -83,10 +206,38 @@ func (s *service) CreateCategory(ctx context.Context, input CreateCategoryInput)
 	}
 	return s.deps.CategoryRepo.CreateCategory(ctx, Category{
 		Name:        input.Name,
+		Slug:        Slugify(input.Name),
 		Description: input.Description,
 	})
 }
 
+func (s *service) GetCategoryBySlug(ctx context.Context, slug string) (Category, error) {
+	if slug == "" {
+		return Category{}, ErrInvalidSlug
+	}
+	return s.deps.CategoryRepo.GetCategoryBySlug(ctx, slug)
+}
+
+// BulkCreateCategories valida el lote completo antes de delegar una unica insercion.
+func (s *service) BulkCreateCategories(ctx context.Context, inputs []CreateCategoryInput) ([]Category, error) {
+	if len(inputs) == 0 {
+		return nil, ErrInvalidCategory
+	}
+	seen := make(map[string]struct{}, len(inputs))
+	cats := make([]Category, 0, len(inputs))
+	for i, in := range inputs {
+		if in.Name == "" {
+			return nil, &BulkCategoryError{Index: i, Err: ErrInvalidCategory}
+		}
+		if _, dup := seen[in.Name]; dup {
+			return nil, &BulkCategoryError{Index: i, Err: ErrCategoryConflict}
+		}
+		seen[in.Name] = struct{}{}
+		cats = append(cats, Category{Name: in.Name, Slug: Slugify(in.Name), Description: in.Description})
+	}
+	return s.deps.CategoryRepo.BulkCreateCategories(ctx, cats)
+}
+
 func (s *service) UpdateCategory(ctx context.Context, input UpdateCategoryInput) (Category, error) {
 	if input.ID == "" {
 		return Category{}, ErrInvalidCategoryID
@@ -108,22 +259,79 @@ func (s *service) DeleteCategory(ctx context.Context, id string) error {
 	return s.deps.CategoryRepo.DeleteCategory(ctx, id)
 }
 
+// ReorderCategories valida que la lista no tenga vacios ni repetidos antes de persistir.
+func (s *service) ReorderCategories(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return ErrInvalidCategoryOrder
+	}
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return ErrInvalidCategoryID
+		}
+		if _, dup := seen[id]; dup {
+			return ErrInvalidCategoryOrder
+		}
+		seen[id] = struct{}{}
+	}
+	return s.deps.CategoryRepo.ReorderCategories(ctx, ids)
+}
+
+func (s *service) CategoryExists(ctx context.Context, id string) (bool, error) {
+	if id == "" {
+		return false, ErrInvalidCategoryID
+	}
+	return s.deps.CategoryRepo.CategoryExists(ctx, id)
+}
+
 func (s *service) ListProducts(ctx context.Context, filter ProductFilter) ([]Product, int64, error) {
 	// defaults basicos de paginacion
 	if filter.Limit <= 0 {
-		filter.Limit = 20
+		filter.Limit = DefaultPageLimit
 	}
 	if filter.Offset < 0 {
 		filter.Offset = 0
 	}
-	items, err := s.deps.ProductRepo.ListProducts(ctx, filter)
+	if !filter.Stock.Valid() {
+		return nil, 0, ErrInvalidStockFilter
+	}
+	if !filter.ValidPriceRange() {
+		return nil, 0, ErrInvalidPriceRange
+	}
+	filter.LowStockThreshold = s.deps.LowStockThreshold
+	var items []Product
+	var total int64
+	var err error
+	if filter.SkipCount {
+		items, total, err = s.listProductsWithoutCount(ctx, filter)
+	} else {
+		items, err = s.deps.ProductRepo.ListProducts(ctx, filter)
+		if err == nil {
+			total, err = s.deps.ProductRepo.CountProducts(ctx, filter)
+		}
+	}
 	if err != nil {
 		return nil, 0, err
 	}
-	total, err := s.deps.ProductRepo.CountProducts(ctx, filter)
+	for i := range items {
+		items[i] = s.withDefaultCurrency(items[i])
+	}
+	return items, total, nil
+}
+
+// listProductsWithoutCount pide Limit+1 filas: la extra solo indica que hay otra
+// pagina, asi que el total es offset+limit+1 en ese caso y exacto en la ultima.
+func (s *service) listProductsWithoutCount(ctx context.Context, filter ProductFilter) ([]Product, int64, error) {
+	page := filter
+	page.Limit++
+	items, err := s.deps.ProductRepo.ListProducts(ctx, page)
 	if err != nil {
 		return nil, 0, err
 	}
+	total := int64(filter.Offset + len(items))
+	if len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
 	return items, total, nil
 }
 
@@ -131,35 +339,222 @@ func (s *service) GetProduct(ctx context.Context, id string) (Product, error) {
 	if id == "" {
 		return Product{}, ErrInvalidProductID
 	}
-	return s.deps.ProductRepo.GetProduct(ctx, id)
+	p, err := s.deps.ProductRepo.GetProductDetail(ctx, id)
+	if err != nil {
+		return Product{}, err
+	}
+	return s.withDefaultCurrency(p), nil
+}
+
+func (s *service) GetProductBySlug(ctx context.Context, slug string) (Product, error) {
+	if slug == "" {
+		return Product{}, ErrInvalidSlug
+	}
+	p, err := s.deps.ProductRepo.GetProductBySlug(ctx, slug)
+	if err != nil {
+		return Product{}, err
+	}
+	return s.withDefaultCurrency(p), nil
+}
+
+// GetProductByBarcode acepta EAN-13 o UPC-A; un codigo invalido no puede existir.
+func (s *service) GetProductByBarcode(ctx context.Context, barcode string) (Product, error) {
+	code, err := NormalizeBarcode(barcode)
+	if err != nil {
+		return Product{}, err
+	}
+	if code == "" {
+		return Product{}, ErrInvalidBarcode
+	}
+	p, err := s.deps.ProductRepo.GetProductByBarcode(ctx, code)
+	if err != nil {
+		return Product{}, err
+	}
+	return s.withDefaultCurrency(p), nil
+}
+
+func (s *service) ProductExists(ctx context.Context, id string) (bool, error) {
+	if id == "" {
+		return false, ErrInvalidProductID
+	}
+	return s.deps.ProductRepo.ProductExists(ctx, id)
 }
 
 func (s *service) CreateProduct(ctx context.Context, input CreateProductInput) (Product, error) {
-	if err := validateProductInput(input.Name, input.Price, input.Stock); err != nil {
+	p, err := s.newProduct(input)
+	if err != nil {
+		return Product{}, err
+	}
+	return s.deps.ProductRepo.CreateProduct(ctx, p)
+}
+
+// CreateProducts reune los errores de todos los items (incluido un barcode repetido
+// dentro del lote) y solo inserta si no hay ninguno.
+func (s *service) CreateProducts(ctx context.Context, inputs []CreateProductInput) ([]Product, error) {
+	if len(inputs) == 0 {
+		return nil, ErrInvalidProduct
+	}
+	products := make([]Product, 0, len(inputs))
+	barcodes := make(map[string]struct{}, len(inputs))
+	var invalid []BulkItemError
+	for i, input := range inputs {
+		p, err := s.newProduct(input)
+		if err == nil && p.Barcode != "" {
+			if _, dup := barcodes[p.Barcode]; dup {
+				err = ErrDuplicateBarcode
+			}
+			barcodes[p.Barcode] = struct{}{}
+		}
+		if err != nil {
+			invalid = append(invalid, BulkItemError{Index: i, Err: err})
+			continue
+		}
+		products = append(products, p)
+	}
+	if len(invalid) > 0 {
+		return nil, &BulkProductError{Items: invalid}
+	}
+	return s.deps.ProductRepo.CreateProducts(ctx, products)
+}
+
+// newProduct aplica defaults y validaciones de un alta sin tocar el repositorio.
+func (s *service) newProduct(input CreateProductInput) (Product, error) {
+	price, stock := s.deps.DefaultProductPrice, s.deps.DefaultProductStock
+	if input.Price != nil {
+		price = *input.Price
+	}
+	if input.Stock != nil {
+		stock = *input.Stock
+	}
+	if err := validateProductInput(input.Name, price, stock); err != nil {
 		return Product{}, err
 	}
-	return s.deps.ProductRepo.CreateProduct(ctx, Product{
+	code, err := s.resolveCurrency(input.Currency)
+	if err != nil {
+		return Product{}, err
+	}
+	barcode, err := NormalizeBarcode(input.Barcode)
+	if err != nil {
+		return Product{}, err
+	}
+	return Product{
 		Name:        input.Name,
-		Description: input.Description,
-		Price:       input.Price,
-		Stock:       input.Stock,
-	})
+		Slug:        Slugify(input.Name),
+		Description: SanitizeDescription(s.deps.DescriptionPolicy, input.Description),
+		Price:       price,
+		Currency:    code,
+		Barcode:     barcode,
+		Stock:       stock,
+		Draft:       input.Draft,
+	}, nil
 }
 
 func (s *service) UpdateProduct(ctx context.Context, input UpdateProductInput) (Product, error) {
 	if input.ID == "" {
 		return Product{}, ErrInvalidProductID
 	}
-	if err := validateProductInput(input.Name, input.Price, input.Stock); err != nil {
+	// el estado base sale del primario: con replica, una lectura atrasada pisaria cambios recientes.
+	current, err := s.deps.ProductRepo.GetProduct(WithPrimaryRead(ctx), input.ID)
+	if err != nil {
 		return Product{}, err
 	}
-	return s.deps.ProductRepo.UpdateProduct(ctx, Product{
-		ID:          input.ID,
-		Name:        input.Name,
-		Description: input.Description,
-		Price:       input.Price,
-		Stock:       input.Stock,
+	if input.Description != nil {
+		// solo lo nuevo: la descripcion guardada ya paso por la politica.
+		description := SanitizeDescription(s.deps.DescriptionPolicy, *input.Description)
+		input.Description = &description
+	}
+	p := applyProductUpdate(current, input)
+	if err := validateProductInput(p.Name, p.Price, p.Stock); err != nil {
+		return Product{}, err
+	}
+	code, err := s.resolveCurrency(p.Currency)
+	if err != nil {
+		return Product{}, err
+	}
+	p.Currency = code
+	if p.Barcode, err = NormalizeBarcode(p.Barcode); err != nil {
+		return Product{}, err
+	}
+	if !exceedsPriceThreshold(current.Price, p.Price, s.deps.PriceApprovalThreshold) {
+		updated, err := s.deps.ProductRepo.UpdateProduct(ctx, p)
+		if err != nil {
+			return Product{}, err
+		}
+		updated.LowStockCrossed = s.crossesLowStock(current.Stock, updated.Stock)
+		return updated, nil
+	}
+	// el resto de los campos se aplica ya; el precio queda a la espera de un admin.
+	requested := p.Price
+	p.Price = current.Price
+	req, updated, err := s.deps.PriceChangeRepo.RequestPriceChange(ctx, p, PriceChangeRequest{
+		ProductID: p.ID,
+		OldPrice:  current.Price,
+		NewPrice:  requested,
+		Status:    PriceChangePending,
 	})
+	if err != nil {
+		return Product{}, err
+	}
+	updated.LowStockCrossed = s.crossesLowStock(current.Stock, updated.Stock)
+	return updated, &PriceChangePendingError{Product: updated, Request: req}
+}
+
+// crossesLowStock es true solo en el cruce: un stock que ya estaba bajo no vuelve a alertar.
+func (s *service) crossesLowStock(before, after int64) bool {
+	threshold := s.deps.LowStockAlertThreshold
+	return threshold > 0 && before > threshold && after <= threshold
+}
+
+// ListPriceChangeRequests lista solicitudes por estado; sin workflow configurado no hay ninguna.
+func (s *service) ListPriceChangeRequests(ctx context.Context, status PriceChangeStatus) ([]PriceChangeRequest, error) {
+	if status != "" && !status.Valid() {
+		return nil, ErrInvalidPriceChangeState
+	}
+	if s.deps.PriceChangeRepo == nil {
+		return []PriceChangeRequest{}, nil
+	}
+	return s.deps.PriceChangeRepo.ListPriceChangeRequests(ctx, status)
+}
+
+func (s *service) ApprovePriceChange(ctx context.Context, id string) (PriceChangeRequest, Product, error) {
+	if s.deps.PriceChangeRepo == nil || id == "" {
+		return PriceChangeRequest{}, Product{}, ErrPriceChangeNotFound
+	}
+	return s.deps.PriceChangeRepo.ApprovePriceChangeRequest(ctx, id)
+}
+
+func (s *service) RejectPriceChange(ctx context.Context, id string) (PriceChangeRequest, error) {
+	if s.deps.PriceChangeRepo == nil || id == "" {
+		return PriceChangeRequest{}, ErrPriceChangeNotFound
+	}
+	return s.deps.PriceChangeRepo.RejectPriceChangeRequest(ctx, id)
+}
+
+// applyProductUpdate copia sobre el producto actual solo los campos presentes.
+func applyProductUpdate(p Product, input UpdateProductInput) Product {
+	if input.Name != nil {
+		// un renombre que no altera el slug base conserva el slug actual (y su sufijo).
+		if Slugify(*input.Name) != Slugify(p.Name) {
+			p.Slug = Slugify(*input.Name)
+		}
+		p.Name = *input.Name
+	}
+	if input.Description != nil {
+		p.Description = *input.Description
+	}
+	if input.Price != nil {
+		p.Price = *input.Price
+	}
+	if input.Currency != nil {
+		p.Currency = *input.Currency
+	}
+	if input.Barcode != nil {
+		p.Barcode = *input.Barcode
+	}
+	if input.Stock != nil {
+		p.Stock = *input.Stock
+	}
+	return p
 }
 
 func (s *service) DeleteProduct(ctx context.Context, id string) error {
@@ -169,6 +564,66 @@ func (s *service) DeleteProduct(ctx context.Context, id string) error {
 	return s.deps.ProductRepo.DeleteProduct(ctx, id)
 }
 
+// RestoreProduct recupera un producto borrado; restaurar uno activo no es error.
+func (s *service) RestoreProduct(ctx context.Context, id string) (Product, bool, error) {
+	if id == "" {
+		return Product{}, false, ErrInvalidProductID
+	}
+	return s.deps.ProductRepo.RestoreProduct(ctx, id)
+}
+
+// PublishProduct publica un borrador si tiene precio y, con PublishRequiresCategory,
+// alguna categoria. Publicar uno ya publicado no es error.
+func (s *service) PublishProduct(ctx context.Context, id string) (Product, bool, error) {
+	if id == "" {
+		return Product{}, false, ErrInvalidProductID
+	}
+	ctx = WithPrimaryRead(ctx)
+	current, err := s.deps.ProductRepo.GetProduct(ctx, id)
+	if err != nil {
+		return Product{}, false, err
+	}
+	if !current.Draft {
+		return s.withDefaultCurrency(current), false, nil
+	}
+	if current.Price <= 0 {
+		return Product{}, false, fmt.Errorf("%w: price is not set", ErrProductIncomplete)
+	}
+	if s.deps.PublishRequiresCategory {
+		cats, err := s.deps.ProductRepo.ListProductCategories(ctx, id)
+		if err != nil {
+			return Product{}, false, err
+		}
+		if len(cats) == 0 {
+			return Product{}, false, fmt.Errorf("%w: no category assigned", ErrProductIncomplete)
+		}
+	}
+	p, published, err := s.deps.ProductRepo.PublishProduct(ctx, id)
+	if err != nil {
+		return Product{}, false, err
+	}
+	return s.withDefaultCurrency(p), published, nil
+}
+
+// BulkAdjustStock valida la forma del lote; el repo verifica existencia y stock negativo
+// dentro de la misma transaccion que aplica los ajustes.
+func (s *service) BulkAdjustStock(ctx context.Context, adjustments []StockAdjustment) ([]Product, error) {
+	if len(adjustments) == 0 || len(adjustments) > MaxStockAdjustments {
+		return nil, ErrInvalidStockAdjustment
+	}
+	seen := make(map[string]struct{}, len(adjustments))
+	for i, adj := range adjustments {
+		if adj.ProductID == "" {
+			return nil, &BulkStockError{Index: i, Err: ErrInvalidProductID}
+		}
+		if _, dup := seen[adj.ProductID]; dup || adj.Delta == 0 {
+			return nil, &BulkStockError{Index: i, ProductID: adj.ProductID, Err: ErrInvalidStockAdjustment}
+		}
+		seen[adj.ProductID] = struct{}{}
+	}
+	return s.deps.ProductRepo.BulkAdjustStock(ctx, adjustments)
+}
+
 func (s *service) GetProductHistory(ctx context.Context, id string, filter ProductHistoryFilter) ([]ProductHistory, error) {
 	if id == "" {
 		return nil, ErrInvalidProductID
@@ -179,6 +634,13 @@ func (s *service) GetProductHistory(ctx context.Context, id string, filter Produ
 	return s.deps.ProductRepo.ListProductHistory(ctx, id, filter)
 }
 
+func (s *service) GetLatestProductHistory(ctx context.Context, id string) (ProductHistory, error) {
+	if id == "" {
+		return ProductHistory{}, ErrInvalidProductID
+	}
+	return s.deps.ProductRepo.GetLatestProductHistory(ctx, id)
+}
+
 func (s *service) AssignProductCategory(ctx context.Context, productID, categoryID string) error {
 	if productID == "" {
 		return ErrInvalidProductID
@@ -189,34 +651,77 @@ func (s *service) AssignProductCategory(ctx context.Context, productID, category
 	return s.deps.ProductRepo.AssignProductCategory(ctx, productID, categoryID)
 }
 
-// Search maneja la busqueda combinada de productos o categorias.
+func (s *service) UnassignProductCategory(ctx context.Context, productID, categoryID string) (bool, error) {
+	if productID == "" {
+		return false, ErrInvalidProductID
+	}
+	if categoryID == "" {
+		return false, ErrInvalidCategoryID
+	}
+	return s.deps.ProductRepo.UnassignProductCategory(ctx, productID, categoryID)
+}
+
+func (s *service) ListProductCategories(ctx context.Context, productID string) ([]Category, error) {
+	if productID == "" {
+		return nil, ErrInvalidProductID
+	}
+	return s.deps.ProductRepo.ListProductCategories(ctx, productID)
+}
+
+// Stats reutiliza los COUNT de los listados: total, sin stock y categorias.
+func (s *service) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	var err error
+	if stats.Products, err = s.deps.ProductRepo.CountProducts(ctx, ProductFilter{}); err != nil {
+		return Stats{}, err
+	}
+	if stats.OutOfStockProducts, err = s.deps.ProductRepo.CountProducts(ctx, ProductFilter{Stock: StockOut}); err != nil {
+		return Stats{}, err
+	}
+	if stats.Categories, err = s.deps.CategoryRepo.CountCategories(ctx); err != nil {
+		return Stats{}, err
+	}
+	return stats, nil
+}
+
+// Search maneja la busqueda combinada de productos o categorias. Un query vacio (o solo
+// espacios) lista todo el tipo pedido, paginado y con el limite acotado a MaxPageLimit.
 func (s *service) Search(ctx context.Context, filter SearchFilter) (SearchResult, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
 	if filter.Limit <= 0 {
-		filter.Limit = 20
+		filter.Limit = s.deps.SearchDefaultLimit
+	}
+	if filter.Limit > MaxPageLimit {
+		filter.Limit = MaxPageLimit
 	}
 	if filter.Offset < 0 {
 		filter.Offset = 0
 	}
 	switch filter.Kind {
-	case "product":
+	case SearchKindProduct:
 		pf := ProductFilter{
-			Query:   filter.Query,
-			Limit:   filter.Limit,
-			Offset:  filter.Offset,
-			SortBy:  filter.SortBy,
-			SortDir: filter.SortDir,
+			Query:         filter.Query,
+			Limit:         filter.Limit,
+			Offset:        filter.Offset,
+			SortBy:        filter.SortBy,
+			SortDir:       filter.SortDir,
+			Highlight:     filter.Highlight,
+			Stock:         filter.Stock,
+			ExcludeDrafts: filter.ExcludeDrafts,
+			MinPrice:      filter.MinPrice,
+			MaxPrice:      filter.MaxPrice,
 		}
 		items, total, err := s.ListProducts(ctx, pf)
 		if err != nil {
 			return SearchResult{}, err
 		}
-		return SearchResult{Products: items, Total: total}, nil
-	case "category":
+		return SearchResult{Products: items, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+	case SearchKindCategory:
 		items, total, err := s.deps.CategoryRepo.SearchCategories(ctx, filter)
 		if err != nil {
 			return SearchResult{}, err
 		}
-		return SearchResult{Categories: items, Total: total}, nil
+		return SearchResult{Categories: items, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
 	default:
 		return SearchResult{}, ErrInvalidSearchKind
 	}
@@ -234,3 +739,19 @@ func validateProductInput(name string, price, stock int64) error {
 	}
 	return nil
 }
+
+// resolveCurrency valida la moneda pedida o usa la configurada por defecto.
+func (s *service) resolveCurrency(code string) (string, error) {
+	if code == "" {
+		return s.deps.DefaultCurrency, nil
+	}
+	return NormalizeCurrency(code)
+}
+
+// withDefaultCurrency completa la moneda de productos guardados sin ella.
+func (s *service) withDefaultCurrency(p Product) Product {
+	if p.Currency == "" {
+		p.Currency = s.deps.DefaultCurrency
+	}
+	return p
+}
@@ -14,29 +14,50 @@ type stubCategoryRepo struct {
 	errCreate  error
 	errUpdate  error
 	errDelete  error
+
+	lastListFilter CategoryFilter
+	countCalls     int
 }
 
 func newStubRepo() *stubCategoryRepo {
 	return &stubCategoryRepo{categories: make(map[string]Category)}
 }
 
-func (s *stubCategoryRepo) ListCategories(ctx context.Context) ([]Category, error) {
+func (s *stubCategoryRepo) ListCategories(ctx context.Context, filter CategoryFilter) ([]Category, error) {
 	if s.errList != nil {
 		return nil, s.errList
 	}
+	s.lastListFilter = filter
 	out := make([]Category, 0, len(s.categories))
 	for _, v := range s.categories {
 		out = append(out, v)
 	}
+	if filter.Limit > 0 && filter.Limit < len(out) {
+		out = out[:filter.Limit]
+	}
 	return out, nil
 }
 
+func (s *stubCategoryRepo) CountCategories(ctx context.Context) (int64, error) {
+	s.countCalls++
+	return int64(len(s.categories)), nil
+}
+
 func (s *stubCategoryRepo) SearchCategories(ctx context.Context, filter SearchFilter) ([]Category, int64, error) {
 	// busqueda simplificada que ignora query/sort en los tests
-	items, err := s.ListCategories(ctx)
+	items, err := s.ListCategories(ctx, CategoryFilter{})
 	return items, int64(len(items)), err
 }
 
+func (s *stubCategoryRepo) GetCategoryBySlug(ctx context.Context, slug string) (Category, error) {
+	for _, c := range s.categories {
+		if c.Slug == slug {
+			return c, nil
+		}
+	}
+	return Category{}, ErrCategoryNotFound
+}
+
 func (s *stubCategoryRepo) CreateCategory(ctx context.Context, cat Category) (Category, error) {
 	if s.errCreate != nil {
 		return Category{}, s.errCreate
@@ -47,6 +68,23 @@ func (s *stubCategoryRepo) CreateCategory(ctx context.Context, cat Category) (Ca
 	return cat, nil
 }
 
+func (s *stubCategoryRepo) BulkCreateCategories(ctx context.Context, cats []Category) ([]Category, error) {
+	if s.errCreate != nil {
+		return nil, s.errCreate
+	}
+	out := make([]Category, 0, len(cats))
+	for _, cat := range cats {
+		created, _ := s.CreateCategory(ctx, cat)
+		out = append(out, created)
+	}
+	return out, nil
+}
+
+func (s *stubCategoryRepo) CategoryExists(ctx context.Context, id string) (bool, error) {
+	_, ok := s.categories[id]
+	return ok, nil
+}
+
 func (s *stubCategoryRepo) UpdateCategory(ctx context.Context, cat Category) (Category, error) {
 	if s.errUpdate != nil {
 		return Category{}, s.errUpdate
@@ -69,6 +107,18 @@ func (s *stubCategoryRepo) DeleteCategory(ctx context.Context, id string) error
 	return nil
 }
 
+func (s *stubCategoryRepo) ReorderCategories(ctx context.Context, ids []string) error {
+	for i, id := range ids {
+		c, ok := s.categories[id]
+		if !ok {
+			return ErrCategoryNotFound
+		}
+		c.DisplayOrder = i + 1
+		s.categories[id] = c
+	}
+	return nil
+}
+
 func generateID(n int) string {
 	return fmt.Sprintf("id-%d", n)
 }
@@ -93,17 +143,103 @@ func TestCreateCategory_Success(t *testing.T) {
 	}
 }
 
+func TestCreateCategory_GeneratesSlug(t *testing.T) {
+	repo := newStubRepo()
+	svc, _ := NewService(ServiceDeps{CategoryRepo: repo, ProductRepo: stubProductRepo{}})
+	cat, err := svc.CreateCategory(context.Background(), CreateCategoryInput{Name: "Electrónica & Hogar"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cat.Slug != "electronica-hogar" {
+		t.Fatalf("expected slug electronica-hogar, got %q", cat.Slug)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Books":                "books",
+		"  Libros   Usados  ":  "libros-usados",
+		"Niños & Bebés":        "ninos-bebes",
+		"100% Algodón -- Ropa": "100-algodon-ropa",
+		"¡¿!?":                 "item",
+		"日本":                   "item",
+	}
+	for in, want := range cases {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNextFreeSlug(t *testing.T) {
+	taken := map[string]struct{}{"books": {}, "books-2": {}, "books-4": {}}
+	if got := NextFreeSlug("books", taken); got != "books-3" {
+		t.Fatalf("expected books-3, got %q", got)
+	}
+	if got := NextFreeSlug("audio", taken); got != "audio" {
+		t.Fatalf("expected audio, got %q", got)
+	}
+}
+
+func TestGetCategoryBySlug_RejectsEmpty(t *testing.T) {
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}})
+	if _, err := svc.GetCategoryBySlug(context.Background(), ""); !errors.Is(err, ErrInvalidSlug) {
+		t.Fatalf("expected ErrInvalidSlug, got %v", err)
+	}
+}
+
+func TestReorderCategories_ValidatesIDs(t *testing.T) {
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}})
+	cases := map[string]struct {
+		ids  []string
+		want error
+	}{
+		"empty":     {ids: nil, want: ErrInvalidCategoryOrder},
+		"duplicate": {ids: []string{"a", "a"}, want: ErrInvalidCategoryOrder},
+		"blank id":  {ids: []string{"a", ""}, want: ErrInvalidCategoryID},
+	}
+	for name, tc := range cases {
+		if err := svc.ReorderCategories(context.Background(), tc.ids); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
+
 func TestListCategories(t *testing.T) {
 	repo := newStubRepo()
 	_, _ = repo.CreateCategory(context.Background(), Category{Name: "A"})
 	_, _ = repo.CreateCategory(context.Background(), Category{Name: "B"})
 	svc, _ := NewService(ServiceDeps{CategoryRepo: repo, ProductRepo: stubProductRepo{}})
-	cats, err := svc.ListCategories(context.Background())
+	cats, total, err := svc.ListCategories(context.Background(), CategoryFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(cats) != 2 {
-		t.Fatalf("expected 2 categories, got %d", len(cats))
+	if len(cats) != 2 || total != 2 {
+		t.Fatalf("expected 2 categories, got %d (total %d)", len(cats), total)
+	}
+	if repo.countCalls != 0 {
+		t.Fatalf("expected no count query when unpaginated, got %d", repo.countCalls)
+	}
+}
+
+func TestListCategories_Paginated(t *testing.T) {
+	repo := newStubRepo()
+	_, _ = repo.CreateCategory(context.Background(), Category{Name: "A"})
+	_, _ = repo.CreateCategory(context.Background(), Category{Name: "B"})
+	_, _ = repo.CreateCategory(context.Background(), Category{Name: "C"})
+	svc, _ := NewService(ServiceDeps{CategoryRepo: repo, ProductRepo: stubProductRepo{}})
+	cats, total, err := svc.ListCategories(context.Background(), CategoryFilter{Limit: 2, Offset: -1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cats) != 2 || total != 3 {
+		t.Fatalf("expected page of 2 with total 3, got %d (total %d)", len(cats), total)
+	}
+	if repo.lastListFilter.Offset != 0 {
+		t.Fatalf("expected negative offset to be normalized, got %d", repo.lastListFilter.Offset)
+	}
+	if repo.countCalls != 1 {
+		t.Fatalf("expected a count query when paginated, got %d", repo.countCalls)
 	}
 }
 
@@ -143,7 +279,7 @@ func TestDeleteCategory_Success(t *testing.T) {
 	if err := svc.DeleteCategory(context.Background(), created.ID); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	cats, _ := repo.ListCategories(context.Background())
+	cats, _ := repo.ListCategories(context.Background(), CategoryFilter{})
 	if len(cats) != 0 {
 		t.Fatalf("expected empty repo after delete")
 	}
@@ -163,10 +299,30 @@ func (stubProductRepo) GetProduct(ctx context.Context, id string) (Product, erro
 	return Product{}, nil
 }
 
-func (stubProductRepo) CreateProduct(ctx context.Context, p Product) (Product, error) {
+func (stubProductRepo) CreateProducts(ctx context.Context, products []Product) ([]Product, error) {
+	return products, nil
+}
+
+func (stubProductRepo) GetProductDetail(ctx context.Context, id string) (Product, error) {
 	return Product{}, nil
 }
 
+func (stubProductRepo) GetProductBySlug(ctx context.Context, slug string) (Product, error) {
+	return Product{}, ErrProductNotFound
+}
+
+func (stubProductRepo) GetProductByBarcode(ctx context.Context, barcode string) (Product, error) {
+	return Product{}, ErrProductNotFound
+}
+
+func (stubProductRepo) ProductExists(ctx context.Context, id string) (bool, error) {
+	return false, nil
+}
+
+func (stubProductRepo) CreateProduct(ctx context.Context, p Product) (Product, error) {
+	return p, nil
+}
+
 func (stubProductRepo) UpdateProduct(ctx context.Context, p Product) (Product, error) {
 	return Product{}, nil
 }
@@ -175,17 +331,598 @@ func (stubProductRepo) DeleteProduct(ctx context.Context, id string) error {
 	return nil
 }
 
+func (stubProductRepo) RestoreProduct(ctx context.Context, id string) (Product, bool, error) {
+	return Product{}, false, nil
+}
+
+func (stubProductRepo) PublishProduct(ctx context.Context, id string) (Product, bool, error) {
+	return Product{}, false, nil
+}
+
 func (stubProductRepo) ListProductHistory(ctx context.Context, id string, filter ProductHistoryFilter) ([]ProductHistory, error) {
 	return nil, nil
 }
 
+func (stubProductRepo) BulkAdjustStock(ctx context.Context, adjustments []StockAdjustment) ([]Product, error) {
+	return nil, nil
+}
+
+func (stubProductRepo) GetLatestProductHistory(ctx context.Context, id string) (ProductHistory, error) {
+	return ProductHistory{}, ErrProductHistoryNotFound
+}
+
+func (stubProductRepo) ListProductCategories(ctx context.Context, productID string) ([]Category, error) {
+	return nil, ErrProductNotFound
+}
+
 func (stubProductRepo) AssignProductCategory(ctx context.Context, productID, categoryID string) error {
 	return nil
 }
 
+func (stubProductRepo) UnassignProductCategory(ctx context.Context, productID, categoryID string) (bool, error) {
+	return true, nil
+}
+
 func TestSearch_InvalidKind(t *testing.T) {
 	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}})
 	if _, err := svc.Search(context.Background(), SearchFilter{Kind: "unknown"}); !errors.Is(err, ErrInvalidSearchKind) {
 		t.Fatalf("expected ErrInvalidSearchKind, got %v", err)
 	}
 }
+
+type searchFilterRepo struct {
+	*stubCategoryRepo
+	got SearchFilter
+}
+
+func (r *searchFilterRepo) SearchCategories(ctx context.Context, filter SearchFilter) ([]Category, int64, error) {
+	r.got = filter
+	return nil, 0, nil
+}
+
+func TestSearch_UsesSearchDefaultLimit(t *testing.T) {
+	repo := &searchFilterRepo{stubCategoryRepo: newStubRepo()}
+	svc, err := NewService(ServiceDeps{CategoryRepo: repo, ProductRepo: stubProductRepo{}, SearchDefaultLimit: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Search(context.Background(), SearchFilter{Kind: "category", Query: "bo"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.got.Limit != 10 {
+		t.Fatalf("expected search default limit 10, got %d", repo.got.Limit)
+	}
+	if _, err := svc.Search(context.Background(), SearchFilter{Kind: "category", Limit: 3}); err != nil || repo.got.Limit != 3 {
+		t.Fatalf("expected explicit limit to win, got %d (%v)", repo.got.Limit, err)
+	}
+}
+
+type searchProductRepo struct {
+	stubProductRepo
+	got ProductFilter
+}
+
+func (r *searchProductRepo) ListProducts(ctx context.Context, filter ProductFilter) ([]Product, error) {
+	r.got = filter
+	return []Product{{ID: "p1"}}, nil
+}
+
+// countingProductRepo devuelve n productos respetando Limit y cuenta los COUNT.
+type countingProductRepo struct {
+	stubProductRepo
+	n      int
+	counts int
+}
+
+func (r *countingProductRepo) ListProducts(ctx context.Context, filter ProductFilter) ([]Product, error) {
+	items := []Product{}
+	for i := filter.Offset; i < r.n && len(items) < filter.Limit; i++ {
+		items = append(items, Product{ID: fmt.Sprintf("p%d", i)})
+	}
+	return items, nil
+}
+
+func (r *countingProductRepo) CountProducts(ctx context.Context, filter ProductFilter) (int64, error) {
+	r.counts++
+	return int64(r.n), nil
+}
+
+func TestListProducts_SkipCount(t *testing.T) {
+	ctx := context.Background()
+	repo := &countingProductRepo{n: 5}
+	svc, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	items, total, err := svc.ListProducts(ctx, ProductFilter{Limit: 2, SkipCount: true})
+	if err != nil || len(items) != 2 || total <= 2 {
+		t.Fatalf("expected a trimmed page signalling more, got %d items total=%d err=%v", len(items), total, err)
+	}
+	items, total, err = svc.ListProducts(ctx, ProductFilter{Limit: 2, Offset: 4, SkipCount: true})
+	if err != nil || len(items) != 1 || total != 5 {
+		t.Fatalf("expected the exact total on the last page, got %d items total=%d err=%v", len(items), total, err)
+	}
+	if repo.counts != 0 {
+		t.Fatalf("expected no count query, got %d", repo.counts)
+	}
+
+	if _, total, err = svc.ListProducts(ctx, ProductFilter{Limit: 2}); err != nil || total != 5 || repo.counts != 1 {
+		t.Fatalf("expected counting by default, got total=%d counts=%d err=%v", total, repo.counts, err)
+	}
+}
+
+func TestSearch_EmptyQueryListsAllWithCap(t *testing.T) {
+	products := &searchProductRepo{}
+	categories := &searchFilterRepo{stubCategoryRepo: newStubRepo()}
+	svc, err := NewService(ServiceDeps{CategoryRepo: categories, ProductRepo: products})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := svc.Search(context.Background(), SearchFilter{Kind: SearchKindProduct, Query: "   ", Limit: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Products) != 1 || products.got.Query != "" || products.got.Limit != MaxPageLimit {
+		t.Fatalf("expected empty product query to list all capped at %d, got %+v", MaxPageLimit, products.got)
+	}
+
+	if _, err := svc.Search(context.Background(), SearchFilter{Kind: SearchKindCategory, Query: "   ", Limit: 1000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if categories.got.Query != "" || categories.got.Limit != MaxPageLimit {
+		t.Fatalf("expected category search to behave the same, got %+v", categories.got)
+	}
+}
+
+func TestNewService_RejectsSearchDefaultAboveCap(t *testing.T) {
+	if _, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}, SearchDefaultLimit: MaxPageLimit + 1}); err == nil {
+		t.Fatalf("expected error for search default above %d", MaxPageLimit)
+	}
+}
+
+func TestCreateProduct_DefaultsCurrency(t *testing.T) {
+	svc, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}, DefaultCurrency: "eur"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := svc.CreateProduct(context.Background(), CreateProductInput{Name: "Pen"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Currency != "EUR" {
+		t.Fatalf("expected default currency EUR, got %q", p.Currency)
+	}
+
+	p, err = svc.CreateProduct(context.Background(), CreateProductInput{Name: "Pen", Currency: "ars"})
+	if err != nil || p.Currency != "ARS" {
+		t.Fatalf("expected override ARS, got %q err=%v", p.Currency, err)
+	}
+
+	// productos guardados sin moneda heredan la de por defecto al leerse.
+	stored, err := svc.GetProduct(context.Background(), "p1")
+	if err != nil || stored.Currency != "EUR" {
+		t.Fatalf("expected stored product to fall back to EUR, got %q err=%v", stored.Currency, err)
+	}
+}
+
+func TestCreateProduct_DefaultsPriceAndStock(t *testing.T) {
+	svc, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}, DefaultProductPrice: 500, DefaultProductStock: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := svc.CreateProduct(context.Background(), CreateProductInput{Name: "Draft"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Price != 500 || p.Stock != 3 {
+		t.Fatalf("expected defaults price=500 stock=3, got price=%d stock=%d", p.Price, p.Stock)
+	}
+
+	// un cero explicito pisa el default.
+	zero := int64(0)
+	p, err = svc.CreateProduct(context.Background(), CreateProductInput{Name: "Draft", Price: &zero, Stock: &zero})
+	if err != nil || p.Price != 0 || p.Stock != 0 {
+		t.Fatalf("expected explicit zeros to win, got price=%d stock=%d err=%v", p.Price, p.Stock, err)
+	}
+
+	if _, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}, DefaultProductStock: -1}); err == nil {
+		t.Fatalf("expected negative default stock to be rejected")
+	}
+}
+
+func TestCreateProduct_RejectsInvalidCurrency(t *testing.T) {
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}})
+	for _, code := range []string{"XYZ", "US", "dollars"} {
+		if _, err := svc.CreateProduct(context.Background(), CreateProductInput{Name: "Pen", Currency: code}); !errors.Is(err, ErrInvalidCurrency) {
+			t.Fatalf("expected ErrInvalidCurrency for %q, got %v", code, err)
+		}
+	}
+	if _, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}, DefaultCurrency: "ZZZ"}); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("expected invalid default currency to be rejected, got %v", err)
+	}
+}
+
+type storedProductRepo struct {
+	stubProductRepo
+	current Product
+	updated Product
+}
+
+func (r *storedProductRepo) GetProduct(ctx context.Context, id string) (Product, error) {
+	if id != r.current.ID {
+		return Product{}, ErrProductNotFound
+	}
+	return r.current, nil
+}
+
+func (r *storedProductRepo) GetProductDetail(ctx context.Context, id string) (Product, error) {
+	return r.GetProduct(ctx, id)
+}
+
+func (r *storedProductRepo) UpdateProduct(ctx context.Context, p Product) (Product, error) {
+	r.updated = p
+	return p, nil
+}
+
+// draftProductRepo agrega categorias y publicacion sobre storedProductRepo.
+type draftProductRepo struct {
+	storedProductRepo
+	categories []Category
+	published  bool
+}
+
+func (r *draftProductRepo) ListProductCategories(ctx context.Context, productID string) ([]Category, error) {
+	return r.categories, nil
+}
+
+func (r *draftProductRepo) PublishProduct(ctx context.Context, id string) (Product, bool, error) {
+	r.published = true
+	p := r.current
+	p.Draft = false
+	return p, true, nil
+}
+
+func TestPublishProduct_BlockedWhenIncomplete(t *testing.T) {
+	cases := []struct {
+		name            string
+		product         Product
+		requireCategory bool
+	}{
+		{name: "no price", product: Product{ID: "p1", Name: "Pen", Draft: true}},
+		{name: "no category", product: Product{ID: "p1", Name: "Pen", Price: 150, Draft: true}, requireCategory: true},
+	}
+	for _, tc := range cases {
+		repo := &draftProductRepo{storedProductRepo: storedProductRepo{current: tc.product}}
+		svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo, PublishRequiresCategory: tc.requireCategory})
+		if _, _, err := svc.PublishProduct(context.Background(), "p1"); !errors.Is(err, ErrProductIncomplete) {
+			t.Fatalf("%s: expected ErrProductIncomplete, got %v", tc.name, err)
+		}
+		if repo.published {
+			t.Fatalf("%s: incomplete product must not be published", tc.name)
+		}
+	}
+}
+
+func TestPublishProduct_Success(t *testing.T) {
+	repo := &draftProductRepo{
+		storedProductRepo: storedProductRepo{current: Product{ID: "p1", Name: "Pen", Price: 150, Draft: true}},
+		categories:        []Category{{ID: "c1", Name: "Office"}},
+	}
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo, PublishRequiresCategory: true})
+	p, published, err := svc.PublishProduct(context.Background(), "p1")
+	if err != nil || !published || p.Draft {
+		t.Fatalf("expected product to be published, got %+v published=%v err=%v", p, published, err)
+	}
+	if p.Currency != DefaultCurrency {
+		t.Fatalf("expected default currency on the published product, got %q", p.Currency)
+	}
+
+	// un producto ya publicado no vuelve a pasar por el repo.
+	repo = &draftProductRepo{storedProductRepo: storedProductRepo{current: Product{ID: "p1", Name: "Pen"}}}
+	svc, _ = NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo})
+	if _, published, err := svc.PublishProduct(context.Background(), "p1"); err != nil || published || repo.published {
+		t.Fatalf("expected no-op publish, got published=%v repo=%v err=%v", published, repo.published, err)
+	}
+}
+
+func TestUpdateProduct_OmittedFieldsKeepCurrentValues(t *testing.T) {
+	repo := &storedProductRepo{current: Product{ID: "p1", Name: "Pen", Description: "Blue", Price: 150, Currency: "EUR", Stock: 7}}
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo})
+
+	name := "Fountain pen"
+	updated, err := svc.UpdateProduct(context.Background(), UpdateProductInput{ID: "p1", Name: &name})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Price != 150 || updated.Stock != 7 || updated.Description != "Blue" || updated.Currency != "EUR" {
+		t.Fatalf("omitted fields must keep their values, got %+v", updated)
+	}
+	if updated.Name != "Fountain pen" {
+		t.Fatalf("expected name to change, got %q", updated.Name)
+	}
+
+	zero := int64(0)
+	if _, err := svc.UpdateProduct(context.Background(), UpdateProductInput{ID: "p1", Stock: &zero}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.updated.Stock != 0 || repo.updated.Price != 150 {
+		t.Fatalf("explicit zero stock must be applied without touching price, got %+v", repo.updated)
+	}
+
+	if _, err := svc.UpdateProduct(context.Background(), UpdateProductInput{ID: "missing", Name: &name}); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestUpdateProduct_LowStockCrossing(t *testing.T) {
+	cases := []struct {
+		name      string
+		threshold int64
+		before    int64
+		after     int64
+		want      bool
+	}{
+		{"crosses down to threshold", 5, 8, 5, true},
+		{"crosses below threshold", 5, 8, 0, true},
+		{"stays above", 5, 8, 6, false},
+		{"already low", 5, 4, 2, false},
+		{"restock", 5, 2, 9, false},
+		{"disabled", 0, 8, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &storedProductRepo{current: Product{ID: "p1", Name: "Pen", Price: 10, Stock: tc.before}}
+			svc, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo, LowStockAlertThreshold: tc.threshold})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			updated, err := svc.UpdateProduct(context.Background(), UpdateProductInput{ID: "p1", Stock: &tc.after})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if updated.LowStockCrossed != tc.want {
+				t.Fatalf("expected LowStockCrossed=%v for %d -> %d, got %v", tc.want, tc.before, tc.after, updated.LowStockCrossed)
+			}
+		})
+	}
+
+	if _, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}, LowStockAlertThreshold: -1}); err == nil {
+		t.Fatalf("expected negative alert threshold to be rejected")
+	}
+}
+
+// bulkProductRepo registra si el lote llego a insertarse.
+type bulkProductRepo struct {
+	stubProductRepo
+	inserted []Product
+}
+
+func (r *bulkProductRepo) CreateProducts(ctx context.Context, products []Product) ([]Product, error) {
+	r.inserted = products
+	return products, nil
+}
+
+func TestCreateProducts_ReportsEveryInvalidItem(t *testing.T) {
+	repo := &bulkProductRepo{}
+	negative, five := int64(-1), int64(5)
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo, DefaultProductStock: 3})
+	ctx := context.Background()
+
+	_, err := svc.CreateProducts(ctx, []CreateProductInput{
+		{Name: "Pen", Barcode: "4006381333931"},
+		{Name: ""},
+		{Name: "Ink", Price: &negative},
+		{Name: "Pen refill", Barcode: "4006381333931"},
+	})
+	var bulkErr *BulkProductError
+	if !errors.As(err, &bulkErr) {
+		t.Fatalf("expected *BulkProductError, got %v", err)
+	}
+	if len(bulkErr.Items) != 3 || bulkErr.Items[0].Index != 1 || bulkErr.Items[1].Index != 2 || bulkErr.Items[2].Index != 3 {
+		t.Fatalf("expected indexes 1, 2 and 3, got %+v", bulkErr.Items)
+	}
+	if !errors.Is(bulkErr.Items[2].Err, ErrDuplicateBarcode) || !errors.Is(err, ErrInvalidProduct) {
+		t.Fatalf("unexpected item errors %+v", bulkErr.Items)
+	}
+	if repo.inserted != nil {
+		t.Fatalf("invalid batch must not reach the repository, got %+v", repo.inserted)
+	}
+
+	created, err := svc.CreateProducts(ctx, []CreateProductInput{{Name: "Pen"}, {Name: "Ink", Price: &five}})
+	if err != nil || len(created) != 2 || created[0].Slug != "pen" || created[0].Stock != 3 || created[1].Price != 5 {
+		t.Fatalf("expected both products with defaults applied, got %+v (%v)", created, err)
+	}
+	if _, err := svc.CreateProducts(ctx, nil); !errors.Is(err, ErrInvalidProduct) {
+		t.Fatalf("expected empty batch to be rejected, got %v", err)
+	}
+}
+
+func TestService_MissingEntitiesReturnNotFound(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: &storedProductRepo{current: Product{ID: "p1"}}})
+
+	if _, err := svc.GetProduct(ctx, "missing"); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+	if _, err := svc.GetCategoryBySlug(ctx, "missing"); !errors.Is(err, ErrCategoryNotFound) {
+		t.Fatalf("expected ErrCategoryNotFound, got %v", err)
+	}
+}
+
+func TestUpdateProduct_RenameRegeneratesSlugOnlyWhenBaseChanges(t *testing.T) {
+	repo := &storedProductRepo{current: Product{ID: "p1", Name: "Pen", Slug: "pen-2", Price: 150, Currency: "EUR", Stock: 7}}
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo})
+
+	sameBase := "PEN!"
+	if _, err := svc.UpdateProduct(context.Background(), UpdateProductInput{ID: "p1", Name: &sameBase}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.updated.Slug != "pen-2" {
+		t.Fatalf("expected slug to be kept, got %q", repo.updated.Slug)
+	}
+
+	renamed := "Pluma Fuente"
+	if _, err := svc.UpdateProduct(context.Background(), UpdateProductInput{ID: "p1", Name: &renamed}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.updated.Slug != "pluma-fuente" {
+		t.Fatalf("expected regenerated slug, got %q", repo.updated.Slug)
+	}
+}
+
+func TestBulkCreateCategories(t *testing.T) {
+	repo := newStubRepo()
+	svc, _ := NewService(ServiceDeps{CategoryRepo: repo, ProductRepo: stubProductRepo{}})
+
+	created, err := svc.BulkCreateCategories(context.Background(), []CreateCategoryInput{{Name: "Books"}, {Name: "Audio"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(created) != 2 || created[0].ID == "" {
+		t.Fatalf("unexpected result %+v", created)
+	}
+
+	_, err = svc.BulkCreateCategories(context.Background(), []CreateCategoryInput{{Name: "Games"}, {Name: ""}})
+	var bulkErr *BulkCategoryError
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 1 || !errors.Is(err, ErrInvalidCategory) {
+		t.Fatalf("expected invalid category at index 1, got %v", err)
+	}
+	_, err = svc.BulkCreateCategories(context.Background(), []CreateCategoryInput{{Name: "Games"}, {Name: "Games"}})
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 1 || !errors.Is(err, ErrCategoryConflict) {
+		t.Fatalf("expected conflict at index 1, got %v", err)
+	}
+	if len(repo.categories) != 2 {
+		t.Fatalf("rejected batches must not reach the repository, got %d categories", len(repo.categories))
+	}
+}
+
+func TestBulkAdjustStock_ValidatesBatchShape(t *testing.T) {
+	svc, _ := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}})
+	ctx := context.Background()
+
+	if _, err := svc.BulkAdjustStock(ctx, nil); err != ErrInvalidStockAdjustment {
+		t.Fatalf("expected ErrInvalidStockAdjustment for empty batch, got %v", err)
+	}
+	_, err := svc.BulkAdjustStock(ctx, []StockAdjustment{{ProductID: "p1", Delta: 1}, {ProductID: "p1", Delta: 2}})
+	var bulkErr *BulkStockError
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 1 || !errors.Is(err, ErrInvalidStockAdjustment) {
+		t.Fatalf("expected duplicate rejected at index 1, got %v", err)
+	}
+	_, err = svc.BulkAdjustStock(ctx, []StockAdjustment{{ProductID: "p1", Delta: 0}})
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 0 {
+		t.Fatalf("expected zero delta rejected, got %v", err)
+	}
+	if _, err := svc.BulkAdjustStock(ctx, []StockAdjustment{{ProductID: "p1", Delta: -1}, {ProductID: "p2", Delta: 3}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestExceedsPriceThreshold(t *testing.T) {
+	cases := []struct {
+		old, new  int64
+		threshold float64
+		want      bool
+	}{
+		{100, 110, 10, false},
+		{100, 111, 10, true},
+		{100, 89, 10, true},
+		{100, 1000, 0, false},
+		{0, 1, 10, true},
+		{50, 50, 10, false},
+	}
+	for _, tc := range cases {
+		if got := exceedsPriceThreshold(tc.old, tc.new, tc.threshold); got != tc.want {
+			t.Fatalf("exceedsPriceThreshold(%d, %d, %v) = %v, want %v", tc.old, tc.new, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+func TestNewService_PriceApprovalRequiresRepo(t *testing.T) {
+	if _, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}, PriceApprovalThreshold: 10}); err == nil {
+		t.Fatalf("expected error when threshold is set without a price change repository")
+	}
+	if _, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: stubProductRepo{}, PriceApprovalThreshold: -1}); err == nil {
+		t.Fatalf("expected negative threshold to be rejected")
+	}
+}
+
+func TestNormalizeBarcode(t *testing.T) {
+	cases := []struct {
+		in, want string
+		err      error
+	}{
+		{"4006381333931", "4006381333931", nil},
+		{" 036000291452 ", "0036000291452", nil}, // UPC-A pasa a EAN-13
+		{"", "", nil},
+		{"4006381333932", "", ErrInvalidBarcode}, // digito verificador incorrecto
+		{"40063813339", "", ErrInvalidBarcode},
+		{"40063813339A1", "", ErrInvalidBarcode},
+	}
+	for _, tc := range cases {
+		got, err := NormalizeBarcode(tc.in)
+		if got != tc.want || !errors.Is(err, tc.err) {
+			t.Fatalf("NormalizeBarcode(%q) = %q, %v; want %q, %v", tc.in, got, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestSanitizeDescription(t *testing.T) {
+	const input = `<p>Tinta <b>azul</b></p><script>alert(1)</script><a href="javascript:alert(1)" onclick="x()">ver</a>`
+	cases := []struct {
+		policy DescriptionPolicy
+		want   string
+	}{
+		{policy: DescriptionPlain, want: "Tinta azulver"},
+		{policy: DescriptionBasic, want: "<p>Tinta <b>azul</b></p>ver"},
+		{policy: DescriptionRaw, want: input},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			got := SanitizeDescription(tc.policy, input)
+			if got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+	// reaplicar la politica a un texto ya saneado no debe escapar dos veces.
+	once := SanitizeDescription(DescriptionPlain, "Tom & Jerry")
+	if twice := SanitizeDescription(DescriptionPlain, once); twice != once {
+		t.Fatalf("expected plain sanitization to be idempotent, got %q then %q", once, twice)
+	}
+}
+
+type descriptionRepo struct {
+	stubProductRepo
+	updated Product
+}
+
+func (r *descriptionRepo) UpdateProduct(ctx context.Context, p Product) (Product, error) {
+	r.updated = p
+	return p, nil
+}
+
+func TestProductDescriptionIsSanitizedOnWrite(t *testing.T) {
+	repo := &descriptionRepo{}
+	svc, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := svc.CreateProduct(context.Background(), CreateProductInput{Name: "Pen", Description: "ok<script>alert(1)</script>"})
+	if err != nil || p.Description != "ok" {
+		t.Fatalf("expected script to be stripped on create, got %q (%v)", p.Description, err)
+	}
+
+	name, description := "Pen", `<img src=x onerror="alert(1)">nuevo`
+	if _, err := svc.UpdateProduct(context.Background(), UpdateProductInput{ID: "p1", Name: &name, Description: &description}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.updated.Description != "nuevo" {
+		t.Fatalf("expected markup to be stripped on update, got %q", repo.updated.Description)
+	}
+
+	if _, err := NewService(ServiceDeps{CategoryRepo: newStubRepo(), ProductRepo: repo, DescriptionPolicy: "markdown"}); err == nil {
+		t.Fatalf("expected unknown description policy to be rejected")
+	}
+}
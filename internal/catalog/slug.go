@@ -0,0 +1,55 @@
+package catalog
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// fallbackSlug se usa cuando el nombre no deja ningun caracter alfanumerico.
+const fallbackSlug = "item"
+
+// Slugify genera un slug en minusculas, sin acentos y separado por guiones.
+func Slugify(name string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// marcas diacriticas que quedan sueltas tras la descomposicion NFD.
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			pendingDash = true
+		}
+	}
+	if b.Len() == 0 {
+		return fallbackSlug
+	}
+	return b.String()
+}
+
+// SlugCandidate devuelve el intento n de un slug: el base para n<=1 y base-n en adelante.
+func SlugCandidate(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
+
+// NextFreeSlug elige el primer candidato de base que no este en taken.
+func NextFreeSlug(base string, taken map[string]struct{}) string {
+	for n := 1; ; n++ {
+		candidate := SlugCandidate(base, n)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package catalog
+
+// StockFilter restringe ListProducts segun el stock disponible.
+type StockFilter string
+
+const (
+	StockAny StockFilter = ""    // sin filtro
+	StockOut StockFilter = "out" // stock = 0
+	StockIn  StockFilter = "in"  // stock > 0
+	StockLow StockFilter = "low" // stock <= umbral de stock bajo
+)
+
+// DefaultLowStockThreshold se usa cuando no se configura un umbral de stock bajo.
+const DefaultLowStockThreshold = 5
+
+// Valid indica si el filtro es uno de los valores soportados.
+func (f StockFilter) Valid() bool {
+	switch f {
+	case StockAny, StockOut, StockIn, StockLow:
+		return true
+	}
+	return false
+}
+
+// MatchesStock aplica el filtro de stock en memoria con la misma semantica que el SQL.
+func (f ProductFilter) MatchesStock(stock int64) bool {
+	switch f.Stock {
+	case StockOut:
+		return stock == 0
+	case StockIn:
+		return stock > 0
+	case StockLow:
+		return stock <= int64(f.LowStockThreshold)
+	}
+	return true
+}
+
+// StockAdjustment suma Delta (positivo o negativo) al stock de un producto.
+type StockAdjustment struct {
+	ProductID string
+	Delta     int64
+}
+
+// MaxStockAdjustments acota el tamano de un ajuste masivo.
+const MaxStockAdjustments = 100
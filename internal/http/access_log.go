@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDHeader se propaga desde el proxy o se genera si no viene.
+const requestIDHeader = "X-Request-ID"
+
+// correlationIDHeader lo envia el cliente para agrupar varias llamadas de una misma
+// operacion; a diferencia del request id nunca se genera.
+const correlationIDHeader = "X-Correlation-ID"
+
+// maxRequestIDLen evita que un cliente inyecte ids enormes en los logs.
+const maxRequestIDLen = 128
+
+// AccessLogMiddleware registra cada peticion con slog en nivel info. Si la latencia
+// alcanza slowThreshold (> 0) emite un warn "slow request" para detectar regresiones.
+func AccessLogMiddleware(logr *slog.Logger, slowThreshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLen {
+			requestID = newRequestID()
+		}
+		c.Set("request_id", requestID)
+		c.Header(requestIDHeader, requestID)
+		ctx := context.WithValue(c.Request.Context(), requestIDKey{}, requestID)
+		correlationID := c.GetHeader(correlationIDHeader)
+		if len(correlationID) > maxRequestIDLen {
+			correlationID = ""
+		}
+		if correlationID != "" {
+			c.Set("correlation_id", correlationID)
+			c.Header(correlationIDHeader, correlationID)
+			ctx = context.WithValue(ctx, correlationIDKey{}, correlationID)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		latency := time.Since(start)
+		route := c.FullPath()
+		if route == "" {
+			// rutas no registradas: se loguea el path crudo para no perder el 404.
+			route = c.Request.URL.Path
+		}
+		attrs := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", latency),
+			slog.String("request_id", requestID),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if correlationID != "" {
+			attrs = append(attrs, slog.String("correlation_id", correlationID))
+		}
+		if slowThreshold > 0 && latency >= slowThreshold {
+			logr.Warn("slow request", append(attrs, slog.Duration("threshold", slowThreshold))...)
+			return
+		}
+		logr.Info("request", attrs...)
+	}
+}
+
+type requestIDKey struct{}
+
+type correlationIDKey struct{}
+
+// RequestIDFromContext devuelve el request id que AccessLogMiddleware dejo en ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// CorrelationIDFromContext devuelve el X-Correlation-ID del cliente; vacio si no lo envio.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
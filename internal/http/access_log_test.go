@@ -0,0 +1,106 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLogMiddleware_WarnsOnSlowRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logr := slog.New(slog.NewJSONHandler(&buf, nil))
+	r := gin.New()
+	r.Use(AccessLogMiddleware(logr, 20*time.Millisecond))
+	r.GET("/slow/:id", func(c *gin.Context) {
+		time.Sleep(30 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fast", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/slow/42", nil)
+	req.Header.Set(requestIDHeader, "req-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["level"] != "WARN" || entry["msg"] != "slow request" {
+		t.Fatalf("expected slow request warning, got %+v", entry)
+	}
+	if entry["route"] != "/slow/:id" || entry["request_id"] != "req-123" {
+		t.Fatalf("expected route and request id in log, got %+v", entry)
+	}
+	if latency, _ := entry["latency"].(float64); latency < float64(20*time.Millisecond) {
+		t.Fatalf("expected latency above threshold, got %+v", entry["latency"])
+	}
+
+	buf.Reset()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	entry = nil
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["level"] != "INFO" {
+		t.Fatalf("expected info level for fast request, got %+v", entry)
+	}
+	if w.Header().Get(requestIDHeader) == "" {
+		t.Fatalf("expected generated request id header")
+	}
+}
+
+func TestAccessLogMiddleware_PropagatesCorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logr := slog.New(slog.NewJSONHandler(&buf, nil))
+	r := gin.New()
+	r.Use(AccessLogMiddleware(logr, 0))
+	var gotRequestID, gotCorrelationID string
+	r.GET("/ping", func(c *gin.Context) {
+		gotRequestID = RequestIDFromContext(c.Request.Context())
+		gotCorrelationID = CorrelationIDFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(requestIDHeader, "req-1")
+	req.Header.Set(correlationIDHeader, "checkout-42")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get(correlationIDHeader); got != "checkout-42" {
+		t.Fatalf("expected correlation id echoed, got %q", got)
+	}
+	if gotRequestID != "req-1" || gotCorrelationID != "checkout-42" {
+		t.Fatalf("expected ids in request context, got %q %q", gotRequestID, gotCorrelationID)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["correlation_id"] != "checkout-42" || entry["request_id"] != "req-1" {
+		t.Fatalf("expected both ids in log, got %+v", entry)
+	}
+
+	// sin header no se inventa un correlation id.
+	buf.Reset()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if got := w.Header().Get(correlationIDHeader); got != "" {
+		t.Fatalf("expected no correlation header, got %q", got)
+	}
+	if gotCorrelationID != "" || strings.Contains(buf.String(), "correlation_id") {
+		t.Fatalf("expected no correlation id, got %q / %s", gotCorrelationID, buf.String())
+	}
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Claves del contexto de gin donde AuthMiddleware deja la identidad autenticada.
+const (
+	ctxUserIDKey = "user_id"
+	ctxRoleKey   = "role"
+)
+
+// setAuth guarda la identidad validada para los middlewares y handlers siguientes.
+func setAuth(c *gin.Context, auth AuthContext) {
+	c.Set(ctxUserIDKey, auth.UserID)
+	c.Set(ctxRoleKey, auth.Role)
+}
+
+// AuthUserID devuelve el id del usuario autenticado; false si no hay uno.
+func AuthUserID(c *gin.Context) (string, bool) {
+	id := c.GetString(ctxUserIDKey)
+	return id, id != ""
+}
+
+// AuthRole devuelve el rol del usuario autenticado; false si no hay uno.
+func AuthRole(c *gin.Context) (string, bool) {
+	role := c.GetString(ctxRoleKey)
+	return role, role != ""
+}
+
+// MustAuth devuelve la identidad o responde 401 y aborta; el handler debe cortar si ok es false.
+func MustAuth(c *gin.Context) (AuthContext, bool) {
+	userID, ok := AuthUserID(c)
+	if !ok {
+		abortError(c, http.StatusUnauthorized, codeUnauthorized, errors.New("missing user identity"), nil)
+		return AuthContext{}, false
+	}
+	role, _ := AuthRole(c)
+	return AuthContext{UserID: userID, Role: role}, true
+}
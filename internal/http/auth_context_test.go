@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"catalog-api/internal/identity"
+	"catalog-api/pkg/crypto"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthAccessors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if _, ok := AuthUserID(c); ok {
+		t.Fatalf("expected no user id before auth")
+	}
+	if _, ok := AuthRole(c); ok {
+		t.Fatalf("expected no role before auth")
+	}
+
+	setAuth(c, AuthContext{UserID: "u1", Role: "admin"})
+	if id, ok := AuthUserID(c); !ok || id != "u1" {
+		t.Fatalf("expected user id u1, got %q (%v)", id, ok)
+	}
+	if role, ok := AuthRole(c); !ok || role != "admin" {
+		t.Fatalf("expected role admin, got %q (%v)", role, ok)
+	}
+}
+
+func TestMustAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if _, ok := MustAuth(c); ok {
+		t.Fatalf("expected MustAuth to fail without identity")
+	}
+	if w.Code != http.StatusUnauthorized || !c.IsAborted() {
+		t.Fatalf("expected aborted 401, got %d (aborted=%v)", w.Code, c.IsAborted())
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	setAuth(c, AuthContext{UserID: "u1", Role: "user"})
+	auth, ok := MustAuth(c)
+	if !ok || auth.UserID != "u1" || auth.Role != "user" || c.IsAborted() {
+		t.Fatalf("unexpected MustAuth result %+v (ok=%v, aborted=%v)", auth, ok, c.IsAborted())
+	}
+}
+
+func TestAuthMiddleware_RejectsTokenWithoutSubject(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	provider := crypto.JWTProvider{Secret: "test-secret", Issuer: "test", TTL: time.Hour}
+	r := gin.New()
+	r.GET("/me", AuthMiddleware(JWTValidatorAdapter{Provider: provider}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tc := range []struct {
+		userID string
+		want   int
+	}{
+		{userID: "u1", want: http.StatusOK},
+		{userID: "", want: http.StatusUnauthorized},
+	} {
+		token, err := provider.Generate(context.Background(), identity.User{ID: tc.userID, Role: identity.RoleAdmin})
+		if err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("subject %q: expected %d, got %d", tc.userID, tc.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// strictJSONKey marca en el contexto que bindJSON debe rechazar campos desconocidos.
+const strictJSONKey = "strict_json"
+
+// StrictJSONMiddleware activa el binding estricto para los endpoints de alta/edicion.
+// Queda fuera por defecto porque algunos clientes envian campos nuevos antes de
+// que el servidor los conozca.
+func StrictJSONMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(strictJSONKey, true)
+		c.Next()
+	}
+}
+
+// bindJSON equivale a ShouldBindJSON, pero en modo estricto un campo desconocido
+// (p.ej. "stok" en vez de "stock") es un error que nombra el campo.
+func bindJSON(c *gin.Context, obj any) error {
+	if !c.GetBool(strictJSONKey) {
+		return c.ShouldBindJSON(obj)
+	}
+	if c.Request == nil || c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(obj); err != nil {
+		// encoding/json no expone un tipo para este caso; solo el mensaje.
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("unknown field %s", field)
+		}
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"catalog-api/internal/catalog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newJSONContext(body string, strict bool) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if strict {
+		c.Set(strictJSONKey, true)
+	}
+	return c, w
+}
+
+func TestBindJSON_StrictRejectsUnknownField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := newJSONContext(`{"name":"Pen","price":10,"stok":5}`, true)
+
+	var req CreateProductRequest
+	err := bindJSON(c, &req)
+	if err == nil || err.Error() != `unknown field "stok"` {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestBindJSON_LenientIgnoresUnknownField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := newJSONContext(`{"name":"Books","descripton":"typo"}`, false)
+
+	var req CreateCategoryRequest
+	if err := bindJSON(c, &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Name != "Books" || req.Description != "" {
+		t.Fatalf("unexpected request %+v", req)
+	}
+}
+
+func TestBindJSON_StrictStillValidates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := newJSONContext(`{"price":10}`, true)
+
+	var req CreateProductRequest
+	if err := bindJSON(c, &req); err == nil || !strings.Contains(err.Error(), "Name") {
+		t.Fatalf("expected required name validation error, got %v", err)
+	}
+}
+
+func TestCreateProduct_StrictJSONUnknownFieldIs400(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{createProductResp: catalog.Product{ID: "p1"}}
+	h := NewCatalogHandler(svc, nil)
+	c, w := newJSONContext(`{"name":"Pen","price":10,"stok":5}`, true)
+
+	h.CreateProduct(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `unknown field \"stok\"`) {
+		t.Fatalf("expected error naming the field, got %s", w.Body.String())
+	}
+	if svc.createProductInput.Name != "" {
+		t.Fatalf("service must not be called, got %+v", svc.createProductInput)
+	}
+}
+
+func TestCreateProduct_ValidationErrorListsFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewCatalogHandler(&stubCatalogService{}, nil)
+	c, w := newJSONContext(`{"price":10,"stock":1}`, false)
+
+	h.CreateProduct(c)
+
+	var body struct {
+		Code    string             `json:"code"`
+		Details []FieldErrorDetail `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if w.Code != http.StatusBadRequest || body.Code != codeValidation {
+		t.Fatalf("expected 400 validation_error, got %d %s", w.Code, w.Body.String())
+	}
+	if len(body.Details) != 1 || body.Details[0].Field != "name" || body.Details[0].Rule != "required" {
+		t.Fatalf("expected the missing name in details, got %+v", body.Details)
+	}
+}
+
+func TestRouter_StrictJSONFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{createCategoryResp: catalog.Category{ID: "c1", Name: "Books"}}
+	body := `{"name":"Books","descripton":"typo"}`
+
+	for _, tc := range []struct {
+		strict bool
+		want   int
+	}{{strict: true, want: http.StatusBadRequest}, {strict: false, want: http.StatusCreated}} {
+		router := (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil), StrictJSON: tc.strict}).Build()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("strict=%v: expected %d, got %d (%s)", tc.strict, tc.want, w.Code, w.Body.String())
+		}
+	}
+}
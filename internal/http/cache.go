@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// noStore es la directiva para respuestas que nunca deben quedar en caches intermedias.
+const noStore = "no-store"
+
+// CacheControlMiddleware fija Cache-Control segun la ruta registrada (c.FullPath, p.ej.
+// /api/v1/categories). rules solo aplica a GET/HEAD; los metodos que mutan y las rutas
+// de identity responden siempre no-store porque pueden llevar tokens o datos personales.
+func CacheControlMiddleware(rules map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch {
+		case c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead:
+			c.Header("Cache-Control", noStore)
+		case strings.HasPrefix(c.Request.URL.Path, "/api/v1/identity"):
+			c.Header("Cache-Control", noStore)
+		default:
+			if directive, ok := rules[c.FullPath()]; ok && directive != "" {
+				c.Header("Cache-Control", directive)
+			}
+		}
+		c.Next()
+	}
+}
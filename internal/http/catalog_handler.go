@@ -1,9 +1,12 @@
 package http
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
+	"net/url"
+	"path"
+	"strings"
 	"time"
 
 	"catalog-api/internal/catalog"
@@ -22,19 +25,95 @@ func NewCatalogHandler(svc catalog.Service, emitter EventEmitter) *CatalogHandle
 	return &CatalogHandler{svc: svc, emitter: emitter}
 }
 
+// emit publica el evento si hay emisor; sin emisor (p.ej. en tests) es un no-op,
+// igual que socketEmitter.Emit sin hub.
+func (h *CatalogHandler) emit(c *gin.Context, event string, data interface{}) {
+	if h.emitter == nil {
+		return
+	}
+	h.emitter.Emit(c.Request.Context(), event, data)
+}
+
+// emitProduct publica un evento con el producto salvo que sea borrador: los eventos
+// llegan a todos los sockets y webhooks, y el borrador se anuncia con product.published.
+func (h *CatalogHandler) emitProduct(c *gin.Context, event string, p catalog.Product, resp ProductResponse) {
+	if p.Draft {
+		return
+	}
+	h.emit(c, event, resp)
+}
+
+// draftHidden indica si id es un borrador que el llamador no puede ver; los errores
+// se ignoran para que la lectura posterior los responda como siempre.
+func (h *CatalogHandler) draftHidden(c *gin.Context, id string) bool {
+	if !hideDrafts(c) {
+		return false
+	}
+	product, err := h.svc.GetProduct(c.Request.Context(), id)
+	return err == nil && product.Draft
+}
+
 // ListCategories godoc
 // @Summary List categories
+// @Description Sin limit/offset devuelve el arreglo completo; con alguno de ellos devuelve {total, categories}.
 // @Tags Catalog
 // @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
 // @Success 200 {array} CategoryResponse
 // @Router /categories [get]
 func (h *CatalogHandler) ListCategories(c *gin.Context) {
-	cats, err := h.svc.ListCategories(c.Request.Context())
+	_, hasLimit := c.GetQuery("limit")
+	_, hasOffset := c.GetQuery("offset")
+	limit, offset, err := parsePagination(c, 0)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "", err)
+		return
+	}
+	filter := catalog.CategoryFilter{Limit: limit, Offset: offset}
+	cats, total, err := h.svc.ListCategories(c.Request.Context(), filter)
 	if err != nil {
 		respondCatalogError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, toCategoryResponses(cats))
+	// se mantiene el arreglo plano cuando no se pide paginacion (compatibilidad).
+	if !hasLimit && !hasOffset {
+		c.JSON(http.StatusOK, toCategoryResponses(cats))
+		return
+	}
+	c.JSON(http.StatusOK, CategoryListResponse{
+		Total:      total,
+		Categories: toCategoryResponses(cats),
+	})
+}
+
+// CategoryExists godoc
+// @Summary Check category existence
+// @Tags Catalog
+// @Param id path string true "Category ID"
+// @Success 200
+// @Failure 404
+// @Router /categories/{id} [head]
+func (h *CatalogHandler) CategoryExists(c *gin.Context) {
+	exists, err := h.svc.CategoryExists(c.Request.Context(), c.Param("id"))
+	respondExists(c, exists, err)
+}
+
+// GetCategoryBySlug godoc
+// @Summary Get category by slug
+// @Tags Catalog
+// @Produce json
+// @Param slug path string true "Category slug"
+// @Success 200 {object} CategoryResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /categories/slug/{slug} [get]
+func (h *CatalogHandler) GetCategoryBySlug(c *gin.Context) {
+	cat, err := h.svc.GetCategoryBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryResponse(cat))
 }
 
 // CreateCategory godoc
@@ -48,8 +127,8 @@ func (h *CatalogHandler) ListCategories(c *gin.Context) {
 // @Router /categories [post]
 func (h *CatalogHandler) CreateCategory(c *gin.Context) {
 	var req CreateCategoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
 		return
 	}
 	cat, err := h.svc.CreateCategory(c.Request.Context(), catalog.CreateCategoryInput{
@@ -60,12 +139,54 @@ func (h *CatalogHandler) CreateCategory(c *gin.Context) {
 		respondCatalogError(c, err)
 		return
 	}
-	if h.emitter != nil {
-		h.emitter.Emit(ws.EventCategoryCreated, toCategoryResponse(cat))
-	}
+	h.emit(c, ws.EventCategoryCreated, toCategoryResponse(cat))
 	c.JSON(http.StatusCreated, toCategoryResponse(cat))
 }
 
+// BulkCreateCategories godoc
+// @Summary Bulk create categories
+// @Tags Catalog
+// @Accept json
+// @Produce json
+// @Param body body BulkCreateCategoriesRequest true "Categories payload"
+// @Success 201 {array} CategoryResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /categories/bulk [post]
+func (h *CatalogHandler) BulkCreateCategories(c *gin.Context) {
+	var req BulkCreateCategoriesRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
+		return
+	}
+	inputs := make([]catalog.CreateCategoryInput, 0, len(req.Categories))
+	for _, item := range req.Categories {
+		inputs = append(inputs, catalog.CreateCategoryInput{Name: item.Name, Description: item.Description})
+	}
+	cats, err := h.svc.BulkCreateCategories(c.Request.Context(), inputs)
+	if err != nil {
+		var bulkErr *catalog.BulkCategoryError
+		if errors.As(err, &bulkErr) {
+			status := http.StatusBadRequest
+			if errors.Is(err, catalog.ErrCategoryConflict) {
+				status = http.StatusConflict
+			}
+			c.JSON(status, struct {
+				ErrorResponse
+				Index int `json:"index"`
+			}{errorBody(c, bulkErr.Err), bulkErr.Index})
+			return
+		}
+		respondCatalogError(c, err)
+		return
+	}
+	resp := toCategoryResponses(cats)
+	for _, cat := range resp {
+		h.emit(c, ws.EventCategoryCreated, cat)
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
 // UpdateCategory godoc
 // @Summary Update category
 // @Tags Catalog
@@ -78,8 +199,8 @@ func (h *CatalogHandler) CreateCategory(c *gin.Context) {
 // @Router /categories/{id} [put]
 func (h *CatalogHandler) UpdateCategory(c *gin.Context) {
 	var req UpdateCategoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
 		return
 	}
 	id := c.Param("id")
@@ -92,12 +213,35 @@ func (h *CatalogHandler) UpdateCategory(c *gin.Context) {
 		respondCatalogError(c, err)
 		return
 	}
-	if h.emitter != nil {
-		h.emitter.Emit(ws.EventCategoryUpdated, toCategoryResponse(cat))
-	}
+	h.emit(c, ws.EventCategoryUpdated, toCategoryResponse(cat))
 	c.JSON(http.StatusOK, toCategoryResponse(cat))
 }
 
+// ReorderCategories godoc
+// @Summary Reorder categories
+// @Description Asigna el orden de visualizacion segun la lista; las categorias omitidas quedan sin orden.
+// @Tags Catalog
+// @Accept json
+// @Param body body ReorderCategoriesRequest true "Ordered category IDs"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /categories/order [put]
+func (h *CatalogHandler) ReorderCategories(c *gin.Context) {
+	var req ReorderCategoriesRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
+		return
+	}
+	if err := h.svc.ReorderCategories(c.Request.Context(), req.IDs); err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	h.emit(c, ws.EventCategoryReordered, gin.H{"ids": req.IDs})
+	c.Status(http.StatusNoContent)
+}
+
 // DeleteCategory godoc
 // @Summary Delete category
 // @Tags Catalog
@@ -111,9 +255,7 @@ func (h *CatalogHandler) DeleteCategory(c *gin.Context) {
 		respondCatalogError(c, err)
 		return
 	}
-	if h.emitter != nil {
-		h.emitter.Emit(ws.EventCategoryDeleted, gin.H{"id": id})
-	}
+	h.emit(c, ws.EventCategoryDeleted, gin.H{"id": id})
 	c.Status(http.StatusNoContent)
 }
 
@@ -127,9 +269,12 @@ func toCategoryResponses(cats []catalog.Category) []CategoryResponse {
 
 func toCategoryResponse(c catalog.Category) CategoryResponse {
 	return CategoryResponse{
-		ID:          c.ID,
-		Name:        c.Name,
-		Description: c.Description,
+		ID:           c.ID,
+		Name:         c.Name,
+		Slug:         c.Slug,
+		Description:  c.Description,
+		DisplayOrder: c.DisplayOrder,
+		Snippet:      c.Snippet,
 	}
 }
 
@@ -139,41 +284,159 @@ func toCategoryResponse(c catalog.Category) CategoryResponse {
 // @Produce json
 // @Param limit query int false "Limit" default(20)
 // @Param offset query int false "Offset" default(0)
-// @Success 200 {object} map[string]interface{}
+// @Param stock query string false "Stock filter" Enums(out, in, low)
+// @Param category_id query string false "Only products assigned to this category"
+// @Param min_price query int false "Minimum price (inclusive)"
+// @Param max_price query int false "Maximum price (inclusive)"
+// @Param count query bool false "false skips the total count (total is null)" default(true)
+// @Param fields query string false "Comma-separated product fields to return (e.g. id,name)"
+// @Success 200 {object} ProductListResponse
+// @Failure 400 {object} ErrorResponse
 // @Router /products [get]
 func (h *CatalogHandler) ListProducts(c *gin.Context) {
-	limit := parseQueryInt(c, "limit", 20)
-	offset := parseQueryInt(c, "offset", 0)
+	limit, offset, err := parsePagination(c, defaultPageLimit)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "", err)
+		return
+	}
+	fields, err := parseFields(c, productFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "", err)
+		return
+	}
+	minPrice, maxPrice, err := priceRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "", err)
+		return
+	}
+	skipCount := c.Query("count") == "false"
 
 	products, total, err := h.svc.ListProducts(c.Request.Context(), catalog.ProductFilter{
-		Limit:  limit,
-		Offset: offset,
+		Limit:         limit,
+		Offset:        offset,
+		Stock:         stockFilter(c),
+		CategoryID:    strings.TrimSpace(c.Query("category_id")),
+		ExcludeDrafts: hideDrafts(c),
+		MinPrice:      minPrice,
+		MaxPrice:      maxPrice,
+		SkipCount:     skipCount,
 	})
 	if err != nil {
 		respondCatalogError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"total":    total,
-		"products": toProductResponses(products),
-	})
+	meta := newPageMeta(total, limit, offset, len(products))
+	if skipCount {
+		// sin COUNT el total es solo una cota; has_more y next_offset siguen siendo validos.
+		meta.Total = nil
+	}
+	respondProducts(c, meta, toProductResponses(products, int64AsString(c)), fields)
+}
+
+// respondProducts responde un listado de productos con su PageMeta, recortado a los
+// campos de ?fields= cuando se piden.
+func respondProducts(c *gin.Context, meta PageMeta, items []ProductResponse, fields []string) {
+	if fields == nil {
+		c.JSON(http.StatusOK, ProductListResponse{PageMeta: meta, Products: items})
+		return
+	}
+	projected, err := projectProducts(items, fields)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "", err)
+		return
+	}
+	c.JSON(http.StatusOK, struct {
+		PageMeta
+		Products []map[string]json.RawMessage `json:"products"`
+	}{meta, projected})
 }
 
 // GetProduct godoc
 // @Summary Get product detail
+// @Description Un borrador responde 404 salvo para admins.
 // @Tags Products
 // @Produce json
 // @Param id path string true "Product ID"
 // @Success 200 {object} ProductResponse
+// @Failure 404 {object} ErrorResponse
 // @Router /products/{id} [get]
 func (h *CatalogHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
 	product, err := h.svc.GetProduct(c.Request.Context(), id)
+	if err == nil && product.Draft && hideDrafts(c) {
+		err = catalog.ErrProductNotFound
+	}
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toProductResponse(product, int64AsString(c)))
+}
+
+// GetProductBySlug godoc
+// @Summary Get product by slug
+// @Description Un slug anterior (producto renombrado) responde 301 hacia el slug canonico. Un borrador responde 404 salvo para admins.
+// @Tags Products
+// @Produce json
+// @Param slug path string true "Product slug"
+// @Success 200 {object} ProductResponse
+// @Success 301
+// @Failure 404 {object} ErrorResponse
+// @Router /products/slug/{slug} [get]
+func (h *CatalogHandler) GetProductBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+	product, err := h.svc.GetProductBySlug(c.Request.Context(), slug)
+	if err == nil && product.Draft && hideDrafts(c) {
+		err = catalog.ErrProductNotFound
+	}
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	if product.Slug != slug {
+		c.Redirect(http.StatusMovedPermanently, path.Join(path.Dir(c.Request.URL.Path), url.PathEscape(product.Slug)))
+		return
+	}
+	c.JSON(http.StatusOK, toProductResponse(product, int64AsString(c)))
+}
+
+// GetProductByBarcode godoc
+// @Summary Get product by barcode
+// @Description Acepta EAN-13 o UPC-A; un codigo con digito verificador invalido responde 400. Un borrador responde 404 salvo para admins.
+// @Tags Products
+// @Produce json
+// @Param code path string true "EAN-13 or UPC-A"
+// @Success 200 {object} ProductResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /products/by-barcode/{code} [get]
+func (h *CatalogHandler) GetProductByBarcode(c *gin.Context) {
+	product, err := h.svc.GetProductByBarcode(c.Request.Context(), c.Param("code"))
+	if err == nil && product.Draft && hideDrafts(c) {
+		err = catalog.ErrProductNotFound
+	}
 	if err != nil {
 		respondCatalogError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, toProductResponse(product))
+	c.JSON(http.StatusOK, toProductResponse(product, int64AsString(c)))
+}
+
+// ProductExists godoc
+// @Summary Check product existence
+// @Description Un borrador responde 404 salvo para admins.
+// @Tags Products
+// @Param id path string true "Product ID"
+// @Success 200
+// @Failure 404
+// @Router /products/{id} [head]
+func (h *CatalogHandler) ProductExists(c *gin.Context) {
+	if h.draftHidden(c, c.Param("id")) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	exists, err := h.svc.ProductExists(c.Request.Context(), c.Param("id"))
+	respondExists(c, exists, err)
 }
 
 // CreateProduct godoc
@@ -187,40 +450,106 @@ func (h *CatalogHandler) GetProduct(c *gin.Context) {
 // @Router /products [post]
 func (h *CatalogHandler) CreateProduct(c *gin.Context) {
 	var req CreateProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
 		return
 	}
 	product, err := h.svc.CreateProduct(c.Request.Context(), catalog.CreateProductInput{
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
+		Price:       flexInt64Ptr(req.Price),
+		Currency:    req.Currency,
+		Barcode:     req.Barcode,
+		Stock:       flexInt64Ptr(req.Stock),
+		Draft:       req.Draft,
 	})
 	if err != nil {
 		respondCatalogError(c, err)
 		return
 	}
-	if h.emitter != nil {
-		h.emitter.Emit(ws.EventProductCreated, toProductResponse(product))
+	h.emitProduct(c, ws.EventProductCreated, product, toProductResponse(product, int64AsString(c)))
+	c.JSON(http.StatusCreated, toProductResponse(product, int64AsString(c)))
+}
+
+// BulkCreateProducts godoc
+// @Summary Bulk create products
+// @Description Valida todos los items antes de insertar; si alguno falla no se crea ninguno y "details" lista cada indice con su error. Emite un product.created por producto que no sea borrador.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param body body BulkCreateProductsRequest true "Products payload"
+// @Success 201 {array} ProductResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/bulk [post]
+func (h *CatalogHandler) BulkCreateProducts(c *gin.Context) {
+	var req BulkCreateProductsRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
+		return
+	}
+	inputs := make([]catalog.CreateProductInput, 0, len(req.Products))
+	for _, item := range req.Products {
+		inputs = append(inputs, catalog.CreateProductInput{
+			Name:        item.Name,
+			Description: item.Description,
+			Price:       flexInt64Ptr(item.Price),
+			Currency:    item.Currency,
+			Barcode:     item.Barcode,
+			Stock:       flexInt64Ptr(item.Stock),
+			Draft:       item.Draft,
+		})
 	}
-	c.JSON(http.StatusCreated, toProductResponse(product))
+	products, err := h.svc.CreateProducts(c.Request.Context(), inputs)
+	if err != nil {
+		var bulkErr *catalog.BulkProductError
+		if errors.As(err, &bulkErr) {
+			respondBulkProductError(c, bulkErr)
+			return
+		}
+		respondCatalogError(c, err)
+		return
+	}
+	resp := toProductResponses(products, int64AsString(c))
+	for i, p := range resp {
+		h.emitProduct(c, ws.EventProductCreated, products[i], p)
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// respondBulkProductError responde 409 si todos los items chocan por barcode y 400 si no.
+func respondBulkProductError(c *gin.Context, bulkErr *catalog.BulkProductError) {
+	status, top := http.StatusConflict, catalog.ErrDuplicateBarcode
+	details := make([]BulkItemErrorDetail, 0, len(bulkErr.Items))
+	for _, item := range bulkErr.Items {
+		if !errors.Is(item.Err, catalog.ErrDuplicateBarcode) {
+			status, top = http.StatusBadRequest, catalog.ErrInvalidProduct
+		}
+		body := errorBody(c, item.Err)
+		details = append(details, BulkItemErrorDetail{Index: item.Index, Message: body.Message, Code: body.Code})
+	}
+	body := errorBody(c, top)
+	body.Details = details
+	c.JSON(status, body)
 }
 
 // UpdateProduct godoc
 // @Summary Update product
+// @Description Actualizacion parcial: los campos omitidos conservan su valor actual.
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param id path string true "Product ID"
 // @Param body body UpdateProductRequest true "Product update payload"
 // @Success 200 {object} ProductResponse
+// @Success 202 {object} PriceChangePendingResponse "El precio supera el umbral y queda pendiente de aprobacion"
 // @Security BearerAuth
 // @Router /products/{id} [put]
 func (h *CatalogHandler) UpdateProduct(c *gin.Context) {
 	var req UpdateProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
 		return
 	}
 	id := c.Param("id")
@@ -228,17 +557,37 @@ func (h *CatalogHandler) UpdateProduct(c *gin.Context) {
 		ID:          id,
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
+		Price:       flexInt64Ptr(req.Price),
+		Currency:    req.Currency,
+		Barcode:     req.Barcode,
+		Stock:       flexInt64Ptr(req.Stock),
 	})
+	var pending *catalog.PriceChangePendingError
+	if errors.As(err, &pending) {
+		// los demas campos ya se guardaron; el precio espera la decision de un admin.
+		h.emitProduct(c, ws.EventProductUpdated, pending.Product, toProductResponse(pending.Product, int64AsString(c)))
+		h.emitLowStock(c, pending.Product)
+		c.JSON(http.StatusAccepted, PriceChangePendingResponse{
+			Product:            toProductResponse(pending.Product, int64AsString(c)),
+			PriceChangeRequest: toPriceChangeResponse(pending.Request, int64AsString(c)),
+		})
+		return
+	}
 	if err != nil {
 		respondCatalogError(c, err)
 		return
 	}
-	if h.emitter != nil {
-		h.emitter.Emit(ws.EventProductUpdated, toProductResponse(product))
+	h.emitProduct(c, ws.EventProductUpdated, product, toProductResponse(product, int64AsString(c)))
+	h.emitLowStock(c, product)
+	c.JSON(http.StatusOK, toProductResponse(product, int64AsString(c)))
+}
+
+// emitLowStock avisa a los admins cuando la actualizacion cruzo el umbral de stock bajo.
+func (h *CatalogHandler) emitLowStock(c *gin.Context, p catalog.Product) {
+	if !p.LowStockCrossed {
+		return
 	}
-	c.JSON(http.StatusOK, toProductResponse(product))
+	h.emit(c, ws.EventProductLowStock, gin.H{"id": p.ID, "name": p.Name, "stock": p.Stock})
 }
 
 // DeleteProduct godoc
@@ -254,12 +603,108 @@ func (h *CatalogHandler) DeleteProduct(c *gin.Context) {
 		respondCatalogError(c, err)
 		return
 	}
-	if h.emitter != nil {
-		h.emitter.Emit(ws.EventProductDeleted, gin.H{"id": id})
-	}
+	h.emit(c, ws.EventProductDeleted, gin.H{"id": id})
 	c.Status(http.StatusNoContent)
 }
 
+// RestoreProduct godoc
+// @Summary Restore soft-deleted product
+// @Description Restaurar un producto activo es un no-op: responde 200 sin emitir product.restored. Si otro producto tomo su barcode responde 409 duplicate_barcode.
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} ProductResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/{id}/restore [post]
+func (h *CatalogHandler) RestoreProduct(c *gin.Context) {
+	product, restored, err := h.svc.RestoreProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	resp := toProductResponse(product, int64AsString(c))
+	if restored {
+		h.emitProduct(c, ws.EventProductRestored, product, resp)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// PublishProduct godoc
+// @Summary Publish draft product
+// @Description Exige precio y, con PUBLISH_REQUIRES_CATEGORY, una categoria asignada. Publicar un producto ya publicado responde 200 sin emitir product.published.
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} ProductResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/{id}/publish [post]
+func (h *CatalogHandler) PublishProduct(c *gin.Context) {
+	product, published, err := h.svc.PublishProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	resp := toProductResponse(product, int64AsString(c))
+	if published {
+		h.emit(c, ws.EventProductPublished, resp)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// BulkAdjustStock godoc
+// @Summary Bulk adjust product stock
+// @Description Aplica todos los ajustes en una transaccion; si alguno deja stock negativo o el producto no existe, no se aplica ninguno.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param body body BulkAdjustStockRequest true "Stock adjustments"
+// @Success 200 {array} ProductResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/stock/bulk-adjust [post]
+func (h *CatalogHandler) BulkAdjustStock(c *gin.Context) {
+	var req BulkAdjustStockRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
+		return
+	}
+	adjustments := make([]catalog.StockAdjustment, 0, len(req.Adjustments))
+	for _, item := range req.Adjustments {
+		adjustments = append(adjustments, catalog.StockAdjustment{ProductID: item.ProductID, Delta: item.Delta})
+	}
+	products, err := h.svc.BulkAdjustStock(c.Request.Context(), adjustments)
+	if err != nil {
+		var bulkErr *catalog.BulkStockError
+		if errors.As(err, &bulkErr) {
+			status := http.StatusBadRequest
+			switch {
+			case errors.Is(err, catalog.ErrProductNotFound):
+				status = http.StatusNotFound
+			case errors.Is(err, catalog.ErrInsufficientStock):
+				status = http.StatusConflict
+			}
+			c.JSON(status, struct {
+				ErrorResponse
+				Index     int    `json:"index"`
+				ProductID string `json:"product_id"`
+			}{errorBody(c, bulkErr.Err), bulkErr.Index, bulkErr.ProductID})
+			return
+		}
+		respondCatalogError(c, err)
+		return
+	}
+	resp := toProductResponses(products, int64AsString(c))
+	for i, p := range resp {
+		h.emitProduct(c, ws.EventProductUpdated, products[i], p)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // AddProductCategory godoc
 // @Summary Relate product to category
 // @Tags Products
@@ -275,8 +720,29 @@ func (h *CatalogHandler) AddProductCategory(c *gin.Context) {
 		respondCatalogError(c, err)
 		return
 	}
-	if h.emitter != nil {
-		h.emitter.Emit(ws.EventProductCategoryAssigned, gin.H{"product_id": productID, "category_id": categoryID})
+	h.emit(c, ws.EventProductCategoryAssigned, gin.H{"product_id": productID, "category_id": categoryID})
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveProductCategory godoc
+// @Summary Remove product from category
+// @Description Idempotente: si no estaban relacionados responde 204 sin emitir el evento.
+// @Tags Products
+// @Param id path string true "Product ID"
+// @Param categoryId path string true "Category ID"
+// @Success 204
+// @Security BearerAuth
+// @Router /products/{id}/categories/{categoryId} [delete]
+func (h *CatalogHandler) RemoveProductCategory(c *gin.Context) {
+	productID := c.Param("id")
+	categoryID := c.Param("categoryId")
+	removed, err := h.svc.UnassignProductCategory(c.Request.Context(), productID, categoryID)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	if removed {
+		h.emit(c, ws.EventProductCategoryUnassigned, gin.H{"product_id": productID, "category_id": categoryID})
 	}
 	c.Status(http.StatusNoContent)
 }
@@ -285,40 +751,67 @@ func (h *CatalogHandler) AddProductCategory(c *gin.Context) {
 func (h *CatalogHandler) Search(c *gin.Context) {
 	kind := c.Query("type")
 	query := c.Query("q")
-	limit := parseQueryInt(c, "limit", 20)
-	offset := parseQueryInt(c, "offset", 0)
+	// sin limit explicito el servicio aplica su propio default de busqueda.
+	limit, offset, err := parsePagination(c, 0)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "", err)
+		return
+	}
 	sortBy := c.Query("sort")
 	sortDir := c.Query("order")
+	// el resaltado es opt-in porque ts_headline es costoso.
+	highlight := c.Query("highlight") == "true"
+	// ?fields= y el rango de precio solo aplican a productos; en categorias se ignoran.
+	var fields []string
+	var minPrice, maxPrice *int64
+	if kind == catalog.SearchKindProduct {
+		if fields, err = parseFields(c, productFields); err != nil {
+			respondError(c, http.StatusBadRequest, "", err)
+			return
+		}
+		if minPrice, maxPrice, err = priceRange(c); err != nil {
+			respondError(c, http.StatusBadRequest, "", err)
+			return
+		}
+	}
 
 	result, err := h.svc.Search(c.Request.Context(), catalog.SearchFilter{
-		Kind:    kind,
-		Query:   query,
-		Limit:   limit,
-		Offset:  offset,
-		SortBy:  sortBy,
-		SortDir: sortDir,
+		Kind:          kind,
+		Query:         query,
+		Limit:         limit,
+		Offset:        offset,
+		SortBy:        sortBy,
+		SortDir:       sortDir,
+		Highlight:     highlight,
+		Stock:         stockFilter(c),
+		ExcludeDrafts: hideDrafts(c),
+		MinPrice:      minPrice,
+		MaxPrice:      maxPrice,
 	})
 	if err != nil {
 		respondCatalogError(c, err)
 		return
 	}
 
-	if kind == "category" {
+	// cada tipo responde solo su clave; sin resultados la lista va vacia, nunca null.
+	switch kind {
+	case catalog.SearchKindCategory:
 		c.JSON(http.StatusOK, gin.H{
 			"total":      result.Total,
 			"categories": toCategoryResponses(result.Categories),
 		})
-		return
+	case catalog.SearchKindProduct:
+		meta := newPageMeta(result.Total, result.Limit, result.Offset, len(result.Products))
+		respondProducts(c, meta, toProductResponses(result.Products, int64AsString(c)), fields)
+	default:
+		// el servicio ya rechaza tipos desconocidos; esto evita responder productos por omision.
+		respondCatalogError(c, catalog.ErrInvalidSearchKind)
 	}
-	// por defecto, productos
-	c.JSON(http.StatusOK, gin.H{
-		"total":    result.Total,
-		"products": toProductResponses(result.Products),
-	})
 }
 
 // GetProductHistory godoc
 // @Summary Product history
+// @Description Un borrador responde 404 salvo para admins.
 // @Tags Products
 // @Produce json
 // @Param id path string true "Product ID"
@@ -328,6 +821,10 @@ func (h *CatalogHandler) Search(c *gin.Context) {
 // @Router /products/{id}/history [get]
 func (h *CatalogHandler) GetProductHistory(c *gin.Context) {
 	id := c.Param("id")
+	if h.draftHidden(c, id) {
+		respondCatalogError(c, catalog.ErrProductNotFound)
+		return
+	}
 	startStr := c.Query("start")
 	endStr := c.Query("end")
 
@@ -336,14 +833,14 @@ func (h *CatalogHandler) GetProductHistory(c *gin.Context) {
 	if startStr != "" {
 		start, err = time.Parse(time.RFC3339, startStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start date"})
+			respondError(c, http.StatusBadRequest, codeInvalidRequest, errors.New("invalid start date"))
 			return
 		}
 	}
 	if endStr != "" {
 		end, err = time.Parse(time.RFC3339, endStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end date"})
+			respondError(c, http.StatusBadRequest, codeInvalidRequest, errors.New("invalid end date"))
 			return
 		}
 	}
@@ -357,60 +854,143 @@ func (h *CatalogHandler) GetProductHistory(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, toProductHistoryResponses(items))
+	c.JSON(http.StatusOK, toProductHistoryResponses(items, int64AsString(c)))
 }
 
-func toProductResponses(products []catalog.Product) []ProductResponse {
+// GetLatestProductHistory godoc
+// @Summary Latest product history entry
+// @Description Un borrador responde 404 salvo para admins.
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} ProductHistoryResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{id}/history/latest [get]
+func (h *CatalogHandler) GetLatestProductHistory(c *gin.Context) {
+	if h.draftHidden(c, c.Param("id")) {
+		respondCatalogError(c, catalog.ErrProductNotFound)
+		return
+	}
+	entry, err := h.svc.GetLatestProductHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toProductHistoryResponse(entry, int64AsString(c)))
+}
+
+// ListProductCategories godoc
+// @Summary List product categories
+// @Description Un borrador responde 404 salvo para admins.
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} CategoryResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{id}/categories [get]
+func (h *CatalogHandler) ListProductCategories(c *gin.Context) {
+	if h.draftHidden(c, c.Param("id")) {
+		respondCatalogError(c, catalog.ErrProductNotFound)
+		return
+	}
+	cats, err := h.svc.ListProductCategories(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toCategoryResponses(cats))
+}
+
+func toProductResponses(products []catalog.Product, asString bool) []ProductResponse {
 	out := make([]ProductResponse, 0, len(products))
 	for _, p := range products {
-		out = append(out, toProductResponse(p))
+		out = append(out, toProductResponse(p, asString))
 	}
 	return out
 }
 
-func toProductResponse(p catalog.Product) ProductResponse {
+func toProductResponse(p catalog.Product, asString bool) ProductResponse {
+	var categories []CategoryResponse
+	if p.Categories != nil {
+		categories = toCategoryResponses(p.Categories)
+	}
 	return ProductResponse{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-		Stock:       p.Stock,
+		ID:            p.ID,
+		Name:          p.Name,
+		Slug:          p.Slug,
+		Description:   p.Description,
+		Price:         p.Price,
+		Currency:      p.Currency,
+		Barcode:       p.Barcode,
+		Stock:         p.Stock,
+		Draft:         p.Draft,
+		Snippet:       p.Snippet,
+		Categories:    categories,
+		int64AsString: asString,
 	}
 }
 
-func toProductHistoryResponses(items []catalog.ProductHistory) []ProductHistoryResponse {
+func toProductHistoryResponses(items []catalog.ProductHistory, asString bool) []ProductHistoryResponse {
 	out := make([]ProductHistoryResponse, 0, len(items))
 	for _, h := range items {
-		out = append(out, ProductHistoryResponse{
-			ID:        h.ID,
-			ProductID: h.ProductID,
-			Price:     h.Price,
-			Stock:     h.Stock,
-			ChangedAt: h.ChangedAt.Format(time.RFC3339),
-		})
+		out = append(out, toProductHistoryResponse(h, asString))
 	}
 	return out
 }
 
-func parseQueryInt(c *gin.Context, key string, fallback int) int {
-	if v, ok := c.GetQuery(key); ok {
-		if parsed, err := strconv.Atoi(v); err == nil {
-			return parsed
-		}
+func toProductHistoryResponse(h catalog.ProductHistory, asString bool) ProductHistoryResponse {
+	return ProductHistoryResponse{
+		ID:            h.ID,
+		ProductID:     h.ProductID,
+		Price:         h.Price,
+		Stock:         h.Stock,
+		ChangedAt:     h.ChangedAt.Format(time.RFC3339),
+		int64AsString: asString,
+	}
+}
+
+// respondExists contesta HEAD sin cuerpo: 200 si existe, 404 si no.
+func respondExists(c *gin.Context, exists bool, err error) {
+	switch {
+	case err != nil && (errors.Is(err, catalog.ErrInvalidCategoryID) || errors.Is(err, catalog.ErrInvalidProductID)):
+		c.Status(http.StatusNotFound)
+	case err != nil:
+		_ = c.Error(err)
+		c.Status(http.StatusInternalServerError)
+	case exists:
+		c.Status(http.StatusOK)
+	default:
+		c.Status(http.StatusNotFound)
 	}
-	return fallback
 }
 
 func respondCatalogError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, catalog.ErrInvalidCategory),
 		errors.Is(err, catalog.ErrInvalidCategoryID),
+		errors.Is(err, catalog.ErrInvalidSlug),
+		errors.Is(err, catalog.ErrInvalidCategoryOrder),
 		errors.Is(err, catalog.ErrInvalidProduct),
 		errors.Is(err, catalog.ErrInvalidProductID),
-		errors.Is(err, catalog.ErrInvalidSearchKind):
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		errors.Is(err, catalog.ErrInvalidSearchKind),
+		errors.Is(err, catalog.ErrInvalidCurrency),
+		errors.Is(err, catalog.ErrInvalidBarcode),
+		errors.Is(err, catalog.ErrInvalidStockFilter),
+		errors.Is(err, catalog.ErrInvalidPriceRange),
+		errors.Is(err, catalog.ErrInvalidStockAdjustment),
+		errors.Is(err, catalog.ErrInvalidPriceChangeState):
+		respondError(c, http.StatusBadRequest, "", err)
+	case errors.Is(err, catalog.ErrProductNotFound),
+		errors.Is(err, catalog.ErrProductHistoryNotFound),
+		errors.Is(err, catalog.ErrCategoryNotFound),
+		errors.Is(err, catalog.ErrPriceChangeNotFound):
+		respondError(c, http.StatusNotFound, "", err)
+	case errors.Is(err, catalog.ErrPriceChangeDecided),
+		errors.Is(err, catalog.ErrPriceChangeStale),
+		errors.Is(err, catalog.ErrDuplicateBarcode),
+		errors.Is(err, catalog.ErrProductIncomplete):
+		respondError(c, http.StatusConflict, "", err)
 	default:
-		_ = c.Error(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		respondError(c, http.StatusInternalServerError, "", err)
 	}
 }
@@ -5,6 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -18,13 +21,23 @@ import (
 )
 
 type stubCatalogService struct {
-	listCategoriesResp []catalog.Category
-	listCategoriesErr  error
+	listCategoriesFilter catalog.CategoryFilter
+	listCategoriesResp   []catalog.Category
+	listCategoriesTotal  int64
+	listCategoriesErr    error
+
+	categorySlug    string
+	categoryBySlug  catalog.Category
+	categorySlugErr error
 
 	createCategoryInput catalog.CreateCategoryInput
 	createCategoryResp  catalog.Category
 	createCategoryErr   error
 
+	bulkCategoryInputs []catalog.CreateCategoryInput
+	bulkCategoryResp   []catalog.Category
+	bulkCategoryErr    error
+
 	updateCategoryInput catalog.UpdateCategoryInput
 	updateCategoryResp  catalog.Category
 	updateCategoryErr   error
@@ -32,6 +45,11 @@ type stubCatalogService struct {
 	deleteCategoryID  string
 	deleteCategoryErr error
 
+	reorderIDs []string
+	reorderErr error
+
+	existingIDs map[string]bool
+
 	listProductsFilter catalog.ProductFilter
 	listProductsResp   []catalog.Product
 	listProductsTotal  int64
@@ -41,9 +59,31 @@ type stubCatalogService struct {
 	getProductResp catalog.Product
 	getProductErr  error
 
-	createProductInput catalog.CreateProductInput
-	createProductResp  catalog.Product
-	createProductErr   error
+	productSlug    string
+	productBySlug  catalog.Product
+	productSlugErr error
+
+	barcode          string
+	productByBarcode catalog.Product
+	barcodeErr       error
+
+	statsResp  catalog.Stats
+	statsErr   error
+	statsCalls int
+
+	priceChanges       []catalog.PriceChangeRequest
+	priceChangeStatus  catalog.PriceChangeStatus
+	priceChangeID      string
+	priceChangeResp    catalog.PriceChangeRequest
+	priceChangeProduct catalog.Product
+	priceChangeErr     error
+
+	createProductInput  catalog.CreateProductInput
+	createProductResp   catalog.Product
+	createProductErr    error
+	createProductsInput []catalog.CreateProductInput
+	createProductsResp  []catalog.Product
+	createProductsErr   error
 
 	updateProductInput catalog.UpdateProductInput
 	updateProductResp  catalog.Product
@@ -52,9 +92,30 @@ type stubCatalogService struct {
 	deleteProductID  string
 	deleteProductErr error
 
-	assignProductCategoryProductID  string
-	assignProductCategoryCategoryID string
-	assignProductCategoryErr        error
+	restoreProductID   string
+	restoreProductResp catalog.Product
+	restored           bool
+	restoreProductErr  error
+
+	publishProductID   string
+	publishProductResp catalog.Product
+	published          bool
+	publishProductErr  error
+
+	bulkAdjustInput []catalog.StockAdjustment
+	bulkAdjustResp  []catalog.Product
+	bulkAdjustErr   error
+
+	assignProductCategoryProductID    string
+	assignProductCategoryCategoryID   string
+	assignProductCategoryErr          error
+	unassignProductCategoryProductID  string
+	unassignProductCategoryCategoryID string
+	unassignProductCategoryRemoved    bool
+
+	productCategoriesID   string
+	productCategoriesResp []catalog.Category
+	productCategoriesErr  error
 
 	searchFilter catalog.SearchFilter
 	searchResp   catalog.SearchResult
@@ -64,10 +125,20 @@ type stubCatalogService struct {
 	historyFilter    catalog.ProductHistoryFilter
 	historyResp      []catalog.ProductHistory
 	historyErr       error
+
+	latestHistoryID   string
+	latestHistoryResp catalog.ProductHistory
+	latestHistoryErr  error
+}
+
+func (s *stubCatalogService) ListCategories(ctx context.Context, filter catalog.CategoryFilter) ([]catalog.Category, int64, error) {
+	s.listCategoriesFilter = filter
+	return s.listCategoriesResp, s.listCategoriesTotal, s.listCategoriesErr
 }
 
-func (s *stubCatalogService) ListCategories(ctx context.Context) ([]catalog.Category, error) {
-	return s.listCategoriesResp, s.listCategoriesErr
+func (s *stubCatalogService) GetCategoryBySlug(ctx context.Context, slug string) (catalog.Category, error) {
+	s.categorySlug = slug
+	return s.categoryBySlug, s.categorySlugErr
 }
 
 func (s *stubCatalogService) CreateCategory(ctx context.Context, input catalog.CreateCategoryInput) (catalog.Category, error) {
@@ -75,6 +146,19 @@ func (s *stubCatalogService) CreateCategory(ctx context.Context, input catalog.C
 	return s.createCategoryResp, s.createCategoryErr
 }
 
+func (s *stubCatalogService) BulkCreateCategories(ctx context.Context, inputs []catalog.CreateCategoryInput) ([]catalog.Category, error) {
+	s.bulkCategoryInputs = inputs
+	return s.bulkCategoryResp, s.bulkCategoryErr
+}
+
+func (s *stubCatalogService) CategoryExists(ctx context.Context, id string) (bool, error) {
+	return s.existingIDs[id], nil
+}
+
+func (s *stubCatalogService) ProductExists(ctx context.Context, id string) (bool, error) {
+	return s.existingIDs[id], nil
+}
+
 func (s *stubCatalogService) UpdateCategory(ctx context.Context, input catalog.UpdateCategoryInput) (catalog.Category, error) {
 	s.updateCategoryInput = input
 	return s.updateCategoryResp, s.updateCategoryErr
@@ -85,6 +169,11 @@ func (s *stubCatalogService) DeleteCategory(ctx context.Context, id string) erro
 	return s.deleteCategoryErr
 }
 
+func (s *stubCatalogService) ReorderCategories(ctx context.Context, ids []string) error {
+	s.reorderIDs = ids
+	return s.reorderErr
+}
+
 func (s *stubCatalogService) ListProducts(ctx context.Context, filter catalog.ProductFilter) ([]catalog.Product, int64, error) {
 	s.listProductsFilter = filter
 	return s.listProductsResp, s.listProductsTotal, s.listProductsErr
@@ -95,21 +184,51 @@ func (s *stubCatalogService) GetProduct(ctx context.Context, id string) (catalog
 	return s.getProductResp, s.getProductErr
 }
 
+func (s *stubCatalogService) GetProductBySlug(ctx context.Context, slug string) (catalog.Product, error) {
+	s.productSlug = slug
+	return s.productBySlug, s.productSlugErr
+}
+
+func (s *stubCatalogService) GetProductByBarcode(ctx context.Context, barcode string) (catalog.Product, error) {
+	s.barcode = barcode
+	return s.productByBarcode, s.barcodeErr
+}
+
 func (s *stubCatalogService) CreateProduct(ctx context.Context, input catalog.CreateProductInput) (catalog.Product, error) {
 	s.createProductInput = input
 	return s.createProductResp, s.createProductErr
 }
 
+func (s *stubCatalogService) CreateProducts(ctx context.Context, inputs []catalog.CreateProductInput) ([]catalog.Product, error) {
+	s.createProductsInput = inputs
+	return s.createProductsResp, s.createProductsErr
+}
+
 func (s *stubCatalogService) UpdateProduct(ctx context.Context, input catalog.UpdateProductInput) (catalog.Product, error) {
 	s.updateProductInput = input
 	return s.updateProductResp, s.updateProductErr
 }
 
+func (s *stubCatalogService) BulkAdjustStock(ctx context.Context, adjustments []catalog.StockAdjustment) ([]catalog.Product, error) {
+	s.bulkAdjustInput = adjustments
+	return s.bulkAdjustResp, s.bulkAdjustErr
+}
+
 func (s *stubCatalogService) DeleteProduct(ctx context.Context, id string) error {
 	s.deleteProductID = id
 	return s.deleteProductErr
 }
 
+func (s *stubCatalogService) RestoreProduct(ctx context.Context, id string) (catalog.Product, bool, error) {
+	s.restoreProductID = id
+	return s.restoreProductResp, s.restored, s.restoreProductErr
+}
+
+func (s *stubCatalogService) PublishProduct(ctx context.Context, id string) (catalog.Product, bool, error) {
+	s.publishProductID = id
+	return s.publishProductResp, s.published, s.publishProductErr
+}
+
 func (s *stubCatalogService) Search(ctx context.Context, filter catalog.SearchFilter) (catalog.SearchResult, error) {
 	s.searchFilter = filter
 	return s.searchResp, s.searchErr
@@ -121,20 +240,58 @@ func (s *stubCatalogService) GetProductHistory(ctx context.Context, id string, f
 	return s.historyResp, s.historyErr
 }
 
+func (s *stubCatalogService) GetLatestProductHistory(ctx context.Context, id string) (catalog.ProductHistory, error) {
+	s.latestHistoryID = id
+	return s.latestHistoryResp, s.latestHistoryErr
+}
+
 func (s *stubCatalogService) AssignProductCategory(ctx context.Context, productID, categoryID string) error {
 	s.assignProductCategoryProductID = productID
 	s.assignProductCategoryCategoryID = categoryID
 	return s.assignProductCategoryErr
 }
 
+func (s *stubCatalogService) UnassignProductCategory(ctx context.Context, productID, categoryID string) (bool, error) {
+	s.unassignProductCategoryProductID = productID
+	s.unassignProductCategoryCategoryID = categoryID
+	return s.unassignProductCategoryRemoved, nil
+}
+
+func (s *stubCatalogService) Stats(ctx context.Context) (catalog.Stats, error) {
+	s.statsCalls++
+	return s.statsResp, s.statsErr
+}
+
+func (s *stubCatalogService) ListPriceChangeRequests(ctx context.Context, status catalog.PriceChangeStatus) ([]catalog.PriceChangeRequest, error) {
+	s.priceChangeStatus = status
+	return s.priceChanges, s.priceChangeErr
+}
+
+func (s *stubCatalogService) ApprovePriceChange(ctx context.Context, id string) (catalog.PriceChangeRequest, catalog.Product, error) {
+	s.priceChangeID = id
+	return s.priceChangeResp, s.priceChangeProduct, s.priceChangeErr
+}
+
+func (s *stubCatalogService) RejectPriceChange(ctx context.Context, id string) (catalog.PriceChangeRequest, error) {
+	s.priceChangeID = id
+	return s.priceChangeResp, s.priceChangeErr
+}
+
+func (s *stubCatalogService) ListProductCategories(ctx context.Context, productID string) ([]catalog.Category, error) {
+	s.productCategoriesID = productID
+	return s.productCategoriesResp, s.productCategoriesErr
+}
+
 type testRecordingEmitter struct {
-	events []string
-	data   []interface{}
+	events         []string
+	data           []interface{}
+	correlationIDs []string
 }
 
-func (r *testRecordingEmitter) Emit(event string, data interface{}) {
+func (r *testRecordingEmitter) Emit(ctx context.Context, event string, data interface{}) {
 	r.events = append(r.events, event)
 	r.data = append(r.data, data)
+	r.correlationIDs = append(r.correlationIDs, CorrelationIDFromContext(ctx))
 }
 
 func TestCreateCategory_Success(t *testing.T) {
@@ -171,6 +328,213 @@ func TestCreateCategory_Success(t *testing.T) {
 	}
 }
 
+func TestGetCategoryBySlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		categoryBySlug: catalog.Category{ID: "c1", Name: "Libros Usados", Slug: "libros-usados"},
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/categories/slug/libros-usados", nil)
+	c.Params = gin.Params{{Key: "slug", Value: "libros-usados"}}
+
+	h.GetCategoryBySlug(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.categorySlug != "libros-usados" {
+		t.Fatalf("expected slug forwarded, got %q", svc.categorySlug)
+	}
+	var resp CategoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Slug != "libros-usados" {
+		t.Fatalf("expected slug in response, got %+v", resp)
+	}
+}
+
+func TestGetCategoryBySlug_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{categorySlugErr: catalog.ErrCategoryNotFound}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/categories/slug/missing", nil)
+	c.Params = gin.Params{{Key: "slug", Value: "missing"}}
+
+	h.GetCategoryBySlug(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestReorderCategories_EmitsEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/categories/order", strings.NewReader(`{"ids":["c2","c1"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.ReorderCategories(c)
+
+	if c.Writer.Status() != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", c.Writer.Status())
+	}
+	if len(svc.reorderIDs) != 2 || svc.reorderIDs[0] != "c2" {
+		t.Fatalf("unexpected ids forwarded: %v", svc.reorderIDs)
+	}
+	if len(em.events) != 1 || em.events[0] != ws.EventCategoryReordered {
+		t.Fatalf("expected category reordered event, got %+v", em.events)
+	}
+	payload, _ := json.Marshal(em.data[0])
+	if string(payload) != `{"ids":["c2","c1"]}` {
+		t.Fatalf("unexpected payload %s", payload)
+	}
+}
+
+func TestReorderCategories_UnknownIDIs404WithoutEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{reorderErr: catalog.ErrCategoryNotFound}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/categories/order", strings.NewReader(`{"ids":["missing"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.ReorderCategories(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if len(em.events) != 0 {
+		t.Fatalf("expected no events, got %+v", em.events)
+	}
+}
+
+func TestCreateProduct_NilEmitterIsNoop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	emitters := map[string]EventEmitter{
+		"nil emitter":         nil,
+		"emitter without hub": NewSocketEmitter(nil),
+	}
+	for name, em := range emitters {
+		t.Run(name, func(t *testing.T) {
+			svc := &stubCatalogService{
+				createProductResp: catalog.Product{ID: "p1", Name: "Pen", Price: 100, Stock: 1},
+			}
+			h := NewCatalogHandler(svc, em)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Pen","price":100,"stock":1}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.CreateProduct(c)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateProduct_EventCarriesCorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		createProductResp: catalog.Product{ID: "p1", Name: "Pen", Price: 100, Stock: 1},
+	}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+	r := gin.New()
+	r.Use(AccessLogMiddleware(slog.New(slog.NewTextHandler(io.Discard, nil)), 0))
+	r.POST("/products", h.CreateProduct)
+
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Pen","price":100,"stock":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(correlationIDHeader, "checkout-42")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(em.correlationIDs) != 1 || em.correlationIDs[0] != "checkout-42" {
+		t.Fatalf("expected event with correlation id, got %v", em.correlationIDs)
+	}
+}
+
+func TestBulkAdjustStock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"adjustments":[{"product_id":"p1","delta":5},{"product_id":"p2","delta":-3}]}`
+	cases := []struct {
+		name       string
+		body       string
+		svc        *stubCatalogService
+		wantCode   int
+		wantEvents int
+	}{
+		{
+			name:       "applied",
+			body:       body,
+			svc:        &stubCatalogService{bulkAdjustResp: []catalog.Product{{ID: "p1", Stock: 15}, {ID: "p2", Stock: 0}}},
+			wantCode:   http.StatusOK,
+			wantEvents: 2,
+		},
+		{
+			name:     "negative stock rejects batch",
+			body:     body,
+			svc:      &stubCatalogService{bulkAdjustErr: &catalog.BulkStockError{Index: 1, ProductID: "p2", Err: catalog.ErrInsufficientStock}},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "unknown product",
+			body:     body,
+			svc:      &stubCatalogService{bulkAdjustErr: &catalog.BulkStockError{Index: 0, ProductID: "p1", Err: catalog.ErrProductNotFound}},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "zero delta",
+			body:     `{"adjustments":[{"product_id":"p1","delta":0}]}`,
+			svc:      &stubCatalogService{},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			em := &testRecordingEmitter{}
+			h := NewCatalogHandler(tc.svc, em)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/products/stock/bulk-adjust", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.BulkAdjustStock(c)
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if len(em.events) != tc.wantEvents {
+				t.Fatalf("expected %d events, got %v", tc.wantEvents, em.events)
+			}
+			if tc.wantCode == http.StatusConflict && !strings.Contains(w.Body.String(), `"product_id":"p2"`) {
+				t.Fatalf("expected offending product in body, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
 func TestCreateCategory_BadRequest(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubCatalogService{}
@@ -264,6 +628,118 @@ func TestListCategories_Error(t *testing.T) {
 	}
 }
 
+func TestListCategories_UnpaginatedKeepsArrayShape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		listCategoriesResp:  []catalog.Category{{ID: "c1", Name: "Books"}, {ID: "c2", Name: "Home"}},
+		listCategoriesTotal: 2,
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/categories", nil)
+
+	h.ListCategories(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.listCategoriesFilter.Limit != 0 || svc.listCategoriesFilter.Offset != 0 {
+		t.Fatalf("expected unpaginated filter, got %+v", svc.listCategoriesFilter)
+	}
+	var resp []CategoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("expected array response: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(resp))
+	}
+}
+
+func TestListCategories_PaginatedIncludesTotal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		listCategoriesResp:  []catalog.Category{{ID: "c2", Name: "Home"}},
+		listCategoriesTotal: 7,
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/categories?limit=1&offset=1", nil)
+
+	h.ListCategories(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.listCategoriesFilter.Limit != 1 || svc.listCategoriesFilter.Offset != 1 {
+		t.Fatalf("expected limit 1 offset 1, got %+v", svc.listCategoriesFilter)
+	}
+	var resp CategoryListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Total != 7 || len(resp.Categories) != 1 || resp.Categories[0].ID != "c2" {
+		t.Fatalf("unexpected paginated response %+v", resp)
+	}
+}
+
+func TestListProducts_PassesStockFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/products?stock=LOW&category_id=c1", nil)
+
+	h.ListProducts(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.listProductsFilter.Stock != catalog.StockLow {
+		t.Fatalf("expected stock filter low, got %q", svc.listProductsFilter.Stock)
+	}
+	if svc.listProductsFilter.CategoryID != "c1" {
+		t.Fatalf("expected category filter c1, got %q", svc.listProductsFilter.CategoryID)
+	}
+}
+
+func TestListProducts_PriceRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	list := func(svc *stubCatalogService, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		NewCatalogHandler(svc, nil).ListProducts(c)
+		return w
+	}
+
+	svc := &stubCatalogService{}
+	if w := list(svc, "/products?min_price=100&max_price=500"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	f := svc.listProductsFilter
+	if f.MinPrice == nil || *f.MinPrice != 100 || f.MaxPrice == nil || *f.MaxPrice != 500 {
+		t.Fatalf("expected price range 100..500, got %+v", f)
+	}
+	list(svc, "/products?min_price=0")
+	if f := svc.listProductsFilter; f.MinPrice == nil || *f.MinPrice != 0 || f.MaxPrice != nil {
+		t.Fatalf("expected min_price=0 to be kept and max_price unset, got %+v", f)
+	}
+
+	if w := list(&stubCatalogService{}, "/products?min_price=cheap"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a non-numeric price, got %d", w.Code)
+	}
+	w := list(&stubCatalogService{listProductsErr: catalog.ErrInvalidPriceRange}, "/products?min_price=500&max_price=100")
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":"invalid_price_range"`) {
+		t.Fatalf("expected 400 invalid_price_range, got %d %s", w.Code, w.Body.String())
+	}
+}
+
 func TestListProducts_UsesQueryDefaults(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubCatalogService{
@@ -299,7 +775,8 @@ func TestListProducts_UsesQueryDefaults(t *testing.T) {
 func TestGetProduct_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubCatalogService{
-		getProductResp: catalog.Product{ID: "p1", Name: "Pen", Description: "Blue", Price: 10, Stock: 2},
+		getProductResp: catalog.Product{ID: "p1", Name: "Pen", Description: "Blue", Price: 10, Stock: 2,
+			Categories: []catalog.Category{{ID: "c1", Name: "Office", Slug: "office"}}},
 	}
 	h := NewCatalogHandler(svc, nil)
 
@@ -323,70 +800,604 @@ func TestGetProduct_Success(t *testing.T) {
 	if resp.ID != "p1" || resp.Name != "Pen" {
 		t.Fatalf("unexpected response %+v", resp)
 	}
+	if len(resp.Categories) != 1 || resp.Categories[0].Slug != "office" {
+		t.Fatalf("expected assigned categories, got %+v", resp.Categories)
+	}
 }
 
-func TestCreateProduct_Success(t *testing.T) {
+func TestGetProductBySlug_Canonical(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	svc := &stubCatalogService{
-		createProductResp: catalog.Product{ID: "p1", Name: "Pen", Description: "Blue", Price: 10, Stock: 2},
-	}
-	em := &testRecordingEmitter{}
-	h := NewCatalogHandler(svc, em)
+	svc := &stubCatalogService{productBySlug: catalog.Product{ID: "p1", Name: "Pen", Slug: "pen"}}
+	h := NewCatalogHandler(svc, nil)
 
-	body := `{"name":"Pen","description":"Blue","price":10,"stock":2}`
-	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request = req
+	c.Params = gin.Params{{Key: "slug", Value: "pen"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/products/slug/pen", nil)
 
-	h.CreateProduct(c)
+	h.GetProductBySlug(c)
 
-	if w.Code != http.StatusCreated {
-		t.Fatalf("expected 201, got %d", w.Code)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
 	}
-	if svc.createProductInput.Name != "Pen" || svc.createProductInput.Price != 10 {
-		t.Fatalf("service received %+v", svc.createProductInput)
+	var resp ProductResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
 	}
-	if len(em.events) != 1 || em.events[0] != ws.EventProductCreated {
-		t.Fatalf("expected product created event, got %+v", em.events)
+	if resp.Slug != "pen" {
+		t.Fatalf("unexpected response %+v", resp)
 	}
 }
 
-func TestUpdateProduct_Success(t *testing.T) {
+func TestGetProductByBarcode(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	svc := &stubCatalogService{
-		updateProductResp: catalog.Product{ID: "p1", Name: "Pen", Description: "Red", Price: 12, Stock: 5},
-	}
-	em := &recordingEmitter{}
-	h := NewCatalogHandler(svc, em)
+	svc := &stubCatalogService{productByBarcode: catalog.Product{ID: "p1", Name: "Pen", Barcode: "4006381333931"}}
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil)}).Build()
 
-	body := `{"name":"Pen","description":"Red","price":12,"stock":5}`
-	req := httptest.NewRequest(http.MethodPut, "/products/p1", bytes.NewBufferString(body))
-	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
-	c.Params = gin.Params{{Key: "id", Value: "p1"}}
-	c.Request = req
-
-	h.UpdateProduct(c)
-
-	if w.Code != http.StatusOK {
-		t.Fatalf("expected 200, got %d", w.Code)
-	}
-	if svc.updateProductInput.ID != "p1" || svc.updateProductInput.Price != 12 {
-		t.Fatalf("service received %+v", svc.updateProductInput)
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/by-barcode/4006381333931", nil))
+	if w.Code != http.StatusOK || svc.barcode != "4006381333931" || !strings.Contains(w.Body.String(), `"barcode":"4006381333931"`) {
+		t.Fatalf("unexpected response %d %s (service got %q)", w.Code, w.Body.String(), svc.barcode)
 	}
-	if len(em.events) != 1 || em.events[0] != ws.EventProductUpdated {
-		t.Fatalf("expected product updated event, got %+v", em.events)
+
+	svc.barcodeErr = catalog.ErrInvalidBarcode
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/by-barcode/4006381333932", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid checksum, got %d", w.Code)
 	}
 }
 
-func TestDeleteProduct_Error(t *testing.T) {
+func TestCreateProduct_DuplicateBarcodeConflict(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	svc := &stubCatalogService{deleteProductErr: errors.New("fail")}
-	em := &recordingEmitter{}
-	h := NewCatalogHandler(svc, em)
+	svc := &stubCatalogService{createProductErr: catalog.ErrDuplicateBarcode}
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil)}).Build()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(`{"name":"Pen","price":10,"stock":1,"barcode":"4006381333931"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"code":"duplicate_barcode"`) {
+		t.Fatalf("expected 409 duplicate_barcode, got %d %s", w.Code, w.Body.String())
+	}
+	if svc.createProductInput.Barcode != "4006381333931" {
+		t.Fatalf("barcode not forwarded: %+v", svc.createProductInput)
+	}
+}
+
+func TestGetProductBySlug_OldSlugRedirects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{productBySlug: catalog.Product{ID: "p1", Name: "Fountain Pen", Slug: "fountain-pen"}}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "slug", Value: "pen"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/products/slug/pen", nil)
+
+	h.GetProductBySlug(c)
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected 301, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "/api/v1/products/slug/fountain-pen" {
+		t.Fatalf("unexpected Location %q", loc)
+	}
+}
+
+func TestListProductCategories_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{productCategoriesResp: []catalog.Category{
+		{ID: "c1", Name: "Audio", Slug: "audio"},
+		{ID: "c2", Name: "Books", Slug: "books"},
+	}}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "p1"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/products/p1/categories", nil)
+
+	h.ListProductCategories(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.productCategoriesID != "p1" {
+		t.Fatalf("service called with wrong id %s", svc.productCategoriesID)
+	}
+	var resp []CategoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 2 || resp[0].ID != "c1" || resp[1].Slug != "books" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestListProductCategories_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{productCategoriesErr: catalog.ErrProductNotFound}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "missing"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/products/missing/categories", nil)
+
+	h.ListProductCategories(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestBulkCreateCategories_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		bulkCategoryResp: []catalog.Category{{ID: "c1", Name: "Books"}, {ID: "c2", Name: "Audio"}},
+	}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	body := `{"categories":[{"name":"Books"},{"name":"Audio"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/categories/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	h.BulkCreateCategories(c)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	if len(svc.bulkCategoryInputs) != 2 || svc.bulkCategoryInputs[1].Name != "Audio" {
+		t.Fatalf("service received %+v", svc.bulkCategoryInputs)
+	}
+	var resp []CategoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 2 || resp[0].ID != "c1" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if len(em.events) != 2 {
+		t.Fatalf("expected one created event per category, got %+v", em.events)
+	}
+}
+
+func TestBulkCreateCategories_ConflictReturnsIndex(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		bulkCategoryErr: &catalog.BulkCategoryError{Index: 1, Err: catalog.ErrCategoryConflict},
+	}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	body := `{"categories":[{"name":"Books"},{"name":"Books"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/categories/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	h.BulkCreateCategories(c)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", w.Code)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["index"].(float64) != 1 {
+		t.Fatalf("expected offending index 1, got %v", resp["index"])
+	}
+	if len(em.events) != 0 {
+		t.Fatalf("expected no events on conflict, got %+v", em.events)
+	}
+}
+
+func TestCreateProduct_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		createProductResp: catalog.Product{ID: "p1", Name: "Pen", Description: "Blue", Price: 10, Stock: 2},
+	}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	body := `{"name":"Pen","description":"Blue","price":10,"stock":2}`
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	h.CreateProduct(c)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	in := svc.createProductInput
+	if in.Name != "Pen" || in.Price == nil || *in.Price != 10 || in.Stock == nil || *in.Stock != 2 {
+		t.Fatalf("service received %+v", in)
+	}
+	if len(em.events) != 1 || em.events[0] != ws.EventProductCreated {
+		t.Fatalf("expected product created event, got %+v", em.events)
+	}
+}
+
+func TestCreateProduct_DraftSkipsCreatedEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{createProductResp: catalog.Product{ID: "p1", Name: "Pen", Draft: true}}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Pen","draft":true}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.CreateProduct(c)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(em.events) != 0 {
+		t.Fatalf("expected no public event for a draft, got %+v", em.events)
+	}
+}
+
+func TestDraftChangesSkipPublicEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	draft := catalog.Product{ID: "p1", Name: "Pen", Draft: true}
+	svc := &stubCatalogService{
+		updateProductResp:  draft,
+		restoreProductResp: draft,
+		restored:           true,
+		bulkAdjustResp:     []catalog.Product{draft, {ID: "p2", Name: "Ink"}},
+	}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	call := func(handler gin.HandlerFunc, method, body string) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/products/p1", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "p1"}}
+		handler(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	call(h.UpdateProduct, http.MethodPut, `{"name":"Pen"}`)
+	call(h.RestoreProduct, http.MethodPost, "")
+	call(h.BulkAdjustStock, http.MethodPost, `{"adjustments":[{"product_id":"p1","delta":1},{"product_id":"p2","delta":1}]}`)
+
+	if len(em.events) != 1 || em.events[0] != ws.EventProductUpdated {
+		t.Fatalf("expected only the published product's update, got %+v", em.events)
+	}
+	if resp, ok := em.data[0].(ProductResponse); !ok || resp.ID != "p2" {
+		t.Fatalf("expected event for p2, got %+v", em.data[0])
+	}
+}
+
+func TestCreateProduct_NameOnlyLeavesDefaultsToService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{createProductResp: catalog.Product{ID: "p1", Name: "Draft"}}
+	h := NewCatalogHandler(svc, &testRecordingEmitter{})
+
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Draft"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	h.CreateProduct(c)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if svc.createProductInput.Price != nil || svc.createProductInput.Stock != nil {
+		t.Fatalf("expected omitted price/stock to reach the service as nil, got %+v", svc.createProductInput)
+	}
+}
+
+func TestCreateProduct_CurrencyPassthroughAndValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		createProductResp: catalog.Product{ID: "p1", Name: "Pen", Price: 10, Currency: "EUR", Stock: 2},
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	body := `{"name":"Pen","price":10,"stock":2,"currency":"eur"}`
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	h.CreateProduct(c)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	if svc.createProductInput.Currency != "eur" {
+		t.Fatalf("expected currency to reach the service, got %+v", svc.createProductInput)
+	}
+	var resp ProductResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Currency != "EUR" {
+		t.Fatalf("expected currency in response, got %+v", resp)
+	}
+
+	svc.createProductErr = catalog.ErrInvalidCurrency
+	req = httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Pen","price":10,"stock":2,"currency":"XYZ"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+	h.CreateProduct(c)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid currency, got %d", w.Code)
+	}
+}
+
+func TestUpdateProduct_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		updateProductResp: catalog.Product{ID: "p1", Name: "Pen", Description: "Red", Price: 12, Stock: 5},
+	}
+	em := &recordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	body := `{"name":"Pen","description":"Red","price":12,"stock":5}`
+	req := httptest.NewRequest(http.MethodPut, "/products/p1", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "p1"}}
+	c.Request = req
+
+	h.UpdateProduct(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.updateProductInput.ID != "p1" || svc.updateProductInput.Price == nil || *svc.updateProductInput.Price != 12 {
+		t.Fatalf("service received %+v", svc.updateProductInput)
+	}
+	if len(em.events) != 1 || em.events[0] != ws.EventProductUpdated {
+		t.Fatalf("expected product updated event, got %+v", em.events)
+	}
+}
+
+func TestRestoreProduct_EmitsOnlyWhenRestored(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, restored := range []bool{true, false} {
+		svc := &stubCatalogService{
+			restoreProductResp: catalog.Product{ID: "p1", Name: "Pen", Price: 12, Stock: 5},
+			restored:           restored,
+		}
+		em := &recordingEmitter{}
+		h := NewCatalogHandler(svc, em)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "p1"}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/products/p1/restore", nil)
+
+		h.RestoreProduct(c)
+
+		if w.Code != http.StatusOK || svc.restoreProductID != "p1" {
+			t.Fatalf("restored=%v: expected 200 for p1, got %d (%q)", restored, w.Code, svc.restoreProductID)
+		}
+		if restored && (len(em.events) != 1 || em.events[0] != ws.EventProductRestored) {
+			t.Fatalf("expected product restored event, got %+v", em.events)
+		}
+		if !restored && len(em.events) != 0 {
+			t.Fatalf("expected no event for a no-op restore, got %+v", em.events)
+		}
+	}
+}
+
+func TestPublishProduct_EmitsOnSuccessAndRejectsIncomplete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	publish := func(svc *stubCatalogService, em *recordingEmitter) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "p1"}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/products/p1/publish", nil)
+		NewCatalogHandler(svc, em).PublishProduct(c)
+		return w
+	}
+
+	em := &recordingEmitter{}
+	w := publish(&stubCatalogService{publishProductResp: catalog.Product{ID: "p1", Name: "Pen", Price: 12}, published: true}, em)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), `"draft"`) {
+		t.Fatalf("expected 200 with a published product, got %d %s", w.Code, w.Body.String())
+	}
+	if len(em.events) != 1 || em.events[0] != ws.EventProductPublished {
+		t.Fatalf("expected product published event, got %+v", em.events)
+	}
+
+	em = &recordingEmitter{}
+	w = publish(&stubCatalogService{publishProductErr: fmt.Errorf("%w: price is not set", catalog.ErrProductIncomplete)}, em)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"code":"product_incomplete"`) {
+		t.Fatalf("expected 409 product_incomplete, got %d %s", w.Code, w.Body.String())
+	}
+	if len(em.events) != 0 {
+		t.Fatalf("expected no event when publish is blocked, got %+v", em.events)
+	}
+}
+
+func TestUpdateProduct_PriceChangePendingReturnsAccepted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	current := catalog.Product{ID: "p1", Name: "Pen", Price: 100}
+	svc := &stubCatalogService{
+		updateProductResp: current,
+		updateProductErr: &catalog.PriceChangePendingError{
+			Product: current,
+			Request: catalog.PriceChangeRequest{ID: "pc1", ProductID: "p1", OldPrice: 100, NewPrice: 300, Status: catalog.PriceChangePending},
+		},
+	}
+	em := &recordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	req := httptest.NewRequest(http.MethodPut, "/products/p1", bytes.NewBufferString(`{"price":300}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "p1"}}
+	c.Request = req
+
+	h.UpdateProduct(c)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp PriceChangePendingResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Product.Price != 100 || resp.PriceChangeRequest.ID != "pc1" || resp.PriceChangeRequest.NewPrice != 300 || resp.PriceChangeRequest.Status != "pending" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if len(em.events) != 1 || em.events[0] != ws.EventProductUpdated {
+		t.Fatalf("expected product updated event, got %+v", em.events)
+	}
+}
+
+func TestUpdateProduct_OmittedPriceIsNotSent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{updateProductResp: catalog.Product{ID: "p1", Name: "Pen", Price: 12}}
+	h := NewCatalogHandler(svc, &recordingEmitter{})
+
+	req := httptest.NewRequest(http.MethodPut, "/products/p1", bytes.NewBufferString(`{"stock":0}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "p1"}}
+	c.Request = req
+
+	h.UpdateProduct(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	in := svc.updateProductInput
+	if in.Price != nil || in.Name != nil {
+		t.Fatalf("omitted fields must stay nil, got %+v", in)
+	}
+	if in.Stock == nil || *in.Stock != 0 {
+		t.Fatalf("explicit zero stock must be forwarded, got %+v", in.Stock)
+	}
+}
+
+func TestBulkCreateProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{createProductsResp: []catalog.Product{{ID: "p1", Name: "Pen"}, {ID: "p2", Name: "Ink"}}}
+	em := &recordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/products/bulk", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.BulkCreateProducts(c)
+		return w
+	}
+
+	w := post(`{"products":[{"name":"Pen","price":10},{"name":"Ink"}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(svc.createProductsInput) != 2 || *svc.createProductsInput[0].Price != 10 || svc.createProductsInput[1].Price != nil {
+		t.Fatalf("unexpected service input %+v", svc.createProductsInput)
+	}
+	if len(em.events) != 2 || em.events[0] != ws.EventProductCreated || em.events[1] != ws.EventProductCreated {
+		t.Fatalf("expected one product.created per product, got %+v", em.events)
+	}
+
+	em.events = nil
+	svc.createProductsResp = []catalog.Product{{ID: "p3", Name: "Pen"}, {ID: "p4", Name: "Ink", Draft: true}}
+	if w := post(`{"products":[{"name":"Pen"},{"name":"Ink","draft":true}]}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+	if len(em.events) != 1 {
+		t.Fatalf("expected drafts to skip product.created, got %+v", em.events)
+	}
+
+	svc.createProductsErr = &catalog.BulkProductError{Items: []catalog.BulkItemError{
+		{Index: 0, Err: catalog.ErrInvalidProduct},
+		{Index: 2, Err: catalog.ErrDuplicateBarcode},
+	}}
+	w = post(`{"products":[{"name":""},{"name":"Ink"},{"name":"Pen"}]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Code    string                `json:"code"`
+		Details []BulkItemErrorDetail `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Code != "invalid_product" || len(resp.Details) != 2 || resp.Details[1].Index != 2 || resp.Details[1].Code != "duplicate_barcode" {
+		t.Fatalf("expected per-index errors, got %+v", resp)
+	}
+	if len(em.events) != 1 {
+		t.Fatalf("expected no events for a rejected batch, got %+v", em.events)
+	}
+
+	svc.createProductsErr = &catalog.BulkProductError{Items: []catalog.BulkItemError{{Index: 1, Err: catalog.ErrDuplicateBarcode}}}
+	if w = post(`{"products":[{"name":"Pen"},{"name":"Ink"}]}`); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when only barcodes collide, got %d", w.Code)
+	}
+}
+
+func TestUpdateProduct_EmitsLowStockOnCrossing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{updateProductResp: catalog.Product{ID: "p1", Name: "Pen", Stock: 2, LowStockCrossed: true}}
+	em := &recordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	update := func() {
+		req := httptest.NewRequest(http.MethodPut, "/products/p1", bytes.NewBufferString(`{"stock":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "p1"}}
+		c.Request = req
+		h.UpdateProduct(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+	}
+
+	update()
+	if len(em.events) != 2 || em.events[1] != ws.EventProductLowStock {
+		t.Fatalf("expected updated and low stock events, got %+v", em.events)
+	}
+	if data, ok := em.data[1].(gin.H); !ok || data["id"] != "p1" || data["name"] != "Pen" || data["stock"] != int64(2) {
+		t.Fatalf("unexpected low stock payload %+v", em.data[1])
+	}
+
+	svc.updateProductResp.LowStockCrossed = false
+	update()
+	if len(em.events) != 3 || em.events[2] != ws.EventProductUpdated {
+		t.Fatalf("expected only the updated event without a crossing, got %+v", em.events)
+	}
+}
+
+func TestDeleteProduct_Error(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{deleteProductErr: errors.New("fail")}
+	em := &recordingEmitter{}
+	h := NewCatalogHandler(svc, em)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -403,6 +1414,44 @@ func TestDeleteProduct_Error(t *testing.T) {
 	}
 }
 
+func TestProductHandlers_MissingProductIs404(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name   string
+		svc    *stubCatalogService
+		method string
+		body   string
+		call   func(h *CatalogHandler, c *gin.Context)
+	}{
+		{name: "get", svc: &stubCatalogService{getProductErr: catalog.ErrProductNotFound}, method: http.MethodGet, call: (*CatalogHandler).GetProduct},
+		{name: "update", svc: &stubCatalogService{updateProductErr: catalog.ErrProductNotFound}, method: http.MethodPut, body: `{"name":"Pen"}`, call: (*CatalogHandler).UpdateProduct},
+		{name: "delete", svc: &stubCatalogService{deleteProductErr: catalog.ErrProductNotFound}, method: http.MethodDelete, call: (*CatalogHandler).DeleteProduct},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewCatalogHandler(tc.svc, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "missing"}}
+			c.Request = httptest.NewRequest(tc.method, "/products/missing", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			tc.call(h, c)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+			}
+			var body map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			if body["code"] != "product_not_found" || body["error"] != catalog.ErrProductNotFound.Error() {
+				t.Fatalf("unexpected body %v", body)
+			}
+		})
+	}
+}
+
 func TestAddProductCategory_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubCatalogService{}
@@ -430,6 +1479,38 @@ func TestAddProductCategory_Success(t *testing.T) {
 	}
 }
 
+func TestRemoveProductCategory_EmitsOnlyWhenRemoved(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{unassignProductCategoryRemoved: true}
+	em := &testRecordingEmitter{}
+	h := NewCatalogHandler(svc, em)
+
+	remove := func() int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "p1"}, {Key: "categoryId", Value: "c1"}}
+		c.Request = httptest.NewRequest(http.MethodDelete, "/products/p1/categories/c1", nil)
+		h.RemoveProductCategory(c)
+		return c.Writer.Status()
+	}
+
+	if status := remove(); status != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", status)
+	}
+	if svc.unassignProductCategoryProductID != "p1" || svc.unassignProductCategoryCategoryID != "c1" {
+		t.Fatalf("service received wrong IDs %s/%s", svc.unassignProductCategoryProductID, svc.unassignProductCategoryCategoryID)
+	}
+	if len(em.events) != 1 || em.events[0] != ws.EventProductCategoryUnassigned {
+		t.Fatalf("expected unassignment event, got %+v", em.events)
+	}
+
+	// quitar una relacion inexistente sigue respondiendo 204 pero no emite.
+	svc.unassignProductCategoryRemoved = false
+	if status := remove(); status != http.StatusNoContent || len(em.events) != 1 {
+		t.Fatalf("expected silent 204, got %d with events %+v", status, em.events)
+	}
+}
+
 func TestSearch_Category(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubCatalogService{
@@ -462,6 +1543,91 @@ func TestSearch_Category(t *testing.T) {
 	}
 }
 
+func TestSearch_CategoryWithoutMatchesReturnsOnlyCategoriesKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewCatalogHandler(&stubCatalogService{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/search?type=category&q=zzz", nil)
+	h.Search(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if body := w.Body.String(); body != `{"categories":[],"total":0}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+}
+
+func TestSearch_UnknownKindNeverDefaultsToProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewCatalogHandler(&stubCatalogService{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/search?type=tags&q=pen", nil)
+	h.Search(c)
+
+	if w.Code != http.StatusBadRequest || strings.Contains(w.Body.String(), "products") {
+		t.Fatalf("expected 400 without products key, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSearch_WithoutLimitDefersToService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/search?type=product&q=pen", nil)
+	h.Search(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.searchFilter.Limit != 0 {
+		t.Fatalf("expected no limit so the service applies the search default, got %d", svc.searchFilter.Limit)
+	}
+}
+
+func TestSearch_HighlightIsOptIn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		searchResp: catalog.SearchResult{
+			Products: []catalog.Product{{ID: "p1", Name: "Pen", Snippet: "<mark>Pen</mark>"}},
+			Total:    1,
+		},
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/search?type=product&q=pen", nil)
+	h.Search(c)
+	if svc.searchFilter.Highlight {
+		t.Fatalf("highlight should be off by default")
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/search?type=product&q=pen&highlight=true", nil)
+	h.Search(c)
+	if !svc.searchFilter.Highlight {
+		t.Fatalf("expected highlight to reach the service")
+	}
+	var body struct {
+		Products []ProductResponse `json:"products"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Products) != 1 || body.Products[0].Snippet != "<mark>Pen</mark>" {
+		t.Fatalf("expected snippet in response, got %+v", body.Products)
+	}
+}
+
 func TestGetProductHistory_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	t1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -497,6 +1663,47 @@ func TestGetProductHistory_Success(t *testing.T) {
 	}
 }
 
+func TestGetLatestProductHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	changed := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		svc      *stubCatalogService
+		wantCode int
+	}{
+		{name: "found", svc: &stubCatalogService{latestHistoryResp: catalog.ProductHistory{ID: "h2", ProductID: "p1", Price: 12, Stock: 2, ChangedAt: changed}}, wantCode: http.StatusOK},
+		{name: "no history", svc: &stubCatalogService{latestHistoryErr: catalog.ErrProductHistoryNotFound}, wantCode: http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewCatalogHandler(tc.svc, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/products/p1/history/latest", nil)
+			c.Params = gin.Params{{Key: "id", Value: "p1"}}
+
+			h.GetLatestProductHistory(c)
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d", tc.wantCode, w.Code)
+			}
+			if tc.svc.latestHistoryID != "p1" {
+				t.Fatalf("expected service called with p1, got %q", tc.svc.latestHistoryID)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var resp ProductHistoryResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.ID != "h2" || resp.Price != 12 || resp.ChangedAt != changed.Format(time.RFC3339) {
+				t.Fatalf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
+
 func TestGetProductHistory_InvalidDate(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubCatalogService{}
@@ -517,3 +1724,102 @@ func TestGetProductHistory_InvalidDate(t *testing.T) {
 		t.Fatalf("service should not be called on invalid date")
 	}
 }
+
+func TestListProducts_FieldsProjectsResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		listProductsResp:  []catalog.Product{{ID: "p1", Name: "Pen", Description: "Blue", Price: 100, Stock: 5}},
+		listProductsTotal: 1,
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/products?fields=id,%20NAME", nil)
+	h.ListProducts(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Body.String(); got != `{"total":1,"limit":20,"offset":0,"has_more":false,"products":[{"id":"p1","name":"Pen"}]}` {
+		t.Fatalf("expected only id and name, got %s", got)
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/products?fields=id,password", nil)
+	h.ListProducts(c)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "password") {
+		t.Fatalf("expected 400 naming the unknown field, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListProducts_PageMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		listProductsResp:  []catalog.Product{{ID: "p3"}, {ID: "p4"}},
+		listProductsTotal: 5,
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	page := func(target string) ProductListResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		h.ListProducts(c)
+		var resp ProductListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("unexpected %d %s (%v)", w.Code, w.Body.String(), err)
+		}
+		return resp
+	}
+
+	resp := page("/products?limit=2&offset=2")
+	if resp.Limit != 2 || resp.Offset != 2 || !resp.HasMore || resp.NextOffset == nil || *resp.NextOffset != 4 {
+		t.Fatalf("expected a middle page pointing at offset 4, got %+v", resp.PageMeta)
+	}
+	resp = page("/products?limit=2&offset=3")
+	if resp.HasMore || resp.NextOffset != nil {
+		t.Fatalf("expected the last page without next_offset, got %+v", resp.PageMeta)
+	}
+}
+
+func TestListProducts_CountFalseReturnsNullTotal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// con SkipCount el servicio devuelve una cota (offset+limit+1) cuando hay mas.
+	svc := &stubCatalogService{
+		listProductsResp:  []catalog.Product{{ID: "p1"}, {ID: "p2"}},
+		listProductsTotal: 3,
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/products?limit=2&count=false&fields=id", nil)
+	h.ListProducts(c)
+
+	if !svc.listProductsFilter.SkipCount {
+		t.Fatalf("expected SkipCount to reach the service, got %+v", svc.listProductsFilter)
+	}
+	if got := w.Body.String(); got != `{"total":null,"limit":2,"offset":0,"has_more":true,"next_offset":2,"products":[{"id":"p1"},{"id":"p2"}]}` {
+		t.Fatalf("unexpected body %s", got)
+	}
+}
+
+func TestSearch_FieldsProjectsProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{
+		searchResp: catalog.SearchResult{Products: []catalog.Product{{ID: "p1", Name: "Pen", Price: 100}}, Total: 1, Limit: 20},
+	}
+	h := NewCatalogHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/search?type=product&q=pe&fields=name", nil)
+	h.Search(c)
+
+	if got := w.Body.String(); w.Code != http.StatusOK || got != `{"total":1,"limit":20,"offset":0,"has_more":false,"products":[{"name":"Pen"}]}` {
+		t.Fatalf("expected projected search results, got %d: %s", w.Code, got)
+	}
+}
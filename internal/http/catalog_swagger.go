@@ -2,12 +2,34 @@ package http
 
 // ListCategoriesDoc godoc
 // @Summary List categories
+// @Description Sin limit/offset devuelve el arreglo completo; con alguno de ellos devuelve {total, categories}.
 // @Tags Catalog
 // @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
 // @Success 200 {array} CategoryResponse
 // @Router /categories [get]
 func ListCategoriesDoc() {}
 
+// CategoryExistsDoc godoc
+// @Summary Check category existence
+// @Tags Catalog
+// @Param id path string true "Category ID"
+// @Success 200
+// @Failure 404
+// @Router /categories/{id} [head]
+func CategoryExistsDoc() {}
+
+// GetCategoryBySlugDoc godoc
+// @Summary Get category by slug
+// @Tags Catalog
+// @Produce json
+// @Param slug path string true "Category slug"
+// @Success 200 {object} CategoryResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /categories/slug/{slug} [get]
+func GetCategoryBySlugDoc() {}
+
 // CreateCategoryDoc godoc
 // @Summary Create category
 // @Tags Catalog
@@ -19,6 +41,19 @@ func ListCategoriesDoc() {}
 // @Router /categories [post]
 func CreateCategoryDoc() {}
 
+// BulkCreateCategoriesDoc godoc
+// @Summary Bulk create categories
+// @Description Crea hasta 100 categorias en una transaccion; un nombre duplicado rechaza el lote indicando su index.
+// @Tags Catalog
+// @Accept json
+// @Produce json
+// @Param body body BulkCreateCategoriesRequest true "Categories payload"
+// @Success 201 {array} CategoryResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /categories/bulk [post]
+func BulkCreateCategoriesDoc() {}
+
 // UpdateCategoryDoc godoc
 // @Summary Update category
 // @Tags Catalog
@@ -31,6 +66,19 @@ func CreateCategoryDoc() {}
 // @Router /categories/{id} [put]
 func UpdateCategoryDoc() {}
 
+// ReorderCategoriesDoc godoc
+// @Summary Reorder categories
+// @Description Asigna el orden de visualizacion segun la lista; las categorias omitidas quedan sin orden.
+// @Tags Catalog
+// @Accept json
+// @Param body body ReorderCategoriesRequest true "Ordered category IDs"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /categories/order [put]
+func ReorderCategoriesDoc() {}
+
 // DeleteCategoryDoc godoc
 // @Summary Delete category
 // @Tags Catalog
@@ -59,6 +107,27 @@ func ListProductsDoc() {}
 // @Router /products/{id} [get]
 func GetProductDoc() {}
 
+// GetProductBySlugDoc godoc
+// @Summary Get product by slug
+// @Description Un slug anterior (producto renombrado) responde 301 hacia el slug canonico.
+// @Tags Products
+// @Produce json
+// @Param slug path string true "Product slug"
+// @Success 200 {object} ProductResponse
+// @Success 301
+// @Failure 404 {object} ErrorResponse
+// @Router /products/slug/{slug} [get]
+func GetProductBySlugDoc() {}
+
+// ProductExistsDoc godoc
+// @Summary Check product existence
+// @Tags Products
+// @Param id path string true "Product ID"
+// @Success 200
+// @Failure 404
+// @Router /products/{id} [head]
+func ProductExistsDoc() {}
+
 // CreateProductDoc godoc
 // @Summary Create product
 // @Tags Products
@@ -72,6 +141,7 @@ func CreateProductDoc() {}
 
 // UpdateProductDoc godoc
 // @Summary Update product
+// @Description Actualizacion parcial: los campos omitidos conservan su valor actual.
 // @Tags Products
 // @Accept json
 // @Produce json
@@ -91,6 +161,53 @@ func UpdateProductDoc() {}
 // @Router /products/{id} [delete]
 func DeleteProductDoc() {}
 
+// RestoreProductDoc godoc
+// @Summary Restore soft-deleted product
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} ProductResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/{id}/restore [post]
+func RestoreProductDoc() {}
+
+// PublishProductDoc godoc
+// @Summary Publish draft product
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} ProductResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/{id}/publish [post]
+func PublishProductDoc() {}
+
+// BulkAdjustStockDoc godoc
+// @Summary Bulk adjust product stock
+// @Description Aplica todos los ajustes en una transaccion; si alguno deja stock negativo o el producto no existe, no se aplica ninguno.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param body body BulkAdjustStockRequest true "Stock adjustments"
+// @Success 200 {array} ProductResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/stock/bulk-adjust [post]
+func BulkAdjustStockDoc() {}
+
+// ListProductCategoriesDoc godoc
+// @Summary List product categories
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} CategoryResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{id}/categories [get]
+func ListProductCategoriesDoc() {}
+
 // AddProductCategoryDoc godoc
 // @Summary Relate product to category
 // @Tags Products
@@ -101,6 +218,16 @@ func DeleteProductDoc() {}
 // @Router /products/{id}/categories/{categoryId} [post]
 func AddProductCategoryDoc() {}
 
+// RemoveProductCategoryDoc godoc
+// @Summary Remove product from category
+// @Tags Products
+// @Param id path string true "Product ID"
+// @Param categoryId path string true "Category ID"
+// @Success 204
+// @Security BearerAuth
+// @Router /products/{id}/categories/{categoryId} [delete]
+func RemoveProductCategoryDoc() {}
+
 // ProductHistoryDoc godoc
 // @Summary Product history
 // @Tags Products
@@ -112,15 +239,29 @@ func AddProductCategoryDoc() {}
 // @Router /products/{id}/history [get]
 func ProductHistoryDoc() {}
 
+// LatestProductHistoryDoc godoc
+// @Summary Latest product history entry
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} ProductHistoryResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{id}/history/latest [get]
+func LatestProductHistoryDoc() {}
+
 // SearchDoc godoc
 // @Summary Search products or categories
 // @Tags Search
 // @Produce json
 // @Param type query string true "product or category"
 // @Param q query string false "Search query"
+// @Param highlight query bool false "Incluye un snippet resaltado con <mark> por resultado"
+// @Param fields query string false "Campos de producto separados por coma (p. ej. id,name); solo con type=product"
+// @Param min_price query int false "Precio minimo inclusivo; solo con type=product"
+// @Param max_price query int false "Precio maximo inclusivo; solo con type=product"
 // @Param limit query int false "Limit" default(20)
 // @Param offset query int false "Offset" default(0)
-// @Param sort query string false "Sort field"
+// @Param sort query string false "Productos: name|price|stock|created_at|relevance (default con q); categorias: name|created_at"
 // @Param order query string false "Sort order asc|desc"
 // @Success 200 {object} map[string]interface{}
 // @Router /search [get]
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCompressionMinSize evita comprimir respuestas chicas donde gzip no compensa.
+const defaultCompressionMinSize = 1024
+
+// CompressionMiddleware comprime con gzip las respuestas JSON/texto de al menos
+// minSize bytes cuando el cliente lo acepta. Se saltea el upgrade de WebSocket y
+// las respuestas que ya traen Content-Encoding.
+func CompressionMiddleware(minSize int) gin.HandlerFunc {
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+	return func(c *gin.Context) {
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) || c.Request.URL.Path == "/ws" || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		w := &gzipWriter{ResponseWriter: c.Writer, minSize: minSize}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// acceptsGzip interpreta Accept-Encoding respetando q=0 como rechazo explicito.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(v, 64)
+			return err == nil && q > 0
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter acumula hasta minSize bytes para decidir si vale la pena comprimir.
+type gzipWriter struct {
+	gin.ResponseWriter
+	minSize int
+	buf     []byte
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// decide fija el modo (gzip o passthrough) y vuelca lo acumulado.
+func (w *gzipWriter) decide(bigEnough bool) error {
+	w.decided = true
+	header := w.Header()
+	// un 206 describe rangos del archivo original; comprimirlo rompe los offsets.
+	if bigEnough && w.Status() != http.StatusPartialContent && compressible(header) {
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+		_, err := w.gz.Write(w.buf)
+		w.buf = nil
+		return err
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// finish vuelca lo pendiente sin comprimir si nunca se llego al umbral.
+func (w *gzipWriter) finish() {
+	if !w.decided {
+		if len(w.buf) > 0 {
+			_ = w.decide(false)
+		}
+		return
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+	}
+}
+
+func (w *gzipWriter) Flush() {
+	if !w.decided && len(w.buf) > 0 {
+		_ = w.decide(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *gzipWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.Hijack()
+}
+
+// compressible limita gzip a JSON/texto que aun no viene codificado (assets .gz, imagenes, etc.).
+func compressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	ct := strings.ToLower(header.Get("Content-Type"))
+	return strings.HasPrefix(ct, "application/json") ||
+		strings.HasPrefix(ct, "text/") ||
+		strings.HasPrefix(ct, "application/javascript")
+}
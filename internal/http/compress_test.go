@@ -0,0 +1,102 @@
+package http
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCompressionRouter(payload string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CompressionMiddleware(256))
+	r.GET("/data", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": payload})
+	})
+	return r
+}
+
+func TestCompressionMiddleware_GzipsLargeJSON(t *testing.T) {
+	payload := strings.Repeat("catalog ", 200)
+	r := newCompressionRouter(payload)
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", got)
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("read gzip body: %v", err)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(raw, &body); err != nil || body["data"] != payload {
+		t.Fatalf("unexpected decompressed body: %v", err)
+	}
+}
+
+func TestCompressionMiddleware_SkipsSmallOrUnrequested(t *testing.T) {
+	cases := []struct {
+		name     string
+		payload  string
+		encoding string
+	}{
+		{name: "below threshold", payload: "tiny", encoding: "gzip"},
+		{name: "not accepted", payload: strings.Repeat("x", 1024), encoding: ""},
+		{name: "explicitly refused", payload: strings.Repeat("x", 1024), encoding: "gzip;q=0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newCompressionRouter(tc.payload)
+			req := httptest.NewRequest(http.MethodGet, "/data", nil)
+			if tc.encoding != "" {
+				req.Header.Set("Accept-Encoding", tc.encoding)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != "" {
+				t.Fatalf("expected no encoding, got %q", got)
+			}
+			if !strings.Contains(w.Body.String(), tc.payload) {
+				t.Fatalf("expected plain body, got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompressionMiddleware_DoesNotDoubleCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CompressionMiddleware(16))
+	precompressed := strings.Repeat("z", 64)
+	r.GET("/asset", func(c *gin.Context) {
+		c.Header("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, "application/json", []byte(precompressed))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/asset", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != precompressed {
+		t.Fatalf("expected body untouched, got %q", w.Body.String())
+	}
+}
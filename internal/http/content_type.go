@@ -0,0 +1,49 @@
+package http
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultContentTypes son los media types aceptados si no se configura otro set.
+var defaultContentTypes = []string{"application/json"}
+
+// ContentTypeMiddleware responde 415 a un body cuyo Content-Type no esta en allowed.
+// Se compara el media type sin parametros, asi que "application/json; charset=utf-8"
+// pasa con "application/json". Un body sin Content-Type se sigue intentando como JSON
+// y las peticiones sin body no se revisan.
+func ContentTypeMiddleware(allowed []string) gin.HandlerFunc {
+	if len(allowed) == 0 {
+		allowed = defaultContentTypes
+	}
+	set := make(map[string]struct{}, len(allowed))
+	for _, ct := range allowed {
+		if mediaType, _, err := mime.ParseMediaType(ct); err == nil {
+			set[mediaType] = struct{}{}
+		}
+	}
+	accepted := strings.Join(allowed, ", ")
+	return func(c *gin.Context) {
+		header := c.GetHeader("Content-Type")
+		if header == "" || !hasBody(c.Request) {
+			c.Next()
+			return
+		}
+		mediaType, _, err := mime.ParseMediaType(header)
+		if _, ok := set[mediaType]; err != nil || !ok {
+			abortError(c, http.StatusUnsupportedMediaType, codeUnsupportedMediaType,
+				fmt.Errorf("unsupported Content-Type %q; send the body as %s", header, accepted), nil)
+			return
+		}
+		c.Next()
+	}
+}
+
+// hasBody cubre tambien el body chunked, que llega con ContentLength -1.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func postProduct(router *gin.Engine, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestContentType_RejectsNonJSONBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{}
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil)}).Build()
+
+	w := postProduct(router, "application/x-www-form-urlencoded", "name=Pen")
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %d: %s", w.Code, w.Body.String())
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != codeUnsupportedMediaType || !strings.Contains(body.Message, "application/json") {
+		t.Fatalf("expected a clear unsupported_media_type error, got %s (%v)", w.Body.String(), err)
+	}
+	if svc.createProductInput.Name != "" {
+		t.Fatalf("handler must not run for a rejected body")
+	}
+
+	for _, ct := range []string{"application/json", "application/json; charset=utf-8", ""} {
+		if w := postProduct(router, ct, `{"name":"Pen"}`); w.Code != http.StatusCreated {
+			t.Fatalf("expected %q to be accepted, got %d: %s", ct, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestContentType_ConfiguredSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{
+		CatalogHandler:      NewCatalogHandler(&stubCatalogService{}, nil),
+		AllowedContentTypes: []string{"application/json", "application/vnd.catalog+json"},
+	}).Build()
+
+	if w := postProduct(router, "application/vnd.catalog+json", `{"name":"Pen"}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected the configured type to be accepted, got %d", w.Code)
+	}
+	if w := postProduct(router, "text/plain", `{"name":"Pen"}`); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415 for text/plain, got %d", w.Code)
+	}
+}
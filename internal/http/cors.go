@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	corsAllowMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders = "Authorization, Content-Type, Accept-Language, " + csrfHeaderName
+)
+
+// CORSConfig define que origenes de navegador pueden llamar a la API.
+type CORSConfig struct {
+	// AllowedOrigins lista origenes exactos (scheme://host[:port]); "*" acepta cualquiera.
+	// Vacio deshabilita CORS.
+	AllowedOrigins []string
+	// AllowCredentials permite cookies (auth por cookie) y solo se envia al responder
+	// con el origen exacto, nunca con *. config.Validate rechaza "*" con credenciales.
+	AllowCredentials bool
+	// MaxAge cachea el preflight en el navegador; 0 no envia el header.
+	MaxAge time.Duration
+}
+
+// CORSMiddleware responde los preflight y agrega los headers CORS a peticiones de
+// origenes permitidos. Un preflight de un origen no permitido responde 403; las demas
+// peticiones siguen sin headers y es el navegador el que bloquea la respuesta.
+func CORSMiddleware(cfg CORSConfig) gin.HandlerFunc {
+	wildcard := slices.Contains(cfg.AllowedOrigins, "*")
+	maxAge := ""
+	if secs := int(cfg.MaxAge / time.Second); secs > 0 {
+		maxAge = strconv.Itoa(secs)
+	}
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		// la respuesta depende del Origin aunque no se permita: una cache no debe mezclarlas.
+		c.Writer.Header().Add("Vary", "Origin")
+		exact := slices.Contains(cfg.AllowedOrigins, origin)
+		if !exact && !wildcard {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+		if exact {
+			c.Header("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		} else {
+			c.Header("Access-Control-Allow-Origin", "*")
+		}
+		if !preflight {
+			c.Next()
+			return
+		}
+		c.Header("Access-Control-Allow-Methods", corsAllowMethods)
+		c.Header("Access-Control-Allow-Headers", corsAllowHeaders)
+		if maxAge != "" {
+			c.Header("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func corsRouter(cfg CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return (&RouterFactory{CatalogHandler: NewCatalogHandler(&stubCatalogService{}, nil), CORS: cfg}).Build()
+}
+
+func preflight(router *gin.Engine, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/products", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "content-type")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS_CredentialedPreflightEchoesExactOrigin(t *testing.T) {
+	router := corsRouter(CORSConfig{
+		AllowedOrigins:   []string{"https://shop.example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	w := preflight(router, "https://shop.example.com")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	h := w.Header()
+	if got := h.Get("Access-Control-Allow-Origin"); got != "https://shop.example.com" {
+		t.Fatalf("expected the exact origin to be echoed, got %q", got)
+	}
+	if h.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("expected credentials to be allowed, got %v", h)
+	}
+	if h.Get("Access-Control-Max-Age") != "600" {
+		t.Fatalf("expected max-age 600, got %q", h.Get("Access-Control-Max-Age"))
+	}
+	if h.Get("Access-Control-Allow-Methods") == "" || h.Get("Vary") != "Origin" {
+		t.Fatalf("expected allow-methods and Vary: Origin, got %v", h)
+	}
+
+	w = preflight(router, "https://evil.example.com")
+	if w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Fatalf("expected 403 without CORS headers for an unknown origin, got %d %v", w.Code, w.Header())
+	}
+
+	// una peticion real del origen permitido tambien lleva las credenciales.
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("expected 200 with credentials header, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestCORS_WildcardNeverSendsCredentials(t *testing.T) {
+	w := preflight(corsRouter(CORSConfig{AllowedOrigins: []string{"*"}}), "https://any.example.com")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("expected wildcard preflight, got %d %v", w.Code, w.Header())
+	}
+	if w.Header().Get("Access-Control-Allow-Credentials") != "" || w.Header().Get("Access-Control-Max-Age") != "" {
+		t.Fatalf("expected no credentials or max-age headers, got %v", w.Header())
+	}
+}
+
+func TestCORS_DisabledWithoutOrigins(t *testing.T) {
+	w := preflight(corsRouter(CORSConfig{}), "https://shop.example.com")
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected no CORS headers when disabled, got %v", w.Header())
+	}
+}
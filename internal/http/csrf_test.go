@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CSRFMiddleware())
+	router.POST("/mutate", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/read", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		name    string
+		method  string
+		path    string
+		bearer  bool
+		cookies map[string]string
+		header  string
+		want    int
+	}{
+		{"safe method with cookie auth", http.MethodGet, "/read", false, map[string]string{"token": "jwt"}, "", http.StatusOK},
+		{"no auth cookie", http.MethodPost, "/mutate", false, nil, "", http.StatusNoContent},
+		{"bearer exempt", http.MethodPost, "/mutate", true, map[string]string{"token": "jwt"}, "", http.StatusNoContent},
+		{"matching token", http.MethodPost, "/mutate", false, map[string]string{"token": "jwt", "csrf_token": "abc"}, "abc", http.StatusNoContent},
+		{"missing header", http.MethodPost, "/mutate", false, map[string]string{"token": "jwt", "csrf_token": "abc"}, "", http.StatusForbidden},
+		{"mismatched header", http.MethodPost, "/mutate", false, map[string]string{"token": "jwt", "csrf_token": "abc"}, "xyz", http.StatusForbidden},
+		{"missing csrf cookie", http.MethodPost, "/mutate", false, map[string]string{"token": "jwt"}, "abc", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.bearer {
+				req.Header.Set("Authorization", "Bearer jwt")
+			}
+			for name, value := range tc.cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			if tc.header != "" {
+				req.Header.Set("X-CSRF-Token", tc.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
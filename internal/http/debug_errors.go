@@ -0,0 +1,18 @@
+package http
+
+import "github.com/gin-gonic/gin"
+
+const ctxDebugErrorsKey = "debug_errors"
+
+// DebugErrorsMiddleware habilita "details" con el error original (y el stack de los
+// panics) en las respuestas 500. Solo para desarrollo: filtra detalles internos.
+func DebugErrorsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ctxDebugErrorsKey, true)
+		c.Next()
+	}
+}
+
+func debugErrors(c *gin.Context) bool {
+	return c.GetBool(ctxDebugErrorsKey)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInternalErrorBody_DetailsOnlyWithDebugErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, debug := range []bool{false, true} {
+		svc := &stubCatalogService{getProductErr: errors.New("pq: connection refused")}
+		router := (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil), DebugErrors: debug}).Build()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/p1", nil))
+
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if w.Code != http.StatusInternalServerError || body["code"] != codeInternal {
+			t.Fatalf("debug=%v: expected 500 envelope, got %d %s", debug, w.Code, w.Body.String())
+		}
+		details, ok := body["details"].(map[string]any)
+		if !debug {
+			if ok {
+				t.Fatalf("details must be stripped without DEBUG_ERRORS, got %s", w.Body.String())
+			}
+			continue
+		}
+		if !ok || details["error"] != "pq: connection refused" {
+			t.Fatalf("expected underlying error in details, got %s", w.Body.String())
+		}
+	}
+}
@@ -1,26 +1,54 @@
 package http
 
+import (
+	"encoding/json"
+	"strconv"
+
+	"catalog-api/internal/ws"
+)
+
 // Los DTOs de identidad mantienen campos de transporte fuera de la capa de dominio.
 
 type RegisterClientRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 	FullName string `json:"full_name" binding:"required"`
+	Username string `json:"username" binding:"omitempty"`
 }
 
 type RegisterUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 	FullName string `json:"full_name" binding:"required"`
+	Username string `json:"username" binding:"omitempty"`
 }
 
+// LoginRequest acepta email o identifier (email o username); al menos uno es obligatorio.
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"omitempty,email"`
+	Identifier string `json:"identifier" binding:"omitempty"`
+	Password   string `json:"password" binding:"required"`
+	// RememberMe pide una sesion extendida (REMEMBER_ME_TTL).
+	RememberMe bool `json:"remember_me"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// SessionResponse describe una sesion activa sin exponer el refresh token.
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
 }
 
 type UpdateUserRequest struct {
@@ -40,9 +68,27 @@ type BlockUserRequest struct {
 	Reason string `json:"reason" binding:"omitempty"`
 }
 
+// VerificationStatusRequest pide el estado de verificacion de varios usuarios a la vez.
+type VerificationStatusRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required,min=1,dive,uuid"`
+}
+
+// VerificationStatusResponse expone solo lo que necesita un tablero de soporte.
+type VerificationStatusResponse struct {
+	UserID     string `json:"user_id"`
+	IsVerified bool   `json:"is_verified"`
+	Status     string `json:"status"`
+}
+
+// VerificationStatusListResponse omite los ids que no corresponden a ningun usuario.
+type VerificationStatusListResponse struct {
+	Users []VerificationStatusResponse `json:"users"`
+}
+
 type IdentityResponse struct {
 	ID         string `json:"id"`
 	Email      string `json:"email"`
+	Username   string `json:"username,omitempty"`
 	FullName   string `json:"full_name"`
 	Role       string `json:"role"`
 	Status     string `json:"status"`
@@ -52,9 +98,17 @@ type IdentityResponse struct {
 // DTOs de catalogo
 
 type CategoryResponse struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	Description  string `json:"description"`
+	DisplayOrder int    `json:"display_order"`
+	Snippet      string `json:"snippet,omitempty"`
+}
+
+type CategoryListResponse struct {
+	Total      int64              `json:"total"`
+	Categories []CategoryResponse `json:"categories"`
 }
 
 type CreateCategoryRequest struct {
@@ -62,6 +116,14 @@ type CreateCategoryRequest struct {
 	Description string `json:"description" binding:"omitempty"`
 }
 
+type BulkCreateCategoriesRequest struct {
+	Categories []CreateCategoryRequest `json:"categories" binding:"required,min=1,max=100,dive"`
+}
+
+type ReorderCategoriesRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1,dive,required"`
+}
+
 type UpdateCategoryRequest struct {
 	Name        string `json:"name" binding:"omitempty"`
 	Description string `json:"description" binding:"omitempty"`
@@ -72,23 +134,126 @@ type UpdateCategoryRequest struct {
 type ProductResponse struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
+	Slug        string `json:"slug"`
 	Description string `json:"description"`
 	Price       int64  `json:"price"`
+	Currency    string `json:"currency"`
+	Barcode     string `json:"barcode,omitempty"`
 	Stock       int64  `json:"stock"`
+	Draft       bool   `json:"draft,omitempty"`
+	Snippet     string `json:"snippet,omitempty"`
+	// Categories solo viene en GET /products/{id}; los listados no la incluyen.
+	Categories []CategoryResponse `json:"categories,omitempty"`
+	// int64AsString serializa price y stock como strings (JSON_INT64_AS_STRING).
+	int64AsString bool
+}
+
+// MarshalJSON respeta int64AsString; por defecto price y stock salen numericos.
+func (p ProductResponse) MarshalJSON() ([]byte, error) {
+	type plain ProductResponse
+	if !p.int64AsString {
+		return json.Marshal(plain(p))
+	}
+	return json.Marshal(struct {
+		plain
+		Price string `json:"price"`
+		Stock string `json:"stock"`
+	}{plain(p), strconv.FormatInt(p.Price, 10), strconv.FormatInt(p.Stock, 10)})
+}
+
+// PageMeta acompana los listados paginados. NextOffset solo viene si HasMore.
+type PageMeta struct {
+	Total      *int64 `json:"total"` // null con ?count=false
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	HasMore    bool   `json:"has_more"`
+	NextOffset *int   `json:"next_offset,omitempty"`
+}
+
+// ProductListResponse es la respuesta de GET /products y de /search?type=product.
+type ProductListResponse struct {
+	PageMeta
+	Products []ProductResponse `json:"products"`
 }
 
 type CreateProductRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description" binding:"omitempty"`
-	Price       int64  `json:"price" binding:"required,min=0"`
-	Stock       int64  `json:"stock" binding:"required,min=0"`
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description" binding:"omitempty"`
+	Price       *FlexInt64 `json:"price,omitempty" binding:"omitempty,min=0" swaggertype:"integer"`
+	Currency    string     `json:"currency" binding:"omitempty,len=3"`
+	Barcode     string     `json:"barcode" binding:"omitempty"`
+	Stock       *FlexInt64 `json:"stock,omitempty" binding:"omitempty,min=0" swaggertype:"integer"`
+	Draft       bool       `json:"draft,omitempty"`
+}
+
+// BulkCreateProductsRequest no usa dive: cada item lo valida el servicio para devolver
+// todos los errores por indice.
+type BulkCreateProductsRequest struct {
+	Products []CreateProductRequest `json:"products" binding:"required,min=1,max=100"`
 }
 
+// BulkItemErrorDetail es el error de un item del lote en "details".
+type BulkItemErrorDetail struct {
+	Index   int    `json:"index"`
+	Message string `json:"error"`
+	Code    string `json:"code"`
+}
+
+// UpdateProductRequest admite actualizaciones parciales: los campos omitidos no se modifican.
 type UpdateProductRequest struct {
-	Name        string `json:"name" binding:"omitempty"`
-	Description string `json:"description" binding:"omitempty"`
-	Price       int64  `json:"price" binding:"omitempty,min=0"`
-	Stock       int64  `json:"stock" binding:"omitempty,min=0"`
+	Name        *string    `json:"name" binding:"omitempty,min=1"`
+	Description *string    `json:"description" binding:"omitempty"`
+	Price       *FlexInt64 `json:"price" binding:"omitempty,min=0" swaggertype:"integer"`
+	Currency    *string    `json:"currency" binding:"omitempty,len=3"`
+	Barcode     *string    `json:"barcode"` // "" quita el codigo de barras
+	Stock       *FlexInt64 `json:"stock" binding:"omitempty,min=0" swaggertype:"integer"`
+}
+
+// PriceChangeResponse describe una solicitud de cambio de precio; decided_at es vacio mientras esta pendiente.
+type PriceChangeResponse struct {
+	ID        string `json:"id"`
+	ProductID string `json:"product_id"`
+	OldPrice  int64  `json:"old_price"`
+	NewPrice  int64  `json:"new_price"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	DecidedAt string `json:"decided_at,omitempty"`
+	// int64AsString serializa old_price y new_price como strings (JSON_INT64_AS_STRING).
+	int64AsString bool
+}
+
+// MarshalJSON respeta int64AsString igual que ProductResponse.
+func (r PriceChangeResponse) MarshalJSON() ([]byte, error) {
+	type plain PriceChangeResponse
+	if !r.int64AsString {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		OldPrice string `json:"old_price"`
+		NewPrice string `json:"new_price"`
+	}{plain(r), strconv.FormatInt(r.OldPrice, 10), strconv.FormatInt(r.NewPrice, 10)})
+}
+
+// PriceChangePendingResponse es la respuesta 202 de un update cuyo precio requiere aprobacion.
+type PriceChangePendingResponse struct {
+	Product            ProductResponse     `json:"product"`
+	PriceChangeRequest PriceChangeResponse `json:"price_change_request"`
+}
+
+type PriceChangeDecisionResponse struct {
+	PriceChangeRequest PriceChangeResponse `json:"price_change_request"`
+	Product            *ProductResponse    `json:"product,omitempty"`
+}
+
+// StockAdjustmentRequest suma delta (distinto de cero, puede ser negativo) al stock.
+type StockAdjustmentRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Delta     int64  `json:"delta" binding:"required"`
+}
+
+type BulkAdjustStockRequest struct {
+	Adjustments []StockAdjustmentRequest `json:"adjustments" binding:"required,min=1,max=100,dive"`
 }
 
 type ProductHistoryResponse struct {
@@ -97,15 +262,104 @@ type ProductHistoryResponse struct {
 	Price     int64  `json:"price"`
 	Stock     int64  `json:"stock"`
 	ChangedAt string `json:"changed_at"`
+	// int64AsString serializa price y stock como strings (JSON_INT64_AS_STRING).
+	int64AsString bool
+}
+
+// MarshalJSON respeta int64AsString igual que ProductResponse.
+func (h ProductHistoryResponse) MarshalJSON() ([]byte, error) {
+	type plain ProductHistoryResponse
+	if !h.int64AsString {
+		return json.Marshal(plain(h))
+	}
+	return json.Marshal(struct {
+		plain
+		Price string `json:"price"`
+		Stock string `json:"stock"`
+	}{plain(h), strconv.FormatInt(h.Price, 10), strconv.FormatInt(h.Stock, 10)})
+}
+
+// AdminStatsResponse resume el catalogo y los usuarios; Users agrupa por estado.
+type AdminStatsResponse struct {
+	Products           int64            `json:"products"`
+	OutOfStockProducts int64            `json:"out_of_stock_products"`
+	Categories         int64            `json:"categories"`
+	UsersTotal         int64            `json:"users_total"`
+	Users              map[string]int64 `json:"users"`
+	GeneratedAt        string           `json:"generated_at"`
+}
+
+// SMTPTestRequest indica a quien enviar el correo de prueba.
+type SMTPTestRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// SMTPTestResponse informa si el correo de prueba se entrego; Error trae el detalle del fallo.
+type SMTPTestResponse struct {
+	Sent  bool   `json:"sent"`
+	Error string `json:"error,omitempty"`
+}
+
+// DTOs de webhooks
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1"`
+	Secret string   `json:"secret" binding:"omitempty,min=16"`
+}
+
+type WebhookResponse struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Secret    string   `json:"secret,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// DTOs de metadatos de consulta
+
+// QueryFilterMeta describe un parametro de filtro; Values vacio significa texto libre.
+type QueryFilterMeta struct {
+	Param  string   `json:"param"`
+	Values []string `json:"values,omitempty"`
+}
+
+type QueryMetaResponse struct {
+	SearchKind   string            `json:"search_kind"`
+	SortFields   []string          `json:"sort_fields"`
+	SortOrders   []string          `json:"sort_orders"`
+	DefaultSort  string            `json:"default_sort"`
+	DefaultOrder string            `json:"default_order"`
+	Filters      []QueryFilterMeta `json:"filters"`
+	DefaultLimit int               `json:"default_limit"`
+	MaxLimit     int               `json:"max_limit"`
 }
 
 // DTOs de eventos
 type EventInfo struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Payload     string `json:"payload"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Payload     string        `json:"payload"`
+	Visibility  ws.Visibility `json:"visibility"`
 }
 
 type EventsResponse struct {
 	Events []EventInfo `json:"events"`
 }
+
+// DTOs de errores
+
+// ErrorResponse es el envelope de todas las respuestas de error. Code es estable y es
+// lo que deben comparar los clientes; Message puede estar traducido.
+type ErrorResponse struct {
+	Message string `json:"error"`
+	Code    string `json:"code"`
+	Details any    `json:"details,omitempty"`
+}
+
+// FieldErrorDetail describe una regla de validacion incumplida en un campo del body.
+type FieldErrorDetail struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
@@ -1,14 +1,18 @@
 package http
 
 import (
+	"context"
+
+	"catalog-api/internal/webhook"
 	"catalog-api/internal/ws"
 
 	"github.com/gin-gonic/gin"
 )
 
-// EventEmitter difunde eventos a clientes conectados.
+// EventEmitter difunde eventos a clientes conectados. ctx es el de la peticion que
+// causo el evento y aporta su correlation id.
 type EventEmitter interface {
-	Emit(event string, data interface{})
+	Emit(ctx context.Context, event string, data interface{})
 }
 
 type socketEmitter struct {
@@ -20,21 +24,82 @@ func NewSocketEmitter(hub *ws.Hub) EventEmitter {
 	return &socketEmitter{hub: hub}
 }
 
-func (e *socketEmitter) Emit(event string, data interface{}) {
+func (e *socketEmitter) Emit(ctx context.Context, event string, data interface{}) {
 	if e == nil || e.hub == nil {
 		return
 	}
-	_ = e.hub.Publish(event, data)
+	_ = e.hub.PublishMessage(ws.EventMessage{
+		Event:         event,
+		Data:          data,
+		CorrelationID: CorrelationIDFromContext(ctx),
+	}, eventVisibility(event))
+}
+
+type webhookEmitter struct {
+	dispatcher *webhook.Dispatcher
+}
+
+// NewWebhookEmitter encola los eventos para las suscripciones de webhooks.
+func NewWebhookEmitter(dispatcher *webhook.Dispatcher) EventEmitter {
+	return &webhookEmitter{dispatcher: dispatcher}
+}
+
+func (e *webhookEmitter) Emit(ctx context.Context, event string, data interface{}) {
+	if e == nil || e.dispatcher == nil {
+		return
+	}
+	e.dispatcher.Publish(ctx, webhook.Event{
+		Name:          event,
+		Data:          data,
+		CorrelationID: CorrelationIDFromContext(ctx),
+	})
+}
+
+type multiEmitter []EventEmitter
+
+// NewMultiEmitter difunde cada evento a todos los emisores, en orden.
+func NewMultiEmitter(emitters ...EventEmitter) EventEmitter {
+	return multiEmitter(emitters)
+}
+
+func (m multiEmitter) Emit(ctx context.Context, event string, data interface{}) {
+	for _, e := range m {
+		e.Emit(ctx, event, data)
+	}
 }
 
 var catalogEvents = []EventInfo{
-	{Name: ws.EventCategoryCreated, Description: "Category created", Payload: `{"id","name","description"}`},
-	{Name: ws.EventCategoryUpdated, Description: "Category updated", Payload: `{"id","name","description"}`},
-	{Name: ws.EventCategoryDeleted, Description: "Category deleted", Payload: `{"id"}`},
-	{Name: ws.EventProductCreated, Description: "Product created", Payload: `{"id","name","description","price","stock"}`},
-	{Name: ws.EventProductUpdated, Description: "Product updated", Payload: `{"id","name","description","price","stock"}`},
-	{Name: ws.EventProductDeleted, Description: "Product deleted", Payload: `{"id"}`},
-	{Name: ws.EventProductCategoryAssigned, Description: "Product assigned to category", Payload: `{"product_id","category_id"}`},
+	{Name: ws.EventCategoryCreated, Description: "Category created", Payload: `{"id","name","description"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventCategoryUpdated, Description: "Category updated", Payload: `{"id","name","description"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventCategoryDeleted, Description: "Category deleted", Payload: `{"id"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventCategoryReordered, Description: "Category display order changed", Payload: `{"ids"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventProductCreated, Description: "Product created; POST /products/bulk emits one per product. Drafts are announced by product.published instead", Payload: `{"id","name","description","price","stock"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventProductUpdated, Description: "Product updated; not emitted for drafts", Payload: `{"id","name","description","price","stock"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventProductDeleted, Description: "Product deleted", Payload: `{"id"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventProductRestored, Description: "Product restored after soft-delete; not emitted for drafts", Payload: `{"id","name","description","price","stock"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventProductPublished, Description: "Draft product published", Payload: `{"id","name","description","price","stock"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventProductLowStock, Description: "Product stock dropped to the low stock alert threshold", Payload: `{"id","name","stock"}`, Visibility: ws.VisibilityAdmin},
+	{Name: ws.EventProductCategoryAssigned, Description: "Product assigned to category", Payload: `{"product_id","category_id"}`, Visibility: ws.VisibilityPublic},
+	{Name: ws.EventProductCategoryUnassigned, Description: "Product removed from category", Payload: `{"product_id","category_id"}`, Visibility: ws.VisibilityPublic},
+}
+
+// CatalogEventNames lista los eventos de catalogo, p.ej. para validar suscripciones.
+func CatalogEventNames() []string {
+	names := make([]string, 0, len(catalogEvents))
+	for _, ev := range catalogEvents {
+		names = append(names, ev.Name)
+	}
+	return names
+}
+
+// eventVisibility resuelve la visibilidad declarada en el catalogo; por defecto publica.
+func eventVisibility(event string) ws.Visibility {
+	for _, ev := range catalogEvents {
+		if ev.Name == event && ev.Visibility != "" {
+			return ev.Visibility
+		}
+	}
+	return ws.VisibilityPublic
 }
 
 // EventsCatalogDoc godoc
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"catalog-api/internal/ws"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
 type recordingEmitter struct {
@@ -17,7 +20,7 @@ type recordingEmitter struct {
 	data   []interface{}
 }
 
-func (r *recordingEmitter) Emit(event string, data interface{}) {
+func (r *recordingEmitter) Emit(ctx context.Context, event string, data interface{}) {
 	r.events = append(r.events, event)
 	r.data = append(r.data, data)
 }
@@ -25,7 +28,7 @@ func (r *recordingEmitter) Emit(event string, data interface{}) {
 func TestSocketEmitter_NilSafe(t *testing.T) {
 	emitter := NewSocketEmitter(nil)
 	// no deberia hacer panic
-	emitter.Emit("test", map[string]string{"hello": "world"})
+	emitter.Emit(context.Background(), "test", map[string]string{"hello": "world"})
 }
 
 func TestCatalogEventsPayloads(t *testing.T) {
@@ -44,11 +47,35 @@ func TestSocketEmitter_Broadcast(t *testing.T) {
 	defer cancel()
 	hub := ws.NewHub(nil, nil)
 	go hub.Run(ctx)
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
 
-	emitter := NewSocketEmitter(hub)
-	// no se valida red real, solo que no haga panic y sea serializable a JSON
-	payload := map[string]string{"id": "123"}
-	emitter.Emit(ws.EventProductCreated, payload)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	// se espera el saludo para emitir con el cliente ya registrado en el hub.
+	next := func() ws.EventMessage {
+		t.Helper()
+		var msg ws.EventMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		return msg
+	}
+	if msg := next(); msg.Event != ws.EventConnected {
+		t.Fatalf("expected %s first, got %s", ws.EventConnected, msg.Event)
+	}
+
+	NewSocketEmitter(hub).Emit(ctx, ws.EventProductCreated, map[string]string{"id": "123"})
+
+	msg := next()
+	data, _ := msg.Data.(map[string]interface{})
+	if msg.Event != ws.EventProductCreated || data["id"] != "123" {
+		t.Fatalf("expected the emitted event to reach the subscriber, got %+v", msg)
+	}
 }
 
 func TestEventsCatalogResponse(t *testing.T) {
@@ -79,3 +106,14 @@ func TestEventsCatalogHandler(t *testing.T) {
 		t.Fatalf("expected %d events, got %d", len(catalogEvents), len(resp.Events))
 	}
 }
+
+func TestEventVisibility_DefaultsToPublic(t *testing.T) {
+	for _, ev := range catalogEvents {
+		if ev.Visibility == "" {
+			t.Fatalf("event %s should declare its visibility", ev.Name)
+		}
+	}
+	if got := eventVisibility("unknown.event"); got != ws.VisibilityPublic {
+		t.Fatalf("expected unknown events to be public, got %q", got)
+	}
+}
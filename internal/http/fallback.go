@@ -0,0 +1,19 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MethodNotAllowedHandler responde 405 con el envelope JSON de error. gin ya
+// completa el header Allow con los metodos registrados para la ruta.
+func MethodNotAllowedHandler(c *gin.Context) {
+	abortError(c, http.StatusMethodNotAllowed, codeMethodNotAllowed, errors.New("method not allowed"), nil)
+}
+
+// NotFoundHandler responde 404 con el envelope JSON de error y la ruta pedida.
+func NotFoundHandler(c *gin.Context) {
+	abortError(c, http.StatusNotFound, codeRouteNotFound, errors.New("route not found"), gin.H{"path": c.Request.URL.Path})
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// productFields son las claves de ProductResponse que admite ?fields=.
+var productFields = map[string]struct{}{
+	"id": {}, "name": {}, "slug": {}, "description": {}, "price": {},
+	"currency": {}, "barcode": {}, "stock": {}, "draft": {}, "snippet": {},
+}
+
+// parseFields lee ?fields=id,name y valida cada campo contra known. Sin el parametro
+// devuelve nil, que significa respuesta completa.
+func parseFields(c *gin.Context, known map[string]struct{}) ([]string, error) {
+	raw, ok := c.GetQuery("fields")
+	if !ok {
+		return nil, nil
+	}
+	var fields []string
+	seen := make(map[string]bool)
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || seen[f] {
+			continue
+		}
+		if _, ok := known[f]; !ok {
+			return nil, fmt.Errorf("unknown field %q in fields", f)
+		}
+		seen[f] = true
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must list at least one field")
+	}
+	return fields, nil
+}
+
+// projectProducts recorta cada producto a fields despues de serializarlo, asi la
+// proyeccion respeta MarshalJSON (p. ej. int64 como string) y omitempty.
+func projectProducts(items []ProductResponse, fields []string) ([]map[string]json.RawMessage, error) {
+	out := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var full map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &full); err != nil {
+			return nil, err
+		}
+		projected := make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			if v, ok := full[f]; ok {
+				projected[f] = v
+			}
+		}
+		out = append(out, projected)
+	}
+	return out, nil
+}
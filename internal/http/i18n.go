@@ -0,0 +1,227 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+
+	"catalog-api/internal/catalog"
+	"catalog-api/internal/identity"
+	"catalog-api/internal/webhook"
+	"catalog-api/pkg/locale"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Codigos estables que no dependen de un error de dominio.
+const (
+	codeInvalidRequest = "invalid_request"
+	codeValidation     = "validation_error"
+	codeInternal       = "internal_error"
+	// codeUnsupportedMediaType acompana el 415 de ContentTypeMiddleware.
+	codeUnsupportedMediaType = "unsupported_media_type"
+	// codigos de los middlewares, que cortan antes de llegar a un handler.
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeRateLimited      = "rate_limited"
+	codeOverloaded       = "server_overloaded"
+	codePayloadTooLarge  = "payload_too_large"
+	codeCSRFInvalid      = "csrf_invalid"
+	codeInvalidID        = "invalid_id"
+	codeRouteNotFound    = "route_not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+)
+
+// errorCodes asocia errores de dominio con un codigo estable para los clientes.
+// Se recorre en orden con errors.Is, asi que los errores envueltos tambien resuelven.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{catalog.ErrInvalidCategory, "invalid_category"},
+	{catalog.ErrInvalidCategoryID, "invalid_category_id"},
+	{catalog.ErrInvalidSlug, "invalid_slug"},
+	{catalog.ErrInvalidCategoryOrder, "invalid_category_order"},
+	{catalog.ErrInvalidProduct, "invalid_product"},
+	{catalog.ErrInvalidProductID, "invalid_product_id"},
+	{catalog.ErrInvalidSearchKind, "invalid_search_kind"},
+	{catalog.ErrInvalidCurrency, "invalid_currency"},
+	{catalog.ErrInvalidBarcode, "invalid_barcode"},
+	{catalog.ErrDuplicateBarcode, "duplicate_barcode"},
+	{catalog.ErrInvalidStockFilter, "invalid_stock_filter"},
+	{catalog.ErrInvalidPriceRange, "invalid_price_range"},
+	{catalog.ErrInvalidStockAdjustment, "invalid_stock_adjustment"},
+	{catalog.ErrInsufficientStock, "insufficient_stock"},
+	{catalog.ErrCategoryConflict, "category_conflict"},
+	{catalog.ErrCategoryNotFound, "category_not_found"},
+	{catalog.ErrProductNotFound, "product_not_found"},
+	{catalog.ErrProductHistoryNotFound, "product_history_not_found"},
+	{catalog.ErrProductIncomplete, "product_incomplete"},
+	{catalog.ErrPriceChangeNotFound, "price_change_not_found"},
+	{catalog.ErrPriceChangeDecided, "price_change_decided"},
+	{catalog.ErrPriceChangeStale, "price_change_stale"},
+	{catalog.ErrInvalidPriceChangeState, "invalid_price_change_status"},
+	{identity.ErrEmailAlreadyRegistered, "email_already_registered"},
+	{identity.ErrUserNotFound, "user_not_found"},
+	{identity.ErrUserBlocked, "user_blocked"},
+	{identity.ErrUserNotVerified, "user_not_verified"},
+	{identity.ErrInvalidCredentials, "invalid_credentials"},
+	{identity.ErrInvalidVerificationCode, "invalid_verification_code"},
+	{identity.ErrVerificationSenderNotSet, "verification_unavailable"},
+	{identity.ErrSessionNotFound, "session_not_found"},
+	{identity.ErrInvalidRefreshToken, "invalid_refresh_token"},
+	{identity.ErrTooManyUserIDs, "too_many_user_ids"},
+	{identity.ErrUsernameTaken, "username_taken"},
+	{identity.ErrInvalidUsername, "invalid_username"},
+	{identity.ErrUsernamesDisabled, "usernames_disabled"},
+	{webhook.ErrInvalidSubscription, "invalid_webhook_subscription"},
+	{webhook.ErrUnknownEvent, "unknown_webhook_event"},
+	{webhook.ErrSubscriptionNotFound, "webhook_subscription_not_found"},
+	{errOffsetTooLarge, "offset_too_large"},
+}
+
+// errorMessages traduce codigos por locale. El ingles no necesita tabla: su mensaje
+// es el texto del error de dominio, que tambien es el default si falta una traduccion.
+var errorMessages = map[string]map[string]string{
+	locale.Spanish: {
+		"invalid_category":               "categoria invalida",
+		"invalid_category_id":            "id de categoria invalido",
+		"invalid_slug":                   "slug invalido",
+		"invalid_category_order":         "el orden de categorias debe listar cada id una sola vez",
+		"invalid_product":                "producto invalido",
+		"invalid_product_id":             "id de producto invalido",
+		"invalid_search_kind":            "tipo de busqueda invalido",
+		"invalid_currency":               "moneda invalida",
+		"invalid_barcode":                "el codigo de barras debe ser un EAN-13 o UPC-A valido",
+		"duplicate_barcode":              "el codigo de barras ya esta asignado a otro producto",
+		"invalid_stock_filter":           "el filtro de stock debe ser out, in o low",
+		"invalid_price_range":            "min_price y max_price no pueden ser negativos y min_price no puede superar a max_price",
+		"invalid_stock_adjustment":       "ajuste de stock invalido",
+		"insufficient_stock":             "el stock no puede quedar negativo",
+		"category_conflict":              "ya existe una categoria con ese nombre",
+		"category_not_found":             "categoria no encontrada",
+		"product_not_found":              "producto no encontrado",
+		"product_history_not_found":      "historial de producto no encontrado",
+		"product_incomplete":             "al producto le faltan datos para publicarse",
+		"price_change_not_found":         "solicitud de cambio de precio no encontrada",
+		"price_change_decided":           "la solicitud de cambio de precio ya fue resuelta",
+		"price_change_stale":             "el precio del producto cambio desde que se creo la solicitud",
+		"invalid_price_change_status":    "el estado debe ser pending, approved o rejected",
+		"email_already_registered":       "el email ya esta registrado",
+		"user_not_found":                 "usuario no encontrado",
+		"user_blocked":                   "el usuario esta bloqueado",
+		"user_not_verified":              "el usuario no esta verificado",
+		"invalid_credentials":            "credenciales invalidas",
+		"invalid_verification_code":      "codigo de verificacion invalido",
+		"verification_unavailable":       "la verificacion por email no esta disponible",
+		"session_not_found":              "sesion no encontrada",
+		"invalid_refresh_token":          "refresh token invalido",
+		"too_many_user_ids":              "demasiados ids de usuario en la solicitud",
+		"username_taken":                 "el username ya esta en uso",
+		"invalid_username":               "username invalido",
+		"usernames_disabled":             "los usernames estan deshabilitados",
+		"invalid_webhook_subscription":   "suscripcion de webhook invalida",
+		"unknown_webhook_event":          "evento de webhook desconocido",
+		"webhook_subscription_not_found": "suscripcion de webhook no encontrada",
+		"offset_too_large":               "offset demasiado grande; acota los resultados con los filtros del listado en lugar de paginar mas profundo",
+		codeUnsupportedMediaType:         "Content-Type no soportado; el body debe enviarse como JSON",
+		codeValidation:                   "el body no cumple el schema",
+		codeUnauthorized:                 "se requiere un token valido",
+		codeForbidden:                    "no tienes permiso para esta operacion",
+		codeRateLimited:                  "demasiadas peticiones; intenta mas tarde",
+		codeOverloaded:                   "el servidor esta sobrecargado; intenta mas tarde",
+		codePayloadTooLarge:              "el body supera el tamano permitido",
+		codeCSRFInvalid:                  "token CSRF invalido",
+		codeInvalidID:                    "id invalido",
+		codeRouteNotFound:                "ruta no encontrada",
+		codeMethodNotAllowed:             "metodo no permitido",
+		codeInternal:                     "error interno del servidor",
+	},
+}
+
+// errorCode devuelve el codigo estable de err, o "" si no es un error de dominio conocido.
+func errorCode(err error) string {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return ""
+}
+
+// localizedMessage traduce code al locale del request; sin traduccion devuelve fallback.
+func localizedMessage(c *gin.Context, code, fallback string) string {
+	if msg, ok := errorMessages[RequestLocale(c)][code]; ok {
+		return msg
+	}
+	return fallback
+}
+
+// errorBody arma el envelope {"error", "code"} para un error de dominio; un error sin
+// codigo conserva su texto y se reporta como invalid_request.
+func errorBody(c *gin.Context, err error) ErrorResponse {
+	code := errorCode(err)
+	if code == "" {
+		return ErrorResponse{Message: err.Error(), Code: codeInvalidRequest}
+	}
+	return ErrorResponse{Message: localizedMessage(c, code, err.Error()), Code: code}
+}
+
+// internalErrorBody es el envelope de un 500. El error original solo se expone en
+// "details" con DebugErrorsMiddleware activo.
+func internalErrorBody(c *gin.Context, err error) ErrorResponse {
+	body := ErrorResponse{Message: localizedMessage(c, codeInternal, "internal server error"), Code: codeInternal}
+	if debugErrors(c) && err != nil {
+		body.Details = gin.H{"error": err.Error()}
+	}
+	return body
+}
+
+// respondError escribe el envelope de err con status. code vacio lo resuelve desde
+// errorCodes; los 5xx sin code nunca exponen el error y quedan registrados en c.Errors.
+func respondError(c *gin.Context, status int, code string, err error) {
+	c.JSON(status, responseBody(c, status, code, err))
+}
+
+// abortError es respondError para middlewares: corta la cadena y, si details no es
+// nil, lo agrega al envelope (p.ej. el parametro o la ruta que fallo).
+func abortError(c *gin.Context, status int, code string, err error, details any) {
+	body := responseBody(c, status, code, err)
+	if details != nil {
+		body.Details = details
+	}
+	c.AbortWithStatusJSON(status, body)
+}
+
+// responseBody arma el envelope de respondError. Un 5xx con code explicito (p.ej. 503
+// por sobrecarga) lleva un mensaje pensado para el cliente y se responde tal cual.
+func responseBody(c *gin.Context, status int, code string, err error) ErrorResponse {
+	if status >= http.StatusInternalServerError && code == "" {
+		_ = c.Error(err)
+		return internalErrorBody(c, err)
+	}
+	if code != "" {
+		return ErrorResponse{Message: localizedMessage(c, code, err.Error()), Code: code}
+	}
+	return errorBody(c, err)
+}
+
+// respondBindError responde 400 a un body que no pudo decodificarse en obj. Las reglas
+// de validacion incumplidas salen como validation_error con el detalle por campo.
+func respondBindError(c *gin.Context, err error, obj any) {
+	body := ErrorResponse{Message: bindErrorMessage(c, err, obj), Code: codeInvalidRequest}
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		details := make([]FieldErrorDetail, 0, len(verrs))
+		for _, fe := range verrs {
+			details = append(details, FieldErrorDetail{
+				Field: jsonFieldPath(reflect.TypeOf(obj), fe.StructNamespace()),
+				Rule:  fe.Tag(),
+				Param: fe.Param(),
+			})
+		}
+		body.Code, body.Details = codeValidation, details
+	}
+	c.JSON(http.StatusBadRequest, body)
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"catalog-api/internal/catalog"
+	"catalog-api/internal/identity"
+	"catalog-api/pkg/locale"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorEnvelope_LocalizedByAcceptLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{getProductErr: fmt.Errorf("lookup: %w", catalog.ErrProductNotFound)}
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil)}).Build()
+
+	cases := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"es", "producto no encontrado"},
+		{"en", "lookup: product not found"},
+		{"", "lookup: product not found"},
+		{"fr", "lookup: product not found"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products/p1", nil)
+		if tc.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+		}
+		router.ServeHTTP(w, req)
+
+		var body map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if w.Code != http.StatusNotFound || body["code"] != "product_not_found" || body["error"] != tc.want {
+			t.Fatalf("Accept-Language %q: unexpected %d %+v", tc.acceptLanguage, w.Code, body)
+		}
+	}
+}
+
+func TestLocalizedMessage_FallsBackWhenTranslationMissing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request = req.WithContext(locale.WithLocale(req.Context(), locale.Spanish))
+
+	if got := localizedMessage(c, "not_translated", "default message"); got != "default message" {
+		t.Fatalf("expected fallback message, got %q", got)
+	}
+	body := errorBody(c, errors.New("something odd"))
+	if body.Message != "something odd" || body.Code != codeInvalidRequest {
+		t.Fatalf("expected unknown errors to keep their text, got %+v", body)
+	}
+	if body := internalErrorBody(c, errors.New("db down")); body.Message != "error interno del servidor" || body.Code != codeInternal {
+		t.Fatalf("unexpected internal error body %+v", body)
+	}
+}
+
+func TestErrorCodes_EveryCodeHasSpanishTranslation(t *testing.T) {
+	for _, entry := range errorCodes {
+		if _, ok := errorMessages[locale.Spanish][entry.code]; !ok {
+			t.Fatalf("missing spanish translation for %s", entry.code)
+		}
+	}
+}
+
+func TestRespondError_CodesAndInternalErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		status   int
+		code     string
+		err      error
+		wantCode string
+		wantMsg  string
+	}{
+		{status: http.StatusConflict, err: identity.ErrEmailAlreadyRegistered, wantCode: "email_already_registered", wantMsg: identity.ErrEmailAlreadyRegistered.Error()},
+		{status: http.StatusBadRequest, code: codeInvalidRequest, err: errors.New("invalid start date"), wantCode: codeInvalidRequest, wantMsg: "invalid start date"},
+		{status: http.StatusInternalServerError, err: errors.New("pgx: conn closed"), wantCode: codeInternal, wantMsg: "internal server error"},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+		respondError(c, tc.status, tc.code, tc.err)
+
+		var body ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if w.Code != tc.status || body.Code != tc.wantCode || body.Message != tc.wantMsg {
+			t.Fatalf("%v: unexpected %d %+v", tc.err, w.Code, body)
+		}
+	}
+}
+
+func TestErrorEnvelope_MiddlewareCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := &stubTokenValidator{}
+	router := (&RouterFactory{
+		CatalogHandler:  NewCatalogHandler(&stubCatalogService{}, nil),
+		IdentityHandler: NewIdentityHandler(&stubIdentityService{}),
+		TokenValidator:  validator,
+		UUIDParams:      true,
+		LoginRatePerMin: 1,
+		LoginBurst:      1,
+	}).Build()
+
+	send := func(method, path, body string, setup func(*http.Request)) (int, map[string]any) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if setup != nil {
+			setup(req)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var out map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &out)
+		return w.Code, out
+	}
+	bearer := func(r *http.Request) { r.Header.Set("Authorization", "Bearer jwt") }
+	category := `{"name":"Books"}`
+
+	cases := []struct {
+		name      string
+		setup     func()
+		method    string
+		path      string
+		body      string
+		header    func(*http.Request)
+		status    int
+		code      string
+		wantError string
+	}{
+		{name: "missing token", method: http.MethodPost, path: "/api/v1/categories", body: category,
+			status: http.StatusUnauthorized, code: "unauthorized", wantError: "missing bearer token"},
+		{name: "parser error is not echoed", setup: func() { validator.err = errors.New("token is malformed: could not base64 decode signature") },
+			method: http.MethodPost, path: "/api/v1/categories", body: category, header: bearer,
+			status: http.StatusUnauthorized, code: "unauthorized", wantError: "invalid or expired token"},
+		{name: "wrong role", setup: func() { validator.err, validator.ctx = nil, AuthContext{UserID: "u1", Role: "client"} },
+			method: http.MethodPost, path: "/api/v1/categories", body: category, header: bearer,
+			status: http.StatusForbidden, code: "forbidden"},
+		{name: "csrf", method: http.MethodPost, path: "/api/v1/categories", body: category,
+			header: func(r *http.Request) { r.AddCookie(&http.Cookie{Name: authCookieName, Value: "jwt"}) },
+			status: http.StatusForbidden, code: "csrf_invalid"},
+		{name: "bad uuid", method: http.MethodGet, path: "/api/v1/products/not-a-uuid",
+			status: http.StatusBadRequest, code: "invalid_id"},
+		{name: "unknown route", method: http.MethodGet, path: "/api/v1/nope",
+			status: http.StatusNotFound, code: "route_not_found"},
+		{name: "unknown route localized", method: http.MethodGet, path: "/api/v1/nope",
+			header: func(r *http.Request) { r.Header.Set("Accept-Language", "es") },
+			status: http.StatusNotFound, code: "route_not_found", wantError: "ruta no encontrada"},
+		{name: "method not allowed", method: http.MethodDelete, path: "/api/v1/categories",
+			status: http.StatusMethodNotAllowed, code: "method_not_allowed"},
+		{name: "login burst spent", setup: func() {
+			send(http.MethodPost, "/api/v1/identity/login", `{"email":"a@b.c","password":"x"}`, nil)
+		}, method: http.MethodPost, path: "/api/v1/identity/login", body: `{"email":"a@b.c","password":"x"}`,
+			status: http.StatusTooManyRequests, code: "rate_limited"},
+	}
+	for _, tc := range cases {
+		if tc.setup != nil {
+			tc.setup()
+		}
+		status, body := send(tc.method, tc.path, tc.body, tc.header)
+		if status != tc.status || body["code"] != tc.code {
+			t.Fatalf("%s: expected %d %s, got %d %+v", tc.name, tc.status, tc.code, status, body)
+		}
+		if tc.wantError != "" && body["error"] != tc.wantError {
+			t.Fatalf("%s: expected error %q, got %+v", tc.name, tc.wantError, body)
+		}
+	}
+}
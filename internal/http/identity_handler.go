@@ -1,26 +1,57 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"catalog-api/internal/identity"
 
 	"github.com/gin-gonic/gin"
 )
 
+// authCookieName es la cookie que leen AuthMiddleware y el handshake WS.
+const authCookieName = "token"
+
 // IdentityHandler orquesta los endpoints HTTP de identidad.
 type IdentityHandler struct {
-	svc identity.Service
+	svc    identity.Service
+	cookie AuthCookieConfig
+}
+
+// AuthCookieConfig define como se entrega el JWT en cookie tras el login.
+type AuthCookieConfig struct {
+	Always   bool // si es false solo se setea con ?cookie=true
+	Secure   bool
+	SameSite http.SameSite
+	Domain   string
+	MaxAge   time.Duration // normalmente el TTL del JWT
 }
 
-func NewIdentityHandler(svc identity.Service) *IdentityHandler {
-	return &IdentityHandler{svc: svc}
+// IdentityHandlerOption ajusta el comportamiento opcional del handler.
+type IdentityHandlerOption func(*IdentityHandler)
+
+// WithAuthCookie habilita la entrega del token en una cookie HttpOnly.
+func WithAuthCookie(cfg AuthCookieConfig) IdentityHandlerOption {
+	return func(h *IdentityHandler) {
+		h.cookie = cfg
+	}
+}
+
+func NewIdentityHandler(svc identity.Service, opts ...IdentityHandlerOption) *IdentityHandler {
+	h := &IdentityHandler{svc: svc}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *IdentityHandler) RegisterClient(c *gin.Context) {
 	var req RegisterClientRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
 		return
 	}
 
@@ -28,9 +59,10 @@ func (h *IdentityHandler) RegisterClient(c *gin.Context) {
 		Email:    req.Email,
 		Password: req.Password,
 		FullName: req.FullName,
+		Username: req.Username,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, registerErrorStatus(err), "", err)
 		return
 	}
 
@@ -39,8 +71,8 @@ func (h *IdentityHandler) RegisterClient(c *gin.Context) {
 
 func (h *IdentityHandler) RegisterUser(c *gin.Context) {
 	var req RegisterUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
 		return
 	}
 
@@ -48,9 +80,10 @@ func (h *IdentityHandler) RegisterUser(c *gin.Context) {
 		Email:    req.Email,
 		Password: req.Password,
 		FullName: req.FullName,
+		Username: req.Username,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, registerErrorStatus(err), "", err)
 		return
 	}
 
@@ -60,7 +93,7 @@ func (h *IdentityHandler) RegisterUser(c *gin.Context) {
 func (h *IdentityHandler) VerifyUser(c *gin.Context) {
 	var req VerifyUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err, &req)
 		return
 	}
 
@@ -68,7 +101,7 @@ func (h *IdentityHandler) VerifyUser(c *gin.Context) {
 		UserID: req.UserID,
 		Code:   req.Code,
 	}); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, "", err)
 		return
 	}
 
@@ -78,19 +111,19 @@ func (h *IdentityHandler) VerifyUser(c *gin.Context) {
 func (h *IdentityHandler) BlockUser(c *gin.Context) {
 	var req BlockUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err, &req)
 		return
 	}
 
 	userID := c.Param("id")
-	adminID := c.GetString("user_id") // TODO: reemplazar con extraccion real del contexto de auth.
+	adminID, _ := AuthUserID(c)
 
 	if err := h.svc.BlockUser(c.Request.Context(), identity.BlockUserInput{
 		AdminID: adminID,
 		UserID:  userID,
 		Reason:  req.Reason,
 	}); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, "", err)
 		return
 	}
 
@@ -99,16 +132,16 @@ func (h *IdentityHandler) BlockUser(c *gin.Context) {
 
 func (h *IdentityHandler) UpdateUser(c *gin.Context) {
 	var req UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
 		return
 	}
 
-	userID := c.GetString("user_id")
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing user identity"})
+	auth, ok := MustAuth(c)
+	if !ok {
 		return
 	}
+	userID := auth.UserID
 
 	updated, err := h.svc.UpdateUser(c.Request.Context(), identity.UpdateUserInput{
 		UserID:    identity.UserID(userID),
@@ -116,7 +149,7 @@ func (h *IdentityHandler) UpdateUser(c *gin.Context) {
 		FullName:  req.FullName,
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, "", err)
 		return
 	}
 
@@ -126,31 +159,123 @@ func (h *IdentityHandler) UpdateUser(c *gin.Context) {
 func (h *IdentityHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err, &req)
+		return
+	}
+	if req.Email == "" && strings.TrimSpace(req.Identifier) == "" {
+		respondError(c, http.StatusBadRequest, codeInvalidRequest, errors.New("email or identifier is required"))
 		return
 	}
 
 	token, err := h.svc.Login(c.Request.Context(), identity.LoginInput{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:      req.Email,
+		Identifier: strings.TrimSpace(req.Identifier),
+		Password:   req.Password,
+		UserAgent:  c.Request.UserAgent(),
+		IP:         c.ClientIP(),
+		RememberMe: req.RememberMe,
 	})
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		respondError(c, http.StatusUnauthorized, "", err)
+		return
+	}
+
+	if err := h.setAuthCookie(c, token.Token); err != nil {
+		respondError(c, http.StatusInternalServerError, "", err)
+		return
+	}
+	c.JSON(http.StatusOK, LoginResponse{Token: token.Token, RefreshToken: token.RefreshToken})
+}
+
+// setAuthCookie escribe el JWT en una cookie HttpOnly cuando esta configurado o se pide con ?cookie=true.
+// Junto a ella emite la cookie CSRF legible por JS que el cliente debe reenviar en X-CSRF-Token.
+func (h *IdentityHandler) setAuthCookie(c *gin.Context, token string) error {
+	if !h.cookie.Always && c.Query("cookie") != "true" {
+		return nil
+	}
+	csrf, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+	sameSite := h.cookie.SameSite
+	if sameSite == 0 {
+		sameSite = http.SameSiteLaxMode
+	}
+	maxAge := int(h.cookie.MaxAge.Seconds())
+	c.SetSameSite(sameSite)
+	c.SetCookie(authCookieName, token, maxAge, "/", h.cookie.Domain, h.cookie.Secure, true)
+	c.SetCookie(csrfCookieName, csrf, maxAge, "/", h.cookie.Domain, h.cookie.Secure, false)
+	return nil
+}
+
+func (h *IdentityHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err, &req)
+		return
+	}
+
+	token, err := h.svc.RefreshSession(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, LoginResponse{Token: token.Token})
+	if err := h.setAuthCookie(c, token.Token); err != nil {
+		respondError(c, http.StatusInternalServerError, "", err)
+		return
+	}
+	c.JSON(http.StatusOK, LoginResponse{Token: token.Token, RefreshToken: token.RefreshToken})
+}
+
+func (h *IdentityHandler) ListSessions(c *gin.Context) {
+	auth, ok := MustAuth(c)
+	if !ok {
+		return
+	}
+	userID := auth.UserID
+
+	sessions, err := h.svc.ListSessions(c.Request.Context(), identity.UserID(userID))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, toSessionResponse(s))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *IdentityHandler) RevokeSession(c *gin.Context) {
+	auth, ok := MustAuth(c)
+	if !ok {
+		return
+	}
+	userID := auth.UserID
+
+	if err := h.svc.RevokeSession(c.Request.Context(), identity.UserID(userID), c.Param("id")); err != nil {
+		if errors.Is(err, identity.ErrSessionNotFound) {
+			respondError(c, http.StatusNotFound, "", err)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 func (h *IdentityHandler) UpdateUserRole(c *gin.Context) {
 	var req UpdateUserRoleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
 		return
 	}
 
 	userID := c.Param("id")
-	adminID := c.GetString("user_id")
+	adminID, _ := AuthUserID(c)
 
 	updated, err := h.svc.UpdateUserRole(c.Request.Context(), identity.UpdateUserRoleInput{
 		AdminID: adminID,
@@ -158,17 +283,68 @@ func (h *IdentityHandler) UpdateUserRole(c *gin.Context) {
 		Role:    identity.RoleName(req.Role),
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, "", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, toIdentityResponse(updated))
 }
 
+func (h *IdentityHandler) VerificationStatuses(c *gin.Context) {
+	var req VerificationStatusRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
+		return
+	}
+
+	statuses, err := h.svc.VerificationStatuses(c.Request.Context(), req.UserIDs)
+	if err != nil {
+		if errors.Is(err, identity.ErrTooManyUserIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": localizedMessage(c, "too_many_user_ids", fmt.Sprintf("at most %d user ids per request", identity.MaxVerificationStatusIDs)),
+				"code":  "too_many_user_ids",
+			})
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	resp := VerificationStatusListResponse{Users: make([]VerificationStatusResponse, 0, len(statuses))}
+	for _, st := range statuses {
+		resp.Users = append(resp.Users, VerificationStatusResponse{
+			UserID:     st.UserID,
+			IsVerified: st.IsVerified,
+			Status:     string(st.Status),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toSessionResponse(s identity.Session) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+		LastUsedAt: s.LastUsedAt.Format(time.RFC3339),
+		ExpiresAt:  s.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// registerErrorStatus separa los fallos de configuracion (503) de los datos invalidos.
+func registerErrorStatus(err error) int {
+	if errors.Is(err, identity.ErrVerificationSenderNotSet) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
 func toIdentityResponse(u identity.User) IdentityResponse {
 	return IdentityResponse{
 		ID:         u.ID,
 		Email:      u.Email,
+		Username:   u.Username,
 		FullName:   u.FullName,
 		Role:       string(u.Role),
 		Status:     string(u.Status),
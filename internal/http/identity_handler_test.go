@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"catalog-api/internal/identity"
 
@@ -15,6 +16,7 @@ import (
 )
 
 type stubIdentityService struct {
+	userCounts          map[identity.UserStatus]int64
 	registerClientInput identity.RegisterUserInput
 	registerClientResp  identity.User
 	registerClientErr   error
@@ -40,6 +42,22 @@ type stubIdentityService struct {
 	updateRoleInput identity.UpdateUserRoleInput
 	updateRoleResp  identity.User
 	updateRoleErr   error
+
+	refreshInput string
+	refreshResp  identity.AuthToken
+	refreshErr   error
+
+	sessionsUserID identity.UserID
+	sessionsResp   []identity.Session
+	sessionsErr    error
+
+	revokeUserID    identity.UserID
+	revokeSessionID string
+	revokeErr       error
+
+	statusesInput []identity.UserID
+	statusesResp  []identity.VerificationStatus
+	statusesErr   error
 }
 
 func (s *stubIdentityService) RegisterClient(ctx context.Context, input identity.RegisterUserInput) (identity.User, error) {
@@ -81,6 +99,31 @@ func (s *stubIdentityService) UpdateUserRole(ctx context.Context, input identity
 	return s.updateRoleResp, s.updateRoleErr
 }
 
+func (s *stubIdentityService) RefreshSession(ctx context.Context, refreshToken string) (identity.AuthToken, error) {
+	s.refreshInput = refreshToken
+	return s.refreshResp, s.refreshErr
+}
+
+func (s *stubIdentityService) ListSessions(ctx context.Context, userID identity.UserID) ([]identity.Session, error) {
+	s.sessionsUserID = userID
+	return s.sessionsResp, s.sessionsErr
+}
+
+func (s *stubIdentityService) RevokeSession(ctx context.Context, userID identity.UserID, sessionID string) error {
+	s.revokeUserID = userID
+	s.revokeSessionID = sessionID
+	return s.revokeErr
+}
+
+func (s *stubIdentityService) VerificationStatuses(ctx context.Context, ids []identity.UserID) ([]identity.VerificationStatus, error) {
+	s.statusesInput = ids
+	return s.statusesResp, s.statusesErr
+}
+
+func (s *stubIdentityService) UserCountsByStatus(ctx context.Context) (map[identity.UserStatus]int64, error) {
+	return s.userCounts, nil
+}
+
 func sampleUser(id, email string) identity.User {
 	return identity.User{
 		ID:         identity.UserID(id),
@@ -164,6 +207,24 @@ func TestRegisterUser_Error(t *testing.T) {
 	}
 }
 
+func TestRegisterClient_MissingSenderIsUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubIdentityService{registerClientErr: identity.ErrVerificationSenderNotSet}
+	h := NewIdentityHandler(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/identity/users/client", strings.NewReader(`{"email":"user@example.com","password":"password123","full_name":"User"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	h.RegisterClient(c)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", w.Code)
+	}
+}
+
 func TestVerifyUser_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubIdentityService{}
@@ -279,6 +340,7 @@ func TestLogin_Success(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodPost, "/identity/login", strings.NewReader(`{"email":"user@example.com","password":"password123"}`))
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("User-Agent", "test-agent/1.0")
 
 	h.Login(c)
 
@@ -288,6 +350,9 @@ func TestLogin_Success(t *testing.T) {
 	if svc.loginInput.Email != "user@example.com" {
 		t.Fatalf("service login input %+v", svc.loginInput)
 	}
+	if svc.loginInput.UserAgent != "test-agent/1.0" || svc.loginInput.IP != "192.0.2.1" {
+		t.Fatalf("expected user agent and ip to be threaded, got %+v", svc.loginInput)
+	}
 	var resp LoginResponse
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("decode response: %v", err)
@@ -314,6 +379,39 @@ func TestLogin_Unauthorized(t *testing.T) {
 	}
 }
 
+func TestLogin_Identifier(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantID   string
+	}{
+		{name: "username", body: `{"identifier":" ana ","password":"password123"}`, wantCode: http.StatusOK, wantID: "ana"},
+		{name: "missing email and identifier", body: `{"password":"password123"}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubIdentityService{loginResp: identity.AuthToken{Token: "jwt123"}}
+			h := NewIdentityHandler(svc)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/identity/login", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.Login(c)
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if svc.loginInput.Identifier != tc.wantID {
+				t.Fatalf("expected identifier %q, got %+v", tc.wantID, svc.loginInput)
+			}
+		})
+	}
+}
+
 func TestUpdateUserRole_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	svc := &stubIdentityService{
@@ -337,3 +435,217 @@ func TestUpdateUserRole_Success(t *testing.T) {
 		t.Fatalf("service received wrong role input %+v", svc.updateRoleInput)
 	}
 }
+
+func TestRefresh_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubIdentityService{refreshResp: identity.AuthToken{Token: "jwt456", RefreshToken: "rt"}}
+	h := NewIdentityHandler(svc)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/identity/refresh", strings.NewReader(`{"refresh_token":"rt"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.Refresh(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.refreshInput != "rt" {
+		t.Fatalf("service received refresh token %q", svc.refreshInput)
+	}
+	var resp LoginResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Token != "jwt456" || resp.RefreshToken != "rt" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestRefresh_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubIdentityService{refreshErr: identity.ErrInvalidRefreshToken}
+	h := NewIdentityHandler(svc)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/identity/refresh", strings.NewReader(`{"refresh_token":"nope"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.Refresh(c)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestListSessions_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	svc := &stubIdentityService{sessionsResp: []identity.Session{
+		{ID: "s1", UserID: "u1", UserAgent: "curl/8.0", CreatedAt: now, LastUsedAt: now, ExpiresAt: now.Add(time.Hour)},
+	}}
+	h := NewIdentityHandler(svc)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", "u1")
+	c.Request = httptest.NewRequest(http.MethodGet, "/identity/users/me/sessions", nil)
+
+	h.ListSessions(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if svc.sessionsUserID != "u1" {
+		t.Fatalf("expected sessions for u1, got %q", svc.sessionsUserID)
+	}
+	if strings.Contains(w.Body.String(), "token") {
+		t.Fatalf("session listing must not expose tokens: %s", w.Body.String())
+	}
+	var resp []SessionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 || resp[0].ID != "s1" || resp[0].UserAgent != "curl/8.0" {
+		t.Fatalf("unexpected sessions %+v", resp)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"revoked", nil, http.StatusNoContent},
+		{"not found", identity.ErrSessionNotFound, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &stubIdentityService{revokeErr: tc.err}
+			h := NewIdentityHandler(svc)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", "u1")
+			c.Params = gin.Params{{Key: "id", Value: "s1"}}
+			c.Request = httptest.NewRequest(http.MethodDelete, "/identity/users/me/sessions/s1", nil)
+
+			h.RevokeSession(c)
+
+			if status := c.Writer.Status(); status != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, status)
+			}
+			if svc.revokeUserID != "u1" || svc.revokeSessionID != "s1" {
+				t.Fatalf("service received %q/%q", svc.revokeUserID, svc.revokeSessionID)
+			}
+		})
+	}
+}
+
+func TestLogin_SetsAuthCookieWhenRequested(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubIdentityService{loginResp: identity.AuthToken{Token: "jwt123"}}
+	h := NewIdentityHandler(svc, WithAuthCookie(AuthCookieConfig{
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   15 * time.Minute,
+	}))
+
+	login := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"email":"user@example.com","password":"password123"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.Login(c)
+		return w
+	}
+
+	if w := login("/identity/login"); w.Header().Get("Set-Cookie") != "" {
+		t.Fatalf("cookie must not be set unless requested, got %q", w.Header().Get("Set-Cookie"))
+	}
+
+	w := login("/identity/login?cookie=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	cookies := map[string]*http.Cookie{}
+	for _, ck := range w.Result().Cookies() {
+		cookies[ck.Name] = ck
+	}
+	ck := cookies["token"]
+	if ck == nil || ck.Value != "jwt123" {
+		t.Fatalf("expected token cookie, got %+v", ck)
+	}
+	if !ck.HttpOnly || !ck.Secure || ck.SameSite != http.SameSiteStrictMode || ck.MaxAge != 900 || ck.Path != "/" {
+		t.Fatalf("unexpected cookie attributes %+v", ck)
+	}
+	csrf := cookies["csrf_token"]
+	if csrf == nil || csrf.Value == "" || csrf.HttpOnly || !csrf.Secure {
+		t.Fatalf("expected a JS-readable csrf cookie, got %+v", csrf)
+	}
+	var resp LoginResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Token != "jwt123" {
+		t.Fatalf("token should still be returned in the body, got %+v err=%v", resp, err)
+	}
+}
+
+func TestVerificationStatuses_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const u1, u2 = "7d0c6a1e-4a5b-4f7e-9a51-1f0f3c2b9a01", "7d0c6a1e-4a5b-4f7e-9a51-1f0f3c2b9a02"
+	svc := &stubIdentityService{statusesResp: []identity.VerificationStatus{
+		{UserID: u1, IsVerified: true, Status: identity.UserStatusActive},
+	}}
+	h := NewIdentityHandler(svc)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/identity/users/verification-status", strings.NewReader(`{"user_ids":["`+u1+`","`+u2+`"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.VerificationStatuses(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(svc.statusesInput) != 2 {
+		t.Fatalf("service received wrong ids %v", svc.statusesInput)
+	}
+	if strings.Contains(w.Body.String(), "password") {
+		t.Fatalf("response must not expose password data: %s", w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"user_id":"`+u1+`","is_verified":true,"status":"active"`) {
+		t.Fatalf("unexpected body %s", w.Body.String())
+	}
+}
+
+func TestVerificationStatuses_Rejects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{name: "empty list", body: `{"user_ids":[]}`},
+		{name: "invalid uuid", body: `{"user_ids":["not-a-uuid"]}`},
+		{name: "over cap", body: `{"user_ids":["7d0c6a1e-4a5b-4f7e-9a51-1f0f3c2b9a01"]}`, err: identity.ErrTooManyUserIDs},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewIdentityHandler(&stubIdentityService{statusesErr: tc.err})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/identity/users/verification-status", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.VerificationStatuses(c)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -32,14 +32,48 @@ func VerifyUserDoc() {}
 
 // LoginDoc godoc
 // @Summary Login user
+// @Description Acepta email o identifier (email o username si USERNAME_LOGIN esta activo)
+// @Param cookie query bool false "Ademas del body, setea el JWT en una cookie HttpOnly"
 // @Tags Identity
 // @Accept json
 // @Produce json
 // @Param body body LoginRequest true "Login payload"
 // @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Router /identity/login [post]
 func LoginDoc() {}
 
+// RefreshDoc godoc
+// @Summary Refresh access token
+// @Tags Identity
+// @Accept json
+// @Produce json
+// @Param body body RefreshRequest true "Refresh token payload"
+// @Success 200 {object} LoginResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /identity/refresh [post]
+func RefreshDoc() {}
+
+// ListSessionsDoc godoc
+// @Summary List active sessions of the current user
+// @Tags Identity
+// @Produce json
+// @Success 200 {array} SessionResponse
+// @Security BearerAuth
+// @Router /identity/users/me/sessions [get]
+func ListSessionsDoc() {}
+
+// RevokeSessionDoc godoc
+// @Summary Revoke a session of the current user
+// @Tags Identity
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /identity/users/me/sessions/{id} [delete]
+func RevokeSessionDoc() {}
+
 // UpdateUserDoc godoc
 // @Summary Update user profile (no role change)
 // @Tags Identity
@@ -74,3 +108,15 @@ func UpdateUserRoleDoc() {}
 // @Security BearerAuth
 // @Router /identity/users/{id}/block [post]
 func BlockUserDoc() {}
+
+// VerificationStatusesDoc godoc
+// @Summary Batch verification status (admin)
+// @Tags Identity
+// @Accept json
+// @Produce json
+// @Param body body VerificationStatusRequest true "User ids (max 100)"
+// @Success 200 {object} VerificationStatusListResponse
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /identity/users/verification-status [post]
+func VerificationStatusesDoc() {}
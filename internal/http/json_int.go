@@ -0,0 +1,57 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// int64AsStringKey marca en el contexto que price/stock se serializan como strings.
+const int64AsStringKey = "int64_as_string"
+
+// Int64AsStringMiddleware hace que las respuestas de producto envien price y stock
+// como strings: los enteros sobre 2^53 pierden precision en clientes JavaScript.
+func Int64AsStringMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(int64AsStringKey, true)
+		c.Next()
+	}
+}
+
+func int64AsString(c *gin.Context) bool {
+	return c.GetBool(int64AsStringKey)
+}
+
+// FlexInt64 es un int64 que en la entrada acepta tanto 42 como "42".
+type FlexInt64 int64
+
+// UnmarshalJSON admite numeros enteros, strings con un entero y null (sin cambios).
+func (n *FlexInt64) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	raw := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*n = FlexInt64(v)
+	return nil
+}
+
+// flexInt64Ptr convierte un campo opcional del DTO al tipo del dominio.
+func flexInt64Ptr(n *FlexInt64) *int64 {
+	if n == nil {
+		return nil
+	}
+	v := int64(*n)
+	return &v
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"catalog-api/internal/catalog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFlexInt64_AcceptsNumberAndString(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: `42`, want: 42},
+		{in: `"9007199254740993"`, want: 9007199254740993},
+		{in: `"-5"`, want: -5},
+		{in: `1.5`, wantErr: true},
+		{in: `"abc"`, wantErr: true},
+		{in: `""`, wantErr: true},
+	} {
+		var n FlexInt64
+		err := json.Unmarshal([]byte(tc.in), &n)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error, got %d", tc.in, n)
+			}
+			continue
+		}
+		if err != nil || int64(n) != tc.want {
+			t.Fatalf("%s: expected %d, got %d (%v)", tc.in, tc.want, n, err)
+		}
+	}
+}
+
+func TestCreateProduct_AcceptsStringEncodedIntegers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{createProductResp: catalog.Product{ID: "p1", Name: "Pen", Price: 9007199254740993, Stock: 2}}
+	h := NewCatalogHandler(svc, nil)
+	c, w := newJSONContext(`{"name":"Pen","price":"9007199254740993","stock":2}`, false)
+
+	h.CreateProduct(c)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	if in := svc.createProductInput; in.Price == nil || *in.Price != 9007199254740993 || in.Stock == nil || *in.Stock != 2 {
+		t.Fatalf("service received %+v", svc.createProductInput)
+	}
+	// sin el flag la salida sigue siendo numerica.
+	if !strings.Contains(w.Body.String(), `"price":9007199254740993`) {
+		t.Fatalf("expected numeric price, got %s", w.Body.String())
+	}
+}
+
+func TestUpdateProduct_StringEncodedNegativeIs400(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{}
+	h := NewCatalogHandler(svc, nil)
+	c, w := newJSONContext(`{"stock":"-1"}`, false)
+	c.Params = gin.Params{{Key: "id", Value: "p1"}}
+
+	h.UpdateProduct(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d (%s)", w.Code, w.Body.String())
+	}
+}
+
+func TestRouter_Int64AsStringFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{getProductResp: catalog.Product{ID: "p1", Name: "Pen", Price: 9007199254740993, Stock: 7}}
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil), Int64AsString: true}).Build()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products/p1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["price"] != "9007199254740993" || body["stock"] != "7" {
+		t.Fatalf("expected string price/stock, got %s", w.Body.String())
+	}
+}
+
+func TestPriceChangeResponse_Int64AsString(t *testing.T) {
+	req := catalog.PriceChangeRequest{ID: "r1", ProductID: "p1", OldPrice: 10, NewPrice: 9007199254740993, Status: catalog.PriceChangePending}
+
+	out, err := json.Marshal(PriceChangePendingResponse{
+		Product:            toProductResponse(catalog.Product{ID: "p1", Price: 10}, true),
+		PriceChangeRequest: toPriceChangeResponse(req, true),
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(out), `"old_price":"10"`) || !strings.Contains(string(out), `"new_price":"9007199254740993"`) {
+		t.Fatalf("expected string prices in the price change request, got %s", out)
+	}
+
+	out, _ = json.Marshal(toPriceChangeResponse(req, false))
+	if !strings.Contains(string(out), `"new_price":9007199254740993`) {
+		t.Fatalf("expected numeric prices without the flag, got %s", out)
+	}
+}
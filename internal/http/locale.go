@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"catalog-api/pkg/locale"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// LocaleMiddleware negocia el locale a partir de header (Accept-Language si esta vacio)
+// y lo guarda en el contexto del request; sin coincidencias usa fallback.
+func LocaleMiddleware(header, fallback string) gin.HandlerFunc {
+	if header == "" {
+		header = "Accept-Language"
+	}
+	if !locale.Supported(fallback) {
+		fallback = locale.Default
+	}
+	return func(c *gin.Context) {
+		tag := locale.Negotiate(c.GetHeader(header), fallback)
+		c.Request = c.Request.WithContext(locale.WithLocale(c.Request.Context(), tag))
+		c.Next()
+	}
+}
+
+// RequestLocale devuelve el locale negociado del request, o locale.Default si no hubo negociacion.
+func RequestLocale(c *gin.Context) string {
+	if c.Request != nil {
+		if tag := locale.FromContext(c.Request.Context()); tag != "" {
+			return tag
+		}
+	}
+	return locale.Default
+}
+
+// bindErrorMessage traduce un error de bindJSON al locale del request. En ingles se
+// conserva el mensaje original; obj es el destino del binding y aporta los nombres JSON.
+func bindErrorMessage(c *gin.Context, err error, obj any) string {
+	if RequestLocale(c) != locale.Spanish {
+		return err.Error()
+	}
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		msgs := make([]string, 0, len(verrs))
+		for _, fe := range verrs {
+			msgs = append(msgs, spanishFieldError(jsonFieldPath(reflect.TypeOf(obj), fe.StructNamespace()), fe))
+		}
+		return strings.Join(msgs, "; ")
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr):
+		return fmt.Sprintf("el campo %s tiene un tipo invalido", typeErr.Field)
+	case errors.As(err, &syntaxErr), err.Error() == "EOF", err.Error() == "unexpected EOF":
+		return "el cuerpo no es un JSON valido"
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "unknown field "); ok {
+		return "campo desconocido " + field
+	}
+	return "solicitud invalida"
+}
+
+func spanishFieldError(field string, fe validator.FieldError) string {
+	unit := "caracteres"
+	switch fe.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = "elementos"
+	}
+	numeric := fe.Kind() >= reflect.Int && fe.Kind() <= reflect.Float64
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("el campo %s es obligatorio", field)
+	case "email":
+		return fmt.Sprintf("el campo %s debe ser un email valido", field)
+	case "url":
+		return fmt.Sprintf("el campo %s debe ser una URL valida", field)
+	case "oneof":
+		return fmt.Sprintf("el campo %s debe ser uno de: %s", field, fe.Param())
+	case "min":
+		if numeric {
+			return fmt.Sprintf("el campo %s debe ser mayor o igual a %s", field, fe.Param())
+		}
+		return fmt.Sprintf("el campo %s debe tener al menos %s %s", field, fe.Param(), unit)
+	case "max":
+		if numeric {
+			return fmt.Sprintf("el campo %s debe ser menor o igual a %s", field, fe.Param())
+		}
+		return fmt.Sprintf("el campo %s admite como maximo %s %s", field, fe.Param(), unit)
+	case "len":
+		return fmt.Sprintf("el campo %s debe tener exactamente %s %s", field, fe.Param(), unit)
+	}
+	return fmt.Sprintf("el campo %s no es valido", field)
+}
+
+// jsonFieldPath convierte "CreateProductRequest.Categories[0].Name" en "categories[0].name"
+// usando los tags json del tipo destino.
+func jsonFieldPath(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		name, index := part, ""
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name, index = part[:i], part[i:]
+		}
+		for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			out = append(out, part)
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			out = append(out, part)
+			t = nil
+			continue
+		}
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+			name = tag
+		}
+		out = append(out, name+index)
+		t = field.Type
+	}
+	return strings.Join(out, ".")
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"catalog-api/pkg/locale"
+
+	"github.com/gin-gonic/gin"
+)
+
+func postCreateProduct(router *gin.Engine, header, value, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if value != "" {
+		req.Header.Set(header, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLocale_SpanishValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(&stubCatalogService{}, nil)}).Build()
+
+	w := postCreateProduct(router, "Accept-Language", "es-AR,es;q=0.9,en;q=0.5", `{"price":10,"stock":1,"currency":"us"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	for _, want := range []string{"el campo name es obligatorio", "el campo currency debe tener exactamente 3 caracteres"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("expected %q in %s", want, w.Body.String())
+		}
+	}
+
+	w = postCreateProduct(router, "Accept-Language", "en;q=0.8, es;q=0.2", `{"price":10,"stock":1}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "failed on the 'required' tag") {
+		t.Fatalf("expected original english message, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = postCreateProduct(router, "Accept-Language", "es", `{"name":`)
+	if !strings.Contains(w.Body.String(), "el cuerpo no es un JSON valido") {
+		t.Fatalf("expected spanish syntax error, got %s", w.Body.String())
+	}
+}
+
+func TestLocale_TrustedHeaderAndDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{
+		CatalogHandler: NewCatalogHandler(&stubCatalogService{}, nil),
+		LocaleHeader:   "X-Locale",
+		DefaultLocale:  locale.Spanish,
+	}).Build()
+
+	// sin header se usa el default configurado.
+	w := postCreateProduct(router, "", "", `{"price":10,"stock":1}`)
+	if !strings.Contains(w.Body.String(), "el campo name es obligatorio") {
+		t.Fatalf("expected default spanish message, got %s", w.Body.String())
+	}
+	w = postCreateProduct(router, "X-Locale", "en", `{"price":10,"stock":1}`)
+	if strings.Contains(w.Body.String(), "el campo") {
+		t.Fatalf("expected english message from trusted header, got %s", w.Body.String())
+	}
+}
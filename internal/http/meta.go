@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+
+	"catalog-api/internal/catalog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductsMetaDoc godoc
+// @Summary Product query metadata
+// @Description sort/order, q y highlight aplican a /search?type=product; stock y min_price/max_price filtran ambos, category_id solo GET /products.
+// @Tags Products
+// @Produce json
+// @Success 200 {object} QueryMetaResponse
+// @Router /products/meta [get]
+func ProductsMeta(c *gin.Context) {
+	c.JSON(http.StatusOK, productsMeta())
+}
+
+// CategoriesMetaDoc godoc
+// @Summary Category query metadata
+// @Description sort/order y q aplican a /search?type=category.
+// @Tags Catalog
+// @Produce json
+// @Success 200 {object} QueryMetaResponse
+// @Router /categories/meta [get]
+func CategoriesMeta(c *gin.Context) {
+	c.JSON(http.StatusOK, categoriesMeta())
+}
+
+// productsMeta se arma con las mismas constantes que usan servicio y repositorios.
+func productsMeta() QueryMetaResponse {
+	stock := make([]string, 0, len(catalog.StockFilters()))
+	for _, f := range catalog.StockFilters() {
+		stock = append(stock, string(f))
+	}
+	return QueryMetaResponse{
+		SearchKind:   catalog.SearchKindProduct,
+		SortFields:   catalog.ProductSortFields(),
+		SortOrders:   []string{catalog.SortAsc, catalog.SortDesc},
+		DefaultSort:  catalog.DefaultProductSort,
+		DefaultOrder: catalog.DefaultProductSortDir,
+		Filters: []QueryFilterMeta{
+			{Param: "q"},
+			{Param: "stock", Values: stock},
+			{Param: "category_id"},
+			{Param: "min_price"},
+			{Param: "max_price"},
+			{Param: "highlight", Values: []string{"true", "false"}},
+		},
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+}
+
+func categoriesMeta() QueryMetaResponse {
+	return QueryMetaResponse{
+		SearchKind:   catalog.SearchKindCategory,
+		SortFields:   catalog.CategorySortFields(),
+		SortOrders:   []string{catalog.SortAsc, catalog.SortDesc},
+		DefaultSort:  catalog.DefaultCategorySort,
+		DefaultOrder: catalog.DefaultCategorySortDir,
+		Filters:      []QueryFilterMeta{{Param: "q"}},
+		DefaultLimit: defaultPageLimit,
+		MaxLimit:     maxPageLimit,
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRouter_QueryMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(&stubCatalogService{}, nil), UUIDParams: true}).Build()
+
+	cases := []struct {
+		path        string
+		kind        string
+		sortFields  []string
+		defaultSort string
+		filters     []string
+	}{
+		{"/api/v1/products/meta", "product", []string{"created_at", "name", "price", "stock", "relevance"}, "created_at", []string{"q", "stock", "category_id", "min_price", "max_price", "highlight"}},
+		{"/api/v1/categories/meta", "category", []string{"name", "created_at"}, "name", []string{"q"}},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tc.path, w.Code, w.Body.String())
+		}
+		var meta QueryMetaResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &meta); err != nil {
+			t.Fatalf("%s: decode: %v", tc.path, err)
+		}
+		if meta.SearchKind != tc.kind || meta.DefaultSort != tc.defaultSort {
+			t.Fatalf("%s: unexpected kind/default %+v", tc.path, meta)
+		}
+		if !slices.Equal(meta.SortFields, tc.sortFields) {
+			t.Fatalf("%s: expected sort fields %v, got %v", tc.path, tc.sortFields, meta.SortFields)
+		}
+		var params []string
+		for _, f := range meta.Filters {
+			params = append(params, f.Param)
+		}
+		if !slices.Equal(params, tc.filters) {
+			t.Fatalf("%s: expected filters %v, got %v", tc.path, tc.filters, params)
+		}
+		if meta.DefaultLimit != defaultPageLimit || meta.MaxLimit != maxPageLimit {
+			t.Fatalf("%s: unexpected limits %+v", tc.path, meta)
+		}
+	}
+
+	stock := productsMeta().Filters[1]
+	if !slices.Equal(stock.Values, []string{"out", "in", "low"}) {
+		t.Fatalf("unexpected stock values %v", stock.Values)
+	}
+}
@@ -1,7 +1,13 @@
 package http
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -10,6 +16,13 @@ import (
 	"golang.org/x/time/rate"
 )
 
+// Mensajes de los 401/429; el code estable lo agrega abortError.
+var (
+	errMissingToken    = errors.New("missing bearer token")
+	errInvalidToken    = errors.New("invalid or expired token")
+	errTooManyRequests = errors.New("too many requests")
+)
+
 // TokenValidator valida tokens de auth y devuelve un contexto de auth.
 type TokenValidator interface {
 	Validate(token string) (AuthContext, error)
@@ -26,17 +39,38 @@ func AuthMiddleware(validator TokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		raw := bearerTokenFromHeader(c.Request)
 		if raw == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			// clientes de navegador envian el JWT en la cookie HttpOnly del login.
+			raw, _ = c.Cookie(authCookieName)
+		}
+		if raw == "" {
+			abortError(c, http.StatusUnauthorized, codeUnauthorized, errMissingToken, nil)
 			return
 		}
 		ctx, err := validator.Validate(raw)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			// el detalle del parser no se expone: solo ayudaria a sondear los tokens.
+			abortError(c, http.StatusUnauthorized, codeUnauthorized, errInvalidToken, nil)
 			return
 		}
 		// propagar identidad hacia los handlers.
-		c.Set("user_id", ctx.UserID)
-		c.Set("role", ctx.Role)
+		setAuth(c, ctx)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware identifica al llamador en rutas publicas: con un token valido
+// deja su identidad en el contexto; sin token o con uno invalido sigue como anonimo.
+func OptionalAuthMiddleware(validator TokenValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := bearerTokenFromHeader(c.Request)
+		if raw == "" {
+			raw, _ = c.Cookie(authCookieName)
+		}
+		if raw != "" {
+			if auth, err := validator.Validate(raw); err == nil {
+				setAuth(c, auth)
+			}
+		}
 		c.Next()
 	}
 }
@@ -61,14 +95,13 @@ func RoleMiddleware(roles ...string) gin.HandlerFunc {
 		roleSet[r] = struct{}{}
 	}
 	return func(c *gin.Context) {
-		roleVal, ok := c.Get("role")
+		role, ok := AuthRole(c)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing role"})
+			abortError(c, http.StatusForbidden, codeForbidden, errors.New("missing role"), nil)
 			return
 		}
-		roleStr, _ := roleVal.(string)
-		if _, allowed := roleSet[roleStr]; !allowed {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		if _, allowed := roleSet[role]; !allowed {
+			abortError(c, http.StatusForbidden, codeForbidden, errors.New("forbidden"), nil)
 			return
 		}
 		c.Next()
@@ -83,7 +116,71 @@ func RateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
 			ip = "unknown"
 		}
 		if !limiter.Allow(ip) {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			abortError(c, http.StatusTooManyRequests, codeRateLimited, errTooManyRequests, nil)
+			return
+		}
+		c.Next()
+	}
+}
+
+// maxRegistrationBody acota lo que EmailRateLimitMiddleware lee antes de autenticar.
+const maxRegistrationBody = 64 << 10
+
+// EmailRateLimitMiddleware limita por el campo "email" del body JSON, sin importar la IP.
+// Restaura el body para el handler; si no hay email deja que la validacion responda.
+func EmailRateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRegistrationBody))
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortError(c, http.StatusRequestEntityTooLarge, codePayloadTooLarge, errors.New("request body too large"), nil)
+				return
+			}
+			c.Next()
+			return
+		}
+		var payload struct {
+			Email string `json:"email"`
+		}
+		if json.Unmarshal(body, &payload) != nil {
+			c.Next()
+			return
+		}
+		email := strings.ToLower(strings.TrimSpace(payload.Email))
+		if email != "" && !limiter.Allow(email) {
+			abortError(c, http.StatusTooManyRequests, codeRateLimited, errTooManyRequests, nil)
+			return
+		}
+		c.Next()
+	}
+}
+
+// GlobalRateLimitMiddleware es una valvula de carga para todo el servidor, independiente
+// de la IP: al superar el limite responde 503 con Retry-After. /healthz no consume tokens
+// para que el balanceador no saque la instancia justo cuando esta bajo carga.
+func GlobalRateLimitMiddleware(limiter *rate.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.URL.Path == "/healthz" {
+			c.Next()
+			return
+		}
+		r := limiter.Reserve()
+		if !r.OK() || r.Delay() > 0 {
+			// la reserva se devuelve: el request rechazado no debe consumir un token futuro.
+			retryAfter := 1
+			if r.OK() {
+				retryAfter = max(1, int(math.Ceil(r.Delay().Seconds())))
+				r.Cancel()
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			abortError(c, http.StatusServiceUnavailable, codeOverloaded, errors.New("server is overloaded, retry later"), nil)
 			return
 		}
 		c.Next()
@@ -100,18 +197,40 @@ type IPRateLimiter struct {
 	cleanupInterval time.Duration
 }
 
-func NewIPRateLimiter(limit rate.Limit, burst int) *IPRateLimiter {
+// defaultLimiterTTL es cuanto se conserva una clave sin uso si su rafaga se repone antes.
+const defaultLimiterTTL = 15 * time.Minute
+
+// NewIPRateLimiter construye el limitador; ttl es cuanto se conserva una clave sin uso
+// (<= 0 usa 15 minutos). Debe cubrir burst/limit: una clave desalojada antes vuelve con
+// la rafaga llena y el limite deja de cumplirse.
+func NewIPRateLimiter(limit rate.Limit, burst int, ttl time.Duration) *IPRateLimiter {
+	if ttl <= 0 {
+		ttl = defaultLimiterTTL
+	}
 	l := &IPRateLimiter{
 		limit:           limit,
 		burst:           burst,
 		clients:         make(map[string]*clientLimiter),
-		ttl:             15 * time.Minute,
+		ttl:             ttl,
 		cleanupInterval: 5 * time.Minute,
 	}
 	go l.runCleanup()
 	return l
 }
 
+// limiterTTL devuelve el ttl por defecto o, si es mayor, lo que tarda en reponerse la
+// rafaga completa, para que el desalojo no regale tokens.
+func limiterTTL(limit rate.Limit, burst int) time.Duration {
+	if limit <= 0 || limit == rate.Inf {
+		return defaultLimiterTTL
+	}
+	refill := time.Duration(float64(burst) / float64(limit) * float64(time.Second))
+	if refill > defaultLimiterTTL {
+		return refill
+	}
+	return defaultLimiterTTL
+}
+
 type clientLimiter struct {
 	limiter *rate.Limiter
 	lastUse time.Time
@@ -136,15 +255,19 @@ func (l *IPRateLimiter) Allow(key string) bool {
 func (l *IPRateLimiter) runCleanup() {
 	ticker := time.NewTicker(l.cleanupInterval)
 	for range ticker.C {
-		now := time.Now()
-		cutoff := now.Add(-l.ttl)
-		l.mu.Lock()
-		for key, cl := range l.clients {
-			if cl.lastUse.Before(cutoff) {
-				delete(l.clients, key)
-			}
+		l.evict(time.Now())
+	}
+}
+
+// evict descarta las claves sin uso desde hace mas de ttl.
+func (l *IPRateLimiter) evict(now time.Time) {
+	cutoff := now.Add(-l.ttl)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, cl := range l.clients {
+		if cl.lastUse.Before(cutoff) {
+			delete(l.clients, key)
 		}
-		l.mu.Unlock()
 	}
 }
 
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"catalog-api/internal/catalog"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = catalog.DefaultPageLimit
+	maxPageLimit     = catalog.MaxPageLimit
+)
+
+var (
+	errInvalidOffset = errors.New("offset must be a non-negative integer")
+	// errOffsetTooLarge tiene codigo propio para que el cliente distinga el tope de un offset mal formado.
+	errOffsetTooLarge = errors.New("offset too large")
+)
+
+// maxOffsetKey guarda en el contexto el offset maximo de MaxOffsetMiddleware.
+const maxOffsetKey = "max_page_offset"
+
+// MaxOffsetMiddleware hace que parsePagination rechace offsets mayores a max: un
+// OFFSET grande obliga a Postgres a leer y descartar todas esas filas.
+func MaxOffsetMiddleware(max int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(maxOffsetKey, max)
+		c.Next()
+	}
+}
+
+// parsePagination lee limit/offset del query string.
+// limit se acota a [1, maxPageLimit] y, si no es numerico, cae a defaultLimit para
+// mantener el comportamiento historico; un offset no numerico es un error de cliente.
+// defaultLimit 0 significa "sin limite" cuando el parametro no viene.
+func parsePagination(c *gin.Context, defaultLimit int) (limit, offset int, err error) {
+	limit = defaultLimit
+	if raw, ok := c.GetQuery("limit"); ok {
+		if parsed, convErr := strconv.Atoi(raw); convErr == nil {
+			limit = clampLimit(parsed)
+		}
+	}
+	if raw, ok := c.GetQuery("offset"); ok {
+		parsed, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			return 0, 0, errInvalidOffset
+		}
+		if parsed > 0 {
+			offset = parsed
+		}
+	}
+	if max := c.GetInt(maxOffsetKey); max > 0 && offset > max {
+		return 0, 0, fmt.Errorf("%w: maximum is %d; narrow the results with the endpoint's filters instead of paging deeper", errOffsetTooLarge, max)
+	}
+	return limit, offset, nil
+}
+
+func clampLimit(limit int) int {
+	if limit < 1 {
+		return 1
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
+// newPageMeta calcula has_more y next_offset a partir de los items devueltos.
+func newPageMeta(total int64, limit, offset, count int) PageMeta {
+	meta := PageMeta{Total: &total, Limit: limit, Offset: offset}
+	if next := offset + count; int64(next) < total {
+		meta.HasMore = true
+		meta.NextOffset = &next
+	}
+	return meta
+}
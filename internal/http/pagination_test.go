@@ -0,0 +1,100 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func paginationContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/products"+query, nil)
+	return c
+}
+
+func TestParsePagination_ClampsValues(t *testing.T) {
+	cases := []struct {
+		query      string
+		wantLimit  int
+		wantOffset int
+	}{
+		{"", defaultPageLimit, 0},
+		{"?limit=10&offset=5", 10, 5},
+		{"?limit=0", 1, 0},
+		{"?limit=-3", 1, 0},
+		{"?limit=5000", maxPageLimit, 0},
+		{"?offset=-7", defaultPageLimit, 0},
+		{"?limit=abc", defaultPageLimit, 0},
+	}
+	for _, tc := range cases {
+		limit, offset, err := parsePagination(paginationContext(tc.query), defaultPageLimit)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.query, err)
+		}
+		if limit != tc.wantLimit || offset != tc.wantOffset {
+			t.Fatalf("%q: expected limit=%d offset=%d, got limit=%d offset=%d", tc.query, tc.wantLimit, tc.wantOffset, limit, offset)
+		}
+	}
+}
+
+func TestParsePagination_RejectsNonNumericOffset(t *testing.T) {
+	if _, _, err := parsePagination(paginationContext("?offset=abc"), defaultPageLimit); err == nil {
+		t.Fatalf("expected error for non-numeric offset")
+	}
+}
+
+func TestListProducts_InvalidOffsetReturnsBadRequest(t *testing.T) {
+	h := NewCatalogHandler(&stubCatalogService{}, nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/products?offset=abc", nil)
+
+	h.ListProducts(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestParsePagination_MaxOffset(t *testing.T) {
+	c := paginationContext("?offset=500")
+	c.Set(maxOffsetKey, 500)
+	if _, offset, err := parsePagination(c, defaultPageLimit); err != nil || offset != 500 {
+		t.Fatalf("expected the maximum itself to be accepted, got offset=%d err=%v", offset, err)
+	}
+
+	c = paginationContext("?offset=501")
+	c.Set(maxOffsetKey, 500)
+	if _, _, err := parsePagination(c, defaultPageLimit); !errors.Is(err, errOffsetTooLarge) {
+		t.Fatalf("expected errOffsetTooLarge, got %v", err)
+	}
+}
+
+func TestRouter_OversizedOffsetReturnsBadRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{
+		CatalogHandler: NewCatalogHandler(&stubCatalogService{}, nil),
+		MaxPageOffset:  1000,
+	}).Build()
+
+	for _, path := range []string{"/api/v1/products", "/api/v1/categories", "/api/v1/search?q=pen"} {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path+sep+"offset=1000000", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", path, w.Code, w.Body.String())
+		}
+		var body ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != "offset_too_large" || !strings.Contains(body.Message, "filters") {
+			t.Fatalf("%s: expected offset_too_large with a hint, got %s", path, w.Body.String())
+		}
+	}
+}
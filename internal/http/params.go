@@ -0,0 +1,61 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidID indica un parametro de ruta que no tiene formato de UUID.
+var ErrInvalidID = errors.New("invalid id")
+
+// UUIDParamsMiddleware rechaza con 400 los parametros de ruta indicados que no son
+// UUID, antes de llegar al servicio y de que Postgres falle al castearlos.
+func UUIDParamsMiddleware(names ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, name := range names {
+			if v, ok := c.Params.Get(name); ok && !isUUID(v) {
+				abortError(c, http.StatusBadRequest, codeInvalidID, ErrInvalidID, gin.H{"param": name})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// UUIDQueryMiddleware es UUIDParamsMiddleware para filtros de query (p.ej. ?category_id=):
+// un valor presente que no es UUID responde 400 en lugar de fallar en Postgres.
+func UUIDQueryMiddleware(names ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, name := range names {
+			if v := strings.TrimSpace(c.Query(name)); v != "" && !isUUID(v) {
+				abortError(c, http.StatusBadRequest, codeInvalidID, ErrInvalidID, gin.H{"param": name})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// isUUID valida la forma canonica 8-4-4-4-12 en hexadecimal, sin exigir version.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			ch := s[i]
+			if !(ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
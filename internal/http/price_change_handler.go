@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"catalog-api/internal/catalog"
+	"catalog-api/internal/ws"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListPriceChanges godoc
+// @Summary List price change requests
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Status filter" Enums(pending, approved, rejected)
+// @Success 200 {array} PriceChangeResponse
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/price-changes [get]
+func (h *CatalogHandler) ListPriceChanges(c *gin.Context) {
+	status := catalog.PriceChangeStatus(strings.ToLower(strings.TrimSpace(c.Query("status"))))
+	items, err := h.svc.ListPriceChangeRequests(c.Request.Context(), status)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	resp := make([]PriceChangeResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toPriceChangeResponse(item, int64AsString(c)))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ApprovePriceChange godoc
+// @Summary Approve a price change request
+// @Description Aplica el precio pedido y registra el historial; una solicitud ya resuelta, o cuyo precio anterior ya no es el vigente (price_change_stale), responde 409.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Price change request ID"
+// @Success 200 {object} PriceChangeDecisionResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/price-changes/{id}/approve [post]
+func (h *CatalogHandler) ApprovePriceChange(c *gin.Context) {
+	req, product, err := h.svc.ApprovePriceChange(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	productResp := toProductResponse(product, int64AsString(c))
+	h.emit(c, ws.EventProductUpdated, productResp)
+	c.JSON(http.StatusOK, PriceChangeDecisionResponse{
+		PriceChangeRequest: toPriceChangeResponse(req, int64AsString(c)),
+		Product:            &productResp,
+	})
+}
+
+// RejectPriceChange godoc
+// @Summary Reject a price change request
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Price change request ID"
+// @Success 200 {object} PriceChangeDecisionResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/price-changes/{id}/reject [post]
+func (h *CatalogHandler) RejectPriceChange(c *gin.Context) {
+	req, err := h.svc.RejectPriceChange(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, PriceChangeDecisionResponse{PriceChangeRequest: toPriceChangeResponse(req, int64AsString(c))})
+}
+
+func toPriceChangeResponse(r catalog.PriceChangeRequest, int64AsString bool) PriceChangeResponse {
+	resp := PriceChangeResponse{
+		ID:            r.ID,
+		ProductID:     r.ProductID,
+		OldPrice:      r.OldPrice,
+		NewPrice:      r.NewPrice,
+		Status:        string(r.Status),
+		CreatedAt:     r.CreatedAt.Format(time.RFC3339),
+		int64AsString: int64AsString,
+	}
+	if !r.DecidedAt.IsZero() {
+		resp.DecidedAt = r.DecidedAt.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidPriceParam = errors.New("min_price and max_price must be integers")
+
+// priceRange lee ?min_price= y ?max_price=; un parametro ausente queda en nil. Que el
+// rango sea coherente (min <= max) lo valida el servicio.
+func priceRange(c *gin.Context) (minPrice, maxPrice *int64, err error) {
+	if minPrice, err = optionalInt64Query(c, "min_price"); err != nil {
+		return nil, nil, err
+	}
+	if maxPrice, err = optionalInt64Query(c, "max_price"); err != nil {
+		return nil, nil, err
+	}
+	return minPrice, maxPrice, nil
+}
+
+func optionalInt64Query(c *gin.Context, key string) (*int64, error) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, errInvalidPriceParam
+	}
+	return &v, nil
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryMiddleware convierte un panic en un 500 con el mismo sobre JSON que el
+// resto de errores. El stack va al log; el cliente recibe un mensaje generico salvo
+// con DebugErrorsMiddleware, que agrega el panic y el stack en "details".
+func RecoveryMiddleware(logr *slog.Logger) gin.HandlerFunc {
+	if logr == nil {
+		logr = slog.Default()
+	}
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// net/http usa este panic para cortar la respuesta a proposito.
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+			stack := string(debug.Stack())
+			logr.Error("panic recovered",
+				slog.String("panic", fmt.Sprint(rec)),
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.String("request_id", c.GetString("request_id")),
+				slog.String("stack", stack),
+			)
+			if c.Writer.Written() {
+				// ya salieron headers; solo queda cortar la cadena.
+				c.Abort()
+				return
+			}
+			body := internalErrorBody(c, nil)
+			if debugErrors(c) {
+				body.Details = gin.H{"error": fmt.Sprint(rec), "stack": stack}
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, body)
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryMiddleware_ReturnsJSON500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logs bytes.Buffer
+	logr := slog.New(slog.NewJSONHandler(&logs, nil))
+	r := gin.New()
+	r.Use(AccessLogMiddleware(logr, 0), RecoveryMiddleware(logr))
+	r.GET("/boom", func(c *gin.Context) {
+		panic("secret internal detail")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+	req.Header.Set(requestIDHeader, "req-9")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("expected JSON content type, got %q", ct)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] != "internal server error" {
+		t.Fatalf("expected standard error envelope, got %q (%v)", w.Body.String(), err)
+	}
+	if strings.Contains(w.Body.String(), "secret") || strings.Contains(w.Body.String(), "goroutine") {
+		t.Fatalf("response leaked panic details: %s", w.Body.String())
+	}
+	if !strings.Contains(logs.String(), `"msg":"panic recovered"`) || !strings.Contains(logs.String(), `"request_id":"req-9"`) {
+		t.Fatalf("expected panic log with request id, got %s", logs.String())
+	}
+}
+
+func TestRecoveryMiddleware_DebugErrorsIncludesDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logr := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	r := gin.New()
+	r.Use(DebugErrorsMiddleware(), RecoveryMiddleware(logr))
+	r.GET("/boom", func(c *gin.Context) {
+		panic("secret internal detail")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	var body struct {
+		Error   string            `json:"error"`
+		Details map[string]string `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if w.Code != http.StatusInternalServerError || body.Details["error"] != "secret internal detail" {
+		t.Fatalf("expected panic detail in response, got %d %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(body.Details["stack"], "goroutine") {
+		t.Fatalf("expected stack in details, got %q", body.Details["stack"])
+	}
+}
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"log/slog"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -13,82 +15,197 @@ import (
 	"golang.org/x/time/rate"
 )
 
+// Limite por IP de /identity cuando la config no lo define: 5 por minuto, rafaga de 5.
+const (
+	DefaultLoginRatePerMin = 5
+	DefaultLoginBurst      = 5
+)
+
+// Limite por email de las altas cuando la config no lo define: 12 por hora, rafaga de 3.
+const (
+	DefaultRegistrationRatePerHour = 12
+	DefaultRegistrationBurst       = 3
+)
+
 // RouterFactory agrupa los handlers necesarios para construir el router HTTP.
 type RouterFactory struct {
 	IdentityHandler *IdentityHandler
 	WSHub           *ws.Hub
 	TokenValidator  TokenValidator
 	CatalogHandler  *CatalogHandler
+	WebhookHandler  *WebhookHandler
+	StatsHandler    *StatsHandler
+	SMTPTestHandler *SMTPTestHandler
+	// WSAllowAnonymous acepta conexiones /ws sin token (solo eventos publicos).
+	// Pensado para despliegues internos; sin validador y sin este flag /ws no se registra.
+	WSAllowAnonymous bool
+	// StrictJSON rechaza con 400 los campos desconocidos en altas y ediciones.
+	StrictJSON bool
+	// UUIDParams responde 400 si :id, :categoryId o ?category_id no son UUID; sin el flag el id es libre
+	// y un formato invalido termina en 404 desde el repositorio.
+	UUIDParams bool
+	// Schemas valida altas y ediciones del catalogo contra JSON Schema; nil deshabilita.
+	Schemas *SchemaValidator
+	// Int64AsString envia price y stock como strings en las respuestas de productos.
+	Int64AsString bool
+	// Compression aplica gzip a respuestas JSON/texto de al menos CompressionMinSize bytes.
+	Compression        bool
+	CompressionMinSize int
+	// CacheControl mapea rutas GET (p.ej. /api/v1/categories) a su directiva Cache-Control.
+	CacheControl map[string]string
+	// LocaleHeader es el header confiable del que se negocia el locale (Accept-Language
+	// si esta vacio); DefaultLocale se usa cuando no hay coincidencias.
+	LocaleHeader  string
+	DefaultLocale string
+	// GlobalRateLimit acota las peticiones por segundo de todo el servidor (sin importar
+	// la IP) con rafagas de GlobalRateBurst; 0 deshabilita.
+	GlobalRateLimit float64
+	GlobalRateBurst int
+	// LoginRatePerMin y LoginBurst limitan por IP las rutas /identity; 0 usa 5 y 5.
+	LoginRatePerMin float64
+	LoginBurst      int
+	// RegistrationRatePerHour y RegistrationBurst limitan las altas por email; 0 usa 12 y 3.
+	RegistrationRatePerHour float64
+	RegistrationBurst       int
+	// Storefront oculta los productos agotados en GET /products y /search salvo que se
+	// pida ?stock= o el llamador sea admin. Los borradores se ocultan a no-admins en
+	// todas las lecturas publicas de productos, con o sin Storefront.
+	Storefront bool
+	// DebugErrors agrega el error original en "details" de los 500. Nunca en produccion.
+	DebugErrors bool
+	// CORS habilita CORSMiddleware cuando lista algun origen.
+	CORS CORSConfig
+	// MaxPageOffset rechaza con 400 los listados con ?offset= mayor; 0 no limita.
+	MaxPageOffset int
+	// AllowedContentTypes son los media types aceptados en bodies de /api/v1; vacio
+	// acepta solo application/json. El resto responde 415.
+	AllowedContentTypes []string
+	// SlowRequestThreshold marca con warn las peticiones mas lentas; 0 deshabilita.
+	SlowRequestThreshold time.Duration
+	Logr                 *slog.Logger
 }
 
 // Build cablea todas las rutas HTTP para REST y WebSocket.
 func (f *RouterFactory) Build() *gin.Engine {
-	router := gin.Default()
-	router.Use(SecurityHeadersMiddleware())
+	router := gin.New()
+	if f.Logr != nil {
+		// con logger estructurado el access log sale en JSON en lugar del formato de gin.
+		router.Use(AccessLogMiddleware(f.Logr, f.SlowRequestThreshold))
+	} else {
+		router.Use(gin.Logger())
+	}
+	if f.DebugErrors {
+		router.Use(DebugErrorsMiddleware())
+	}
+	// recovery va despues del access log para que el 500 y el request id queden registrados.
+	router.Use(RecoveryMiddleware(f.Logr))
+	if len(f.CORS.AllowedOrigins) > 0 {
+		// antes de los limites y de CSRF: un preflight se responde aca y no llega a las rutas.
+		router.Use(CORSMiddleware(f.CORS))
+	}
+	if f.GlobalRateLimit > 0 {
+		burst := f.GlobalRateBurst
+		if burst <= 0 {
+			burst = int(math.Ceil(f.GlobalRateLimit))
+		}
+		router.Use(GlobalRateLimitMiddleware(rate.NewLimiter(rate.Limit(f.GlobalRateLimit), burst)))
+	}
+	// Sin redirects automaticos: un 301 entre /products y /products/ puede perder el
+	// header Authorization en algunos clientes. La ruta canonica es sin barra final y
+	// la variante con barra responde 404.
+	router.RedirectTrailingSlash = false
+	router.RedirectFixedPath = false
+	// una ruta existente con metodo equivocado responde 405 + Allow en vez de 404.
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(MethodNotAllowedHandler)
+	// /docs/*any es una ruta registrada, asi que el 404 JSON no tapa la UI de swagger.
+	router.NoRoute(NotFoundHandler)
+	router.Use(SecurityHeadersMiddleware(), CacheControlMiddleware(f.CacheControl), LocaleMiddleware(f.LocaleHeader, f.DefaultLocale))
+	if f.Compression {
+		router.Use(CompressionMiddleware(f.CompressionMinSize))
+	}
 
 	router.GET("/healthz", func(c *gin.Context) {
 		c.Status(http.StatusOK)
 	})
 
-	if f.WSHub != nil {
-		router.GET("/ws", func(c *gin.Context) {
-			if f.TokenValidator == nil {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing token validator"})
-				return
-			}
-			token := websocketToken(c)
-			if token == "" {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
-				return
-			}
-			if _, err := f.TokenValidator.Validate(token); err != nil {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-				return
-			}
-			f.WSHub.ServeHTTP(c.Writer, c.Request)
-		})
-	}
+	f.registerWebsocket(router)
 
 	api := router.Group("/api/v1")
+	api.Use(CSRFMiddleware(), ContentTypeMiddleware(f.AllowedContentTypes))
+	if f.StrictJSON {
+		api.Use(StrictJSONMiddleware())
+	}
+	if f.UUIDParams {
+		api.Use(UUIDParamsMiddleware("id", "categoryId"), UUIDQueryMiddleware("category_id"))
+	}
+	if f.Int64AsString {
+		api.Use(Int64AsStringMiddleware())
+	}
+	if f.MaxPageOffset > 0 {
+		api.Use(MaxOffsetMiddleware(f.MaxPageOffset))
+	}
 	if f.CatalogHandler != nil {
 		cat := api.Group("/categories")
 		{
 			cat.GET("", f.CatalogHandler.ListCategories)
+			cat.GET("/meta", CategoriesMeta)
+			cat.HEAD("/:id", f.CatalogHandler.CategoryExists)
+			cat.GET("/slug/:slug", f.CatalogHandler.GetCategoryBySlug)
 			adminCats := cat.Group("")
 			if f.TokenValidator != nil {
 				adminCats.Use(AuthMiddleware(f.TokenValidator), RoleMiddleware("admin"))
 			}
-			adminCats.POST("", f.CatalogHandler.CreateCategory)
-			adminCats.PUT("/:id", f.CatalogHandler.UpdateCategory)
+			adminCats.POST("", f.schema(SchemaCategoryCreate), f.CatalogHandler.CreateCategory)
+			adminCats.POST("/bulk", f.CatalogHandler.BulkCreateCategories)
+			adminCats.PUT("/order", f.CatalogHandler.ReorderCategories)
+			adminCats.PUT("/:id", f.schema(SchemaCategoryUpdate), f.CatalogHandler.UpdateCategory)
 			adminCats.DELETE("/:id", f.CatalogHandler.DeleteCategory)
 		}
 
 		prod := api.Group("/products")
 		{
-			prod.GET("", f.CatalogHandler.ListProducts)
-			prod.GET("/:id", f.CatalogHandler.GetProduct)
-			prod.GET("/:id/history", f.CatalogHandler.GetProductHistory)
+			prod.GET("", f.optionalAuth(), f.storefront(), f.CatalogHandler.ListProducts)
+			prod.GET("/meta", ProductsMeta)
+			prod.GET("/:id", f.optionalAuth(), f.CatalogHandler.GetProduct)
+			prod.GET("/slug/:slug", f.optionalAuth(), f.CatalogHandler.GetProductBySlug)
+			prod.GET("/by-barcode/:code", f.optionalAuth(), f.CatalogHandler.GetProductByBarcode)
+			prod.HEAD("/:id", f.optionalAuth(), f.CatalogHandler.ProductExists)
+			prod.GET("/:id/history", f.optionalAuth(), f.CatalogHandler.GetProductHistory)
+			prod.GET("/:id/history/latest", f.optionalAuth(), f.CatalogHandler.GetLatestProductHistory)
+			prod.GET("/:id/categories", f.optionalAuth(), f.CatalogHandler.ListProductCategories)
 
 			adminProd := prod.Group("")
 			if f.TokenValidator != nil {
 				adminProd.Use(AuthMiddleware(f.TokenValidator), RoleMiddleware("admin"))
 			}
-			adminProd.POST("", f.CatalogHandler.CreateProduct)
-			adminProd.PUT("/:id", f.CatalogHandler.UpdateProduct)
+			adminProd.POST("", f.schema(SchemaProductCreate), f.CatalogHandler.CreateProduct)
+			adminProd.POST("/bulk", f.CatalogHandler.BulkCreateProducts)
+			adminProd.PUT("/:id", f.schema(SchemaProductUpdate), f.CatalogHandler.UpdateProduct)
 			adminProd.DELETE("/:id", f.CatalogHandler.DeleteProduct)
+			adminProd.POST("/:id/restore", f.CatalogHandler.RestoreProduct)
+			adminProd.POST("/:id/publish", f.CatalogHandler.PublishProduct)
+			adminProd.POST("/stock/bulk-adjust", f.CatalogHandler.BulkAdjustStock)
 			adminProd.POST("/:id/categories/:categoryId", f.CatalogHandler.AddProductCategory)
+			adminProd.DELETE("/:id/categories/:categoryId", f.CatalogHandler.RemoveProductCategory)
 		}
 
-		api.GET("/search", f.CatalogHandler.Search)
+		api.GET("/search", f.optionalAuth(), f.storefront(), f.CatalogHandler.Search)
 	}
 	if f.IdentityHandler != nil {
 		identityGroup := api.Group("/identity")
-		identityLimiter := NewIPRateLimiter(rate.Every(time.Minute/5), 5)
+		loginRate, loginBurst := f.loginLimit()
+		identityLimiter := NewIPRateLimiter(loginRate, loginBurst, limiterTTL(loginRate, loginBurst))
 		identityGroup.Use(RateLimitMiddleware(identityLimiter))
-		identityGroup.POST("/users/client", f.IdentityHandler.RegisterClient)
-		identityGroup.POST("/users", f.IdentityHandler.RegisterUser)
+		// el alta reenvia el codigo si el anterior vencio; se limita tambien por email
+		// para que rotar IPs no permita crear cuentas en rafaga ni spamear un buzon.
+		regRate, regBurst := f.registrationLimit()
+		registrationLimiter := EmailRateLimitMiddleware(NewIPRateLimiter(regRate, regBurst, limiterTTL(regRate, regBurst)))
+		identityGroup.POST("/users/client", registrationLimiter, f.IdentityHandler.RegisterClient)
+		identityGroup.POST("/users", registrationLimiter, f.IdentityHandler.RegisterUser)
 		identityGroup.POST("/verify", f.IdentityHandler.VerifyUser)
 		identityGroup.POST("/login", f.IdentityHandler.Login)
+		identityGroup.POST("/refresh", f.IdentityHandler.Refresh)
 
 		protected := identityGroup.Group("")
 		if f.TokenValidator != nil {
@@ -96,6 +213,8 @@ func (f *RouterFactory) Build() *gin.Engine {
 		}
 
 		protected.PUT("/users/me", f.IdentityHandler.UpdateUser)
+		protected.GET("/users/me/sessions", f.IdentityHandler.ListSessions)
+		protected.DELETE("/users/me/sessions/:id", f.IdentityHandler.RevokeSession)
 
 		adminProtected := protected.Group("")
 		if f.TokenValidator != nil {
@@ -103,6 +222,33 @@ func (f *RouterFactory) Build() *gin.Engine {
 		}
 		adminProtected.POST("/users/:id/block", f.IdentityHandler.BlockUser)
 		adminProtected.PUT("/users/:id/role", f.IdentityHandler.UpdateUserRole)
+		adminProtected.POST("/users/verification-status", f.IdentityHandler.VerificationStatuses)
+	}
+
+	admin := api.Group("/admin")
+	if f.TokenValidator != nil {
+		admin.Use(AuthMiddleware(f.TokenValidator), RoleMiddleware("admin"))
+	}
+	if f.StatsHandler != nil {
+		admin.GET("/stats", f.StatsHandler.GetStats)
+	}
+	if f.SMTPTestHandler != nil {
+		// cada envio abre una conexion SMTP real; se limita para no usarlo como relay.
+		smtpLimiter := NewIPRateLimiter(rate.Every(time.Minute), 3, 0)
+		admin.POST("/smtp/test", RateLimitMiddleware(smtpLimiter), f.SMTPTestHandler.SendTestEmail)
+	}
+	if f.CatalogHandler != nil {
+		admin.GET("/price-changes", f.CatalogHandler.ListPriceChanges)
+		admin.POST("/price-changes/:id/approve", f.CatalogHandler.ApprovePriceChange)
+		admin.POST("/price-changes/:id/reject", f.CatalogHandler.RejectPriceChange)
+	}
+	if f.WSHub != nil {
+		admin.GET("/ws/clients", WSStats(f.WSHub))
+	}
+	if f.WebhookHandler != nil {
+		admin.POST("/webhooks", f.WebhookHandler.CreateWebhook)
+		admin.GET("/webhooks", f.WebhookHandler.ListWebhooks)
+		admin.DELETE("/webhooks/:id", f.WebhookHandler.DeleteWebhook)
 	}
 
 	api.GET("/events", EventsCatalog)
@@ -120,6 +266,41 @@ func (f *RouterFactory) Build() *gin.Engine {
 	return router
 }
 
+// registerWebsocket expone /ws solo cuando hay forma de autenticar o el modo anonimo es explicito.
+func (f *RouterFactory) registerWebsocket(router *gin.Engine) {
+	if f.WSHub == nil {
+		return
+	}
+	if f.TokenValidator == nil && !f.WSAllowAnonymous {
+		if f.Logr != nil {
+			f.Logr.Warn("websocket route disabled: no token validator configured and anonymous mode is off")
+		}
+		return
+	}
+	if f.WSAllowAnonymous && f.Logr != nil {
+		f.Logr.Warn("websocket anonymous mode enabled; connections without token receive public events only")
+	}
+	router.GET("/ws", func(c *gin.Context) {
+		var identity ws.Identity
+		token := websocketToken(c)
+		switch {
+		case token != "" && f.TokenValidator != nil:
+			authCtx, err := f.TokenValidator.Validate(token)
+			if err != nil {
+				abortError(c, http.StatusUnauthorized, codeUnauthorized, errInvalidToken, nil)
+				return
+			}
+			identity = ws.Identity{UserID: authCtx.UserID, Role: authCtx.Role}
+		case !f.WSAllowAnonymous:
+			abortError(c, http.StatusUnauthorized, codeUnauthorized, errMissingToken, nil)
+			return
+		}
+		// la identidad viaja con la conexion para filtrar eventos por visibilidad.
+		ctx := ws.WithIdentity(c.Request.Context(), identity)
+		f.WSHub.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	})
+}
+
 func websocketToken(c *gin.Context) string {
 	if token := tokenFromWSProtocol(c.Request.Header.Get("Sec-WebSocket-Protocol")); token != "" {
 		return token
@@ -127,7 +308,7 @@ func websocketToken(c *gin.Context) string {
 	if token := bearerTokenFromHeader(c.Request); token != "" {
 		return token
 	}
-	if token, err := c.Cookie("token"); err == nil && token != "" {
+	if token, err := c.Cookie(authCookieName); err == nil && token != "" {
 		return token
 	}
 	return ""
@@ -147,3 +328,52 @@ func tokenFromWSProtocol(header string) string {
 	}
 	return ""
 }
+
+// schema devuelve el middleware de validacion del schema name, o uno vacio si no hay validador.
+func (f *RouterFactory) schema(name string) gin.HandlerFunc {
+	if f.Schemas == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return f.Schemas.Middleware(name)
+}
+
+// storefront aplica StorefrontMiddleware solo si el modo vitrina esta activo.
+func (f *RouterFactory) storefront() gin.HandlerFunc {
+	if !f.Storefront {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return StorefrontMiddleware()
+}
+
+// optionalAuth identifica al admin en lecturas publicas para mostrarle borradores;
+// sin validador todos son anonimos.
+func (f *RouterFactory) optionalAuth() gin.HandlerFunc {
+	if f.TokenValidator == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return OptionalAuthMiddleware(f.TokenValidator)
+}
+
+// loginLimit devuelve la tasa y rafaga por IP de /identity con sus defaults.
+func (f *RouterFactory) loginLimit() (rate.Limit, int) {
+	perMin, burst := f.LoginRatePerMin, f.LoginBurst
+	if perMin <= 0 {
+		perMin = DefaultLoginRatePerMin
+	}
+	if burst <= 0 {
+		burst = DefaultLoginBurst
+	}
+	return rate.Limit(perMin / 60), burst
+}
+
+// registrationLimit devuelve la tasa y rafaga por email de las altas con sus defaults.
+func (f *RouterFactory) registrationLimit() (rate.Limit, int) {
+	perHour, burst := f.RegistrationRatePerHour, f.RegistrationBurst
+	if perHour <= 0 {
+		perHour = DefaultRegistrationRatePerHour
+	}
+	if burst <= 0 {
+		burst = DefaultRegistrationBurst
+	}
+	return rate.Limit(perHour / 3600), burst
+}
@@ -1,10 +1,13 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"catalog-api/internal/catalog"
 	"catalog-api/internal/identity"
@@ -78,6 +81,48 @@ func TestRouter_WebsocketRouteValidatesTokenFromProtocolHeader(t *testing.T) {
 	}
 }
 
+func TestRouter_WebsocketRouteSkippedWithoutValidator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{WSHub: ws.NewHub(nil, nil)}).Build()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected /ws not to be registered without validator, got %d", w.Code)
+	}
+}
+
+func TestRouter_WebsocketAnonymousModeSkipsAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{WSHub: ws.NewHub(nil, nil), WSAllowAnonymous: true}).Build()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	router.ServeHTTP(w, req)
+
+	// sin upgrade el hub responde 400: la request paso el chequeo de auth.
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected request to reach the hub in anonymous mode, got %d", w.Code)
+	}
+}
+
+func TestRouter_WebsocketAnonymousModeStillRejectsInvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := &stubTokenValidator{err: errors.New("bad token")}
+	router := (&RouterFactory{WSHub: ws.NewHub(nil, nil), TokenValidator: validator, WSAllowAnonymous: true}).Build()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Authorization", "Bearer nope")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for invalid token, got %d", w.Code)
+	}
+}
+
 func TestRouter_AdminCategory_RequiresAuth(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	catSvc := &stubCatalogService{}
@@ -170,6 +215,31 @@ func TestRouter_UpdateUser_RequiresAuth(t *testing.T) {
 	}
 }
 
+func TestRouter_AuthMiddlewareAcceptsTokenCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idSvc := &stubIdentityService{updateUserResp: sampleUser("u1", "user@example.com")}
+	validator := &stubTokenValidator{ctx: AuthContext{UserID: "u1", Role: "user"}}
+	router := (&RouterFactory{
+		IdentityHandler: NewIdentityHandler(idSvc),
+		TokenValidator:  validator,
+	}).Build()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/identity/users/me", strings.NewReader(`{"full_name":"New Name"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "token", Value: "cookietoken"})
+	req.AddCookie(&http.Cookie{Name: "csrf_token", Value: "csrf"})
+	req.Header.Set("X-CSRF-Token", "csrf")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with cookie auth, got %d", w.Code)
+	}
+	if len(validator.tokens) != 1 || validator.tokens[0] != "cookietoken" {
+		t.Fatalf("expected cookie token to be validated, got %v", validator.tokens)
+	}
+}
+
 func TestRouter_AdminIdentityRoutesUseRoleMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	idSvc := &stubIdentityService{
@@ -223,3 +293,415 @@ func TestRouter_LoginRateLimitedPerIP(t *testing.T) {
 		t.Fatalf("expected 429 after exceeding rate limit, got %d", w.Code)
 	}
 }
+
+func TestRouter_LoginRateLimitUsesConfiguredBurst(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idSvc := &stubIdentityService{
+		loginResp: identity.AuthToken{Token: "tok"},
+	}
+	router := (&RouterFactory{
+		IdentityHandler: NewIdentityHandler(idSvc),
+		LoginRatePerMin: 1,
+		LoginBurst:      2,
+	}).Build()
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/login", strings.NewReader(`{"email":"a@b.c","password":"x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = "192.0.2.7:1234"
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("attempt %d: expected %d, got %d", i+1, want, w.Code)
+		}
+	}
+}
+
+func TestRouter_RegistrationRateLimitedPerEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idSvc := &stubIdentityService{
+		registerClientResp: identity.User{ID: "u1", Email: "victim@example.com"},
+	}
+	router := (&RouterFactory{
+		IdentityHandler: NewIdentityHandler(idSvc),
+	}).Build()
+
+	register := func(ip, email string) int {
+		w := httptest.NewRecorder()
+		body := `{"email":"` + email + `","password":"secret123","full_name":"Victim"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/users/client", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i, email := range []string{"victim@example.com", "Victim@Example.com", "VICTIM@example.com"} {
+		if code := register(fmt.Sprintf("198.51.100.%d", i+1), email); code != http.StatusCreated {
+			t.Fatalf("expected 201 on attempt %d, got %d", i+1, code)
+		}
+	}
+	if idSvc.registerClientInput.Email == "" {
+		t.Fatalf("expected body to reach the handler after the limiter read it")
+	}
+	if code := register("198.51.100.9", "victim@example.com"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for repeated email from a new ip, got %d", code)
+	}
+	if code := register("198.51.100.10", "other@example.com"); code != http.StatusCreated {
+		t.Fatalf("expected other emails to be unaffected, got %d", code)
+	}
+}
+
+func TestRouter_RegistrationLimitFromConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idSvc := &stubIdentityService{
+		registerClientResp: identity.User{ID: "u1", Email: "victim@example.com"},
+	}
+	router := (&RouterFactory{
+		IdentityHandler:         NewIdentityHandler(idSvc),
+		RegistrationRatePerHour: 1,
+		RegistrationBurst:       1,
+	}).Build()
+
+	for i, want := range []int{http.StatusCreated, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		body := `{"email":"victim@example.com","password":"secret123","full_name":"Victim"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/users/client", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = fmt.Sprintf("198.51.100.%d:1234", i+1)
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Fatalf("attempt %d: expected %d, got %d", i+1, want, w.Code)
+		}
+	}
+}
+
+func TestRegistrationLimiter_SurvivesIdleEviction(t *testing.T) {
+	limit, burst := (&RouterFactory{RegistrationRatePerHour: 1, RegistrationBurst: 1}).registrationLimit()
+	limiter := NewIPRateLimiter(limit, burst, limiterTTL(limit, burst))
+
+	if !limiter.Allow("victim@example.com") {
+		t.Fatalf("expected first registration to be allowed")
+	}
+	// una limpieza tras el ttl por defecto no debe devolver la rafaga antes de la hora.
+	limiter.evict(time.Now().Add(defaultLimiterTTL + time.Minute))
+	if limiter.Allow("victim@example.com") {
+		t.Fatalf("expected the email to stay limited after an idle cleanup")
+	}
+	limiter.evict(time.Now().Add(2 * time.Hour))
+	if !limiter.Allow("victim@example.com") {
+		t.Fatalf("expected the key to be evicted once the burst refilled")
+	}
+}
+
+func TestRouter_RegistrationRejectsOversizedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idSvc := &stubIdentityService{}
+	router := (&RouterFactory{IdentityHandler: NewIdentityHandler(idSvc)}).Build()
+
+	body := `{"email":"a@b.c","full_name":"` + strings.Repeat("x", maxRegistrationBody) + `"}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/users/client", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+	if idSvc.registerClientInput.Email != "" {
+		t.Fatalf("expected oversized body not to reach the handler")
+	}
+}
+
+func TestRouter_HeadExistenceChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	catSvc := &stubCatalogService{existingIDs: map[string]bool{"p1": true, "c1": true}}
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(catSvc, nil)}).Build()
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/api/v1/products/p1", http.StatusOK},
+		{"/api/v1/products/missing", http.StatusNotFound},
+		{"/api/v1/categories/c1", http.StatusOK},
+		{"/api/v1/categories/missing", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodHead, tc.path, nil))
+		if w.Code != tc.want {
+			t.Fatalf("HEAD %s: expected %d, got %d", tc.path, tc.want, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Fatalf("HEAD %s: expected empty body, got %q", tc.path, w.Body.String())
+		}
+	}
+}
+
+func TestRouter_TrailingSlashIsNotRedirected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	catSvc := &stubCatalogService{}
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(catSvc, nil)}).Build()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for canonical path, got %d", w.Code)
+	}
+
+	for _, path := range []string{"/api/v1/products/", "/API/v1/products"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 without redirect for %s, got %d (Location=%q)", path, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestRouter_CacheControlPerRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{
+		CatalogHandler:  NewCatalogHandler(&stubCatalogService{}, nil),
+		IdentityHandler: NewIdentityHandler(&stubIdentityService{}),
+		CacheControl:    map[string]string{"/api/v1/categories": "public, max-age=30"},
+	}).Build()
+
+	cases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: http.MethodGet, path: "/api/v1/categories", want: "public, max-age=30"},
+		{method: http.MethodPost, path: "/api/v1/categories", want: "no-store"},
+		{method: http.MethodPost, path: "/api/v1/identity/login", want: "no-store"},
+		{method: http.MethodGet, path: "/api/v1/products", want: ""},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Cache-Control"); got != tc.want {
+			t.Fatalf("%s %s: expected Cache-Control %q, got %q", tc.method, tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(&stubCatalogService{}, nil)}).Build()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/categories", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d (%s)", w.Code, w.Body.String())
+	}
+	if allow := w.Header().Get("Allow"); !strings.Contains(allow, http.MethodGet) || !strings.Contains(allow, http.MethodPost) {
+		t.Fatalf("expected Allow to list GET and POST, got %q", allow)
+	}
+	if !strings.Contains(w.Body.String(), `"error":"method not allowed"`) {
+		t.Fatalf("expected JSON error body, got %s", w.Body.String())
+	}
+}
+
+func TestRouter_UnknownRouteIsJSON404(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{}).Build()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/nope", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("expected JSON content type, got %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), `"path":"/api/v1/nope"`) {
+		t.Fatalf("expected path in body, got %s", w.Body.String())
+	}
+
+	// el wildcard de swagger sigue resolviendo sus propias rutas.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/index.html", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected swagger UI to be served, got %d", w.Code)
+	}
+}
+
+func TestRouter_UUIDParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &stubCatalogService{getProductResp: catalog.Product{ID: "p1", Name: "Pen"}}
+	for _, tc := range []struct {
+		uuidParams bool
+		path       string
+		want       int
+		param      string
+	}{
+		{uuidParams: true, path: "/api/v1/products/not-a-uuid", want: http.StatusBadRequest},
+		{uuidParams: true, path: "/api/v1/products/3f2b8c1e-9a4d-4e7b-8c21-5d6e7f8a9b0c", want: http.StatusOK},
+		// el filtro de categoria no debe llegar a Postgres ni responder product_not_found.
+		{uuidParams: true, path: "/api/v1/products?category_id=abc", want: http.StatusBadRequest, param: "category_id"},
+		{uuidParams: true, path: "/api/v1/products?category_id=3f2b8c1e-9a4d-4e7b-8c21-5d6e7f8a9b0c", want: http.StatusOK},
+		// los tests y despliegues con ids libres siguen funcionando sin el flag.
+		{uuidParams: false, path: "/api/v1/products/not-a-uuid", want: http.StatusOK},
+	} {
+		router := (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil), UUIDParams: tc.uuidParams}).Build()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.want {
+			t.Fatalf("uuid=%v %s: expected %d, got %d (%s)", tc.uuidParams, tc.path, tc.want, w.Code, w.Body.String())
+		}
+		if tc.param == "" {
+			tc.param = "id"
+		}
+		if tc.want == http.StatusBadRequest && !strings.Contains(w.Body.String(), `"param":"`+tc.param+`"`) {
+			t.Fatalf("expected offending param in body, got %s", w.Body.String())
+		}
+	}
+}
+
+func TestRouter_GlobalRateLimitIgnoresClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{GlobalRateLimit: 0.001, GlobalRateBurst: 2}).Build()
+
+	codes := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)
+		req.RemoteAddr = fmt.Sprintf("203.0.113.%d:1234", i+1)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+		if w.Code == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+			t.Fatalf("expected Retry-After on 503")
+		}
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusServiceUnavailable {
+		t.Fatalf("expected the third request from a new IP to be shed, got %v", codes)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("health checks must bypass the global limit, got %d", w.Code)
+	}
+}
+
+func TestRouter_StorefrontHidesOutOfStockForNonAdmins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	catSvc := &stubCatalogService{}
+	router := (&RouterFactory{
+		CatalogHandler: NewCatalogHandler(catSvc, nil),
+		TokenValidator: &stubTokenValidator{ctx: AuthContext{UserID: "admin", Role: "admin"}},
+		Storefront:     true,
+	}).Build()
+
+	cases := []struct {
+		name       string
+		path       string
+		token      string
+		want       catalog.StockFilter
+		hideDrafts bool
+	}{
+		{name: "anonymous", path: "/api/v1/products", want: catalog.StockIn, hideDrafts: true},
+		{name: "admin", path: "/api/v1/products", token: "goodtoken", want: catalog.StockAny},
+		// ?stock= cambia el filtro de stock pero los borradores siguen ocultos.
+		{name: "explicit filter", path: "/api/v1/products?stock=out", want: catalog.StockOut, hideDrafts: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if catSvc.listProductsFilter.Stock != tc.want {
+				t.Fatalf("expected stock filter %q, got %q", tc.want, catSvc.listProductsFilter.Stock)
+			}
+			if catSvc.listProductsFilter.ExcludeDrafts != tc.hideDrafts {
+				t.Fatalf("expected ExcludeDrafts=%v, got %v", tc.hideDrafts, catSvc.listProductsFilter.ExcludeDrafts)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/search?q=book&kind=product", nil))
+	if catSvc.searchFilter.Stock != catalog.StockIn || !catSvc.searchFilter.ExcludeDrafts {
+		t.Fatalf("expected storefront search to default to in-stock without drafts, got %+v (status %d)", catSvc.searchFilter, w.Code)
+	}
+}
+
+func TestRouter_DraftsHiddenFromNonAdminsWithoutStorefront(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	draft := catalog.Product{ID: "p1", Name: "Pen", Slug: "pen", Barcode: "4006381333931", Draft: true}
+	catSvc := &stubCatalogService{getProductResp: draft, productBySlug: draft, productByBarcode: draft}
+	validator := &stubTokenValidator{ctx: AuthContext{UserID: "admin", Role: "admin"}}
+	router := (&RouterFactory{CatalogHandler: NewCatalogHandler(catSvc, nil), TokenValidator: validator}).Build()
+
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, path := range []string{"/api/v1/products/p1", "/api/v1/products/slug/pen", "/api/v1/products/by-barcode/4006381333931"} {
+		if code := get(path, ""); code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404 for anonymous caller, got %d", path, code)
+		}
+		if code := get(path, "admintoken"); code != http.StatusOK {
+			t.Fatalf("%s: expected 200 for admin, got %d", path, code)
+		}
+	}
+
+	get("/api/v1/products", "")
+	if !catSvc.listProductsFilter.ExcludeDrafts {
+		t.Fatalf("expected anonymous listing to exclude drafts without storefront mode")
+	}
+	get("/api/v1/products", "admintoken")
+	if catSvc.listProductsFilter.ExcludeDrafts {
+		t.Fatalf("expected admin listing to include drafts")
+	}
+
+	catSvc.existingIDs = map[string]bool{"p1": true}
+	catSvc.latestHistoryResp = catalog.ProductHistory{ProductID: "p1"}
+	for _, path := range []string{"/api/v1/products/p1/history", "/api/v1/products/p1/history/latest", "/api/v1/products/p1/categories"} {
+		if code := get(path, ""); code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404 for anonymous caller, got %d", path, code)
+		}
+		if code := get(path, "admintoken"); code != http.StatusOK {
+			t.Fatalf("%s: expected 200 for admin, got %d", path, code)
+		}
+	}
+	head := func(token string) int {
+		req := httptest.NewRequest(http.MethodHead, "/api/v1/products/p1", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := head(""); code != http.StatusNotFound {
+		t.Fatalf("HEAD: expected 404 for anonymous caller, got %d", code)
+	}
+	if code := head("admintoken"); code != http.StatusOK {
+		t.Fatalf("HEAD: expected 200 for admin, got %d", code)
+	}
+
+	validator.ctx = AuthContext{UserID: "u1", Role: "client"}
+	if code := get("/api/v1/products/p1", "clienttoken"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for non-admin caller, got %d", code)
+	}
+}
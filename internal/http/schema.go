@@ -0,0 +1,151 @@
+package http
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Schemas publicados para los endpoints de alta/edicion del catalogo.
+const (
+	SchemaCategoryCreate = "category_create"
+	SchemaCategoryUpdate = "category_update"
+	SchemaProductCreate  = "product_create"
+	SchemaProductUpdate  = "product_update"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// FieldError describe un campo que no cumple el schema; Field usa notacion con puntos.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SchemaValidator valida bodies JSON contra los schemas embebidos antes del binding.
+type SchemaValidator struct {
+	schemas map[string]*jsonschema.Schema
+	printer *message.Printer
+}
+
+// NewSchemaValidator compila todos los schemas embebidos; falla si alguno es invalido.
+func NewSchemaValidator() (*SchemaValidator, error) {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		raw, err := schemaFiles.ReadFile("schemas/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", entry.Name(), err)
+		}
+		if err := compiler.AddResource(schemaURL(entry.Name()), doc); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", entry.Name(), err)
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	v := &SchemaValidator{
+		schemas: make(map[string]*jsonschema.Schema, len(names)),
+		printer: message.NewPrinter(language.English),
+	}
+	for _, name := range names {
+		sch, err := compiler.Compile(schemaURL(name + ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		v.schemas[name] = sch
+	}
+	return v, nil
+}
+
+func schemaURL(file string) string {
+	return "mem://schemas/" + file
+}
+
+// Middleware valida el body contra el schema name y responde 400 con errores por campo.
+// Un body que no es JSON se deja pasar para que el handler devuelva su error habitual.
+func (v *SchemaValidator) Middleware(name string) gin.HandlerFunc {
+	sch, ok := v.schemas[name]
+	if !ok {
+		panic("unknown json schema " + name)
+	}
+	return func(c *gin.Context) {
+		if c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			c.Next()
+			return
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+		if err != nil {
+			c.Next()
+			return
+		}
+		if err := sch.Validate(doc); err != nil {
+			var verr *jsonschema.ValidationError
+			if !errors.As(err, &verr) {
+				abortError(c, http.StatusBadRequest, codeInvalidRequest, err, nil)
+				return
+			}
+			abortError(c, http.StatusBadRequest, codeValidation, errors.New("payload does not match schema"), v.fieldErrors(verr))
+			return
+		}
+		c.Next()
+	}
+}
+
+// fieldErrors aplana el arbol de causas a un error por campo, ordenado por campo.
+func (v *SchemaValidator) fieldErrors(verr *jsonschema.ValidationError) []FieldError {
+	var out []FieldError
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		// required se reporta en el objeto padre; se traslada a cada propiedad faltante.
+		if req, ok := e.ErrorKind.(*kind.Required); ok {
+			for _, missing := range req.Missing {
+				out = append(out, FieldError{Field: fieldPath(append(slices.Clone(e.InstanceLocation), missing)), Message: "is required"})
+			}
+			return
+		}
+		out = append(out, FieldError{Field: fieldPath(e.InstanceLocation), Message: e.ErrorKind.LocalizedString(v.printer)})
+	}
+	walk(verr)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
+}
+
+func fieldPath(location []string) string {
+	if len(location) == 0 {
+		return "$"
+	}
+	return strings.Join(location, ".")
+}
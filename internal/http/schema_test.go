@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"catalog-api/internal/catalog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newSchemaRouter(t *testing.T, svc *stubCatalogService) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	schemas, err := NewSchemaValidator()
+	if err != nil {
+		t.Fatalf("compile schemas: %v", err)
+	}
+	return (&RouterFactory{CatalogHandler: NewCatalogHandler(svc, nil), Schemas: schemas}).Build()
+}
+
+func TestNewSchemaValidator_CompilesEmbeddedSchemas(t *testing.T) {
+	v, err := NewSchemaValidator()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{SchemaCategoryCreate, SchemaCategoryUpdate, SchemaProductCreate, SchemaProductUpdate} {
+		if v.schemas[name] == nil {
+			t.Fatalf("expected schema %s to be loaded", name)
+		}
+	}
+}
+
+func TestSchemaValidation_ValidBodyReachesHandler(t *testing.T) {
+	svc := &stubCatalogService{createProductResp: catalog.Product{ID: "p1", Name: "Mouse", Price: 1500, Stock: 3}}
+	router := newSchemaRouter(t, svc)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(`{"name":"Mouse","price":"1500","currency":"usd","stock":3}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if in := svc.createProductInput; in.Name != "Mouse" || in.Price == nil || *in.Price != 1500 {
+		t.Fatalf("expected body to be bound after validation, got %+v", svc.createProductInput)
+	}
+}
+
+func TestSchemaValidation_InvalidBodyReturnsFieldErrors(t *testing.T) {
+	svc := &stubCatalogService{}
+	router := newSchemaRouter(t, svc)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products", strings.NewReader(`{"name":"","price":-5,"currency":"dollars","stock":-1}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Error  string       `json:"error"`
+		Code   string       `json:"code"`
+		Fields []FieldError `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != codeValidation {
+		t.Fatalf("expected code %s, got %q", codeValidation, body.Code)
+	}
+	got := map[string]bool{}
+	for _, f := range body.Fields {
+		if f.Message == "" {
+			t.Fatalf("expected a message for field %s", f.Field)
+		}
+		got[f.Field] = true
+	}
+	for _, field := range []string{"name", "price", "currency", "stock"} {
+		if !got[field] {
+			t.Fatalf("expected an error for %s, got %+v", field, body.Fields)
+		}
+	}
+	if svc.createProductInput.Name != "" {
+		t.Fatalf("service should not be invoked on schema errors")
+	}
+}
+
+func TestSchemaValidation_UpdateCategoryRejectsWrongType(t *testing.T) {
+	router := newSchemaRouter(t, &stubCatalogService{})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/categories/c1", strings.NewReader(`{"name":42}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"field":"name"`) {
+		t.Fatalf("expected 400 naming the field, got %d: %s", w.Code, w.Body.String())
+	}
+}
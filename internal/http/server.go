@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// defaultReadHeaderTimeout se usa si no se configura uno, para que un cliente lento
+// no pueda retener la conexion enviando headers de a poco.
+const defaultReadHeaderTimeout = 5 * time.Second
+
+// ServerConfig define la direccion y los timeouts del http.Server.
+type ServerConfig struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout no alcanza a /ws: el hub limpia el deadline antes del upgrade.
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	KeepAlive    bool
+}
+
+// NewServer construye el http.Server con los timeouts configurados.
+func NewServer(cfg ServerConfig, handler http.Handler) *http.Server {
+	readHeader := cfg.ReadHeaderTimeout
+	if readHeader <= 0 {
+		readHeader = defaultReadHeaderTimeout
+	}
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: readHeader,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	srv.SetKeepAlivesEnabled(cfg.KeepAlive)
+	return srv
+}
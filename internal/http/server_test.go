@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewServer_AppliesTimeouts(t *testing.T) {
+	handler := http.NewServeMux()
+	srv := NewServer(ServerConfig{
+		Addr:              ":8080",
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 3 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		KeepAlive:         true,
+	}, handler)
+
+	if srv.Addr != ":8080" || srv.Handler != handler {
+		t.Fatalf("unexpected server %+v", srv)
+	}
+	if srv.ReadTimeout != 15*time.Second || srv.ReadHeaderTimeout != 3*time.Second ||
+		srv.WriteTimeout != 30*time.Second || srv.IdleTimeout != 2*time.Minute {
+		t.Fatalf("timeouts not applied: read=%s header=%s write=%s idle=%s",
+			srv.ReadTimeout, srv.ReadHeaderTimeout, srv.WriteTimeout, srv.IdleTimeout)
+	}
+}
+
+func TestNewServer_AlwaysBoundsHeaderRead(t *testing.T) {
+	srv := NewServer(ServerConfig{Addr: ":8080"}, http.NewServeMux())
+	if srv.ReadHeaderTimeout != defaultReadHeaderTimeout {
+		t.Fatalf("expected default header timeout, got %s", srv.ReadHeaderTimeout)
+	}
+}
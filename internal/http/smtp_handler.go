@@ -0,0 +1,46 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestEmailSender envia un correo de prueba con el sender configurado.
+type TestEmailSender interface {
+	SendTestEmail(ctx context.Context, email string) error
+}
+
+// SMTPTestHandler permite a los admins comprobar la entrega de correo.
+type SMTPTestHandler struct {
+	sender TestEmailSender
+}
+
+func NewSMTPTestHandler(sender TestEmailSender) *SMTPTestHandler {
+	return &SMTPTestHandler{sender: sender}
+}
+
+// SendTestEmail godoc
+// @Summary Send SMTP test email
+// @Description Envia un correo de prueba al email indicado con el sender configurado. Responde 502 con el detalle si el envio falla (incluido el sender noop sin SMTP).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body SMTPTestRequest true "Target email"
+// @Success 200 {object} SMTPTestResponse
+// @Failure 502 {object} SMTPTestResponse
+// @Security BearerAuth
+// @Router /admin/smtp/test [post]
+func (h *SMTPTestHandler) SendTestEmail(c *gin.Context) {
+	var req SMTPTestRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
+		return
+	}
+	if err := h.sender.SendTestEmail(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusBadGateway, SMTPTestResponse{Sent: false, Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, SMTPTestResponse{Sent: true})
+}
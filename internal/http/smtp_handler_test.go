@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubTestEmailSender struct {
+	to  []string
+	err error
+}
+
+func (s *stubTestEmailSender) SendTestEmail(ctx context.Context, email string) error {
+	s.to = append(s.to, email)
+	return s.err
+}
+
+func serveSMTPTest(router http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/smtp/test", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSMTPTestHandler_ReportsDelivery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sender := &stubTestEmailSender{}
+	router := (&RouterFactory{SMTPTestHandler: NewSMTPTestHandler(sender)}).Build()
+
+	w := serveSMTPTest(router, `{"email":"admin@example.com"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	var resp SMTPTestResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || !resp.Sent {
+		t.Fatalf("expected sent=true, got %s", w.Body.String())
+	}
+	if len(sender.to) != 1 || sender.to[0] != "admin@example.com" {
+		t.Fatalf("sender received %v", sender.to)
+	}
+
+	sender.err = errors.New("dial tcp: connection refused")
+	w = serveSMTPTest(router, `{"email":"admin@example.com"}`)
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d", w.Code)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Sent || resp.Error != "dial tcp: connection refused" {
+		t.Fatalf("expected failure detail, got %s", w.Body.String())
+	}
+
+	if w := serveSMTPTest(router, `{"email":"not-an-email"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid email, got %d", w.Code)
+	}
+	if w := serveSMTPTest(router, `{"email":"admin@example.com"}`); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 once the limit is exhausted, got %d", w.Code)
+	}
+}
+
+func TestSMTPTestHandler_RequiresAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := (&RouterFactory{
+		SMTPTestHandler: NewSMTPTestHandler(&stubTestEmailSender{}),
+		TokenValidator:  &stubTokenValidator{ctx: AuthContext{UserID: "u1", Role: "client"}},
+	}).Build()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/smtp/test", strings.NewReader(`{"email":"admin@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for non-admin, got %d", w.Code)
+	}
+}
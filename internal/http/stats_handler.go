@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"catalog-api/internal/catalog"
+	"catalog-api/internal/identity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultStatsCacheTTL es cuanto se reutiliza un resumen antes de volver a contar.
+const DefaultStatsCacheTTL = 30 * time.Second
+
+// StatsHandler arma el resumen del panel de administracion y lo cachea brevemente
+// para que refrescar el dashboard no dispare los COUNT en cada peticion.
+type StatsHandler struct {
+	catalog  catalog.Service
+	identity identity.Service
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	cached    AdminStatsResponse
+	expiresAt time.Time
+}
+
+// NewStatsHandler construye el handler; ttl <= 0 usa DefaultStatsCacheTTL.
+func NewStatsHandler(catalogSvc catalog.Service, identitySvc identity.Service, ttl time.Duration) *StatsHandler {
+	if ttl <= 0 {
+		ttl = DefaultStatsCacheTTL
+	}
+	return &StatsHandler{catalog: catalogSvc, identity: identitySvc, ttl: ttl, now: time.Now}
+}
+
+// GetStats godoc
+// @Summary Admin dashboard stats
+// @Description Totales de productos, productos sin stock, categorias y usuarios por estado. Se cachea unos segundos (ADMIN_STATS_CACHE_TTL).
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} AdminStatsResponse
+// @Security BearerAuth
+// @Router /admin/stats [get]
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	if now.Before(h.expiresAt) {
+		c.JSON(http.StatusOK, h.cached)
+		return
+	}
+	ctx := c.Request.Context()
+	stats, err := h.catalog.Stats(ctx)
+	if err != nil {
+		respondCatalogError(c, err)
+		return
+	}
+	users, err := h.identity.UserCountsByStatus(ctx)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "", err)
+		return
+	}
+	resp := AdminStatsResponse{
+		Products:           stats.Products,
+		OutOfStockProducts: stats.OutOfStockProducts,
+		Categories:         stats.Categories,
+		Users:              make(map[string]int64, len(users)),
+		GeneratedAt:        now.UTC().Format(time.RFC3339),
+	}
+	for status, total := range users {
+		resp.Users[string(status)] = total
+		resp.UsersTotal += total
+	}
+	h.cached, h.expiresAt = resp, now.Add(h.ttl)
+	c.JSON(http.StatusOK, resp)
+}
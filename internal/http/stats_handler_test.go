@@ -0,0 +1,58 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"catalog-api/internal/catalog"
+	"catalog-api/internal/identity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStatsHandler_AggregatesAndCaches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	catSvc := &stubCatalogService{statsResp: catalog.Stats{Products: 12, OutOfStockProducts: 3, Categories: 4}}
+	idSvc := &stubIdentityService{userCounts: map[identity.UserStatus]int64{
+		identity.UserStatusActive:              5,
+		identity.UserStatusPendingVerification: 2,
+	}}
+	h := NewStatsHandler(catSvc, idSvc, time.Minute)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	router := (&RouterFactory{StatsHandler: h}).Build()
+
+	get := func() AdminStatsResponse {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/stats", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d (%s)", w.Code, w.Body.String())
+		}
+		var resp AdminStatsResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	resp := get()
+	if resp.Products != 12 || resp.OutOfStockProducts != 3 || resp.Categories != 4 {
+		t.Fatalf("unexpected catalog stats %+v", resp)
+	}
+	if resp.UsersTotal != 7 || resp.Users["active"] != 5 || resp.Users["pending_verification"] != 2 {
+		t.Fatalf("unexpected user stats %+v", resp)
+	}
+
+	get()
+	if catSvc.statsCalls != 1 {
+		t.Fatalf("expected cached response within ttl, got %d calls", catSvc.statsCalls)
+	}
+	now = now.Add(2 * time.Minute)
+	get()
+	if catSvc.statsCalls != 2 {
+		t.Fatalf("expected refresh after ttl, got %d calls", catSvc.statsCalls)
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"strings"
+
+	"catalog-api/internal/catalog"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ctxStorefrontKey = "storefront"
+
+// StorefrontMiddleware marca el listado publico como vitrina: sin filtro de stock
+// explicito se ocultan los productos agotados. Los admins siguen viendo todo; la
+// identidad la deja OptionalAuthMiddleware antes.
+func StorefrontMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := AuthRole(c); role != "admin" {
+			c.Set(ctxStorefrontKey, true)
+		}
+		c.Next()
+	}
+}
+
+// hideDrafts indica si el llamador no es admin y no debe ver borradores, con o sin
+// modo vitrina.
+func hideDrafts(c *gin.Context) bool {
+	role, _ := AuthRole(c)
+	return role != "admin"
+}
+
+// stockFilter lee ?stock=; en modo vitrina el default es solo productos con stock.
+func stockFilter(c *gin.Context) catalog.StockFilter {
+	stock := catalog.StockFilter(strings.ToLower(strings.TrimSpace(c.Query("stock"))))
+	if stock == catalog.StockAny && c.GetBool(ctxStorefrontKey) {
+		return catalog.StockIn
+	}
+	return stock
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"catalog-api/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler expone la administracion de suscripciones de webhooks.
+type WebhookHandler struct {
+	svc webhook.Service
+}
+
+func NewWebhookHandler(svc webhook.Service) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+// CreateWebhook godoc
+// @Summary Register webhook subscription
+// @Description Registra un endpoint HTTPS que recibira por POST los eventos indicados, firmados con HMAC-SHA256 en X-Webhook-Signature. El secret solo se devuelve en esta respuesta; si se omite se genera uno.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param body body CreateWebhookRequest true "Subscription"
+// @Success 201 {object} WebhookResponse
+// @Security BearerAuth
+// @Router /admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := bindJSON(c, &req); err != nil {
+		respondBindError(c, err, &req)
+		return
+	}
+	sub, err := h.svc.CreateSubscription(c.Request.Context(), webhook.CreateSubscriptionInput{
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: req.Secret,
+	})
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	resp := toWebhookResponse(sub)
+	resp.Secret = sub.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListWebhooks godoc
+// @Summary List webhook subscriptions
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} WebhookResponse
+// @Security BearerAuth
+// @Router /admin/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subs, err := h.svc.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	resp := make([]WebhookResponse, 0, len(subs))
+	for _, sub := range subs {
+		resp = append(resp, toWebhookResponse(sub))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook subscription
+// @Tags Webhooks
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Security BearerAuth
+// @Router /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.svc.DeleteSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// toWebhookResponse nunca copia el secret: solo se muestra al crear.
+func toWebhookResponse(sub webhook.Subscription) WebhookResponse {
+	return WebhookResponse{
+		ID:        sub.ID,
+		URL:       sub.URL,
+		Events:    sub.Events,
+		CreatedAt: sub.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func respondWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidSubscription), errors.Is(err, webhook.ErrUnknownEvent):
+		respondError(c, http.StatusBadRequest, "", err)
+	case errors.Is(err, webhook.ErrSubscriptionNotFound):
+		respondError(c, http.StatusNotFound, "", err)
+	default:
+		respondError(c, http.StatusInternalServerError, "", err)
+	}
+}
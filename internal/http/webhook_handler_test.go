@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"catalog-api/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubWebhookService struct {
+	createInput webhook.CreateSubscriptionInput
+	createResp  webhook.Subscription
+	createErr   error
+	listResp    []webhook.Subscription
+	deleteErr   error
+}
+
+func (s *stubWebhookService) CreateSubscription(ctx context.Context, input webhook.CreateSubscriptionInput) (webhook.Subscription, error) {
+	s.createInput = input
+	return s.createResp, s.createErr
+}
+
+func (s *stubWebhookService) ListSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	return s.listResp, nil
+}
+
+func (s *stubWebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	return s.deleteErr
+}
+
+func serveWebhooks(svc webhook.Service, method, path, body string) *httptest.ResponseRecorder {
+	router := (&RouterFactory{WebhookHandler: NewWebhookHandler(svc)}).Build()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookHandler_CreateReturnsSecretOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sub := webhook.Subscription{ID: "w1", URL: "https://example.com/hook", Events: []string{"product.created"}, Secret: "generated"}
+	svc := &stubWebhookService{createResp: sub, listResp: []webhook.Subscription{sub}}
+
+	w := serveWebhooks(svc, http.MethodPost, "/api/v1/admin/webhooks", `{"url":"https://example.com/hook","events":["product.created"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var created WebhookResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.Secret != "generated" {
+		t.Fatalf("expected secret in create response, got %s", w.Body.String())
+	}
+	if svc.createInput.URL != "https://example.com/hook" || len(svc.createInput.Events) != 1 {
+		t.Fatalf("service received %+v", svc.createInput)
+	}
+
+	w = serveWebhooks(svc, http.MethodGet, "/api/v1/admin/webhooks", "")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "generated") {
+		t.Fatalf("list must not expose secrets, got %d (%s)", w.Code, w.Body.String())
+	}
+}
+
+func TestWebhookHandler_ErrorStatuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		name   string
+		svc    *stubWebhookService
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "unknown event", svc: &stubWebhookService{createErr: webhook.ErrUnknownEvent}, method: http.MethodPost, path: "/api/v1/admin/webhooks", body: `{"url":"https://example.com/hook","events":["nope"]}`, want: http.StatusBadRequest},
+		{name: "missing events", svc: &stubWebhookService{}, method: http.MethodPost, path: "/api/v1/admin/webhooks", body: `{"url":"https://example.com/hook"}`, want: http.StatusBadRequest},
+		{name: "delete missing", svc: &stubWebhookService{deleteErr: webhook.ErrSubscriptionNotFound}, method: http.MethodDelete, path: "/api/v1/admin/webhooks/w9", want: http.StatusNotFound},
+		{name: "delete ok", svc: &stubWebhookService{}, method: http.MethodDelete, path: "/api/v1/admin/webhooks/w1", want: http.StatusNoContent},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serveWebhooks(tc.svc, tc.method, tc.path, tc.body)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d (%s)", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"catalog-api/internal/ws"
+
+	"github.com/gin-gonic/gin"
+)
+
+// wsStatsTimeout acota la espera a que el hub arme la foto de sus clientes.
+const wsStatsTimeout = 2 * time.Second
+
+// WSStats godoc
+// @Summary WebSocket backpressure
+// @Description Profundidad de la cola de salida y high-water mark de cada conexion, mas los mensajes descartados por clientes lentos o por la cola del hub llena.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} ws.HubStats
+// @Failure 503 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/ws/clients [get]
+func WSStats(hub *ws.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), wsStatsTimeout)
+		defer cancel()
+		stats, err := hub.Stats(ctx)
+		if err != nil {
+			respondError(c, http.StatusServiceUnavailable, "", err)
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	}
+}
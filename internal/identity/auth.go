@@ -22,3 +22,8 @@ type VerificationCodeGenerator interface {
 type VerificationSender interface {
 	SendVerification(ctx context.Context, email, code string) error
 }
+
+// LoginNotifier avisa al usuario de inicios de sesion desde dispositivos nuevos.
+type LoginNotifier interface {
+	SendNewDeviceLogin(ctx context.Context, email, userAgent, ip string) error
+}
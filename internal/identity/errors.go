@@ -13,4 +13,10 @@ var (
 	ErrPasswordHasherNotSet     = errors.New("password hasher not configured")
 	ErrVerificationSenderNotSet = errors.New("verification sender not configured")
 	ErrNotImplemented           = errors.New("not implemented")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrInvalidRefreshToken      = errors.New("invalid refresh token")
+	ErrTooManyUserIDs           = errors.New("too many user ids")
+	ErrUsernameTaken            = errors.New("username already taken")
+	ErrInvalidUsername          = errors.New("invalid username")
+	ErrUsernamesDisabled        = errors.New("usernames are disabled")
 )
@@ -0,0 +1,50 @@
+package identity
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// CodeJanitor purga periodicamente los codigos de verificacion vencidos.
+type CodeJanitor struct {
+	cleaner  VerificationCodeCleaner
+	interval time.Duration
+	logr     *slog.Logger
+}
+
+// NewCodeJanitor construye un janitor; un intervalo <= 0 lo deja deshabilitado.
+func NewCodeJanitor(cleaner VerificationCodeCleaner, interval time.Duration, logr *slog.Logger) *CodeJanitor {
+	if logr == nil {
+		logr = slog.Default()
+	}
+	return &CodeJanitor{cleaner: cleaner, interval: interval, logr: logr}
+}
+
+// Run bloquea hasta que ctx se cancele, limpiando en cada tick.
+func (j *CodeJanitor) Run(ctx context.Context) {
+	if j.cleaner == nil || j.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep ejecuta una pasada de limpieza y devuelve la cantidad de codigos borrados.
+func (j *CodeJanitor) Sweep(ctx context.Context) int64 {
+	removed, err := j.cleaner.DeleteExpiredVerificationCodes(ctx, time.Now())
+	if err != nil {
+		j.logr.Warn("verification code cleanup failed", "error", err)
+		return 0
+	}
+	j.logr.Info("expired verification codes removed", "count", removed)
+	return removed
+}
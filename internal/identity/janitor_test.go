@@ -0,0 +1,53 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubCleaner struct {
+	removed int64
+	err     error
+	calls   int
+}
+
+func (s *stubCleaner) DeleteExpiredVerificationCodes(ctx context.Context, now time.Time) (int64, error) {
+	s.calls++
+	return s.removed, s.err
+}
+
+func TestCodeJanitor_SweepReportsRemovedRows(t *testing.T) {
+	cleaner := &stubCleaner{removed: 4}
+	j := NewCodeJanitor(cleaner, time.Minute, nil)
+	if got := j.Sweep(context.Background()); got != 4 {
+		t.Fatalf("expected 4 removed, got %d", got)
+	}
+
+	cleaner.err = errors.New("db down")
+	if got := j.Sweep(context.Background()); got != 0 {
+		t.Fatalf("expected 0 on error, got %d", got)
+	}
+}
+
+func TestCodeJanitor_RunStopsOnCancel(t *testing.T) {
+	cleaner := &stubCleaner{}
+	j := NewCodeJanitor(cleaner, 5*time.Millisecond, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		j.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("janitor did not stop after cancel")
+	}
+	if cleaner.calls == 0 {
+		t.Fatalf("expected at least one sweep")
+	}
+}
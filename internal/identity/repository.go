@@ -11,7 +11,13 @@ type UserRepository interface {
 	BeginTx(ctx context.Context) (UserTx, error)
 	CreateUser(ctx context.Context, user User) (User, error)
 	GetByEmail(ctx context.Context, email string) (User, error)
+	// GetByIdentifier busca por email o por username (sin distinguir mayusculas).
+	GetByIdentifier(ctx context.Context, identifier string) (User, error)
 	GetByID(ctx context.Context, id UserID) (User, error)
+	// GetVerificationStatuses devuelve el estado de los ids existentes; los desconocidos se omiten.
+	GetVerificationStatuses(ctx context.Context, ids []UserID) ([]VerificationStatus, error)
+	// CountUsersByStatus agrupa los usuarios por estado; los estados sin usuarios se omiten.
+	CountUsersByStatus(ctx context.Context) (map[UserStatus]int64, error)
 	SetVerification(ctx context.Context, userID UserID, verified bool) error
 	UpdateStatus(ctx context.Context, userID UserID, status UserStatus) error
 	UpdateUserProfile(ctx context.Context, user User) (User, error)
@@ -23,6 +29,8 @@ type UserRepository interface {
 
 // UserTx define las operaciones necesarias dentro de una transaccion de usuarios.
 type UserTx interface {
+	// CreateUser devuelve ErrEmailAlreadyRegistered o ErrUsernameTaken cuando un
+	// registro concurrente gano la carrera; el llamador debe hacer Rollback.
 	CreateUser(ctx context.Context, user User) (User, error)
 	SaveVerificationCode(ctx context.Context, userID UserID, code string, expiresAt time.Time) error
 	Commit(ctx context.Context) error
@@ -34,3 +42,14 @@ type RoleRepository interface {
 	EnsureRole(ctx context.Context, role RoleName) error
 	AssignRole(ctx context.Context, userID UserID, role RoleName) error
 }
+
+// KnownDeviceRepository recuerda las huellas de dispositivo vistas por usuario.
+type KnownDeviceRepository interface {
+	// RememberDevice registra la huella y devuelve true si no se habia visto antes.
+	RememberDevice(ctx context.Context, userID UserID, fingerprint string) (bool, error)
+}
+
+// VerificationCodeCleaner elimina codigos de verificacion vencidos.
+type VerificationCodeCleaner interface {
+	DeleteExpiredVerificationCodes(ctx context.Context, now time.Time) (int64, error)
+}
@@ -17,6 +17,11 @@ type Service interface {
 	SeedAdmin(ctx context.Context, seed AdminSeedInput) error
 	UpdateUser(ctx context.Context, input UpdateUserInput) (User, error)
 	UpdateUserRole(ctx context.Context, input UpdateUserRoleInput) (User, error)
+	RefreshSession(ctx context.Context, refreshToken string) (AuthToken, error)
+	ListSessions(ctx context.Context, userID UserID) ([]Session, error)
+	RevokeSession(ctx context.Context, userID UserID, sessionID string) error
+	VerificationStatuses(ctx context.Context, ids []UserID) ([]VerificationStatus, error)
+	UserCountsByStatus(ctx context.Context) (map[UserStatus]int64, error)
 }
 
 // RegisterUserInput encapsula datos de registro.
@@ -24,6 +29,7 @@ type RegisterUserInput struct {
 	Email    string
 	Password string
 	FullName string
+	Username string // opcional; requiere UsernameLogin
 }
 
 // VerifyUserInput contiene datos del desafio de verificacion.
@@ -40,14 +46,23 @@ type BlockUserInput struct {
 }
 
 // LoginInput contiene credenciales para autenticacion.
+// Identifier acepta email o username (este ultimo solo con UsernameLogin); si viene
+// vacio se usa Email. UserAgent e IP son opcionales y solo etiquetan la sesion emitida.
 type LoginInput struct {
-	Email    string
-	Password string
+	Email      string
+	Identifier string
+	Password   string
+	UserAgent  string
+	IP         string
+	// RememberMe pide un refresh token con RememberMeTTL en lugar de SessionTTL.
+	RememberMe bool
 }
 
 // AuthToken contiene el token emitido tras autenticacion.
+// RefreshToken solo se completa cuando hay un SessionRepo configurado.
 type AuthToken struct {
-	Token string
+	Token        string
+	RefreshToken string
 }
 
 // UpdateUserInput contiene campos editables e info del actor.
@@ -79,6 +94,18 @@ type ServiceDeps struct {
 	VerificationSender       VerificationSender
 	VerificationCodeProvider VerificationCodeGenerator
 	TokenProvider            TokenProvider
+	SessionRepo              SessionRepository // opcional; sin el no se emiten refresh tokens
+	SessionTTL               time.Duration     // cero usa DefaultSessionTTL
+	// RememberMeTTL es la vigencia del refresh token cuando el login pide RememberMe;
+	// se acota a MaxRememberMeTTL y si no supera SessionTTL la opcion no tiene efecto.
+	RememberMeTTL time.Duration
+	// DeviceRepo y LoginNotifier habilitan el aviso de login desde dispositivo nuevo; ambos opcionales.
+	DeviceRepo    KnownDeviceRepository
+	LoginNotifier LoginNotifier
+	// UsernameLogin permite registrar username y usarlo para login; apagado solo acepta email.
+	UsernameLogin bool
+	// RequireVerificationSender rechaza registros que no podrian recibir su codigo.
+	RequireVerificationSender bool
 }
 
 type service struct {
@@ -88,8 +115,17 @@ type service struct {
 const dummyPasswordHash = "$2a$10$7EqJtq98hPqEX7fNZaFWoOHi4bxmC8lzQju0aDY9.6e2cqE8X4Fi."
 const minPasswordLength = 8
 
+// MaxVerificationStatusIDs acota la consulta en lote de estados de verificacion.
+const MaxVerificationStatusIDs = 100
+
 // NewService construye el servicio de identidad con dependencias inyectadas.
 func NewService(deps ServiceDeps) Service {
+	if deps.SessionTTL <= 0 {
+		deps.SessionTTL = DefaultSessionTTL
+	}
+	if deps.RememberMeTTL > MaxRememberMeTTL {
+		deps.RememberMeTTL = MaxRememberMeTTL
+	}
 	return &service{deps: deps}
 }
 
@@ -97,6 +133,9 @@ func (s *service) register(ctx context.Context, input RegisterUserInput, role Ro
 	if s.deps.PasswordHasher == nil || s.deps.RoleRepo == nil {
 		return User{}, ErrNotImplemented
 	}
+	if s.deps.RequireVerificationSender && (s.deps.VerificationSender == nil || s.deps.VerificationCodeProvider == nil) {
+		return User{}, ErrVerificationSenderNotSet
+	}
 	if input.Email == "" || input.Password == "" || input.FullName == "" {
 		return User{}, ErrInvalidCredentials
 	}
@@ -109,6 +148,10 @@ func (s *service) register(ctx context.Context, input RegisterUserInput, role Ro
 	if _, err := s.deps.UserRepo.GetByEmail(ctx, input.Email); err == nil {
 		return User{}, ErrEmailAlreadyRegistered
 	}
+	username, err := s.registrationUsername(ctx, input.Username)
+	if err != nil {
+		return User{}, err
+	}
 	hashed, err := s.deps.PasswordHasher.Hash(input.Password)
 	if err != nil {
 		return User{}, err
@@ -118,6 +161,7 @@ func (s *service) register(ctx context.Context, input RegisterUserInput, role Ro
 	}
 	user := User{
 		Email:        input.Email,
+		Username:     username,
 		FullName:     input.FullName,
 		PasswordHash: hashed,
 		Role:         role,
@@ -158,6 +202,25 @@ func (s *service) register(ctx context.Context, input RegisterUserInput, role Ro
 	return created, nil
 }
 
+// registrationUsername valida el username opcional del registro y descarta los ya usados.
+// El indice unico de la base cubre la carrera entre dos registros simultaneos.
+func (s *service) registrationUsername(ctx context.Context, raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	if !s.deps.UsernameLogin {
+		return "", ErrUsernamesDisabled
+	}
+	username, err := NormalizeUsername(raw)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.deps.UserRepo.GetByIdentifier(ctx, username); err == nil {
+		return "", ErrUsernameTaken
+	}
+	return username, nil
+}
+
 func (s *service) seedAdmin(ctx context.Context, seed AdminSeedInput) error {
 	if seed.Email == "" || seed.Password == "" {
 		return nil
@@ -248,7 +311,7 @@ func (s *service) Login(ctx context.Context, input LoginInput) (AuthToken, error
 	if s.deps.PasswordHasher == nil || s.deps.TokenProvider == nil {
 		return AuthToken{}, ErrNotImplemented
 	}
-	user, err := s.deps.UserRepo.GetByEmail(ctx, input.Email)
+	user, err := s.findLoginUser(ctx, input)
 	if err != nil {
 		s.consumePasswordHash(input.Password)
 		if errors.Is(err, ErrUserNotFound) {
@@ -266,7 +329,150 @@ func (s *service) Login(ctx context.Context, input LoginInput) (AuthToken, error
 	if err != nil {
 		return AuthToken{}, err
 	}
-	return AuthToken{Token: token}, nil
+	refresh, err := s.startSession(ctx, user, input)
+	if err != nil {
+		return AuthToken{}, err
+	}
+	s.notifyNewDevice(ctx, user, input)
+	return AuthToken{Token: token, RefreshToken: refresh}, nil
+}
+
+// findLoginUser resuelve el usuario del login; sin UsernameLogin el identificador
+// se trata siempre como email.
+func (s *service) findLoginUser(ctx context.Context, input LoginInput) (User, error) {
+	identifier := input.Identifier
+	if identifier == "" {
+		identifier = input.Email
+	}
+	if s.deps.UsernameLogin {
+		return s.deps.UserRepo.GetByIdentifier(ctx, identifier)
+	}
+	return s.deps.UserRepo.GetByEmail(ctx, identifier)
+}
+
+// notifyNewDevice envia un aviso solo la primera vez que el usuario entra desde un
+// dispositivo/IP. Es best-effort: un fallo no debe impedir el login.
+func (s *service) notifyNewDevice(ctx context.Context, user User, input LoginInput) {
+	if s.deps.DeviceRepo == nil || s.deps.LoginNotifier == nil {
+		return
+	}
+	if input.UserAgent == "" && input.IP == "" {
+		return
+	}
+	isNew, err := s.deps.DeviceRepo.RememberDevice(ctx, user.ID, deviceFingerprint(input.UserAgent, input.IP))
+	if err != nil || !isNew {
+		return
+	}
+	_ = s.deps.LoginNotifier.SendNewDeviceLogin(ctx, user.Email, input.UserAgent, input.IP)
+}
+
+// startSession registra un refresh token para el usuario; sin SessionRepo no emite ninguno.
+func (s *service) startSession(ctx context.Context, user User, input LoginInput) (string, error) {
+	if s.deps.SessionRepo == nil {
+		return "", nil
+	}
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if _, err := s.deps.SessionRepo.CreateSession(ctx, Session{
+		UserID:     user.ID,
+		UserAgent:  input.UserAgent,
+		IP:         input.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.sessionTTL(input)),
+	}, hash); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// sessionTTL elige la vigencia del refresh token segun RememberMe.
+func (s *service) sessionTTL(input LoginInput) time.Duration {
+	if input.RememberMe && s.deps.RememberMeTTL > s.deps.SessionTTL {
+		return s.deps.RememberMeTTL
+	}
+	return s.deps.SessionTTL
+}
+
+// RefreshSession emite un nuevo access token a partir de un refresh token vigente.
+func (s *service) RefreshSession(ctx context.Context, refreshToken string) (AuthToken, error) {
+	if s.deps.SessionRepo == nil || s.deps.UserRepo == nil {
+		return AuthToken{}, ErrRepositoryNotConfigured
+	}
+	if s.deps.TokenProvider == nil {
+		return AuthToken{}, ErrNotImplemented
+	}
+	if refreshToken == "" {
+		return AuthToken{}, ErrInvalidRefreshToken
+	}
+	now := time.Now()
+	session, err := s.deps.SessionRepo.GetSessionByTokenHash(ctx, hashRefreshToken(refreshToken), now)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return AuthToken{}, ErrInvalidRefreshToken
+		}
+		return AuthToken{}, err
+	}
+	user, err := s.deps.UserRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		return AuthToken{}, err
+	}
+	if user.Status == UserStatusBlocked {
+		return AuthToken{}, ErrInvalidRefreshToken
+	}
+	token, err := s.deps.TokenProvider.Generate(ctx, user)
+	if err != nil {
+		return AuthToken{}, err
+	}
+	if err := s.deps.SessionRepo.TouchSession(ctx, session.ID, now); err != nil {
+		return AuthToken{}, err
+	}
+	return AuthToken{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// ListSessions devuelve las sesiones vigentes del usuario.
+func (s *service) ListSessions(ctx context.Context, userID UserID) ([]Session, error) {
+	if s.deps.SessionRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	return s.deps.SessionRepo.ListActiveSessions(ctx, userID, time.Now())
+}
+
+// RevokeSession invalida una sesion propia; una sesion de otro usuario se reporta como inexistente.
+func (s *service) RevokeSession(ctx context.Context, userID UserID, sessionID string) error {
+	if s.deps.SessionRepo == nil {
+		return ErrRepositoryNotConfigured
+	}
+	if sessionID == "" {
+		return ErrSessionNotFound
+	}
+	return s.deps.SessionRepo.RevokeSession(ctx, userID, sessionID)
+}
+
+// VerificationStatuses consulta en lote el estado de verificacion; ids repetidos cuentan una vez.
+func (s *service) VerificationStatuses(ctx context.Context, ids []UserID) ([]VerificationStatus, error) {
+	if s.deps.UserRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	seen := make(map[UserID]struct{}, len(ids))
+	unique := make([]UserID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	if len(unique) > MaxVerificationStatusIDs {
+		return nil, ErrTooManyUserIDs
+	}
+	if len(unique) == 0 {
+		return []VerificationStatus{}, nil
+	}
+	return s.deps.UserRepo.GetVerificationStatuses(ctx, unique)
 }
 
 func (s *service) consumePasswordHash(password string) {
@@ -322,3 +528,11 @@ func (s *service) UpdateUserRole(ctx context.Context, input UpdateUserRoleInput)
 	}
 	return s.deps.UserRepo.GetByID(ctx, input.UserID)
 }
+
+// UserCountsByStatus devuelve cuantos usuarios hay en cada estado.
+func (s *service) UserCountsByStatus(ctx context.Context) (map[UserStatus]int64, error) {
+	if s.deps.UserRepo == nil {
+		return nil, ErrRepositoryNotConfigured
+	}
+	return s.deps.UserRepo.CountUsersByStatus(ctx)
+}
@@ -3,6 +3,9 @@ package identity
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -16,9 +19,18 @@ func (stubUserRepo) CreateUser(ctx context.Context, user User) (User, error) { r
 func (stubUserRepo) GetByEmail(ctx context.Context, email string) (User, error) {
 	return User{}, ErrUserNotFound
 }
+func (stubUserRepo) GetByIdentifier(ctx context.Context, identifier string) (User, error) {
+	return User{}, ErrUserNotFound
+}
 func (stubUserRepo) GetByID(ctx context.Context, id UserID) (User, error) {
 	return User{ID: id, Email: string(id)}, nil
 }
+func (stubUserRepo) GetVerificationStatuses(ctx context.Context, ids []UserID) ([]VerificationStatus, error) {
+	return nil, nil
+}
+func (stubUserRepo) CountUsersByStatus(ctx context.Context) (map[UserStatus]int64, error) {
+	return map[UserStatus]int64{}, nil
+}
 func (stubUserRepo) SetVerification(ctx context.Context, userID UserID, verified bool) error {
 	return nil
 }
@@ -87,6 +99,25 @@ func TestRegister_EnforcesPasswordPolicy(t *testing.T) {
 	}
 }
 
+func TestRegister_RequiredSenderMissing(t *testing.T) {
+	svc := NewService(ServiceDeps{
+		UserRepo:                  stubUserRepo{},
+		RoleRepo:                  stubUserRepo{},
+		PasswordHasher:            stubHasher{},
+		RequireVerificationSender: true,
+	})
+	input := RegisterUserInput{Email: "a@b.c", Password: "Strong123!", FullName: "Test"}
+	if _, err := svc.RegisterClient(context.Background(), input); err != ErrVerificationSenderNotSet {
+		t.Fatalf("expected ErrVerificationSenderNotSet, got %v", err)
+	}
+
+	// sin el flag se mantiene el registro sin envio de codigo (modo desarrollo).
+	svc = NewService(ServiceDeps{UserRepo: stubUserRepo{}, RoleRepo: stubUserRepo{}, PasswordHasher: stubHasher{}})
+	if _, err := svc.RegisterClient(context.Background(), input); err != nil {
+		t.Fatalf("unexpected error without the flag: %v", err)
+	}
+}
+
 type loginRepo struct {
 	stubUserRepo
 	user User
@@ -160,6 +191,105 @@ func TestLogin_Success(t *testing.T) {
 	}
 }
 
+// identifierRepo resuelve por email o username como el repo de Postgres.
+type identifierRepo struct {
+	stubUserRepo
+	user User
+}
+
+func (r identifierRepo) GetByEmail(ctx context.Context, email string) (User, error) {
+	if email == r.user.Email {
+		return r.user, nil
+	}
+	return User{}, ErrUserNotFound
+}
+
+func (r identifierRepo) GetByIdentifier(ctx context.Context, identifier string) (User, error) {
+	if identifier == r.user.Email || strings.EqualFold(identifier, r.user.Username) {
+		return r.user, nil
+	}
+	return User{}, ErrUserNotFound
+}
+
+func TestLogin_ByUsernameOrEmail(t *testing.T) {
+	repo := identifierRepo{user: User{
+		ID:           "u1",
+		Email:        "ana@example.com",
+		Username:     "ana",
+		PasswordHash: "hash",
+		Status:       UserStatusActive,
+		IsVerified:   true,
+	}}
+	cases := []struct {
+		name    string
+		enabled bool
+		input   LoginInput
+		wantErr error
+	}{
+		{name: "username", enabled: true, input: LoginInput{Identifier: "Ana", Password: "secret"}},
+		{name: "email as identifier", enabled: true, input: LoginInput{Identifier: "ana@example.com", Password: "secret"}},
+		{name: "email field", enabled: true, input: LoginInput{Email: "ana@example.com", Password: "secret"}},
+		{name: "email with usernames disabled", input: LoginInput{Identifier: "ana@example.com", Password: "secret"}},
+		{name: "username with usernames disabled", input: LoginInput{Identifier: "ana", Password: "secret"}, wantErr: ErrInvalidCredentials},
+		{name: "unknown username", enabled: true, input: LoginInput{Identifier: "ghost", Password: "secret"}, wantErr: ErrInvalidCredentials},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			hasher := &trackingHasher{}
+			svc := NewService(ServiceDeps{
+				UserRepo:       repo,
+				PasswordHasher: hasher,
+				TokenProvider:  stubTokenProvider{token: "tok"},
+				UsernameLogin:  tc.enabled,
+			})
+			_, err := svc.Login(context.Background(), tc.input)
+			if err != tc.wantErr {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+			// la comparacion de hash ocurre siempre para no delatar si el usuario existe.
+			if hasher.compareCount != 1 {
+				t.Fatalf("expected hasher compare once, got %d", hasher.compareCount)
+			}
+		})
+	}
+}
+
+func TestRegister_Username(t *testing.T) {
+	taken := identifierRepo{user: User{Email: "ana@example.com", Username: "ana"}}
+	input := RegisterUserInput{Email: "new@example.com", Password: "Strong123!", FullName: "New"}
+	cases := []struct {
+		name     string
+		enabled  bool
+		username string
+		want     string
+		wantErr  error
+	}{
+		{name: "normalized", enabled: true, username: " Luis.P ", want: "luis.p"},
+		{name: "taken", enabled: true, username: "ANA", wantErr: ErrUsernameTaken},
+		{name: "invalid", enabled: true, username: "a@b", wantErr: ErrInvalidUsername},
+		{name: "disabled", username: "luis", wantErr: ErrUsernamesDisabled},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(ServiceDeps{
+				UserRepo:       taken,
+				RoleRepo:       taken,
+				PasswordHasher: stubHasher{},
+				UsernameLogin:  tc.enabled,
+			})
+			in := input
+			in.Username = tc.username
+			user, err := svc.RegisterClient(context.Background(), in)
+			if err != tc.wantErr {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+			if user.Username != tc.want {
+				t.Fatalf("expected username %q, got %q", tc.want, user.Username)
+			}
+		})
+	}
+}
+
 func TestVerifyUser_RepoRequired(t *testing.T) {
 	svc := NewService(ServiceDeps{})
 	if err := svc.VerifyUser(context.Background(), VerifyUserInput{UserID: "id"}); err != ErrRepositoryNotConfigured {
@@ -246,6 +376,7 @@ type trackingRepo struct {
 	committed  bool
 	rolledBack bool
 	tx         *trackingTx
+	createErr  error
 }
 
 func (t *trackingRepo) BeginTx(ctx context.Context) (UserTx, error) {
@@ -260,6 +391,9 @@ type trackingTx struct {
 }
 
 func (t *trackingTx) CreateUser(ctx context.Context, user User) (User, error) {
+	if t.repo.createErr != nil {
+		return User{}, t.repo.createErr
+	}
 	user.ID = "generated-id"
 	return user, nil
 }
@@ -368,6 +502,26 @@ func TestRegister_UsesVerificationSenderWhenConfigured(t *testing.T) {
 	}
 }
 
+func TestRegisterClient_ConcurrentDuplicateRollsBack(t *testing.T) {
+	// el GetByEmail previo no ve al otro registro; el insert choca con el indice unico.
+	repo := &trackingRepo{createErr: fmt.Errorf("insert user: %w", ErrEmailAlreadyRegistered)}
+	sender := &trackingSender{}
+	svc := NewService(ServiceDeps{
+		UserRepo:                 repo,
+		RoleRepo:                 repo,
+		PasswordHasher:           stubHasher{},
+		VerificationCodeProvider: fixedCodeProvider{code: "123456"},
+		VerificationSender:       sender,
+	})
+	_, err := svc.RegisterClient(context.Background(), RegisterUserInput{Email: "a@b.c", Password: "Secret123!", FullName: "Test"})
+	if !errors.Is(err, ErrEmailAlreadyRegistered) {
+		t.Fatalf("expected ErrEmailAlreadyRegistered, got %v", err)
+	}
+	if repo.committed || !repo.rolledBack || repo.saved || sender.sentTo != "" {
+		t.Fatalf("expected clean rollback without code, got committed=%v rolledBack=%v saved=%v sent=%q", repo.committed, repo.rolledBack, repo.saved, sender.sentTo)
+	}
+}
+
 func TestBlockUser_RepoRequired(t *testing.T) {
 	svc := NewService(ServiceDeps{})
 	if err := svc.BlockUser(context.Background(), BlockUserInput{UserID: "id"}); err != ErrRepositoryNotConfigured {
@@ -434,3 +588,75 @@ func (f *flakySender) SendVerification(ctx context.Context, email, code string)
 	f.sentCode = code
 	return nil
 }
+
+type seedRepo struct {
+	stubUserRepo
+	users   map[string]User
+	created int
+}
+
+func (r *seedRepo) GetByEmail(ctx context.Context, email string) (User, error) {
+	if u, ok := r.users[email]; ok {
+		return u, nil
+	}
+	return User{}, ErrUserNotFound
+}
+
+func (r *seedRepo) CreateUser(ctx context.Context, user User) (User, error) {
+	r.created++
+	r.users[user.Email] = user
+	return user, nil
+}
+
+func TestSeedAdmin_SeedsMultipleAdminsIdempotently(t *testing.T) {
+	repo := &seedRepo{users: map[string]User{}}
+	svc := NewService(ServiceDeps{UserRepo: repo, RoleRepo: repo, PasswordHasher: stubHasher{}})
+	seeds := []AdminSeedInput{
+		{Email: "a@example.com", Password: "secret1", FullName: "Alice"},
+		{Email: "b@example.com", Password: "secret2", FullName: "Bob"},
+	}
+	for round := 0; round < 2; round++ {
+		for _, seed := range seeds {
+			if err := svc.SeedAdmin(context.Background(), seed); err != nil {
+				t.Fatalf("round %d: unexpected error seeding %s: %v", round, seed.Email, err)
+			}
+		}
+	}
+	if len(repo.users) != 2 || repo.created != 2 {
+		t.Fatalf("expected two admins created once each, got %d creates for %+v", repo.created, repo.users)
+	}
+	for _, seed := range seeds {
+		if u := repo.users[seed.Email]; u.Role != RoleAdmin || !u.IsVerified {
+			t.Fatalf("expected verified admin for %s, got %+v", seed.Email, u)
+		}
+	}
+}
+
+type statusRepo struct {
+	stubUserRepo
+	got []UserID
+}
+
+func (r *statusRepo) GetVerificationStatuses(ctx context.Context, ids []UserID) ([]VerificationStatus, error) {
+	r.got = ids
+	return []VerificationStatus{{UserID: ids[0], IsVerified: true, Status: UserStatusActive}}, nil
+}
+
+func TestVerificationStatuses_DedupesAndCaps(t *testing.T) {
+	repo := &statusRepo{}
+	svc := NewService(ServiceDeps{UserRepo: repo})
+	if _, err := svc.VerificationStatuses(context.Background(), []UserID{"u1", "u1", "", "u2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.got) != 2 || repo.got[0] != "u1" || repo.got[1] != "u2" {
+		t.Fatalf("expected deduped ids, got %v", repo.got)
+	}
+
+	tooMany := make([]UserID, MaxVerificationStatusIDs+1)
+	for i := range tooMany {
+		tooMany[i] = UserID("u" + strconv.Itoa(i))
+	}
+	if _, err := svc.VerificationStatuses(context.Background(), tooMany); !errors.Is(err, ErrTooManyUserIDs) {
+		t.Fatalf("expected ErrTooManyUserIDs, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// DefaultSessionTTL es la vigencia de un refresh token cuando no se configura otra.
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+// MaxRememberMeTTL acota la sesion extendida de "recordarme".
+const MaxRememberMeTTL = 365 * 24 * time.Hour
+
+// Session representa un refresh token emitido en un login. Nunca guarda el token en claro.
+type Session struct {
+	ID         string
+	UserID     UserID
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+// SessionRepository persiste refresh tokens identificados por el hash del token.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session Session, tokenHash string) (Session, error)
+	GetSessionByTokenHash(ctx context.Context, tokenHash string, now time.Time) (Session, error)
+	TouchSession(ctx context.Context, id string, now time.Time) error
+	ListActiveSessions(ctx context.Context, userID UserID, now time.Time) ([]Session, error)
+	RevokeSession(ctx context.Context, userID UserID, id string) error
+}
+
+// newRefreshToken genera un token opaco y devuelve tambien su hash para persistir.
+func newRefreshToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+// deviceFingerprint resume user-agent e IP en una huella estable.
+func deviceFingerprint(userAgent, ip string) string {
+	sum := sha256.Sum256([]byte(userAgent + "|" + ip))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,290 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type memorySessionRepo struct {
+	sessions map[string]Session
+	hashes   map[string]string // hash -> session id
+	revoked  map[string]bool
+	touched  map[string]time.Time
+}
+
+func newMemorySessionRepo() *memorySessionRepo {
+	return &memorySessionRepo{
+		sessions: map[string]Session{},
+		hashes:   map[string]string{},
+		revoked:  map[string]bool{},
+		touched:  map[string]time.Time{},
+	}
+}
+
+func (r *memorySessionRepo) CreateSession(ctx context.Context, s Session, tokenHash string) (Session, error) {
+	s.ID = fmt.Sprintf("s%d", len(r.sessions)+1)
+	r.sessions[s.ID] = s
+	r.hashes[tokenHash] = s.ID
+	return s, nil
+}
+
+func (r *memorySessionRepo) GetSessionByTokenHash(ctx context.Context, tokenHash string, now time.Time) (Session, error) {
+	id, ok := r.hashes[tokenHash]
+	if !ok || r.revoked[id] || !r.sessions[id].ExpiresAt.After(now) {
+		return Session{}, ErrSessionNotFound
+	}
+	return r.sessions[id], nil
+}
+
+func (r *memorySessionRepo) TouchSession(ctx context.Context, id string, now time.Time) error {
+	r.touched[id] = now
+	return nil
+}
+
+func (r *memorySessionRepo) ListActiveSessions(ctx context.Context, userID UserID, now time.Time) ([]Session, error) {
+	var out []Session
+	for id, s := range r.sessions {
+		if s.UserID == userID && !r.revoked[id] && s.ExpiresAt.After(now) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (r *memorySessionRepo) RevokeSession(ctx context.Context, userID UserID, id string) error {
+	s, ok := r.sessions[id]
+	if !ok || s.UserID != userID || r.revoked[id] {
+		return ErrSessionNotFound
+	}
+	r.revoked[id] = true
+	return nil
+}
+
+func newSessionService(repo *memorySessionRepo) Service {
+	return NewService(ServiceDeps{
+		UserRepo: loginRepo{user: User{
+			ID:           "u1",
+			Email:        "user@example.com",
+			PasswordHash: "hash",
+			Status:       UserStatusActive,
+			IsVerified:   true,
+		}},
+		PasswordHasher: &trackingHasher{},
+		TokenProvider:  stubTokenProvider{token: "tok"},
+		SessionRepo:    repo,
+	})
+}
+
+func TestLogin_IssuesRefreshTokenStoringOnlyHash(t *testing.T) {
+	repo := newMemorySessionRepo()
+	svc := newSessionService(repo)
+
+	token, err := svc.Login(context.Background(), LoginInput{Email: "user@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.RefreshToken == "" {
+		t.Fatalf("expected refresh token to be issued")
+	}
+	if _, raw := repo.hashes[token.RefreshToken]; raw {
+		t.Fatalf("refresh token must not be stored in clear")
+	}
+	if _, ok := repo.hashes[hashRefreshToken(token.RefreshToken)]; !ok {
+		t.Fatalf("expected token hash to be stored")
+	}
+}
+
+func TestLogin_PersistsUserAgentAndIP(t *testing.T) {
+	repo := newMemorySessionRepo()
+	svc := newSessionService(repo)
+
+	token, err := svc.Login(context.Background(), LoginInput{
+		Email:     "user@example.com",
+		Password:  "secret",
+		UserAgent: "Mozilla/5.0",
+		IP:        "203.0.113.7",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored := repo.sessions[repo.hashes[hashRefreshToken(token.RefreshToken)]]
+	if stored.UserAgent != "Mozilla/5.0" || stored.IP != "203.0.113.7" {
+		t.Fatalf("expected device data to be persisted, got %+v", stored)
+	}
+}
+
+func TestLogin_RememberMeExtendsSessionTTL(t *testing.T) {
+	newService := func(repo *memorySessionRepo, rememberMe time.Duration) Service {
+		return NewService(ServiceDeps{
+			UserRepo: loginRepo{user: User{
+				ID:           "u1",
+				Email:        "user@example.com",
+				PasswordHash: "hash",
+				Status:       UserStatusActive,
+				IsVerified:   true,
+			}},
+			PasswordHasher: &trackingHasher{},
+			TokenProvider:  stubTokenProvider{token: "tok"},
+			SessionRepo:    repo,
+			SessionTTL:     24 * time.Hour,
+			RememberMeTTL:  rememberMe,
+		})
+	}
+	cases := []struct {
+		name       string
+		rememberMe bool
+		configured time.Duration
+		want       time.Duration
+	}{
+		{name: "standard", rememberMe: false, configured: 90 * 24 * time.Hour, want: 24 * time.Hour},
+		{name: "extended", rememberMe: true, configured: 90 * 24 * time.Hour, want: 90 * 24 * time.Hour},
+		{name: "disabled", rememberMe: true, configured: 0, want: 24 * time.Hour},
+		{name: "capped", rememberMe: true, configured: 10 * MaxRememberMeTTL, want: MaxRememberMeTTL},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newMemorySessionRepo()
+			svc := newService(repo, tc.configured)
+
+			before := time.Now().UTC()
+			token, err := svc.Login(context.Background(), LoginInput{
+				Email:      "user@example.com",
+				Password:   "secret",
+				RememberMe: tc.rememberMe,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			stored := repo.sessions[repo.hashes[hashRefreshToken(token.RefreshToken)]]
+			ttl := stored.ExpiresAt.Sub(before)
+			if ttl < tc.want || ttl > tc.want+time.Minute {
+				t.Fatalf("expected session ttl ~%s, got %s", tc.want, ttl)
+			}
+		})
+	}
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemorySessionRepo()
+	svc := newSessionService(repo)
+
+	token, err := svc.Login(ctx, LoginInput{Email: "user@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Login(ctx, LoginInput{Email: "user@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sessions, err := svc.ListSessions(ctx, "u1")
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d err=%v", len(sessions), err)
+	}
+
+	id := repo.hashes[hashRefreshToken(token.RefreshToken)]
+	if err := svc.RevokeSession(ctx, "other-user", id); err != ErrSessionNotFound {
+		t.Fatalf("expected ErrSessionNotFound revoking another user's session, got %v", err)
+	}
+	if err := svc.RevokeSession(ctx, "u1", id); err != nil {
+		t.Fatalf("unexpected error revoking: %v", err)
+	}
+	sessions, _ = svc.ListSessions(ctx, "u1")
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session after revoke, got %d", len(sessions))
+	}
+	if _, err := svc.RefreshSession(ctx, token.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Fatalf("expected revoked token to be rejected, got %v", err)
+	}
+}
+
+func TestRefreshSession_IssuesTokenAndTouchesSession(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemorySessionRepo()
+	svc := newSessionService(repo)
+
+	login, err := svc.Login(ctx, LoginInput{Email: "user@example.com", Password: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	refreshed, err := svc.RefreshSession(ctx, login.RefreshToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refreshed.Token != "tok" {
+		t.Fatalf("expected access token, got %q", refreshed.Token)
+	}
+	if len(repo.touched) != 1 {
+		t.Fatalf("expected session last-used to be updated")
+	}
+	if _, err := svc.RefreshSession(ctx, "unknown"); err != ErrInvalidRefreshToken {
+		t.Fatalf("expected ErrInvalidRefreshToken, got %v", err)
+	}
+}
+
+type memoryDeviceRepo struct {
+	seen map[string]bool
+}
+
+func (r *memoryDeviceRepo) RememberDevice(ctx context.Context, userID UserID, fingerprint string) (bool, error) {
+	key := string(userID) + "/" + fingerprint
+	if r.seen[key] {
+		return false, nil
+	}
+	r.seen[key] = true
+	return true, nil
+}
+
+type recordingNotifier struct {
+	calls []string
+}
+
+func (n *recordingNotifier) SendNewDeviceLogin(ctx context.Context, email, userAgent, ip string) error {
+	n.calls = append(n.calls, userAgent+"|"+ip)
+	return nil
+}
+
+func TestLogin_NotifiesOnlyOnNewDevice(t *testing.T) {
+	ctx := context.Background()
+	notifier := &recordingNotifier{}
+	svc := NewService(ServiceDeps{
+		UserRepo: loginRepo{user: User{
+			ID:           "u1",
+			Email:        "user@example.com",
+			PasswordHash: "hash",
+			Status:       UserStatusActive,
+			IsVerified:   true,
+		}},
+		PasswordHasher: &trackingHasher{},
+		TokenProvider:  stubTokenProvider{token: "tok"},
+		DeviceRepo:     &memoryDeviceRepo{seen: map[string]bool{}},
+		LoginNotifier:  notifier,
+	})
+
+	laptop := LoginInput{Email: "user@example.com", Password: "secret", UserAgent: "Firefox", IP: "203.0.113.7"}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Login(ctx, laptop); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(notifier.calls) != 1 {
+		t.Fatalf("expected one notification for first login from device, got %d", len(notifier.calls))
+	}
+
+	phone := LoginInput{Email: "user@example.com", Password: "secret", UserAgent: "Safari iOS", IP: "198.51.100.2"}
+	if _, err := svc.Login(ctx, phone); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifier.calls) != 2 || notifier.calls[1] != "Safari iOS|198.51.100.2" {
+		t.Fatalf("expected notification for new device, got %v", notifier.calls)
+	}
+
+	if _, err := svc.Login(ctx, LoginInput{Email: "user@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifier.calls) != 2 {
+		t.Fatalf("logins without device data must not notify, got %v", notifier.calls)
+	}
+}
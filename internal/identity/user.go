@@ -18,6 +18,7 @@ const (
 type User struct {
 	ID           UserID
 	Email        string
+	Username     string // opcional; solo se usa con USERNAME_LOGIN
 	FullName     string
 	PasswordHash string
 	Role         RoleName
@@ -26,3 +27,10 @@ type User struct {
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
+
+// VerificationStatus es la vista reducida de un usuario para tableros de soporte.
+type VerificationStatus struct {
+	UserID     UserID
+	IsVerified bool
+	Status     UserStatus
+}
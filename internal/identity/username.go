@@ -0,0 +1,25 @@
+package identity
+
+import "strings"
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 32
+)
+
+// NormalizeUsername recorta y pasa a minusculas; devuelve ErrInvalidUsername si no
+// cumple 3-32 caracteres [a-z0-9._-]. Excluir '@' evita ambiguedad con un email.
+func NormalizeUsername(raw string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	if len(name) < minUsernameLength || len(name) > maxUsernameLength {
+		return "", ErrInvalidUsername
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return "", ErrInvalidUsername
+		}
+	}
+	return name, nil
+}
@@ -0,0 +1,721 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"catalog-api/internal/catalog"
+)
+
+// CatalogRepository implementa los repositorios de catalogo en memoria.
+// Pensado para desarrollo local y tests; no persiste entre reinicios.
+type CatalogRepository struct {
+	mu                sync.RWMutex
+	categories        map[string]catalog.Category
+	products          map[string]catalog.Product
+	deletedProducts   map[string]catalog.Product // soft-delete: fuera de lecturas, restaurables
+	history           map[string][]catalog.ProductHistory
+	productCategories map[string]map[string]struct{}
+	productSlugs      map[string]string // slugs anteriores -> product ID
+	priceChanges      map[string]catalog.PriceChangeRequest
+}
+
+// NewCatalogRepository construye un repo de catalogo vacio en memoria.
+func NewCatalogRepository() *CatalogRepository {
+	return &CatalogRepository{
+		categories:        make(map[string]catalog.Category),
+		products:          make(map[string]catalog.Product),
+		deletedProducts:   make(map[string]catalog.Product),
+		history:           make(map[string][]catalog.ProductHistory),
+		productCategories: make(map[string]map[string]struct{}),
+		productSlugs:      make(map[string]string),
+		priceChanges:      make(map[string]catalog.PriceChangeRequest),
+	}
+}
+
+// ListCategories devuelve las categorias ordenadas por nombre, paginando solo si se pide.
+func (r *CatalogRepository) ListCategories(ctx context.Context, filter catalog.CategoryFilter) ([]catalog.Category, error) {
+	items := r.sortedCategories()
+	// primero las ordenadas por un admin, luego el resto por nombre (orden estable).
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].DisplayOrder, items[j].DisplayOrder
+		if (a == 0) != (b == 0) {
+			return b == 0
+		}
+		return a < b
+	})
+	return paginate(items, filter.Limit, filter.Offset), nil
+}
+
+// CountCategories devuelve el total de categorias.
+func (r *CatalogRepository) CountCategories(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.categories)), nil
+}
+
+// CreateCategory inserta una nueva categoria respetando la unicidad del nombre.
+func (r *CatalogRepository) CreateCategory(ctx context.Context, cat catalog.Category) (catalog.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.categoryNameTaken(cat.Name, "") {
+		return catalog.Category{}, catalog.ErrCategoryConflict
+	}
+	now := time.Now()
+	cat.ID = freshID(r.categories)
+	cat.Slug = r.freeCategorySlug(cat.Slug)
+	cat.CreatedAt = now
+	cat.UpdatedAt = now
+	r.categories[cat.ID] = cat
+	return cat, nil
+}
+
+// GetCategoryBySlug busca una categoria por su slug.
+func (r *CatalogRepository) GetCategoryBySlug(ctx context.Context, slug string) (catalog.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.categories {
+		if c.Slug == slug {
+			return c, nil
+		}
+	}
+	return catalog.Category{}, catalog.ErrCategoryNotFound
+}
+
+// BulkCreateCategories valida todo el lote antes de insertar para no dejar altas parciales.
+func (r *CatalogRepository) BulkCreateCategories(ctx context.Context, cats []catalog.Category) ([]catalog.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, cat := range cats {
+		if r.categoryNameTaken(cat.Name, "") {
+			return nil, &catalog.BulkCategoryError{Index: i, Err: catalog.ErrCategoryConflict}
+		}
+	}
+	now := time.Now()
+	out := make([]catalog.Category, 0, len(cats))
+	for _, cat := range cats {
+		cat.ID = freshID(r.categories)
+		cat.Slug = r.freeCategorySlug(cat.Slug)
+		cat.CreatedAt = now
+		cat.UpdatedAt = now
+		r.categories[cat.ID] = cat
+		out = append(out, cat)
+	}
+	return out, nil
+}
+
+// UpdateCategory actualiza nombre/descripcion.
+func (r *CatalogRepository) UpdateCategory(ctx context.Context, cat catalog.Category) (catalog.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.categories[cat.ID]
+	if !ok {
+		return catalog.Category{}, catalog.ErrCategoryNotFound
+	}
+	if r.categoryNameTaken(cat.Name, cat.ID) {
+		return catalog.Category{}, catalog.ErrCategoryConflict
+	}
+	current.Name = cat.Name
+	current.Description = cat.Description
+	current.UpdatedAt = time.Now()
+	r.categories[cat.ID] = current
+	return current, nil
+}
+
+// DeleteCategory elimina una categoria y sus relaciones con productos.
+func (r *CatalogRepository) DeleteCategory(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.categories[id]; !ok {
+		return catalog.ErrCategoryNotFound
+	}
+	delete(r.categories, id)
+	for _, cats := range r.productCategories {
+		delete(cats, id)
+	}
+	return nil
+}
+
+// ReorderCategories asigna rangos 1..n y deja sin orden al resto; valida antes de tocar nada.
+func (r *CatalogRepository) ReorderCategories(ctx context.Context, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := r.categories[id]; !ok {
+			return catalog.ErrCategoryNotFound
+		}
+	}
+	for id, c := range r.categories {
+		c.DisplayOrder = 0
+		r.categories[id] = c
+	}
+	for i, id := range ids {
+		c := r.categories[id]
+		c.DisplayOrder = i + 1
+		r.categories[id] = c
+	}
+	return nil
+}
+
+// CategoryExists indica si existe una categoria con ese ID.
+func (r *CatalogRepository) CategoryExists(ctx context.Context, id string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.categories[id]
+	return ok, nil
+}
+
+// SearchCategories filtra por nombre/descripcion sin distinguir mayusculas.
+func (r *CatalogRepository) SearchCategories(ctx context.Context, filter catalog.SearchFilter) ([]catalog.Category, int64, error) {
+	all := r.sortedCategories()
+	query := strings.ToLower(strings.TrimSpace(filter.Query))
+	matched := make([]catalog.Category, 0, len(all))
+	for _, c := range all {
+		if query == "" || containsFold(c.Name, query) || containsFold(c.Description, query) {
+			if filter.Highlight && query != "" {
+				c.Snippet = highlightMatch(c.Name+" "+c.Description, query)
+			}
+			matched = append(matched, c)
+		}
+	}
+	sortCategories(matched, filter.SortBy, filter.SortDir)
+	return paginate(matched, filter.Limit, filter.Offset), int64(len(matched)), nil
+}
+
+// ListProducts obtiene productos con query de texto opcional y ordenamiento.
+func (r *CatalogRepository) ListProducts(ctx context.Context, filter catalog.ProductFilter) ([]catalog.Product, error) {
+	matched := r.filterProducts(filter)
+	if filter.SortsByRelevance() {
+		sortProductsByRelevance(matched, filter.Query)
+	} else {
+		sortProducts(matched, filter.SortBy, filter.SortDir)
+	}
+	return paginate(matched, filter.Limit, filter.Offset), nil
+}
+
+// CountProducts devuelve el total de productos que cumplen el filtro.
+func (r *CatalogRepository) CountProducts(ctx context.Context, filter catalog.ProductFilter) (int64, error) {
+	return int64(len(r.filterProducts(filter))), nil
+}
+
+// GetProduct obtiene un producto por ID.
+func (r *CatalogRepository) GetProduct(ctx context.Context, id string) (catalog.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.products[id]
+	if !ok {
+		return catalog.Product{}, catalog.ErrProductNotFound
+	}
+	return p, nil
+}
+
+// GetProductDetail obtiene el producto con sus categorias.
+func (r *CatalogRepository) GetProductDetail(ctx context.Context, id string) (catalog.Product, error) {
+	p, err := r.GetProduct(ctx, id)
+	if err != nil {
+		return catalog.Product{}, err
+	}
+	if p.Categories, err = r.ListProductCategories(ctx, id); err != nil {
+		return catalog.Product{}, err
+	}
+	return p, nil
+}
+
+// GetProductBySlug busca por slug actual o por uno anterior del historial.
+func (r *CatalogRepository) GetProductBySlug(ctx context.Context, slug string) (catalog.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.products {
+		if p.Slug == slug {
+			return p, nil
+		}
+	}
+	if id, ok := r.productSlugs[slug]; ok {
+		if p, ok := r.products[id]; ok {
+			return p, nil
+		}
+	}
+	return catalog.Product{}, catalog.ErrProductNotFound
+}
+
+// GetProductByBarcode busca por el EAN-13 normalizado.
+func (r *CatalogRepository) GetProductByBarcode(ctx context.Context, barcode string) (catalog.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.products {
+		if p.Barcode != "" && p.Barcode == barcode {
+			return p, nil
+		}
+	}
+	return catalog.Product{}, catalog.ErrProductNotFound
+}
+
+// barcodeTaken emula el indice unico de barcode; asume el lock tomado.
+func (r *CatalogRepository) barcodeTaken(barcode, exceptID string) bool {
+	if barcode == "" {
+		return false
+	}
+	// como el indice parcial de Postgres, los productos borrados no retienen el barcode.
+	for id, p := range r.products {
+		if id != exceptID && p.Barcode == barcode {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductExists indica si existe un producto con ese ID.
+func (r *CatalogRepository) ProductExists(ctx context.Context, id string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.products[id]
+	return ok, nil
+}
+
+// CreateProduct inserta un nuevo producto.
+func (r *CatalogRepository) CreateProduct(ctx context.Context, p catalog.Product) (catalog.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.barcodeTaken(p.Barcode, "") {
+		return catalog.Product{}, catalog.ErrDuplicateBarcode
+	}
+	now := time.Now()
+	p.ID = freshID(r.products)
+	p.Slug = r.freeProductSlug(p.Slug, "")
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	r.products[p.ID] = p
+	return p, nil
+}
+
+// CreateProducts inserta todo el lote o nada; valida los barcodes antes de escribir.
+func (r *CatalogRepository) CreateProducts(ctx context.Context, products []catalog.Product) ([]catalog.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var taken []catalog.BulkItemError
+	for i, p := range products {
+		if r.barcodeTaken(p.Barcode, "") {
+			taken = append(taken, catalog.BulkItemError{Index: i, Err: catalog.ErrDuplicateBarcode})
+		}
+	}
+	if len(taken) > 0 {
+		return nil, &catalog.BulkProductError{Items: taken}
+	}
+	now := time.Now()
+	out := make([]catalog.Product, 0, len(products))
+	for _, p := range products {
+		p.ID = freshID(r.products)
+		p.Slug = r.freeProductSlug(p.Slug, "")
+		p.CreatedAt = now
+		p.UpdatedAt = now
+		r.products[p.ID] = p
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// UpdateProduct actualiza un producto y registra historial si cambia precio o stock.
+func (r *CatalogRepository) UpdateProduct(ctx context.Context, p catalog.Product) (catalog.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateProduct(p)
+}
+
+// updateProduct asume el lock tomado.
+func (r *CatalogRepository) updateProduct(p catalog.Product) (catalog.Product, error) {
+	original, ok := r.products[p.ID]
+	if !ok {
+		return catalog.Product{}, catalog.ErrProductNotFound
+	}
+	if r.barcodeTaken(p.Barcode, p.ID) {
+		return catalog.Product{}, catalog.ErrDuplicateBarcode
+	}
+	now := time.Now()
+	if p.Slug == "" {
+		p.Slug = original.Slug
+	} else if p.Slug != original.Slug {
+		p.Slug = r.freeProductSlug(p.Slug, p.ID)
+	}
+	if p.Slug != original.Slug {
+		// el slug viejo sigue resolviendo; si el nuevo era uno propio anterior, se libera.
+		r.productSlugs[original.Slug] = p.ID
+		delete(r.productSlugs, p.Slug)
+	}
+	p.CreatedAt = original.CreatedAt
+	p.UpdatedAt = now
+	// como en Postgres, el borrador solo se levanta con PublishProduct.
+	p.Draft = original.Draft
+	r.products[p.ID] = p
+	if original.Price != p.Price || original.Stock != p.Stock {
+		r.history[p.ID] = append(r.history[p.ID], catalog.ProductHistory{
+			ID:        newID(),
+			ProductID: p.ID,
+			Price:     p.Price,
+			Stock:     p.Stock,
+			ChangedAt: now,
+		})
+	}
+	return p, nil
+}
+
+// BulkAdjustStock valida todo el lote antes de escribir para no dejar ajustes parciales.
+func (r *CatalogRepository) BulkAdjustStock(ctx context.Context, adjustments []catalog.StockAdjustment) ([]catalog.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, adj := range adjustments {
+		p, ok := r.products[adj.ProductID]
+		if !ok {
+			return nil, &catalog.BulkStockError{Index: i, ProductID: adj.ProductID, Err: catalog.ErrProductNotFound}
+		}
+		if p.Stock+adj.Delta < 0 {
+			return nil, &catalog.BulkStockError{Index: i, ProductID: adj.ProductID, Err: catalog.ErrInsufficientStock}
+		}
+	}
+	now := time.Now()
+	out := make([]catalog.Product, 0, len(adjustments))
+	for _, adj := range adjustments {
+		p := r.products[adj.ProductID]
+		p.Stock += adj.Delta
+		p.UpdatedAt = now
+		r.products[p.ID] = p
+		r.history[p.ID] = append(r.history[p.ID], catalog.ProductHistory{
+			ID:        newID(),
+			ProductID: p.ID,
+			Price:     p.Price,
+			Stock:     p.Stock,
+			ChangedAt: now,
+		})
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// DeleteProduct hace soft-delete: el producto deja de leerse pero conserva historial y relaciones.
+func (r *CatalogRepository) DeleteProduct(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.products[id]
+	if !ok {
+		return catalog.ErrProductNotFound
+	}
+	p.UpdatedAt = time.Now()
+	r.deletedProducts[id] = p
+	delete(r.products, id)
+	return nil
+}
+
+// RestoreProduct deshace un soft-delete; restored es false si el producto no estaba borrado.
+func (r *CatalogRepository) RestoreProduct(ctx context.Context, id string) (catalog.Product, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.products[id]; ok {
+		return p, false, nil
+	}
+	p, ok := r.deletedProducts[id]
+	if !ok {
+		return catalog.Product{}, false, catalog.ErrProductNotFound
+	}
+	if r.barcodeTaken(p.Barcode, id) {
+		return catalog.Product{}, false, catalog.ErrDuplicateBarcode
+	}
+	p.UpdatedAt = time.Now()
+	r.products[id] = p
+	delete(r.deletedProducts, id)
+	return p, true, nil
+}
+
+// PublishProduct quita la marca de borrador; published es false si ya estaba publicado.
+func (r *CatalogRepository) PublishProduct(ctx context.Context, id string) (catalog.Product, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.products[id]
+	if !ok {
+		return catalog.Product{}, false, catalog.ErrProductNotFound
+	}
+	if !p.Draft {
+		return p, false, nil
+	}
+	p.Draft = false
+	p.UpdatedAt = time.Now()
+	r.products[id] = p
+	return p, true, nil
+}
+
+// GetLatestProductHistory devuelve la ultima entrada; el historial esta en orden cronologico.
+func (r *CatalogRepository) GetLatestProductHistory(ctx context.Context, id string) (catalog.ProductHistory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := r.history[id]
+	if len(entries) == 0 {
+		return catalog.ProductHistory{}, catalog.ErrProductHistoryNotFound
+	}
+	return entries[len(entries)-1], nil
+}
+
+// PruneProductHistory replica la poda de Postgres; el historial se guarda en orden cronologico.
+func (r *CatalogRepository) PruneProductHistory(ctx context.Context, olderThan time.Time, keep int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var removed int64
+	for id, entries := range r.history {
+		cut := len(entries) - keep
+		kept := entries[:0:0]
+		for i, h := range entries {
+			if i < cut && h.ChangedAt.Before(olderThan) {
+				removed++
+				continue
+			}
+			kept = append(kept, h)
+		}
+		r.history[id] = kept
+	}
+	return removed, nil
+}
+
+// ListProductHistory devuelve historial de precio/stock del mas reciente al mas antiguo.
+func (r *CatalogRepository) ListProductHistory(ctx context.Context, id string, filter catalog.ProductHistoryFilter) ([]catalog.ProductHistory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var items []catalog.ProductHistory
+	for _, h := range r.history[id] {
+		if !filter.Start.IsZero() && h.ChangedAt.Before(filter.Start) {
+			continue
+		}
+		if !filter.End.IsZero() && h.ChangedAt.After(filter.End) {
+			continue
+		}
+		items = append(items, h)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].ChangedAt.After(items[j].ChangedAt) })
+	return items, nil
+}
+
+// ListProductCategories devuelve las categorias de un producto ordenadas por nombre.
+func (r *CatalogRepository) ListProductCategories(ctx context.Context, productID string) ([]catalog.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.products[productID]; !ok {
+		return nil, catalog.ErrProductNotFound
+	}
+	items := make([]catalog.Category, 0, len(r.productCategories[productID]))
+	for id := range r.productCategories[productID] {
+		if c, ok := r.categories[id]; ok {
+			items = append(items, c)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items, nil
+}
+
+// AssignProductCategory relaciona un producto con una categoria; es idempotente.
+func (r *CatalogRepository) AssignProductCategory(ctx context.Context, productID, categoryID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.products[productID]; !ok {
+		return catalog.ErrProductNotFound
+	}
+	if _, ok := r.categories[categoryID]; !ok {
+		return catalog.ErrCategoryNotFound
+	}
+	cats, ok := r.productCategories[productID]
+	if !ok {
+		cats = make(map[string]struct{})
+		r.productCategories[productID] = cats
+	}
+	cats[categoryID] = struct{}{}
+	return nil
+}
+
+// UnassignProductCategory quita la relacion; removed es false si no existia.
+func (r *CatalogRepository) UnassignProductCategory(ctx context.Context, productID, categoryID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.productCategories[productID][categoryID]; !ok {
+		return false, nil
+	}
+	delete(r.productCategories[productID], categoryID)
+	return true, nil
+}
+
+func (r *CatalogRepository) sortedCategories() []catalog.Category {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	items := make([]catalog.Category, 0, len(r.categories))
+	for _, c := range r.categories {
+		items = append(items, c)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+func (r *CatalogRepository) categoryNameTaken(name, exceptID string) bool {
+	for id, c := range r.categories {
+		if id != exceptID && c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// freeCategorySlug agrega un sufijo numerico si el slug ya esta en uso.
+func (r *CatalogRepository) freeCategorySlug(base string) string {
+	if base == "" {
+		base = catalog.Slugify("")
+	}
+	taken := make(map[string]struct{}, len(r.categories))
+	for _, c := range r.categories {
+		taken[c.Slug] = struct{}{}
+	}
+	return catalog.NextFreeSlug(base, taken)
+}
+
+// freeProductSlug evita slugs actuales o historicos de otros productos.
+func (r *CatalogRepository) freeProductSlug(base, exceptID string) string {
+	if base == "" {
+		base = catalog.Slugify("")
+	}
+	taken := make(map[string]struct{}, len(r.products)+len(r.productSlugs))
+	for _, set := range []map[string]catalog.Product{r.products, r.deletedProducts} {
+		for id, p := range set {
+			if id != exceptID {
+				taken[p.Slug] = struct{}{}
+			}
+		}
+	}
+	for slug, id := range r.productSlugs {
+		if id != exceptID {
+			taken[slug] = struct{}{}
+		}
+	}
+	return catalog.NextFreeSlug(base, taken)
+}
+
+func (r *CatalogRepository) filterProducts(filter catalog.ProductFilter) []catalog.Product {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	query := strings.ToLower(strings.TrimSpace(filter.Query))
+	items := make([]catalog.Product, 0, len(r.products))
+	for _, p := range r.products {
+		if query != "" && !containsFold(p.Name, query) && !containsFold(p.Description, query) {
+			continue
+		}
+		if !filter.MatchesStock(p.Stock) {
+			continue
+		}
+		if !filter.MatchesPrice(p.Price) {
+			continue
+		}
+		if filter.ExcludeDrafts && p.Draft {
+			continue
+		}
+		if filter.CategoryID != "" {
+			if _, ok := r.productCategories[p.ID][filter.CategoryID]; !ok {
+				continue
+			}
+		}
+		if filter.Highlight && query != "" {
+			p.Snippet = highlightMatch(p.Name+" "+p.Description, query)
+		}
+		items = append(items, p)
+	}
+	return items
+}
+
+// sortProducts replica buildProductOrderClause del repo Postgres.
+func sortProducts(items []catalog.Product, sortBy, sortDir string) {
+	asc := strings.EqualFold(sortDir, catalog.SortAsc)
+	less := func(a, b catalog.Product) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	switch sortBy {
+	case catalog.SortByName:
+		less = func(a, b catalog.Product) bool { return a.Name < b.Name }
+	case catalog.SortByPrice:
+		less = func(a, b catalog.Product) bool { return a.Price < b.Price }
+	case catalog.SortByStock:
+		less = func(a, b catalog.Product) bool { return a.Stock < b.Stock }
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if asc {
+			return less(items[i], items[j])
+		}
+		return less(items[j], items[i])
+	})
+}
+
+// sortProductsByRelevance replica relevanceOrderClause del repo Postgres.
+func sortProductsByRelevance(items []catalog.Product, query string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ri, rj := catalog.NameRelevance(items[i].Name, query), catalog.NameRelevance(items[j].Name, query)
+		if ri != rj {
+			return ri < rj
+		}
+		return items[j].CreatedAt.Before(items[i].CreatedAt)
+	})
+}
+
+// sortCategories replica buildCategoryOrderClause: name o created_at, ASC por defecto.
+func sortCategories(items []catalog.Category, sortBy, sortDir string) {
+	desc := strings.EqualFold(sortDir, catalog.SortDesc)
+	less := func(a, b catalog.Category) bool { return a.Name < b.Name }
+	if sortBy == catalog.SortByCreatedAt {
+		less = func(a, b catalog.Category) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+}
+
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return items[offset:end]
+}
+
+func containsFold(s, lowerQuery string) bool {
+	return strings.Contains(strings.ToLower(s), lowerQuery)
+}
+
+// highlightMatch envuelve la primera coincidencia con <mark>, como ts_headline en Postgres.
+func highlightMatch(text, lowerQuery string) string {
+	idx := strings.Index(strings.ToLower(text), lowerQuery)
+	if idx < 0 {
+		return text
+	}
+	end := idx + len(lowerQuery)
+	return text[:idx] + "<mark>" + text[idx:end] + "</mark>" + text[end:]
+}
+
+// freshID genera ids hasta dar con uno libre en taken: un choque pisaria la entrada
+// existente en lugar de fallar como la clave primaria de Postgres.
+func freshID[V any](taken map[string]V) string {
+	for {
+		id := newID()
+		if _, ok := taken[id]; !ok {
+			return id
+		}
+	}
+}
+
+// newID genera un UUID v4 para mantener el mismo formato que Postgres.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
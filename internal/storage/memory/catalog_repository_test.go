@@ -0,0 +1,749 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"catalog-api/internal/catalog"
+)
+
+func newTestService(t *testing.T) (catalog.Service, *CatalogRepository) {
+	t.Helper()
+	repo := NewCatalogRepository()
+	svc, err := catalog.NewService(catalog.ServiceDeps{CategoryRepo: repo, ProductRepo: repo})
+	if err != nil {
+		t.Fatalf("unexpected error building service: %v", err)
+	}
+	return svc, repo
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestMemoryRepository_CategoryLifecycle(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	books, err := svc.CreateCategory(ctx, catalog.CreateCategoryInput{Name: "Books", Description: "All books"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if books.ID == "" {
+		t.Fatalf("expected generated id")
+	}
+	if _, err := svc.CreateCategory(ctx, catalog.CreateCategoryInput{Name: "Books"}); err == nil {
+		t.Fatalf("expected duplicate name to be rejected")
+	}
+	if _, err := svc.CreateCategory(ctx, catalog.CreateCategoryInput{Name: "Audio"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cats, _, _ := svc.ListCategories(ctx, catalog.CategoryFilter{})
+	if len(cats) != 2 || cats[0].Name != "Audio" {
+		t.Fatalf("expected categories ordered by name, got %+v", cats)
+	}
+
+	updated, err := svc.UpdateCategory(ctx, catalog.UpdateCategoryInput{ID: books.ID, Name: "Novels"})
+	if err != nil || updated.Name != "Novels" {
+		t.Fatalf("unexpected update result %+v err=%v", updated, err)
+	}
+
+	res, err := svc.Search(ctx, catalog.SearchFilter{Kind: "category", Query: "nov"})
+	if err != nil || res.Total != 1 || res.Categories[0].ID != books.ID {
+		t.Fatalf("unexpected search result %+v err=%v", res, err)
+	}
+
+	if err := svc.DeleteCategory(ctx, books.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cats, _, _ = svc.ListCategories(ctx, catalog.CategoryFilter{})
+	if len(cats) != 1 {
+		t.Fatalf("expected one category after delete, got %d", len(cats))
+	}
+}
+
+func TestMemoryRepository_ReorderCategories(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	ids := map[string]string{}
+	for _, name := range []string{"Audio", "Books", "Cameras", "Drones"} {
+		c, err := svc.CreateCategory(ctx, catalog.CreateCategoryInput{Name: name})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids[name] = c.ID
+	}
+
+	if err := svc.ReorderCategories(ctx, []string{ids["Cameras"], ids["Audio"]}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cats, _, _ := svc.ListCategories(ctx, catalog.CategoryFilter{})
+	var got []string
+	for _, c := range cats {
+		got = append(got, c.Name)
+	}
+	if want := "Cameras,Audio,Books,Drones"; strings.Join(got, ",") != want {
+		t.Fatalf("expected %s, got %v", want, got)
+	}
+
+	if err := svc.ReorderCategories(ctx, []string{ids["Books"], "missing"}); !errors.Is(err, catalog.ErrCategoryNotFound) {
+		t.Fatalf("expected ErrCategoryNotFound, got %v", err)
+	}
+	cats, _, _ = svc.ListCategories(ctx, catalog.CategoryFilter{})
+	if cats[0].Name != "Cameras" || cats[0].DisplayOrder != 1 {
+		t.Fatalf("failed reorder must not change ranks, got %+v", cats[0])
+	}
+}
+
+func TestMemoryRepository_CategorySlugsAreSuffixedOnCollision(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	names := []string{"Libros Usados", "Libros usados!", "libros-usados"}
+	want := []string{"libros-usados", "libros-usados-2", "libros-usados-3"}
+	for i, name := range names {
+		cat, err := svc.CreateCategory(ctx, catalog.CreateCategoryInput{Name: name})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cat.Slug != want[i] {
+			t.Fatalf("expected slug %q for %q, got %q", want[i], name, cat.Slug)
+		}
+	}
+
+	got, err := svc.GetCategoryBySlug(ctx, "libros-usados-2")
+	if err != nil || got.Name != "Libros usados!" {
+		t.Fatalf("unexpected lookup result %+v err=%v", got, err)
+	}
+	if _, err := svc.GetCategoryBySlug(ctx, "missing"); !errors.Is(err, catalog.ErrCategoryNotFound) {
+		t.Fatalf("expected ErrCategoryNotFound, got %v", err)
+	}
+}
+
+func TestMemoryRepository_RenamedProductResolvesOldSlug(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	pen, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(100)), Stock: ptr(int64(1))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Fountain Pen", Price: ptr(int64(100)), Stock: ptr(int64(1))})
+	if err != nil || other.Slug != "fountain-pen" {
+		t.Fatalf("unexpected product %+v err=%v", other, err)
+	}
+
+	renamed, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: pen.ID, Name: ptr("Fountain Pen")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renamed.Slug != "fountain-pen-2" {
+		t.Fatalf("expected suffixed slug, got %q", renamed.Slug)
+	}
+
+	// el slug viejo resuelve al producto con su slug canonico nuevo.
+	got, err := svc.GetProductBySlug(ctx, "pen")
+	if err != nil || got.ID != pen.ID || got.Slug != "fountain-pen-2" {
+		t.Fatalf("unexpected lookup %+v err=%v", got, err)
+	}
+
+	// un producto nuevo no puede tomar un slug historico.
+	fresh, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(100)), Stock: ptr(int64(1))})
+	if err != nil || fresh.Slug != "pen-2" {
+		t.Fatalf("expected pen-2 for new product, got %+v err=%v", fresh, err)
+	}
+
+	// volver al nombre original recupera el slug propio.
+	back, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: pen.ID, Name: ptr("Pen")})
+	if err != nil || back.Slug != "pen" {
+		t.Fatalf("expected own old slug to be reused, got %+v err=%v", back, err)
+	}
+	got, err = svc.GetProductBySlug(ctx, "fountain-pen-2")
+	if err != nil || got.ID != pen.ID || got.Slug != "pen" {
+		t.Fatalf("unexpected lookup %+v err=%v", got, err)
+	}
+}
+
+func TestMemoryRepository_ListProductsFiltersSortsAndPaginates(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	for _, in := range []catalog.CreateProductInput{
+		{Name: "Pen", Description: "Blue ink", Price: ptr(int64(300)), Stock: ptr(int64(1))},
+		{Name: "Pencil", Description: "HB", Price: ptr(int64(100)), Stock: ptr(int64(2))},
+		{Name: "Notebook", Description: "Lined", Price: ptr(int64(200)), Stock: ptr(int64(3))},
+	} {
+		if _, err := svc.CreateProduct(ctx, in); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	items, total, err := svc.ListProducts(ctx, catalog.ProductFilter{Query: "pen", SortBy: "price", SortDir: "asc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 2 || len(items) != 2 || items[0].Name != "Pencil" {
+		t.Fatalf("unexpected filtered list total=%d items=%+v", total, items)
+	}
+
+	items, total, _ = svc.ListProducts(ctx, catalog.ProductFilter{SortBy: "name", SortDir: "asc", Limit: 1, Offset: 1})
+	if total != 3 || len(items) != 1 || items[0].Name != "Pen" {
+		t.Fatalf("unexpected page total=%d items=%+v", total, items)
+	}
+}
+
+func TestMemoryRepository_ListProductsOrdersQueryByRelevance(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	// se crean del menos al mas relevante: con created_at DESC el exacto quedaria ultimo.
+	for _, in := range []catalog.CreateProductInput{
+		{Name: "Notebook", Description: "Comes with a pen"},
+		{Name: "Blue pen"},
+		{Name: "Pencil"},
+		{Name: "Pen"},
+	} {
+		if _, err := svc.CreateProduct(ctx, in); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	items, _, err := svc.ListProducts(ctx, catalog.ProductFilter{Query: "PEN"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, p := range items {
+		names = append(names, p.Name)
+	}
+	if want := []string{"Pen", "Pencil", "Blue pen", "Notebook"}; !slices.Equal(names, want) {
+		t.Fatalf("expected relevance order %v, got %v", want, names)
+	}
+
+	items, _, _ = svc.ListProducts(ctx, catalog.ProductFilter{Query: "pen", SortBy: catalog.SortByName, SortDir: catalog.SortAsc})
+	if items[0].Name != "Blue pen" {
+		t.Fatalf("explicit sort must win over relevance, got %q first", items[0].Name)
+	}
+}
+
+func TestMemoryRepository_ListProductsStockFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCatalogRepository()
+	svc, err := catalog.NewService(catalog.ServiceDeps{CategoryRepo: repo, ProductRepo: repo, LowStockThreshold: 3})
+	if err != nil {
+		t.Fatalf("unexpected error building service: %v", err)
+	}
+	for _, in := range []catalog.CreateProductInput{
+		{Name: "Pen", Price: ptr(int64(300)), Stock: ptr(int64(0))},
+		{Name: "Pencil", Price: ptr(int64(100)), Stock: ptr(int64(2))},
+		{Name: "Notebook", Price: ptr(int64(200)), Stock: ptr(int64(10))},
+	} {
+		if _, err := svc.CreateProduct(ctx, in); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cases := []struct {
+		stock catalog.StockFilter
+		want  []string
+	}{
+		{stock: catalog.StockAny, want: []string{"Notebook", "Pen", "Pencil"}},
+		{stock: catalog.StockOut, want: []string{"Pen"}},
+		{stock: catalog.StockIn, want: []string{"Notebook", "Pencil"}},
+		{stock: catalog.StockLow, want: []string{"Pen", "Pencil"}},
+	}
+	for _, tc := range cases {
+		items, total, err := svc.ListProducts(ctx, catalog.ProductFilter{Stock: tc.stock, SortBy: "name", SortDir: "asc"})
+		if err != nil {
+			t.Fatalf("stock=%q: unexpected error: %v", tc.stock, err)
+		}
+		names := make([]string, 0, len(items))
+		for _, p := range items {
+			names = append(names, p.Name)
+		}
+		if total != int64(len(tc.want)) || strings.Join(names, ",") != strings.Join(tc.want, ",") {
+			t.Fatalf("stock=%q: expected %v, got %v (total %d)", tc.stock, tc.want, names, total)
+		}
+	}
+
+	if _, _, err := svc.ListProducts(ctx, catalog.ProductFilter{Stock: "empty"}); !errors.Is(err, catalog.ErrInvalidStockFilter) {
+		t.Fatalf("expected ErrInvalidStockFilter, got %v", err)
+	}
+}
+
+func TestMemoryRepository_ListProductsPriceRange(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	for _, in := range []catalog.CreateProductInput{
+		{Name: "Pen", Price: ptr(int64(300))},
+		{Name: "Pencil", Price: ptr(int64(100))},
+		{Name: "Notebook", Price: ptr(int64(200))},
+	} {
+		if _, err := svc.CreateProduct(ctx, in); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cases := []struct {
+		min, max *int64
+		want     []string
+	}{
+		{want: []string{"Notebook", "Pen", "Pencil"}},
+		{min: ptr(int64(200)), want: []string{"Notebook", "Pen"}},
+		{max: ptr(int64(200)), want: []string{"Notebook", "Pencil"}},
+		{min: ptr(int64(100)), max: ptr(int64(100)), want: []string{"Pencil"}},
+		{min: ptr(int64(0)), max: ptr(int64(50)), want: []string{}},
+	}
+	for _, tc := range cases {
+		items, total, err := svc.ListProducts(ctx, catalog.ProductFilter{MinPrice: tc.min, MaxPrice: tc.max, SortBy: "name", SortDir: "asc"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		names := make([]string, 0, len(items))
+		for _, p := range items {
+			names = append(names, p.Name)
+		}
+		if total != int64(len(tc.want)) || strings.Join(names, ",") != strings.Join(tc.want, ",") {
+			t.Fatalf("expected %v, got %v (total %d)", tc.want, names, total)
+		}
+	}
+
+	for _, f := range []catalog.ProductFilter{
+		{MinPrice: ptr(int64(300)), MaxPrice: ptr(int64(100))},
+		{MinPrice: ptr(int64(-1))},
+	} {
+		if _, _, err := svc.ListProducts(ctx, f); !errors.Is(err, catalog.ErrInvalidPriceRange) {
+			t.Fatalf("expected ErrInvalidPriceRange, got %v", err)
+		}
+	}
+	result, err := svc.Search(ctx, catalog.SearchFilter{Kind: catalog.SearchKindProduct, MinPrice: ptr(int64(150)), MaxPrice: ptr(int64(250))})
+	if err != nil || result.Total != 1 || result.Products[0].Name != "Notebook" {
+		t.Fatalf("expected search to apply the price range, got %+v err=%v", result, err)
+	}
+}
+
+func TestMemoryRepository_UpdateProductRecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	p, _ := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(10)), Stock: ptr(int64(5))})
+
+	if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Description: ptr("Red")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Price: ptr(int64(12)), Stock: ptr(int64(3))}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history, err := svc.GetProductHistory(ctx, p.ID, catalog.ProductHistoryFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 1 || history[0].Price != 12 || history[0].Stock != 3 {
+		t.Fatalf("expected a single history entry for the price/stock change, got %+v", history)
+	}
+
+	if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: "missing", Name: ptr("X")}); err == nil {
+		t.Fatalf("expected error updating a missing product")
+	}
+}
+
+func TestMemoryRepository_SoftDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	p, _ := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(10)), Stock: ptr(int64(5))})
+	if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Price: ptr(int64(12))}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := svc.DeleteProduct(ctx, p.ID); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+	if _, err := svc.GetProduct(ctx, p.ID); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected deleted product to be hidden, got %v", err)
+	}
+	if items, total, _ := svc.ListProducts(ctx, catalog.ProductFilter{}); len(items) != 0 || total != 0 {
+		t.Fatalf("expected empty listing after delete, got %d items (total %d)", len(items), total)
+	}
+	if err := svc.DeleteProduct(ctx, p.ID); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected second delete to fail, got %v", err)
+	}
+	if history, err := svc.GetProductHistory(ctx, p.ID, catalog.ProductHistoryFilter{}); err != nil || len(history) != 1 {
+		t.Fatalf("expected history to survive the delete, got %+v (%v)", history, err)
+	}
+
+	restored, ok, err := svc.RestoreProduct(ctx, p.ID)
+	if err != nil || !ok || restored.Price != 12 {
+		t.Fatalf("expected restore, got %+v restored=%v err=%v", restored, ok, err)
+	}
+	if _, err := svc.GetProduct(ctx, p.ID); err != nil {
+		t.Fatalf("expected restored product to be readable, got %v", err)
+	}
+	if _, ok, err := svc.RestoreProduct(ctx, p.ID); err != nil || ok {
+		t.Fatalf("expected no-op restore of an active product, got restored=%v err=%v", ok, err)
+	}
+	if _, _, err := svc.RestoreProduct(ctx, "missing"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestMemoryRepository_DraftHiddenUntilPublished(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	draft, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Draft: true})
+	if err != nil || !draft.Draft {
+		t.Fatalf("expected a draft product, got %+v err=%v", draft, err)
+	}
+	if _, total, _ := svc.ListProducts(ctx, catalog.ProductFilter{ExcludeDrafts: true}); total != 0 {
+		t.Fatalf("expected draft to be hidden from storefront listings, got total %d", total)
+	}
+	if _, total, _ := svc.ListProducts(ctx, catalog.ProductFilter{}); total != 1 {
+		t.Fatalf("expected draft in the full listing, got total %d", total)
+	}
+
+	if _, _, err := svc.PublishProduct(ctx, draft.ID); !errors.Is(err, catalog.ErrProductIncomplete) {
+		t.Fatalf("expected ErrProductIncomplete without a price, got %v", err)
+	}
+	// editar no levanta el borrador.
+	if p, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: draft.ID, Price: ptr(int64(10))}); err != nil || !p.Draft {
+		t.Fatalf("expected product to stay a draft after update, got %+v err=%v", p, err)
+	}
+	p, published, err := svc.PublishProduct(ctx, draft.ID)
+	if err != nil || !published || p.Draft {
+		t.Fatalf("expected publish, got %+v published=%v err=%v", p, published, err)
+	}
+	if _, total, _ := svc.ListProducts(ctx, catalog.ProductFilter{ExcludeDrafts: true}); total != 1 {
+		t.Fatalf("expected published product in storefront listings, got total %d", total)
+	}
+	if _, published, err := svc.PublishProduct(ctx, draft.ID); err != nil || published {
+		t.Fatalf("expected no-op publish, got published=%v err=%v", published, err)
+	}
+}
+
+func TestMemoryRepository_PruneProductHistoryKeepsFloor(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestService(t)
+	p, _ := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(10)), Stock: ptr(int64(5))})
+	for price := int64(11); price <= 14; price++ {
+		if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Price: ptr(price)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// todo el historial es anterior al corte, pero se conservan las 2 mas recientes.
+	removed, err := repo.PruneProductHistory(ctx, time.Now().Add(time.Hour), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 entries removed, got %d", removed)
+	}
+	history, _ := svc.GetProductHistory(ctx, p.ID, catalog.ProductHistoryFilter{})
+	if len(history) != 2 || history[0].Price != 14 || history[1].Price != 13 {
+		t.Fatalf("expected the two latest entries to survive, got %+v", history)
+	}
+
+	// nada es anterior a un corte en el pasado.
+	if removed, _ := repo.PruneProductHistory(ctx, time.Now().Add(-time.Hour), 0); removed != 0 {
+		t.Fatalf("expected nothing removed before the cutoff, got %d", removed)
+	}
+}
+
+func TestMemoryRepository_AssignProductCategory(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestService(t)
+	p, _ := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen"})
+	c, _ := svc.CreateCategory(ctx, catalog.CreateCategoryInput{Name: "Office"})
+
+	if err := svc.AssignProductCategory(ctx, p.ID, c.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.AssignProductCategory(ctx, p.ID, c.ID); err != nil {
+		t.Fatalf("expected assignment to be idempotent, got %v", err)
+	}
+	if _, ok := repo.productCategories[p.ID][c.ID]; !ok {
+		t.Fatalf("expected relation to be stored")
+	}
+	cats, err := svc.ListProductCategories(ctx, p.ID)
+	if err != nil || len(cats) != 1 || cats[0].ID != c.ID {
+		t.Fatalf("unexpected product categories %+v err=%v", cats, err)
+	}
+	if err := svc.AssignProductCategory(ctx, p.ID, "missing"); !errors.Is(err, catalog.ErrCategoryNotFound) {
+		t.Fatalf("expected ErrCategoryNotFound for unknown category, got %v", err)
+	}
+	if _, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Ink"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items, total, err := svc.ListProducts(ctx, catalog.ProductFilter{CategoryID: c.ID})
+	if err != nil || total != 1 || len(items) != 1 || items[0].ID != p.ID {
+		t.Fatalf("expected only the assigned product, got %+v total=%d err=%v", items, total, err)
+	}
+	if len(items[0].Categories) != 0 {
+		t.Fatalf("expected listings to skip categories, got %+v", items[0].Categories)
+	}
+	detail, err := svc.GetProduct(ctx, p.ID)
+	if err != nil || len(detail.Categories) != 1 || detail.Categories[0].ID != c.ID {
+		t.Fatalf("expected GetProduct to include categories, got %+v err=%v", detail.Categories, err)
+	}
+	if removed, err := svc.UnassignProductCategory(ctx, p.ID, c.ID); err != nil || !removed {
+		t.Fatalf("expected unassignment, got removed=%v err=%v", removed, err)
+	}
+	if removed, err := svc.UnassignProductCategory(ctx, p.ID, c.ID); err != nil || removed {
+		t.Fatalf("expected second unassignment to be a no-op, got removed=%v err=%v", removed, err)
+	}
+	if _, err := svc.UnassignProductCategory(ctx, p.ID, ""); !errors.Is(err, catalog.ErrInvalidCategoryID) {
+		t.Fatalf("expected ErrInvalidCategoryID, got %v", err)
+	}
+	if err := svc.AssignProductCategory(ctx, p.ID, c.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := svc.DeleteProduct(ctx, p.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.GetProduct(ctx, p.ID); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound for deleted product, got %v", err)
+	}
+	if err := svc.DeleteProduct(ctx, p.ID); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound deleting twice, got %v", err)
+	}
+	if _, err := svc.ListProductCategories(ctx, p.ID); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound listing categories of deleted product, got %v", err)
+	}
+}
+
+func TestMemoryRepository_BulkCreateCategoriesIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestService(t)
+	if _, err := svc.CreateCategory(ctx, catalog.CreateCategoryInput{Name: "Books"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := svc.BulkCreateCategories(ctx, []catalog.CreateCategoryInput{{Name: "Audio"}, {Name: "Books"}})
+	var bulkErr *catalog.BulkCategoryError
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 1 {
+		t.Fatalf("expected conflict at index 1, got %v", err)
+	}
+	if len(repo.categories) != 1 {
+		t.Fatalf("expected batch to be rolled back, got %d categories", len(repo.categories))
+	}
+
+	created, err := svc.BulkCreateCategories(ctx, []catalog.CreateCategoryInput{{Name: "Audio"}, {Name: "Games"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(created) != 2 || created[0].ID == "" || created[1].Name != "Games" {
+		t.Fatalf("unexpected created categories %+v", created)
+	}
+}
+
+func TestMemoryRepository_SearchHighlight(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+	if _, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Fountain Pen", Description: "Ink"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := svc.Search(ctx, catalog.SearchFilter{Kind: "product", Query: "pen"})
+	if err != nil || res.Products[0].Snippet != "" {
+		t.Fatalf("expected no snippet without highlight, got %+v err=%v", res.Products, err)
+	}
+	res, err = svc.Search(ctx, catalog.SearchFilter{Kind: "product", Query: "pen", Highlight: true})
+	if err != nil || res.Products[0].Snippet != "Fountain <mark>Pen</mark> Ink" {
+		t.Fatalf("unexpected snippet %+v err=%v", res.Products, err)
+	}
+}
+
+func TestMemoryRepository_PriceChangeApprovalWorkflow(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCatalogRepository()
+	svc, err := catalog.NewService(catalog.ServiceDeps{
+		CategoryRepo:           repo,
+		ProductRepo:            repo,
+		PriceApprovalThreshold: 10,
+		PriceChangeRepo:        repo,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error building service: %v", err)
+	}
+	p, _ := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(100)), Stock: ptr(int64(5))})
+
+	small, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Price: ptr(int64(105))})
+	if err != nil || small.Price != 105 {
+		t.Fatalf("expected change under threshold to apply, got %+v err=%v", small, err)
+	}
+
+	updated, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Name: ptr("Fountain Pen"), Price: ptr(int64(200))})
+	var pending *catalog.PriceChangePendingError
+	if !errors.As(err, &pending) {
+		t.Fatalf("expected PriceChangePendingError, got %v", err)
+	}
+	if updated.Price != 105 || updated.Name != "Fountain Pen" {
+		t.Fatalf("expected other fields saved and price kept, got %+v", updated)
+	}
+	if pending.Request.OldPrice != 105 || pending.Request.NewPrice != 200 || pending.Request.Status != catalog.PriceChangePending {
+		t.Fatalf("unexpected pending request %+v", pending.Request)
+	}
+
+	req, approved, err := svc.ApprovePriceChange(ctx, pending.Request.ID)
+	if err != nil {
+		t.Fatalf("unexpected error approving: %v", err)
+	}
+	if req.Status != catalog.PriceChangeApproved || req.DecidedAt.IsZero() || approved.Price != 200 {
+		t.Fatalf("expected approved request and applied price, got %+v %+v", req, approved)
+	}
+	latest, err := svc.GetLatestProductHistory(ctx, p.ID)
+	if err != nil || latest.Price != 200 {
+		t.Fatalf("expected history entry for approved price, got %+v err=%v", latest, err)
+	}
+	if _, _, err := svc.ApprovePriceChange(ctx, pending.Request.ID); !errors.Is(err, catalog.ErrPriceChangeDecided) {
+		t.Fatalf("expected ErrPriceChangeDecided, got %v", err)
+	}
+
+	_, err = svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Price: ptr(int64(20))})
+	if !errors.As(err, &pending) {
+		t.Fatalf("expected PriceChangePendingError, got %v", err)
+	}
+	rejected, err := svc.RejectPriceChange(ctx, pending.Request.ID)
+	if err != nil || rejected.Status != catalog.PriceChangeRejected {
+		t.Fatalf("expected rejected request, got %+v err=%v", rejected, err)
+	}
+	current, _ := svc.GetProduct(ctx, p.ID)
+	if current.Price != 200 {
+		t.Fatalf("rejected change must not apply, got price %d", current.Price)
+	}
+
+	pendingList, _ := svc.ListPriceChangeRequests(ctx, catalog.PriceChangePending)
+	all, _ := svc.ListPriceChangeRequests(ctx, "")
+	if len(pendingList) != 0 || len(all) != 2 {
+		t.Fatalf("expected 0 pending and 2 total requests, got %d and %d", len(pendingList), len(all))
+	}
+	if _, err := svc.ListPriceChangeRequests(ctx, "bogus"); !errors.Is(err, catalog.ErrInvalidPriceChangeState) {
+		t.Fatalf("expected ErrInvalidPriceChangeState, got %v", err)
+	}
+	if _, err := svc.RejectPriceChange(ctx, "missing"); !errors.Is(err, catalog.ErrPriceChangeNotFound) {
+		t.Fatalf("expected ErrPriceChangeNotFound, got %v", err)
+	}
+}
+
+func TestMemoryRepository_StalePriceChangeIsNotApplied(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCatalogRepository()
+	svc, err := catalog.NewService(catalog.ServiceDeps{
+		CategoryRepo:           repo,
+		ProductRepo:            repo,
+		PriceApprovalThreshold: 10,
+		PriceChangeRepo:        repo,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error building service: %v", err)
+	}
+	p, _ := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(100)), Stock: ptr(int64(5))})
+
+	var raise, cut *catalog.PriceChangePendingError
+	if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Price: ptr(int64(200))}); !errors.As(err, &raise) {
+		t.Fatalf("expected PriceChangePendingError, got %v", err)
+	}
+	if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: p.ID, Price: ptr(int64(20))}); !errors.As(err, &cut) {
+		t.Fatalf("expected PriceChangePendingError, got %v", err)
+	}
+	if _, _, err := svc.ApprovePriceChange(ctx, raise.Request.ID); err != nil {
+		t.Fatalf("unexpected error approving: %v", err)
+	}
+	if _, _, err := svc.ApprovePriceChange(ctx, cut.Request.ID); !errors.Is(err, catalog.ErrPriceChangeStale) {
+		t.Fatalf("expected ErrPriceChangeStale, got %v", err)
+	}
+	current, _ := svc.GetProduct(ctx, p.ID)
+	if current.Price != 200 {
+		t.Fatalf("stale approval must not revert the price, got %d", current.Price)
+	}
+	if _, err := svc.RejectPriceChange(ctx, cut.Request.ID); err != nil {
+		t.Fatalf("expected the stale request to stay pending and rejectable, got %v", err)
+	}
+}
+
+func TestMemoryRepository_ProductBarcode(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	pen, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(10)), Stock: ptr(int64(1)), Barcode: "036000291452"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pen.Barcode != "0036000291452" {
+		t.Fatalf("expected barcode stored as EAN-13, got %q", pen.Barcode)
+	}
+	if _, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Ink", Price: ptr(int64(5)), Stock: ptr(int64(1)), Barcode: "4006381333932"}); !errors.Is(err, catalog.ErrInvalidBarcode) {
+		t.Fatalf("expected ErrInvalidBarcode for bad checksum, got %v", err)
+	}
+	if _, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen Copy", Price: ptr(int64(10)), Stock: ptr(int64(1)), Barcode: "0036000291452"}); !errors.Is(err, catalog.ErrDuplicateBarcode) {
+		t.Fatalf("expected ErrDuplicateBarcode, got %v", err)
+	}
+
+	ink, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Ink", Price: ptr(int64(5)), Stock: ptr(int64(1))})
+	if err != nil || ink.Barcode != "" {
+		t.Fatalf("barcode must be optional, got %+v err=%v", ink, err)
+	}
+	if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: ink.ID, Barcode: ptr("036000291452")}); !errors.Is(err, catalog.ErrDuplicateBarcode) {
+		t.Fatalf("expected ErrDuplicateBarcode on update, got %v", err)
+	}
+
+	got, err := svc.GetProductByBarcode(ctx, "036000291452")
+	if err != nil || got.ID != pen.ID {
+		t.Fatalf("expected lookup by UPC-A to find pen, got %+v err=%v", got, err)
+	}
+	if _, err := svc.UpdateProduct(ctx, catalog.UpdateProductInput{ID: pen.ID, Barcode: ptr("")}); err != nil {
+		t.Fatalf("unexpected error clearing barcode: %v", err)
+	}
+	if _, err := svc.GetProductByBarcode(ctx, "0036000291452"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound after clearing, got %v", err)
+	}
+}
+
+func TestMemoryRepository_DeletedProductReleasesBarcode(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	pen, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Price: ptr(int64(10)), Stock: ptr(int64(1)), Barcode: "4006381333931"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.DeleteProduct(ctx, pen.ID); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+	if _, err := svc.GetProductByBarcode(ctx, "4006381333931"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected deleted product to be hidden from barcode lookup, got %v", err)
+	}
+	replacement, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen v2", Price: ptr(int64(11)), Stock: ptr(int64(1)), Barcode: "4006381333931"})
+	if err != nil {
+		t.Fatalf("expected the deleted product's barcode to be reusable, got %v", err)
+	}
+	if _, _, err := svc.RestoreProduct(ctx, pen.ID); !errors.Is(err, catalog.ErrDuplicateBarcode) {
+		t.Fatalf("expected ErrDuplicateBarcode restoring over a taken barcode, got %v", err)
+	}
+	if err := svc.DeleteProduct(ctx, replacement.ID); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+	if _, restored, err := svc.RestoreProduct(ctx, pen.ID); err != nil || !restored {
+		t.Fatalf("expected restore once the barcode is free, got restored=%v err=%v", restored, err)
+	}
+}
+
+func TestMemoryRepository_CreateProductsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newTestService(t)
+	if _, err := svc.CreateProduct(ctx, catalog.CreateProductInput{Name: "Pen", Barcode: "4006381333931"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := svc.CreateProducts(ctx, []catalog.CreateProductInput{{Name: "Ink"}, {Name: "Pen copy", Barcode: "4006381333931"}})
+	if !errors.Is(err, catalog.ErrDuplicateBarcode) || len(repo.products) != 1 {
+		t.Fatalf("expected the batch to be rejected untouched, got %v with %d products", err, len(repo.products))
+	}
+
+	created, err := svc.CreateProducts(ctx, []catalog.CreateProductInput{{Name: "Pen"}, {Name: "Ink"}})
+	if err != nil || len(created) != 2 || created[0].Slug != "pen-2" || created[1].Slug != "ink" {
+		t.Fatalf("expected two products with free slugs, got %+v (%v)", created, err)
+	}
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"catalog-api/internal/catalog"
+)
+
+// RequestPriceChange aplica los demas campos y guarda la solicitud bajo el mismo lock.
+func (r *CatalogRepository) RequestPriceChange(ctx context.Context, p catalog.Product, req catalog.PriceChangeRequest) (catalog.PriceChangeRequest, catalog.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	product, err := r.updateProduct(p)
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, err
+	}
+	req.ProductID = product.ID
+	req.ID = newID()
+	req.Status = catalog.PriceChangePending
+	req.CreatedAt = time.Now()
+	req.DecidedAt = time.Time{}
+	r.priceChanges[req.ID] = req
+	return req, product, nil
+}
+
+func (r *CatalogRepository) ListPriceChangeRequests(ctx context.Context, status catalog.PriceChangeStatus) ([]catalog.PriceChangeRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	items := make([]catalog.PriceChangeRequest, 0, len(r.priceChanges))
+	for _, req := range r.priceChanges {
+		if status == "" || req.Status == status {
+			items = append(items, req)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.After(items[j].CreatedAt) })
+	return items, nil
+}
+
+// ApprovePriceChangeRequest aplica el precio y registra historial bajo el mismo lock.
+func (r *CatalogRepository) ApprovePriceChangeRequest(ctx context.Context, id string) (catalog.PriceChangeRequest, catalog.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, err := r.pendingPriceChange(id)
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, err
+	}
+	p, ok := r.products[req.ProductID]
+	if !ok {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, catalog.ErrProductNotFound
+	}
+	if p.Price != req.OldPrice {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, catalog.ErrPriceChangeStale
+	}
+	now := time.Now()
+	if p.Price != req.NewPrice {
+		p.Price = req.NewPrice
+		p.UpdatedAt = now
+		r.products[p.ID] = p
+		r.history[p.ID] = append(r.history[p.ID], catalog.ProductHistory{
+			ID:        newID(),
+			ProductID: p.ID,
+			Price:     p.Price,
+			Stock:     p.Stock,
+			ChangedAt: now,
+		})
+	}
+	req.Status = catalog.PriceChangeApproved
+	req.DecidedAt = now
+	r.priceChanges[id] = req
+	return req, p, nil
+}
+
+func (r *CatalogRepository) RejectPriceChangeRequest(ctx context.Context, id string) (catalog.PriceChangeRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, err := r.pendingPriceChange(id)
+	if err != nil {
+		return catalog.PriceChangeRequest{}, err
+	}
+	req.Status = catalog.PriceChangeRejected
+	req.DecidedAt = time.Now()
+	r.priceChanges[id] = req
+	return req, nil
+}
+
+// pendingPriceChange asume el lock tomado.
+func (r *CatalogRepository) pendingPriceChange(id string) (catalog.PriceChangeRequest, error) {
+	req, ok := r.priceChanges[id]
+	if !ok {
+		return catalog.PriceChangeRequest{}, catalog.ErrPriceChangeNotFound
+	}
+	if req.Status != catalog.PriceChangePending {
+		return catalog.PriceChangeRequest{}, catalog.ErrPriceChangeDecided
+	}
+	return req, nil
+}
@@ -2,8 +2,11 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
+	"time"
 
 	"catalog-api/internal/catalog"
 
@@ -18,50 +21,200 @@ type pgxPool interface {
 	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 }
 
+var (
+	categoryErrors = errorMapping{notFound: catalog.ErrCategoryNotFound, conflict: catalog.ErrCategoryConflict}
+	productErrors  = errorMapping{notFound: catalog.ErrProductNotFound}
+	historyErrors  = errorMapping{notFound: catalog.ErrProductHistoryNotFound}
+)
+
+// categoryColumns es el orden de columnas que espera scanCategory.
+const categoryColumns = "id, name, slug, description, display_order, created_at, updated_at"
+
+// productColumns es el orden de columnas que espera scanProduct.
+const productColumns = "id, name, slug, description, price, COALESCE(currency, ''), COALESCE(barcode, ''), stock, created_at, updated_at, draft"
+
+// queryer es lo comun entre el pool y una transaccion para lecturas.
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
 // CatalogRepository implementa repositorios de catalogo sobre Postgres.
 type CatalogRepository struct {
-	pool pgxPool
+	pool    pgxPool
+	replica pgxPool // opcional: solo lecturas; nil usa pool
+	// idRetries reintenta un alta cuyo id choca con la clave primaria; 0 no reintenta.
+	idRetries int
+	logr      *slog.Logger
+}
+
+// DefaultIDCollisionRetries es la cantidad de reintentos ante un choque de id.
+const DefaultIDCollisionRetries = 3
+
+// CatalogOption ajusta parametros opcionales del repositorio.
+type CatalogOption func(*CatalogRepository)
+
+// WithReadReplica envia las lecturas (listados, detalle, busqueda, conteos e
+// historial) a una replica de solo lectura; las escrituras siguen en el primario.
+func WithReadReplica(replica pgxPool) CatalogOption {
+	return func(r *CatalogRepository) {
+		r.replica = replica
+	}
+}
+
+// WithIDCollisionRetries define cuantas veces se reintenta un alta cuyo id generado
+// choca con la clave primaria; cada reintento se registra en logr.
+func WithIDCollisionRetries(retries int, logr *slog.Logger) CatalogOption {
+	return func(r *CatalogRepository) {
+		r.idRetries = retries
+		r.logr = logr
+	}
 }
 
 // NewCatalogRepository construye un repo de catalogo respaldado por un pool pgx.
-func NewCatalogRepository(pool pgxPool) *CatalogRepository {
-	return &CatalogRepository{pool: pool}
+func NewCatalogRepository(pool pgxPool, options ...CatalogOption) *CatalogRepository {
+	r := &CatalogRepository{pool: pool, idRetries: DefaultIDCollisionRetries}
+	for _, opt := range options {
+		opt(r)
+	}
+	if r.logr == nil {
+		r.logr = slog.Default()
+	}
+	return r
 }
 
-// ListCategories devuelve todas las categorias ordenadas por nombre.
-func (r *CatalogRepository) ListCategories(ctx context.Context) ([]catalog.Category, error) {
+// retryIDCollision repite insert mientras falle por la clave primaria, hasta idRetries
+// veces. Solo sirve fuera de transacciones: en una, el error ya aborto la tx.
+func (r *CatalogRepository) retryIDCollision(ctx context.Context, table string, insert func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := insert()
+		if err == nil || !isPrimaryKeyViolation(err) || attempt > r.idRetries {
+			return err
+		}
+		if r.logr != nil {
+			r.logr.WarnContext(ctx, "primary key collision on insert, retrying", "table", table, "attempt", attempt)
+		}
+	}
+}
+
+// reader elige el pool de lectura: la replica salvo que ctx pida el primario.
+func (r *CatalogRepository) reader(ctx context.Context) pgxPool {
+	if r.replica == nil || catalog.PrimaryRead(ctx) {
+		return r.pool
+	}
+	return r.replica
+}
+
+// ListCategories devuelve las categorias ordenadas por nombre, paginando solo si se pide.
+func (r *CatalogRepository) ListCategories(ctx context.Context, filter catalog.CategoryFilter) ([]catalog.Category, error) {
 	if r.pool == nil {
 		return nil, catalog.ErrRepositoryNotConfigured
 	}
-	rows, err := r.pool.Query(ctx, `SELECT id, name, description, created_at, updated_at FROM categories ORDER BY name`)
+	// primero las ordenadas por un admin (display_order > 0), luego el resto por nombre.
+	query := `SELECT ` + categoryColumns + ` FROM categories ORDER BY display_order = 0, display_order, name`
+	args := []any{}
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, categoryErrors.translate(err)
 	}
 	defer rows.Close()
 	var items []catalog.Category
 	for rows.Next() {
-		var c catalog.Category
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt); err != nil {
-			return nil, err
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, categoryErrors.translate(err)
 		}
 		items = append(items, c)
 	}
-	return items, rows.Err()
+	return items, categoryErrors.translate(rows.Err())
 }
 
-// CreateCategory inserta una nueva categoria.
+// CountCategories devuelve el total de categorias.
+func (r *CatalogRepository) CountCategories(ctx context.Context) (int64, error) {
+	if r.pool == nil {
+		return 0, catalog.ErrRepositoryNotConfigured
+	}
+	var total int64
+	err := r.reader(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM categories`).Scan(&total)
+	return total, categoryErrors.translate(err)
+}
+
+// GetCategoryBySlug busca una categoria por su slug.
+func (r *CatalogRepository) GetCategoryBySlug(ctx context.Context, slug string) (catalog.Category, error) {
+	if r.pool == nil {
+		return catalog.Category{}, catalog.ErrRepositoryNotConfigured
+	}
+	row := r.reader(ctx).QueryRow(ctx, `SELECT `+categoryColumns+` FROM categories WHERE slug = $1`, slug)
+	out, err := scanCategory(row)
+	if err != nil {
+		return catalog.Category{}, categoryErrors.translate(err)
+	}
+	return out, nil
+}
+
+// CreateCategory inserta una nueva categoria con el primer slug libre.
 func (r *CatalogRepository) CreateCategory(ctx context.Context, cat catalog.Category) (catalog.Category, error) {
 	if r.pool == nil {
 		return catalog.Category{}, catalog.ErrRepositoryNotConfigured
 	}
-	row := r.pool.QueryRow(ctx, `
-		INSERT INTO categories (name, description)
-		VALUES ($1, $2)
-		RETURNING id, name, description, created_at, updated_at
-	`, cat.Name, cat.Description)
+	slug, err := freeCategorySlug(ctx, r.pool, cat.Slug)
+	if err != nil {
+		return catalog.Category{}, categoryErrors.translate(err)
+	}
 	var out catalog.Category
-	if err := row.Scan(&out.ID, &out.Name, &out.Description, &out.CreatedAt, &out.UpdatedAt); err != nil {
-		return catalog.Category{}, err
+	err = r.retryIDCollision(ctx, "categories", func() (err error) {
+		out, err = scanCategory(r.pool.QueryRow(ctx, `
+			INSERT INTO categories (name, slug, description)
+			VALUES ($1, $2, $3)
+			RETURNING `+categoryColumns, cat.Name, slug, cat.Description))
+		return err
+	})
+	if err != nil {
+		return catalog.Category{}, categoryErrors.translate(err)
+	}
+	return out, nil
+}
+
+// BulkCreateCategories inserta el lote en una transaccion; un nombre duplicado revierte todo.
+func (r *CatalogRepository) BulkCreateCategories(ctx context.Context, cats []catalog.Category) ([]catalog.Category, error) {
+	if r.pool == nil {
+		return nil, catalog.ErrRepositoryNotConfigured
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	out := make([]catalog.Category, 0, len(cats))
+	for i, cat := range cats {
+		// las filas ya insertadas en la transaccion cuentan como slugs tomados.
+		slug, err := freeCategorySlug(ctx, tx, cat.Slug)
+		if err != nil {
+			return nil, categoryErrors.translate(err)
+		}
+		created, err := scanCategory(tx.QueryRow(ctx, `
+			INSERT INTO categories (name, slug, description)
+			VALUES ($1, $2, $3)
+			RETURNING `+categoryColumns, cat.Name, slug, cat.Description))
+		if err != nil {
+			err = categoryErrors.translate(err)
+			if errors.Is(err, catalog.ErrCategoryConflict) {
+				return nil, &catalog.BulkCategoryError{Index: i, Err: err}
+			}
+			return nil, err
+		}
+		out = append(out, created)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
@@ -75,11 +228,10 @@ func (r *CatalogRepository) UpdateCategory(ctx context.Context, cat catalog.Cate
 		UPDATE categories
 		SET name = $1, description = $2, updated_at = NOW()
 		WHERE id = $3
-		RETURNING id, name, description, created_at, updated_at
-	`, cat.Name, cat.Description, cat.ID)
-	var out catalog.Category
-	if err := row.Scan(&out.ID, &out.Name, &out.Description, &out.CreatedAt, &out.UpdatedAt); err != nil {
-		return catalog.Category{}, err
+		RETURNING `+categoryColumns, cat.Name, cat.Description, cat.ID)
+	out, err := scanCategory(row)
+	if err != nil {
+		return catalog.Category{}, categoryErrors.translate(err)
 	}
 	return out, nil
 }
@@ -89,8 +241,48 @@ func (r *CatalogRepository) DeleteCategory(ctx context.Context, id string) error
 	if r.pool == nil {
 		return catalog.ErrRepositoryNotConfigured
 	}
-	_, err := r.pool.Exec(ctx, `DELETE FROM categories WHERE id = $1`, id)
-	return err
+	tag, err := r.pool.Exec(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		return categoryErrors.translate(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return catalog.ErrCategoryNotFound
+	}
+	return nil
+}
+
+// ReorderCategories reescribe los rangos en una transaccion; un id inexistente revierte todo.
+func (r *CatalogRepository) ReorderCategories(ctx context.Context, ids []string) error {
+	if r.pool == nil {
+		return catalog.ErrRepositoryNotConfigured
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return categoryErrors.translate(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE categories SET display_order = 0 WHERE display_order <> 0`); err != nil {
+		return categoryErrors.translate(err)
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE categories c
+		SET display_order = o.rank
+		FROM unnest($1::uuid[]) WITH ORDINALITY AS o(id, rank)
+		WHERE c.id = o.id
+	`, ids)
+	if err != nil {
+		return categoryErrors.translate(err)
+	}
+	if tag.RowsAffected() != int64(len(ids)) {
+		return catalog.ErrCategoryNotFound
+	}
+	return categoryErrors.translate(tx.Commit(ctx))
+}
+
+// CategoryExists verifica la existencia sin traer la fila completa.
+func (r *CatalogRepository) CategoryExists(ctx context.Context, id string) (bool, error) {
+	return r.exists(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1)`, id)
 }
 
 // SearchCategories ejecuta una busqueda de texto simple con paginacion.
@@ -105,33 +297,142 @@ func (r *CatalogRepository) SearchCategories(ctx context.Context, filter catalog
 		where = "(name ILIKE $1 OR description ILIKE $1)"
 		args = append(args, "%"+query+"%")
 	}
-	order := "ORDER BY name"
+	order := buildCategoryOrderClause(filter.SortBy, filter.SortDir)
 	limit := "LIMIT $%d"
 	offset := "OFFSET $%d"
 	args = append(args, filter.Limit, filter.Offset)
 	limit = fmt.Sprintf(limit, len(args)-1)
 	offset = fmt.Sprintf(offset, len(args))
 
-	rows, err := r.pool.Query(ctx, fmt.Sprintf(`SELECT id, name, description, created_at, updated_at FROM categories WHERE %s %s %s`, where, order, limit+" "+offset), args...)
+	highlight := filter.Highlight && query != ""
+	snippet := ""
+	if highlight {
+		snippet = ", " + headlineExpr("$1")
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, fmt.Sprintf(`SELECT %s%s FROM categories WHERE %s %s %s`, categoryColumns, snippet, where, order, limit+" "+offset), args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, categoryErrors.translate(err)
 	}
 	defer rows.Close()
 	var items []catalog.Category
 	for rows.Next() {
 		var c catalog.Category
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt); err != nil {
-			return nil, 0, err
+		dest := []any{&c.ID, &c.Name, &c.Slug, &c.Description, &c.DisplayOrder, &c.CreatedAt, &c.UpdatedAt}
+		if highlight {
+			dest = append(dest, &c.Snippet)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, categoryErrors.translate(err)
 		}
 		items = append(items, c)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, 0, err
+		return nil, 0, categoryErrors.translate(err)
 	}
 
 	var total int64
-	err = r.pool.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM categories WHERE %s`, where), args[:len(args)-2]...).Scan(&total)
-	return items, total, err
+	err = r.reader(ctx).QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM categories WHERE %s`, where), args[:len(args)-2]...).Scan(&total)
+	return items, total, categoryErrors.translate(err)
+}
+
+// freeCategorySlug devuelve el primer slug base o base-N libre entre las categorias.
+// Dos altas concurrentes pueden elegir el mismo; el indice unico rechaza la segunda.
+func freeCategorySlug(ctx context.Context, q queryer, base string) (string, error) {
+	return freeSlug(ctx, q, base, `SELECT slug FROM categories WHERE slug = $1 OR slug LIKE $2`)
+}
+
+// freeProductSlug considera tomados los slugs actuales e historicos de otros productos;
+// los del propio producto (exceptID) se pueden reutilizar al volver a un nombre anterior.
+func freeProductSlug(ctx context.Context, q queryer, base, exceptID string) (string, error) {
+	return freeSlug(ctx, q, base, `
+		SELECT slug FROM products WHERE id::text <> $3 AND (slug = $1 OR slug LIKE $2)
+		UNION
+		SELECT slug FROM product_slug_history WHERE product_id::text <> $3 AND (slug = $1 OR slug LIKE $2)
+	`, exceptID)
+}
+
+// freeSlug ejecuta query con $1=base y $2=patron base-% y elige el primer candidato libre.
+func freeSlug(ctx context.Context, q queryer, base, query string, extra ...any) (string, error) {
+	if base == "" {
+		base = catalog.Slugify("")
+	}
+	rows, err := q.Query(ctx, query, append([]any{base, base + "-%"}, extra...)...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	taken := make(map[string]struct{})
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", err
+		}
+		taken[slug] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return catalog.NextFreeSlug(base, taken), nil
+}
+
+// scanProduct lee una fila con las columnas de productColumns.
+func scanProduct(row pgx.Row) (catalog.Product, error) {
+	var p catalog.Product
+	err := row.Scan(&p.ID, &p.Name, &p.Slug, &p.Description, &p.Price, &p.Currency, &p.Barcode, &p.Stock, &p.CreatedAt, &p.UpdatedAt, &p.Draft)
+	return p, err
+}
+
+// scanCategory lee una fila con las columnas de categoryColumns.
+func scanCategory(row pgx.Row) (catalog.Category, error) {
+	var c catalog.Category
+	err := row.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.DisplayOrder, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+// productWhere arma el WHERE compartido por ListProducts y CountProducts.
+func productWhere(filter catalog.ProductFilter) (string, []any) {
+	// los productos borrados (soft-delete) nunca se listan ni cuentan.
+	conds := []string{"deleted_at IS NULL"}
+	args := []any{}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		args = append(args, "%"+q+"%")
+		conds = append(conds, "(name ILIKE $1 OR description ILIKE $1)")
+	}
+	switch filter.Stock {
+	case catalog.StockOut:
+		conds = append(conds, "stock = 0")
+	case catalog.StockIn:
+		conds = append(conds, "stock > 0")
+	case catalog.StockLow:
+		args = append(args, filter.LowStockThreshold)
+		conds = append(conds, fmt.Sprintf("stock <= $%d", len(args)))
+	}
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		conds = append(conds, fmt.Sprintf("price >= $%d", len(args)))
+	}
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		conds = append(conds, fmt.Sprintf("price <= $%d", len(args)))
+	}
+	if filter.ExcludeDrafts {
+		conds = append(conds, "draft = false")
+	}
+	if filter.CategoryID != "" {
+		args = append(args, filter.CategoryID)
+		conds = append(conds, fmt.Sprintf("pc.category_id = $%d", len(args)))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// productFrom suma el JOIN con product_category que usa la condicion de productWhere.
+// La PK (product_id, category_id) evita filas duplicadas.
+func productFrom(filter catalog.ProductFilter) string {
+	if filter.CategoryID == "" {
+		return "products"
+	}
+	return "products JOIN product_category pc ON pc.product_id = products.id"
 }
 
 // ListProducts obtiene productos con query de texto opcional y ordenamiento.
@@ -139,34 +440,44 @@ func (r *CatalogRepository) ListProducts(ctx context.Context, filter catalog.Pro
 	if r.pool == nil {
 		return nil, catalog.ErrRepositoryNotConfigured
 	}
-	where := "1=1"
-	args := []any{}
-	if strings.TrimSpace(filter.Query) != "" {
-		where = "(name ILIKE $1 OR description ILIKE $1)"
-		args = append(args, "%"+strings.TrimSpace(filter.Query)+"%")
-	}
+	where, args := productWhere(filter)
 	order := buildProductOrderClause(filter.SortBy, filter.SortDir)
+	if filter.SortsByRelevance() {
+		q := strings.TrimSpace(filter.Query)
+		args = append(args, q, q+"%")
+		order = relevanceOrderClause(len(args)-1, len(args))
+	}
+	// la query de texto siempre es $1, que es lo que usa headlineExpr.
+	highlight := filter.Highlight && strings.TrimSpace(filter.Query) != ""
+	snippet := ""
+	if highlight {
+		snippet = ", " + headlineExpr("$1")
+	}
 	args = append(args, filter.Limit, filter.Offset)
-	rows, err := r.pool.Query(ctx, fmt.Sprintf(`
-		SELECT id, name, description, price, stock, created_at, updated_at
-		FROM products
+	rows, err := r.reader(ctx).Query(ctx, fmt.Sprintf(`
+		SELECT %s%s
+		FROM %s
 		WHERE %s
 		%s
 		LIMIT $%d OFFSET $%d
-	`, where, order, len(args)-1, len(args)), args...)
+	`, productColumns, snippet, productFrom(filter), where, order, len(args)-1, len(args)), args...)
 	if err != nil {
-		return nil, err
+		return nil, productErrors.translate(err)
 	}
 	defer rows.Close()
 	var items []catalog.Product
 	for rows.Next() {
 		var p catalog.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.Stock, &p.CreatedAt, &p.UpdatedAt); err != nil {
-			return nil, err
+		dest := []any{&p.ID, &p.Name, &p.Slug, &p.Description, &p.Price, &p.Currency, &p.Barcode, &p.Stock, &p.CreatedAt, &p.UpdatedAt, &p.Draft}
+		if highlight {
+			dest = append(dest, &p.Snippet)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, productErrors.translate(err)
 		}
 		items = append(items, p)
 	}
-	return items, rows.Err()
+	return items, productErrors.translate(rows.Err())
 }
 
 // CountProducts devuelve el total de productos que cumplen el filtro.
@@ -174,15 +485,10 @@ func (r *CatalogRepository) CountProducts(ctx context.Context, filter catalog.Pr
 	if r.pool == nil {
 		return 0, catalog.ErrRepositoryNotConfigured
 	}
-	where := "1=1"
-	args := []any{}
-	if strings.TrimSpace(filter.Query) != "" {
-		where = "(name ILIKE $1 OR description ILIKE $1)"
-		args = append(args, "%"+strings.TrimSpace(filter.Query)+"%")
-	}
+	where, args := productWhere(filter)
 	var total int64
-	err := r.pool.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM products WHERE %s`, where), args...).Scan(&total)
-	return total, err
+	err := r.reader(ctx).QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, productFrom(filter), where), args...).Scan(&total)
+	return total, productErrors.translate(err)
 }
 
 // GetProduct obtiene un producto por ID.
@@ -190,13 +496,66 @@ func (r *CatalogRepository) GetProduct(ctx context.Context, id string) (catalog.
 	if r.pool == nil {
 		return catalog.Product{}, catalog.ErrRepositoryNotConfigured
 	}
-	var p catalog.Product
-	err := r.pool.QueryRow(ctx, `
-		SELECT id, name, description, price, stock, created_at, updated_at
+	p, err := scanProduct(r.reader(ctx).QueryRow(ctx, `SELECT `+productColumns+` FROM products WHERE id = $1 AND deleted_at IS NULL`, id))
+	if err != nil {
+		return catalog.Product{}, productErrors.translate(err)
+	}
+	return p, nil
+}
+
+// GetProductDetail obtiene el producto y sus categorias en la misma replica.
+func (r *CatalogRepository) GetProductDetail(ctx context.Context, id string) (catalog.Product, error) {
+	p, err := r.GetProduct(ctx, id)
+	if err != nil {
+		return catalog.Product{}, err
+	}
+	if p.Categories, err = r.productCategories(ctx, id); err != nil {
+		return catalog.Product{}, err
+	}
+	return p, nil
+}
+
+// GetProductBySlug busca por slug actual o por uno anterior del historial.
+func (r *CatalogRepository) GetProductBySlug(ctx context.Context, slug string) (catalog.Product, error) {
+	if r.pool == nil {
+		return catalog.Product{}, catalog.ErrRepositoryNotConfigured
+	}
+	p, err := scanProduct(r.reader(ctx).QueryRow(ctx, `
+		SELECT `+productColumns+`
 		FROM products
-		WHERE id = $1
-	`, id).Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.Stock, &p.CreatedAt, &p.UpdatedAt)
-	return p, err
+		WHERE deleted_at IS NULL
+			AND (slug = $1 OR id = (SELECT product_id FROM product_slug_history WHERE slug = $1))
+	`, slug))
+	if err != nil {
+		return catalog.Product{}, productErrors.translate(err)
+	}
+	return p, nil
+}
+
+// GetProductByBarcode busca por el EAN-13 normalizado.
+func (r *CatalogRepository) GetProductByBarcode(ctx context.Context, barcode string) (catalog.Product, error) {
+	if r.pool == nil {
+		return catalog.Product{}, catalog.ErrRepositoryNotConfigured
+	}
+	p, err := scanProduct(r.reader(ctx).QueryRow(ctx, `SELECT `+productColumns+` FROM products WHERE barcode = $1 AND deleted_at IS NULL`, barcode))
+	if err != nil {
+		return catalog.Product{}, productErrors.translate(err)
+	}
+	return p, nil
+}
+
+// translateProductWriteError distingue el barcode duplicado del resto de los errores.
+func translateProductWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && strings.Contains(pgErr.ConstraintName, "barcode") {
+		return wrapDomain(catalog.ErrDuplicateBarcode, err)
+	}
+	return productErrors.translate(err)
+}
+
+// ProductExists verifica la existencia sin traer la fila completa.
+func (r *CatalogRepository) ProductExists(ctx context.Context, id string) (bool, error) {
+	return r.exists(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)`, id)
 }
 
 // CreateProduct inserta un nuevo producto.
@@ -204,14 +563,57 @@ func (r *CatalogRepository) CreateProduct(ctx context.Context, p catalog.Product
 	if r.pool == nil {
 		return catalog.Product{}, catalog.ErrRepositoryNotConfigured
 	}
-	row := r.pool.QueryRow(ctx, `
-		INSERT INTO products (name, description, price, stock)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, name, description, price, stock, created_at, updated_at
-	`, p.Name, p.Description, p.Price, p.Stock)
+	slug, err := freeProductSlug(ctx, r.pool, p.Slug, "")
+	if err != nil {
+		return catalog.Product{}, productErrors.translate(err)
+	}
 	var out catalog.Product
-	if err := row.Scan(&out.ID, &out.Name, &out.Description, &out.Price, &out.Stock, &out.CreatedAt, &out.UpdatedAt); err != nil {
-		return catalog.Product{}, err
+	err = r.retryIDCollision(ctx, "products", func() (err error) {
+		out, err = scanProduct(r.pool.QueryRow(ctx, `
+			INSERT INTO products (name, slug, description, price, currency, barcode, stock, draft)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)
+			RETURNING `+productColumns, p.Name, slug, p.Description, p.Price, p.Currency, p.Barcode, p.Stock, p.Draft))
+		return err
+	})
+	if err != nil {
+		return catalog.Product{}, translateProductWriteError(err)
+	}
+	return out, nil
+}
+
+// CreateProducts inserta el lote en una transaccion; un barcode ya tomado revierte todo.
+func (r *CatalogRepository) CreateProducts(ctx context.Context, products []catalog.Product) ([]catalog.Product, error) {
+	if r.pool == nil {
+		return nil, catalog.ErrRepositoryNotConfigured
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, productErrors.translate(err)
+	}
+	defer tx.Rollback(ctx)
+
+	out := make([]catalog.Product, 0, len(products))
+	for i, p := range products {
+		// las filas ya insertadas en la transaccion cuentan como slugs tomados.
+		slug, err := freeProductSlug(ctx, tx, p.Slug, "")
+		if err != nil {
+			return nil, productErrors.translate(err)
+		}
+		created, err := scanProduct(tx.QueryRow(ctx, `
+			INSERT INTO products (name, slug, description, price, currency, barcode, stock, draft)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)
+			RETURNING `+productColumns, p.Name, slug, p.Description, p.Price, p.Currency, p.Barcode, p.Stock, p.Draft))
+		if err != nil {
+			err = translateProductWriteError(err)
+			if errors.Is(err, catalog.ErrDuplicateBarcode) {
+				return nil, &catalog.BulkProductError{Items: []catalog.BulkItemError{{Index: i, Err: err}}}
+			}
+			return nil, err
+		}
+		out = append(out, created)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, productErrors.translate(err)
 	}
 	return out, nil
 }
@@ -223,26 +625,56 @@ func (r *CatalogRepository) UpdateProduct(ctx context.Context, p catalog.Product
 	}
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return catalog.Product{}, err
+		return catalog.Product{}, productErrors.translate(err)
 	}
 	defer tx.Rollback(ctx)
 
+	out, err := updateProduct(ctx, tx, p)
+	if err != nil {
+		return catalog.Product{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return catalog.Product{}, productErrors.translate(err)
+	}
+	return out, nil
+}
+
+// updateProduct bloquea el producto, resuelve el slug y escribe el historial dentro de tx.
+func updateProduct(ctx context.Context, tx pgx.Tx, p catalog.Product) (catalog.Product, error) {
 	var original struct {
 		Price int64
 		Stock int64
+		Slug  string
 	}
-	if err := tx.QueryRow(ctx, `SELECT price::bigint, stock FROM products WHERE id = $1 FOR UPDATE`, p.ID).Scan(&original.Price, &original.Stock); err != nil {
-		return catalog.Product{}, err
+	if err := tx.QueryRow(ctx, `SELECT price::bigint, stock, slug FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, p.ID).Scan(&original.Price, &original.Stock, &original.Slug); err != nil {
+		return catalog.Product{}, productErrors.translate(err)
+	}
+	slug := original.Slug
+	if p.Slug != "" && p.Slug != original.Slug {
+		var err error
+		if slug, err = freeProductSlug(ctx, tx, p.Slug, p.ID); err != nil {
+			return catalog.Product{}, productErrors.translate(err)
+		}
+	}
+	if slug != original.Slug {
+		// el slug viejo sigue resolviendo via historial; si el nuevo era uno propio anterior, se libera.
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_slug_history (slug, product_id) VALUES ($1, $2)
+			ON CONFLICT (slug) DO NOTHING
+		`, original.Slug, p.ID); err != nil {
+			return catalog.Product{}, productErrors.translate(err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM product_slug_history WHERE slug = $1`, slug); err != nil {
+			return catalog.Product{}, productErrors.translate(err)
+		}
 	}
-	row := tx.QueryRow(ctx, `
+	out, err := scanProduct(tx.QueryRow(ctx, `
 		UPDATE products
-		SET name = $1, description = $2, price = $3, stock = $4, updated_at = NOW()
-		WHERE id = $5
-		RETURNING id, name, description, price, stock, created_at, updated_at
-	`, p.Name, p.Description, p.Price, p.Stock, p.ID)
-	var out catalog.Product
-	if err := row.Scan(&out.ID, &out.Name, &out.Description, &out.Price, &out.Stock, &out.CreatedAt, &out.UpdatedAt); err != nil {
-		return catalog.Product{}, err
+		SET name = $1, slug = $2, description = $3, price = $4, currency = NULLIF($5, ''), barcode = NULLIF($6, ''), stock = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING `+productColumns, p.Name, slug, p.Description, p.Price, p.Currency, p.Barcode, p.Stock, p.ID))
+	if err != nil {
+		return catalog.Product{}, translateProductWriteError(err)
 	}
 	// Guarda historial solo cuando cambia precio o stock.
 	if original.Price != out.Price || original.Stock != out.Stock {
@@ -250,22 +682,140 @@ func (r *CatalogRepository) UpdateProduct(ctx context.Context, p catalog.Product
 			INSERT INTO product_history (product_id, price, stock)
 			VALUES ($1, $2, $3)
 		`, out.ID, out.Price, out.Stock); err != nil {
-			return catalog.Product{}, err
+			return catalog.Product{}, productErrors.translate(err)
+		}
+	}
+	return out, nil
+}
+
+// BulkAdjustStock bloquea todos los productos del lote en orden de id (evita deadlocks
+// entre lotes concurrentes), valida el stock resultante y recien entonces escribe.
+func (r *CatalogRepository) BulkAdjustStock(ctx context.Context, adjustments []catalog.StockAdjustment) ([]catalog.Product, error) {
+	if r.pool == nil {
+		return nil, catalog.ErrRepositoryNotConfigured
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, productErrors.translate(err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids := make([]string, 0, len(adjustments))
+	for _, adj := range adjustments {
+		ids = append(ids, adj.ProductID)
+	}
+	rows, err := tx.Query(ctx, `SELECT id, stock FROM products WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL ORDER BY id FOR UPDATE`, ids)
+	if err != nil {
+		return nil, productErrors.translate(err)
+	}
+	current := make(map[string]int64, len(ids))
+	for rows.Next() {
+		var id string
+		var stock int64
+		if err := rows.Scan(&id, &stock); err != nil {
+			rows.Close()
+			return nil, productErrors.translate(err)
+		}
+		current[id] = stock
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, productErrors.translate(err)
+	}
+	for i, adj := range adjustments {
+		stock, ok := current[adj.ProductID]
+		if !ok {
+			return nil, &catalog.BulkStockError{Index: i, ProductID: adj.ProductID, Err: catalog.ErrProductNotFound}
+		}
+		if stock+adj.Delta < 0 {
+			return nil, &catalog.BulkStockError{Index: i, ProductID: adj.ProductID, Err: catalog.ErrInsufficientStock}
+		}
+	}
+
+	out := make([]catalog.Product, 0, len(adjustments))
+	for _, adj := range adjustments {
+		p, err := scanProduct(tx.QueryRow(ctx, `
+			UPDATE products SET stock = stock + $1, updated_at = NOW()
+			WHERE id = $2
+			RETURNING `+productColumns, adj.Delta, adj.ProductID))
+		if err != nil {
+			return nil, productErrors.translate(err)
 		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_history (product_id, price, stock)
+			VALUES ($1, $2, $3)
+		`, p.ID, p.Price, p.Stock); err != nil {
+			return nil, productErrors.translate(err)
+		}
+		out = append(out, p)
 	}
 	if err := tx.Commit(ctx); err != nil {
-		return catalog.Product{}, err
+		return nil, productErrors.translate(err)
 	}
 	return out, nil
 }
 
-// DeleteProduct elimina un producto por ID.
+// DeleteProduct hace soft-delete: marca deleted_at y conserva historial y relaciones.
+// Borrar un producto ya borrado devuelve ErrProductNotFound.
 func (r *CatalogRepository) DeleteProduct(ctx context.Context, id string) error {
 	if r.pool == nil {
 		return catalog.ErrRepositoryNotConfigured
 	}
-	_, err := r.pool.Exec(ctx, `DELETE FROM products WHERE id = $1`, id)
-	return err
+	tag, err := r.pool.Exec(ctx, `UPDATE products SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return productErrors.translate(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return catalog.ErrProductNotFound
+	}
+	return nil
+}
+
+// RestoreProduct deshace un soft-delete. restored es false si el producto existia y no
+// estaba borrado, en cuyo caso se devuelve sin cambios. Si otro producto vigente tomo su
+// barcode devuelve ErrDuplicateBarcode.
+func (r *CatalogRepository) RestoreProduct(ctx context.Context, id string) (catalog.Product, bool, error) {
+	if r.pool == nil {
+		return catalog.Product{}, false, catalog.ErrRepositoryNotConfigured
+	}
+	p, err := scanProduct(r.pool.QueryRow(ctx, `
+		UPDATE products SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING `+productColumns, id))
+	if err == nil {
+		return p, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return catalog.Product{}, false, translateProductWriteError(err)
+	}
+	p, err = scanProduct(r.pool.QueryRow(ctx, `SELECT `+productColumns+` FROM products WHERE id = $1`, id))
+	if err != nil {
+		return catalog.Product{}, false, productErrors.translate(err)
+	}
+	return p, false, nil
+}
+
+// PublishProduct quita la marca de borrador. published es false si el producto ya
+// estaba publicado, en cuyo caso se devuelve sin cambios.
+func (r *CatalogRepository) PublishProduct(ctx context.Context, id string) (catalog.Product, bool, error) {
+	if r.pool == nil {
+		return catalog.Product{}, false, catalog.ErrRepositoryNotConfigured
+	}
+	p, err := scanProduct(r.pool.QueryRow(ctx, `
+		UPDATE products SET draft = false, updated_at = NOW()
+		WHERE id = $1 AND draft AND deleted_at IS NULL
+		RETURNING `+productColumns, id))
+	if err == nil {
+		return p, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return catalog.Product{}, false, productErrors.translate(err)
+	}
+	p, err = scanProduct(r.pool.QueryRow(ctx, `SELECT `+productColumns+` FROM products WHERE id = $1 AND deleted_at IS NULL`, id))
+	if err != nil {
+		return catalog.Product{}, false, productErrors.translate(err)
+	}
+	return p, false, nil
 }
 
 // ListProductHistory devuelve historial de precio/stock de un producto.
@@ -287,25 +837,67 @@ func (r *CatalogRepository) ListProductHistory(ctx context.Context, id string, f
 		idx++
 	}
 	where := strings.Join(clauses, " AND ")
-	rows, err := r.pool.Query(ctx, fmt.Sprintf(`
+	rows, err := r.reader(ctx).Query(ctx, fmt.Sprintf(`
 		SELECT id, product_id, price::bigint, stock, changed_at
 		FROM product_history
 		WHERE %s
 		ORDER BY changed_at DESC
 	`, where), args...)
 	if err != nil {
-		return nil, err
+		return nil, productErrors.translate(err)
 	}
 	defer rows.Close()
 	var items []catalog.ProductHistory
 	for rows.Next() {
 		var h catalog.ProductHistory
 		if err := rows.Scan(&h.ID, &h.ProductID, &h.Price, &h.Stock, &h.ChangedAt); err != nil {
-			return nil, err
+			return nil, productErrors.translate(err)
 		}
 		items = append(items, h)
 	}
-	return items, rows.Err()
+	return items, productErrors.translate(rows.Err())
+}
+
+// GetLatestProductHistory lee solo la ultima entrada en lugar de la serie completa.
+func (r *CatalogRepository) GetLatestProductHistory(ctx context.Context, id string) (catalog.ProductHistory, error) {
+	if r.pool == nil {
+		return catalog.ProductHistory{}, catalog.ErrRepositoryNotConfigured
+	}
+	var h catalog.ProductHistory
+	err := r.reader(ctx).QueryRow(ctx, `
+		SELECT id, product_id, price::bigint, stock, changed_at
+		FROM product_history
+		WHERE product_id = $1
+		ORDER BY changed_at DESC, id DESC
+		LIMIT 1
+	`, id).Scan(&h.ID, &h.ProductID, &h.Price, &h.Stock, &h.ChangedAt)
+	if err != nil {
+		return catalog.ProductHistory{}, historyErrors.translate(err)
+	}
+	return h, nil
+}
+
+// PruneProductHistory borra en una sola sentencia las entradas anteriores a olderThan
+// que no esten entre las keep mas recientes de su producto.
+func (r *CatalogRepository) PruneProductHistory(ctx context.Context, olderThan time.Time, keep int) (int64, error) {
+	if r.pool == nil {
+		return 0, catalog.ErrRepositoryNotConfigured
+	}
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM product_history
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, changed_at,
+					ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY changed_at DESC, id DESC) AS rn
+				FROM product_history
+			) ranked
+			WHERE ranked.rn > $2 AND ranked.changed_at < $1
+		)
+	`, olderThan, keep)
+	if err != nil {
+		return 0, productErrors.translate(err)
+	}
+	return tag.RowsAffected(), nil
 }
 
 // AssignProductCategory relaciona un producto con una categoria (muchos a muchos).
@@ -318,24 +910,129 @@ func (r *CatalogRepository) AssignProductCategory(ctx context.Context, productID
 		VALUES ($1, $2)
 		ON CONFLICT DO NOTHING
 	`, productID, categoryID)
-	return err
+	return translateAssignError(err)
+}
+
+// UnassignProductCategory borra la relacion; removed es false si no existia.
+func (r *CatalogRepository) UnassignProductCategory(ctx context.Context, productID, categoryID string) (bool, error) {
+	if r.pool == nil {
+		return false, catalog.ErrRepositoryNotConfigured
+	}
+	tag, err := r.pool.Exec(ctx, `DELETE FROM product_category WHERE product_id = $1 AND category_id = $2`, productID, categoryID)
+	if err != nil {
+		return false, productErrors.translate(err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListProductCategories devuelve las categorias de un producto ordenadas por nombre.
+func (r *CatalogRepository) ListProductCategories(ctx context.Context, productID string) ([]catalog.Category, error) {
+	if r.pool == nil {
+		return nil, catalog.ErrRepositoryNotConfigured
+	}
+	// sin esta verificacion un producto inexistente y uno sin categorias darian la misma lista vacia.
+	exists, err := r.ProductExists(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, catalog.ErrProductNotFound
+	}
+	return r.productCategories(ctx, productID)
+}
+
+// productCategories une product_category con categories sin verificar el producto.
+func (r *CatalogRepository) productCategories(ctx context.Context, productID string) ([]catalog.Category, error) {
+	rows, err := r.reader(ctx).Query(ctx, `
+		SELECT c.id, c.name, c.slug, c.description, c.display_order, c.created_at, c.updated_at
+		FROM categories c
+		JOIN product_category pc ON pc.category_id = c.id
+		WHERE pc.product_id = $1
+		ORDER BY c.name
+	`, productID)
+	if err != nil {
+		return nil, categoryErrors.translate(err)
+	}
+	defer rows.Close()
+	items := []catalog.Category{}
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, categoryErrors.translate(err)
+		}
+		items = append(items, c)
+	}
+	return items, categoryErrors.translate(rows.Err())
+}
+
+// translateAssignError distingue por constraint cual de los dos extremos no existe.
+func translateAssignError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		if strings.Contains(pgErr.ConstraintName, "category_id") {
+			return wrapDomain(catalog.ErrCategoryNotFound, err)
+		}
+		return wrapDomain(catalog.ErrProductNotFound, err)
+	}
+	return productErrors.translate(err)
 }
 
 func buildProductOrderClause(sortBy, sortDir string) string {
-	field := "created_at"
+	field := catalog.DefaultProductSort
 	switch sortBy {
-	case "name":
-		field = "name"
-	case "price":
-		field = "price"
-	case "stock":
-		field = "stock"
-	case "created_at":
-		field = "created_at"
-	}
-	dir := strings.ToUpper(sortDir)
-	if dir != "ASC" {
+	case catalog.SortByName, catalog.SortByPrice, catalog.SortByStock, catalog.SortByCreatedAt:
+		field = sortBy
+	}
+	dir := "DESC"
+	if strings.EqualFold(sortDir, catalog.SortAsc) {
+		dir = "ASC"
+	}
+	return fmt.Sprintf("ORDER BY %s %s", field, dir)
+}
+
+// relevanceOrderClause ordena por catalog.NameRelevance: nombre exacto ($exact), prefijo
+// ($prefix), contenido ($1) y el resto; los empates van por created_at DESC.
+func relevanceOrderClause(exact, prefix int) string {
+	return fmt.Sprintf(`ORDER BY CASE
+			WHEN lower(name) = lower($%d) THEN 0
+			WHEN name ILIKE $%d THEN 1
+			WHEN name ILIKE $1 THEN 2
+			ELSE 3
+		END, created_at DESC`, exact, prefix)
+}
+
+// buildCategoryOrderClause admite name y created_at; cualquier otro campo ordena por
+// nombre. A diferencia de productos, la direccion por defecto es ASC (orden alfabetico).
+func buildCategoryOrderClause(sortBy, sortDir string) string {
+	field := catalog.DefaultCategorySort
+	if sortBy == catalog.SortByCreatedAt {
+		field = catalog.SortByCreatedAt
+	}
+	dir := "ASC"
+	if strings.EqualFold(sortDir, catalog.SortDesc) {
 		dir = "DESC"
 	}
 	return fmt.Sprintf("ORDER BY %s %s", field, dir)
 }
+
+// exists ejecuta un SELECT EXISTS; un id que no es UUID valido se trata como inexistente.
+func (r *CatalogRepository) exists(ctx context.Context, query, id string) (bool, error) {
+	if r.pool == nil {
+		return false, catalog.ErrRepositoryNotConfigured
+	}
+	var found bool
+	if err := r.reader(ctx).QueryRow(ctx, query, id).Scan(&found); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentation {
+			return false, nil
+		}
+		return false, err
+	}
+	return found, nil
+}
+
+// headlineExpr arma un ts_headline sobre nombre y descripcion. El parametro trae el
+// patron ILIKE ("%q%"), por eso se limpian los comodines antes de armar el tsquery.
+func headlineExpr(param string) string {
+	return fmt.Sprintf(`ts_headline('simple', name || ' ' || COALESCE(description, ''), plainto_tsquery('simple', trim(both '%%' from %s)), 'StartSel=<mark>, StopSel=</mark>, MaxFragments=1, MaxWords=20, MinWords=5') AS snippet`, param)
+}
@@ -8,12 +8,14 @@ import (
 
 	"catalog-api/internal/catalog"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	pgxmock "github.com/pashagolub/pgxmock/v3"
 )
 
 func TestCatalogRepository_ListCategoriesNilPool(t *testing.T) {
 	repo := &CatalogRepository{}
-	if _, err := repo.ListCategories(context.Background()); !errors.Is(err, catalog.ErrRepositoryNotConfigured) {
+	if _, err := repo.ListCategories(context.Background(), catalog.CategoryFilter{}); !errors.Is(err, catalog.ErrRepositoryNotConfigured) {
 		t.Fatalf("expected ErrRepositoryNotConfigured, got %v", err)
 	}
 }
@@ -27,12 +29,12 @@ func TestCatalogRepository_ListCategories(t *testing.T) {
 	defer mock.Close()
 
 	now := time.Now()
-	mock.ExpectQuery(`SELECT id, name, description, created_at, updated_at FROM categories ORDER BY name`).
-		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-			AddRow("c1", "Books", "All", now, now))
+	mock.ExpectQuery(`SELECT id, name, slug, description, display_order, created_at, updated_at FROM categories ORDER BY display_order = 0, display_order, name`).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "display_order", "created_at", "updated_at"}).
+			AddRow("c1", "Books", "books", "All", 0, now, now))
 
 	repo := &CatalogRepository{pool: mock}
-	items, err := repo.ListCategories(ctx)
+	items, err := repo.ListCategories(ctx, catalog.CategoryFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -44,6 +46,33 @@ func TestCatalogRepository_ListCategories(t *testing.T) {
 	}
 }
 
+func TestCatalogRepository_ListCategoriesPaginated(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, name, slug, description, display_order, created_at, updated_at FROM categories ORDER BY display_order = 0, display_order, name LIMIT \$1 OFFSET \$2`).
+		WithArgs(10, 20).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "display_order", "created_at", "updated_at"}).
+			AddRow("c1", "Books", "books", "All", 0, now, now))
+
+	repo := &CatalogRepository{pool: mock}
+	items, err := repo.ListCategories(ctx, catalog.CategoryFilter{Limit: 10, Offset: 20})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("unexpected categories %+v", items)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestCatalogRepository_UpdateProductRecordsHistoryOnChange(t *testing.T) {
 	ctx := context.Background()
 	mock, err := pgxmock.NewPool()
@@ -53,14 +82,14 @@ func TestCatalogRepository_UpdateProductRecordsHistoryOnChange(t *testing.T) {
 	defer mock.Close()
 
 	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT price::bigint, stock FROM products WHERE id = \$1 FOR UPDATE`).
+	mock.ExpectQuery(`SELECT price::bigint, stock, slug FROM products WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
 		WithArgs("p1").
-		WillReturnRows(pgxmock.NewRows([]string{"price", "stock"}).AddRow(int64(10), int64(5)))
+		WillReturnRows(pgxmock.NewRows([]string{"price", "stock", "slug"}).AddRow(int64(10), int64(5), "pen"))
 
-	mock.ExpectQuery(`UPDATE products\s+SET name = \$1, description = \$2, price = \$3, stock = \$4, updated_at = NOW\(\)\s+WHERE id = \$5\s+RETURNING id, name, description, price, stock, created_at, updated_at`).
-		WithArgs("Pen", "Red", int64(12), int64(3), "p1").
-		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "price", "stock", "created_at", "updated_at"}).
-			AddRow("p1", "Pen", "Red", int64(12), int64(3), time.Now(), time.Now()))
+	mock.ExpectQuery(`UPDATE products\s+SET name = \$1, slug = \$2, description = \$3, price = \$4, currency = NULLIF\(\$5, ''\), barcode = NULLIF\(\$6, ''\), stock = \$7, updated_at = NOW\(\)\s+WHERE id = \$8\s+RETURNING id, name, slug, description, price, COALESCE\(currency, ''\), COALESCE\(barcode, ''\), stock, created_at, updated_at`).
+		WithArgs("Pen", "pen", "Red", int64(12), "USD", "", int64(3), "p1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}).
+			AddRow("p1", "Pen", "pen", "Red", int64(12), "USD", "", int64(3), time.Now(), time.Now(), false))
 
 	mock.ExpectExec(`INSERT INTO product_history \(product_id, price, stock\)\s+VALUES \(\$1, \$2, \$3\)`).
 		WithArgs("p1", int64(12), int64(3)).
@@ -73,6 +102,7 @@ func TestCatalogRepository_UpdateProductRecordsHistoryOnChange(t *testing.T) {
 		Name:        "Pen",
 		Description: "Red",
 		Price:       12,
+		Currency:    "USD",
 		Stock:       3,
 	})
 	if err != nil {
@@ -86,6 +116,48 @@ func TestCatalogRepository_UpdateProductRecordsHistoryOnChange(t *testing.T) {
 	}
 }
 
+func TestCatalogRepository_UpdateProductRenameKeepsOldSlug(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT price::bigint, stock, slug FROM products WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows([]string{"price", "stock", "slug"}).AddRow(int64(10), int64(5), "pen"))
+	mock.ExpectQuery(`SELECT slug FROM products WHERE id::text <> \$3 .*UNION\s+SELECT slug FROM product_slug_history`).
+		WithArgs("fountain-pen", "fountain-pen-%", "p1").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}).AddRow("fountain-pen"))
+	mock.ExpectExec(`INSERT INTO product_slug_history \(slug, product_id\) VALUES \(\$1, \$2\)`).
+		WithArgs("pen", "p1").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(`DELETE FROM product_slug_history WHERE slug = \$1`).
+		WithArgs("fountain-pen-2").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectQuery(`UPDATE products`).
+		WithArgs("Fountain Pen", "fountain-pen-2", "", int64(10), "USD", "", int64(5), "p1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}).
+			AddRow("p1", "Fountain Pen", "fountain-pen-2", "", int64(10), "USD", "", int64(5), time.Now(), time.Now(), false))
+	mock.ExpectCommit()
+
+	repo := &CatalogRepository{pool: mock}
+	updated, err := repo.UpdateProduct(ctx, catalog.Product{
+		ID: "p1", Name: "Fountain Pen", Slug: "fountain-pen", Price: 10, Currency: "USD", Stock: 5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Slug != "fountain-pen-2" {
+		t.Fatalf("expected suffixed slug, got %q", updated.Slug)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestCatalogRepository_UpdateProductRollsBackOnHistoryFailure(t *testing.T) {
 	ctx := context.Background()
 	mock, err := pgxmock.NewPool()
@@ -95,14 +167,14 @@ func TestCatalogRepository_UpdateProductRollsBackOnHistoryFailure(t *testing.T)
 	defer mock.Close()
 
 	mock.ExpectBegin()
-	mock.ExpectQuery(`SELECT price::bigint, stock FROM products WHERE id = \$1 FOR UPDATE`).
+	mock.ExpectQuery(`SELECT price::bigint, stock, slug FROM products WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
 		WithArgs("p1").
-		WillReturnRows(pgxmock.NewRows([]string{"price", "stock"}).AddRow(int64(10), int64(5)))
+		WillReturnRows(pgxmock.NewRows([]string{"price", "stock", "slug"}).AddRow(int64(10), int64(5), "pen"))
 
-	mock.ExpectQuery(`UPDATE products\s+SET name = \$1, description = \$2, price = \$3, stock = \$4, updated_at = NOW\(\)\s+WHERE id = \$5\s+RETURNING id, name, description, price, stock, created_at, updated_at`).
-		WithArgs("Pen", "Red", int64(12), int64(3), "p1").
-		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "price", "stock", "created_at", "updated_at"}).
-			AddRow("p1", "Pen", "Red", int64(12), int64(3), time.Now(), time.Now()))
+	mock.ExpectQuery(`UPDATE products\s+SET name = \$1, slug = \$2, description = \$3, price = \$4, currency = NULLIF\(\$5, ''\), barcode = NULLIF\(\$6, ''\), stock = \$7, updated_at = NOW\(\)\s+WHERE id = \$8\s+RETURNING id, name, slug, description, price, COALESCE\(currency, ''\), COALESCE\(barcode, ''\), stock, created_at, updated_at`).
+		WithArgs("Pen", "pen", "Red", int64(12), "USD", "", int64(3), "p1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}).
+			AddRow("p1", "Pen", "pen", "Red", int64(12), "USD", "", int64(3), time.Now(), time.Now(), false))
 
 	mock.ExpectExec(`INSERT INTO product_history \(product_id, price, stock\)\s+VALUES \(\$1, \$2, \$3\)`).
 		WithArgs("p1", int64(12), int64(3)).
@@ -115,6 +187,7 @@ func TestCatalogRepository_UpdateProductRollsBackOnHistoryFailure(t *testing.T)
 		Name:        "Pen",
 		Description: "Red",
 		Price:       12,
+		Currency:    "USD",
 		Stock:       3,
 	}); err == nil {
 		t.Fatalf("expected error when history insert fails")
@@ -124,6 +197,68 @@ func TestCatalogRepository_UpdateProductRollsBackOnHistoryFailure(t *testing.T)
 	}
 }
 
+func TestCatalogRepository_RequestPriceChangeRollsBackProductOnInsertFailure(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT price::bigint, stock, slug FROM products WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows([]string{"price", "stock", "slug"}).AddRow(int64(10), int64(5), "pen"))
+	mock.ExpectQuery(`UPDATE products\s+SET name = \$1`).
+		WithArgs("Pen", "pen", "Red", int64(10), "USD", "", int64(5), "p1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}).
+			AddRow("p1", "Pen", "pen", "Red", int64(10), "USD", "", int64(5), time.Now(), time.Now(), false))
+	mock.ExpectQuery(`INSERT INTO price_change_requests \(product_id, old_price, new_price\)`).
+		WithArgs("p1", int64(10), int64(50)).
+		WillReturnError(errors.New("insert fail"))
+	mock.ExpectRollback()
+
+	repo := &CatalogRepository{pool: mock}
+	_, _, err = repo.RequestPriceChange(ctx,
+		catalog.Product{ID: "p1", Name: "Pen", Description: "Red", Price: 10, Currency: "USD", Stock: 5},
+		catalog.PriceChangeRequest{ProductID: "p1", OldPrice: 10, NewPrice: 50})
+	if err == nil {
+		t.Fatalf("expected error when the request insert fails")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ApprovePriceChangeRejectsStaleRequest(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM price_change_requests\s+WHERE id = \$1\s+FOR UPDATE`).
+		WithArgs("r1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "product_id", "old_price", "new_price", "status", "created_at", "decided_at"}).
+			AddRow("r1", "p1", int64(100), int64(20), "pending", now, nil))
+	// otra solicitud ya llevo el precio a 200: aplicar esta revertiria ese cambio.
+	mock.ExpectQuery(`SELECT price::bigint FROM products WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows([]string{"price"}).AddRow(int64(200)))
+	mock.ExpectRollback()
+
+	repo := &CatalogRepository{pool: mock}
+	if _, _, err := repo.ApprovePriceChangeRequest(ctx, "r1"); !errors.Is(err, catalog.ErrPriceChangeStale) {
+		t.Fatalf("expected ErrPriceChangeStale, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestCatalogRepository_SearchCategoriesWithQuery(t *testing.T) {
 	ctx := context.Background()
 	mock, err := pgxmock.NewPool()
@@ -132,10 +267,10 @@ func TestCatalogRepository_SearchCategoriesWithQuery(t *testing.T) {
 	}
 	defer mock.Close()
 
-	mock.ExpectQuery(`SELECT id, name, description, created_at, updated_at FROM categories WHERE \(name ILIKE \$1 OR description ILIKE \$1\) ORDER BY name LIMIT \$2 OFFSET \$3`).
+	mock.ExpectQuery(`SELECT id, name, slug, description, display_order, created_at, updated_at FROM categories WHERE \(name ILIKE \$1 OR description ILIKE \$1\) ORDER BY name ASC LIMIT \$2 OFFSET \$3`).
 		WithArgs("%bo%", 10, 5).
-		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-			AddRow("c1", "Books", "All", time.Now(), time.Now()))
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "display_order", "created_at", "updated_at"}).
+			AddRow("c1", "Books", "books", "All", 0, time.Now(), time.Now()))
 
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM categories WHERE \(name ILIKE \$1 OR description ILIKE \$1\)`).
 		WithArgs("%bo%").
@@ -157,3 +292,975 @@ func TestCatalogRepository_SearchCategoriesWithQuery(t *testing.T) {
 		t.Fatalf("unmet expectations: %v", err)
 	}
 }
+
+func TestCatalogRepository_SearchCategoriesSort(t *testing.T) {
+	cases := []struct {
+		name    string
+		sortBy  string
+		sortDir string
+		order   string
+	}{
+		{name: "default", order: `ORDER BY name ASC`},
+		{name: "name desc", sortBy: "name", sortDir: "desc", order: `ORDER BY name DESC`},
+		{name: "created_at", sortBy: "created_at", sortDir: "DESC", order: `ORDER BY created_at DESC`},
+		{name: "unknown field falls back to name", sortBy: "price; DROP TABLE categories", order: `ORDER BY name ASC`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatalf("failed to create pgxmock: %v", err)
+			}
+			defer mock.Close()
+
+			mock.ExpectQuery(`FROM categories WHERE 1=1 `+tc.order+` LIMIT \$1 OFFSET \$2`).
+				WithArgs(20, 0).
+				WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "display_order", "created_at", "updated_at"}))
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM categories`).
+				WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(0)))
+
+			repo := &CatalogRepository{pool: mock}
+			if _, _, err := repo.SearchCategories(context.Background(), catalog.SearchFilter{
+				Limit:   20,
+				SortBy:  tc.sortBy,
+				SortDir: tc.sortDir,
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestCatalogRepository_GetLatestProductHistory(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	columns := []string{"id", "product_id", "price", "stock", "changed_at"}
+	mock.ExpectQuery(`FROM product_history\s+WHERE product_id = \$1\s+ORDER BY changed_at DESC, id DESC\s+LIMIT 1`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows(columns).AddRow("h2", "p1", int64(1200), int64(3), now))
+	mock.ExpectQuery(`FROM product_history`).
+		WithArgs("p2").
+		WillReturnError(pgx.ErrNoRows)
+
+	repo := &CatalogRepository{pool: mock}
+	entry, err := repo.GetLatestProductHistory(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.ID != "h2" || entry.Price != 1200 || entry.Stock != 3 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if _, err := repo.GetLatestProductHistory(context.Background(), "p2"); !errors.Is(err, catalog.ErrProductHistoryNotFound) {
+		t.Fatalf("expected ErrProductHistoryNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_PruneProductHistoryKeepsFloor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	cutoff := time.Now().Add(-90 * 24 * time.Hour)
+	mock.ExpectExec(`DELETE FROM product_history\s+WHERE id IN \(.*ROW_NUMBER\(\) OVER \(PARTITION BY product_id ORDER BY changed_at DESC, id DESC\).*WHERE ranked.rn > \$2 AND ranked.changed_at < \$1`).
+		WithArgs(cutoff, 5).
+		WillReturnResult(pgxmock.NewResult("DELETE", 12))
+
+	repo := &CatalogRepository{pool: mock}
+	removed, err := repo.PruneProductHistory(context.Background(), cutoff, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 12 {
+		t.Fatalf("expected 12 rows removed, got %d", removed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_BulkCreateCategoriesRollsBackOnConflict(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT slug FROM categories`).
+		WithArgs("books", "books-%").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}))
+	mock.ExpectQuery(`INSERT INTO categories \(name, slug, description\)`).
+		WithArgs("Books", "books", "").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "display_order", "created_at", "updated_at"}).
+			AddRow("c1", "Books", "books", "", 0, now, now))
+	mock.ExpectQuery(`SELECT slug FROM categories`).
+		WithArgs("audio", "audio-%").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}))
+	mock.ExpectQuery(`INSERT INTO categories \(name, slug, description\)`).
+		WithArgs("Audio", "audio", "").
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectRollback()
+
+	repo := &CatalogRepository{pool: mock}
+	_, err = repo.BulkCreateCategories(ctx, []catalog.Category{{Name: "Books", Slug: "books"}, {Name: "Audio", Slug: "audio"}})
+	var bulkErr *catalog.BulkCategoryError
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 1 || !errors.Is(err, catalog.ErrCategoryConflict) {
+		t.Fatalf("expected conflict at index 1, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_BulkAdjustStockRollsBackOnNegative(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, stock FROM products WHERE id = ANY\(\$1::uuid\[\]\) AND deleted_at IS NULL ORDER BY id FOR UPDATE`).
+		WithArgs([]string{"p1", "p2"}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stock"}).AddRow("p1", int64(10)).AddRow("p2", int64(2)))
+	// p2 quedaria en -3: no se ejecuta ningun UPDATE y la transaccion se revierte.
+	mock.ExpectRollback()
+
+	repo := &CatalogRepository{pool: mock}
+	_, err = repo.BulkAdjustStock(ctx, []catalog.StockAdjustment{{ProductID: "p1", Delta: 5}, {ProductID: "p2", Delta: -5}})
+	var bulkErr *catalog.BulkStockError
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 1 || bulkErr.ProductID != "p2" || !errors.Is(err, catalog.ErrInsufficientStock) {
+		t.Fatalf("expected insufficient stock at index 1, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_BulkAdjustStockAppliesAllWithHistory(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	columns := []string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, stock FROM products`).
+		WithArgs([]string{"p1", "p2"}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stock"}).AddRow("p1", int64(10)).AddRow("p2", int64(2)))
+	mock.ExpectQuery(`UPDATE products SET stock = stock \+ \$1`).
+		WithArgs(int64(5), "p1").
+		WillReturnRows(pgxmock.NewRows(columns).AddRow("p1", "Pen", "pen", "", int64(100), "USD", "", int64(15), now, now, false))
+	mock.ExpectExec(`INSERT INTO product_history`).
+		WithArgs("p1", int64(100), int64(15)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery(`UPDATE products SET stock = stock \+ \$1`).
+		WithArgs(int64(-2), "p2").
+		WillReturnRows(pgxmock.NewRows(columns).AddRow("p2", "Ink", "ink", "", int64(50), "USD", "", int64(0), now, now, false))
+	mock.ExpectExec(`INSERT INTO product_history`).
+		WithArgs("p2", int64(50), int64(0)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	repo := &CatalogRepository{pool: mock}
+	products, err := repo.BulkAdjustStock(ctx, []catalog.StockAdjustment{{ProductID: "p1", Delta: 5}, {ProductID: "p2", Delta: -2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(products) != 2 || products[0].Stock != 15 || products[1].Stock != 0 {
+		t.Fatalf("unexpected products %+v", products)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ReorderCategories(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	ids := []string{"c2", "c1", "c3"}
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE categories SET display_order = 0 WHERE display_order <> 0`).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectExec(`UPDATE categories c\s+SET display_order = o.rank\s+FROM unnest\(\$1::uuid\[\]\) WITH ORDINALITY AS o\(id, rank\)`).
+		WithArgs(ids).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 3))
+	mock.ExpectCommit()
+
+	repo := &CatalogRepository{pool: mock}
+	if err := repo.ReorderCategories(ctx, ids); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ReorderCategoriesRollsBackOnUnknownID(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	ids := []string{"c1", "missing"}
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE categories SET display_order = 0`).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec(`UPDATE categories c`).
+		WithArgs(ids).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectRollback()
+
+	repo := &CatalogRepository{pool: mock}
+	if err := repo.ReorderCategories(ctx, ids); !errors.Is(err, catalog.ErrCategoryNotFound) {
+		t.Fatalf("expected ErrCategoryNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ProductExists(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM products WHERE id = \$1 AND deleted_at IS NULL\)`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM products WHERE id = \$1 AND deleted_at IS NULL\)`).
+		WithArgs("not-a-uuid").
+		WillReturnError(&pgconn.PgError{Code: "22P02"})
+
+	repo := &CatalogRepository{pool: mock}
+	if ok, err := repo.ProductExists(ctx, "p1"); err != nil || !ok {
+		t.Fatalf("expected product to exist, got %v err=%v", ok, err)
+	}
+	if ok, err := repo.ProductExists(ctx, "not-a-uuid"); err != nil || ok {
+		t.Fatalf("expected invalid uuid to be reported as missing, got %v err=%v", ok, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ListProductCategories(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM products WHERE id = \$1 AND deleted_at IS NULL\)`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`FROM categories c\s+JOIN product_category pc ON pc.category_id = c.id\s+WHERE pc.product_id = \$1\s+ORDER BY c.name`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "display_order", "created_at", "updated_at"}).
+			AddRow("c1", "Audio", "audio", "", 0, now, now).
+			AddRow("c2", "Books", "books", "", 0, now, now))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM products WHERE id = \$1 AND deleted_at IS NULL\)`).
+		WithArgs("missing").
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+
+	repo := &CatalogRepository{pool: mock}
+	cats, err := repo.ListProductCategories(ctx, "p1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cats) != 2 || cats[0].ID != "c1" || cats[1].Slug != "books" {
+		t.Fatalf("unexpected categories %+v", cats)
+	}
+	if _, err := repo.ListProductCategories(ctx, "missing"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_GetProductDetail(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`FROM products WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}).
+			AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+	// el producto ya se leyo: no hace falta el EXISTS de ListProductCategories.
+	mock.ExpectQuery(`FROM categories c\s+JOIN product_category pc ON pc.category_id = c.id\s+WHERE pc.product_id = \$1\s+ORDER BY c.name`).
+		WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "display_order", "created_at", "updated_at"}).
+			AddRow("c1", "Office", "office", "", 0, now, now))
+	mock.ExpectQuery(`FROM products WHERE id = \$1 AND deleted_at IS NULL`).WithArgs("missing").WillReturnError(pgx.ErrNoRows)
+
+	repo := &CatalogRepository{pool: mock}
+	p, err := repo.GetProductDetail(ctx, "p1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.ID != "p1" || len(p.Categories) != 1 || p.Categories[0].Slug != "office" {
+		t.Fatalf("unexpected product %+v", p)
+	}
+	if _, err := repo.GetProductDetail(ctx, "missing"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ListProductsWithHighlight(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, name, slug, description, price, COALESCE\(currency, ''\), COALESCE\(barcode, ''\), stock, created_at, updated_at, draft, ts_headline\('simple', .*plainto_tsquery\('simple', trim\(both '%' from \$1\)\).*AS snippet\s+FROM products`).
+		WithArgs("%pen%", "pen", "pen%", 20, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft", "snippet"}).
+			AddRow("p1", "Pen", "pen", "Blue pen", int64(10), "USD", "", int64(1), now, now, false, "<mark>Pen</mark> Blue <mark>pen</mark>"))
+
+	repo := &CatalogRepository{pool: mock}
+	items, err := repo.ListProducts(ctx, catalog.ProductFilter{Query: "pen", Limit: 20, Highlight: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 || items[0].Snippet != "<mark>Pen</mark> Blue <mark>pen</mark>" {
+		t.Fatalf("expected highlighted snippet, got %+v", items)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ListProductsOrdersByRelevance(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	columns := []string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}
+	mock.ExpectQuery(`ORDER BY CASE\s+WHEN lower\(name\) = lower\(\$2\) THEN 0\s+WHEN name ILIKE \$3 THEN 1\s+WHEN name ILIKE \$1 THEN 2\s+ELSE 3\s+END, created_at DESC\s+LIMIT \$4 OFFSET \$5`).
+		WithArgs("%pen%", "pen", "pen%", 20, 0).
+		WillReturnRows(pgxmock.NewRows(columns).AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+	// un orden explicito sigue mandando aunque haya query.
+	mock.ExpectQuery(`ORDER BY price ASC\s+LIMIT \$2 OFFSET \$3`).
+		WithArgs("%pen%", 20, 0).
+		WillReturnRows(pgxmock.NewRows(columns))
+
+	repo := &CatalogRepository{pool: mock}
+	if _, err := repo.ListProducts(ctx, catalog.ProductFilter{Query: " pen ", Limit: 20}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.ListProducts(ctx, catalog.ProductFilter{Query: "pen", Limit: 20, SortBy: catalog.SortByPrice, SortDir: catalog.SortAsc}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestProductWhere_StockFilter(t *testing.T) {
+	minPrice, maxPrice := int64(100), int64(500)
+	cases := []struct {
+		filter catalog.ProductFilter
+		where  string
+		args   int
+	}{
+		{filter: catalog.ProductFilter{}, where: "deleted_at IS NULL"},
+		{filter: catalog.ProductFilter{Stock: catalog.StockOut}, where: "deleted_at IS NULL AND stock = 0"},
+		{filter: catalog.ProductFilter{Stock: catalog.StockIn}, where: "deleted_at IS NULL AND stock > 0"},
+		{filter: catalog.ProductFilter{Stock: catalog.StockLow, LowStockThreshold: 5}, where: "deleted_at IS NULL AND stock <= $1", args: 1},
+		{
+			filter: catalog.ProductFilter{Query: "pen", Stock: catalog.StockLow, LowStockThreshold: 5},
+			where:  "deleted_at IS NULL AND (name ILIKE $1 OR description ILIKE $1) AND stock <= $2",
+			args:   2,
+		},
+		{filter: catalog.ProductFilter{ExcludeDrafts: true}, where: "deleted_at IS NULL AND draft = false"},
+		{
+			filter: catalog.ProductFilter{Stock: catalog.StockLow, LowStockThreshold: 5, MinPrice: &minPrice, MaxPrice: &maxPrice},
+			where:  "deleted_at IS NULL AND stock <= $1 AND price >= $2 AND price <= $3",
+			args:   3,
+		},
+		{
+			filter: catalog.ProductFilter{Query: "pen", Stock: catalog.StockLow, LowStockThreshold: 5, CategoryID: "c1"},
+			where:  "deleted_at IS NULL AND (name ILIKE $1 OR description ILIKE $1) AND stock <= $2 AND pc.category_id = $3",
+			args:   3,
+		},
+	}
+	for _, tc := range cases {
+		where, args := productWhere(tc.filter)
+		if where != tc.where || len(args) != tc.args {
+			t.Fatalf("stock=%q: expected %q with %d args, got %q with %v", tc.filter.Stock, tc.where, tc.args, where, args)
+		}
+	}
+}
+
+func TestCatalogRepository_ListProductsByCategory(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	columns := []string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}
+	join := `FROM products JOIN product_category pc ON pc\.product_id = products\.id\s+`
+	// solo categoria: el id es $1 y la paginacion va despues.
+	mock.ExpectQuery(join+`WHERE deleted_at IS NULL AND pc\.category_id = \$1\s+ORDER BY created_at DESC\s+LIMIT \$2 OFFSET \$3`).
+		WithArgs("c1", 20, 0).
+		WillReturnRows(pgxmock.NewRows(columns).AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+	// con query y stock bajo la categoria queda tercera y la relevancia se corre a $4/$5.
+	mock.ExpectQuery(join+`WHERE deleted_at IS NULL AND \(name ILIKE \$1 OR description ILIKE \$1\) AND stock <= \$2 AND pc\.category_id = \$3\s+`+
+		`ORDER BY CASE\s+WHEN lower\(name\) = lower\(\$4\) THEN 0\s+WHEN name ILIKE \$5 THEN 1.*LIMIT \$6 OFFSET \$7`).
+		WithArgs("%pen%", 5, "c1", "pen", "pen%", 10, 5).
+		WillReturnRows(pgxmock.NewRows(columns))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products JOIN product_category pc ON pc\.product_id = products\.id WHERE deleted_at IS NULL AND stock = 0 AND pc\.category_id = \$1`).
+		WithArgs("c1").
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(2)))
+
+	repo := &CatalogRepository{pool: mock}
+	items, err := repo.ListProducts(ctx, catalog.ProductFilter{CategoryID: "c1", Limit: 20})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one product, got %+v err=%v", items, err)
+	}
+	if _, err := repo.ListProducts(ctx, catalog.ProductFilter{Query: "pen", Stock: catalog.StockLow, LowStockThreshold: 5, CategoryID: "c1", Limit: 10, Offset: 5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	total, err := repo.CountProducts(ctx, catalog.ProductFilter{Stock: catalog.StockOut, CategoryID: "c1"})
+	if err != nil || total != 2 {
+		t.Fatalf("expected count 2, got %d err=%v", total, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ListAndCountProductsByPriceRange(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	columns := []string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}
+	// la query va en $1 y el rango a continuacion; list y count comparten el WHERE.
+	where := `WHERE deleted_at IS NULL AND \(name ILIKE \$1 OR description ILIKE \$1\) AND price >= \$2 AND price <= \$3`
+	mock.ExpectQuery(`FROM products\s+`+where+`\s+ORDER BY price ASC\s+LIMIT \$4 OFFSET \$5`).
+		WithArgs("%pen%", int64(100), int64(500), 20, 0).
+		WillReturnRows(pgxmock.NewRows(columns).AddRow("p1", "Pen", "pen", "", int64(150), "USD", "", int64(1), now, now, false))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products `+where+`$`).
+		WithArgs("%pen%", int64(100), int64(500)).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(1)))
+	// solo max_price: queda en $1.
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE deleted_at IS NULL AND price <= \$1$`).
+		WithArgs(int64(0)).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(3)))
+
+	repo := &CatalogRepository{pool: mock}
+	minPrice, maxPrice := int64(100), int64(500)
+	filter := catalog.ProductFilter{Query: "pen", MinPrice: &minPrice, MaxPrice: &maxPrice, Limit: 20, SortBy: catalog.SortByPrice, SortDir: catalog.SortAsc}
+	if items, err := repo.ListProducts(ctx, filter); err != nil || len(items) != 1 {
+		t.Fatalf("expected one product, got %+v err=%v", items, err)
+	}
+	if total, err := repo.CountProducts(ctx, filter); err != nil || total != 1 {
+		t.Fatalf("expected count 1, got %d err=%v", total, err)
+	}
+	zero := int64(0)
+	if total, err := repo.CountProducts(ctx, catalog.ProductFilter{MaxPrice: &zero}); err != nil || total != 3 {
+		t.Fatalf("expected count 3, got %d err=%v", total, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_CountProductsLowStock(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM products WHERE deleted_at IS NULL AND stock <= \$1`).
+		WithArgs(5).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(4)))
+
+	repo := &CatalogRepository{pool: mock}
+	total, err := repo.CountProducts(context.Background(), catalog.ProductFilter{Stock: catalog.StockLow, LowStockThreshold: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 4 {
+		t.Fatalf("expected 4, got %d", total)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_GetProductNotFound(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`FROM products`).WithArgs("missing").WillReturnError(pgx.ErrNoRows)
+
+	repo := &CatalogRepository{pool: mock}
+	if _, err := repo.GetProduct(ctx, "missing"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_CreateCategorySuffixesTakenSlug(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT slug FROM categories WHERE slug = \$1 OR slug LIKE \$2`).
+		WithArgs("books", "books-%").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}).AddRow("books").AddRow("books-2").AddRow("books-old"))
+	mock.ExpectQuery(`INSERT INTO categories \(name, slug, description\)`).
+		WithArgs("Books!", "books-3", "").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "display_order", "created_at", "updated_at"}).
+			AddRow("c3", "Books!", "books-3", "", 0, now, now))
+
+	repo := &CatalogRepository{pool: mock}
+	cat, err := repo.CreateCategory(ctx, catalog.Category{Name: "Books!", Slug: "books"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cat.Slug != "books-3" {
+		t.Fatalf("expected books-3, got %q", cat.Slug)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_GetCategoryBySlugNotFound(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`FROM categories WHERE slug = \$1`).
+		WithArgs("missing").
+		WillReturnError(pgx.ErrNoRows)
+
+	repo := &CatalogRepository{pool: mock}
+	_, err = repo.GetCategoryBySlug(ctx, "missing")
+	if !errors.Is(err, catalog.ErrCategoryNotFound) {
+		t.Fatalf("expected ErrCategoryNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_CreateCategoryConflict(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT slug FROM categories`).
+		WithArgs("books", "books-%").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}))
+	mock.ExpectQuery(`INSERT INTO categories`).WithArgs("Books", "books", "").WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	repo := &CatalogRepository{pool: mock}
+	_, err = repo.CreateCategory(ctx, catalog.Category{Name: "Books", Slug: "books"})
+	if !errors.Is(err, catalog.ErrCategoryConflict) {
+		t.Fatalf("expected ErrCategoryConflict, got %v", err)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		t.Fatalf("expected original PgError to be preserved")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_CreateProductDuplicateBarcode(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT slug FROM products`).
+		WithArgs("pen", "pen-%", "").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}))
+	mock.ExpectQuery(`INSERT INTO products \(name, slug, description, price, currency, barcode, stock, draft\)`).
+		WithArgs("Pen", "pen", "", int64(10), "USD", "4006381333931", int64(1), false).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "products_barcode_key"})
+
+	repo := &CatalogRepository{pool: mock}
+	_, err = repo.CreateProduct(ctx, catalog.Product{Name: "Pen", Slug: "pen", Price: 10, Currency: "USD", Barcode: "4006381333931", Stock: 1})
+	if !errors.Is(err, catalog.ErrDuplicateBarcode) {
+		t.Fatalf("expected ErrDuplicateBarcode, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_CreateProductsRollsBackOnDuplicateBarcode(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT slug FROM products`).
+		WithArgs("pen", "pen-%", "").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}))
+	mock.ExpectQuery(`INSERT INTO products`).
+		WithArgs("Pen", "pen", "", int64(10), "USD", "", int64(1), false).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}).
+			AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+	mock.ExpectQuery(`SELECT slug FROM products`).
+		WithArgs("ink", "ink-%", "").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}))
+	mock.ExpectQuery(`INSERT INTO products`).
+		WithArgs("Ink", "ink", "", int64(5), "USD", "4006381333931", int64(2), false).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "products_barcode_key"})
+	mock.ExpectRollback()
+
+	repo := &CatalogRepository{pool: mock}
+	_, err = repo.CreateProducts(ctx, []catalog.Product{
+		{Name: "Pen", Slug: "pen", Price: 10, Currency: "USD", Stock: 1},
+		{Name: "Ink", Slug: "ink", Price: 5, Currency: "USD", Barcode: "4006381333931", Stock: 2},
+	})
+	var bulkErr *catalog.BulkProductError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Items) != 1 || bulkErr.Items[0].Index != 1 || !errors.Is(err, catalog.ErrDuplicateBarcode) {
+		t.Fatalf("expected duplicate barcode at index 1, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_CreateProductRetriesPrimaryKeyCollision(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT slug FROM products`).
+		WithArgs("pen", "pen-%", "").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}))
+	mock.ExpectQuery(`INSERT INTO products`).
+		WithArgs("Pen", "pen", "", int64(10), "USD", "", int64(1), false).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "products_pkey"})
+	mock.ExpectQuery(`INSERT INTO products`).
+		WithArgs("Pen", "pen", "", int64(10), "USD", "", int64(1), false).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}).
+			AddRow("p2", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+
+	repo := NewCatalogRepository(mock, WithIDCollisionRetries(1, nil))
+	out, err := repo.CreateProduct(ctx, catalog.Product{Name: "Pen", Slug: "pen", Price: 10, Currency: "USD", Stock: 1})
+	if err != nil || out.ID != "p2" {
+		t.Fatalf("expected insert to succeed after retry, got %+v (%v)", out, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_CreateProductGivesUpAfterRetries(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT slug FROM products`).
+		WithArgs("pen", "pen-%", "").
+		WillReturnRows(pgxmock.NewRows([]string{"slug"}))
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(`INSERT INTO products`).
+			WithArgs("Pen", "pen", "", int64(10), "USD", "", int64(1), false).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "products_pkey"})
+	}
+
+	repo := NewCatalogRepository(mock, WithIDCollisionRetries(1, nil))
+	_, err = repo.CreateProduct(ctx, catalog.Product{Name: "Pen", Slug: "pen", Price: 10, Currency: "USD", Stock: 1})
+	if err == nil || errors.Is(err, catalog.ErrDuplicateBarcode) {
+		t.Fatalf("expected the collision to surface after the last retry, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_AssignProductCategoryForeignKey(t *testing.T) {
+	cases := []struct {
+		constraint string
+		want       error
+	}{
+		{"product_category_product_id_fkey", catalog.ErrProductNotFound},
+		{"product_category_category_id_fkey", catalog.ErrCategoryNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.constraint, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatalf("failed to create pgxmock: %v", err)
+			}
+			defer mock.Close()
+
+			mock.ExpectExec(`INSERT INTO product_category`).WithArgs("p1", "c1").
+				WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: tc.constraint})
+
+			repo := &CatalogRepository{pool: mock}
+			if err := repo.AssignProductCategory(context.Background(), "p1", "c1"); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestCatalogRepository_UnassignProductCategory(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectExec(`DELETE FROM product_category WHERE product_id = \$1 AND category_id = \$2`).WithArgs("p1", "c1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec(`DELETE FROM product_category`).WithArgs("p1", "c1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	ctx := context.Background()
+	repo := &CatalogRepository{pool: mock}
+	if removed, err := repo.UnassignProductCategory(ctx, "p1", "c1"); err != nil || !removed {
+		t.Fatalf("expected removal, got removed=%v err=%v", removed, err)
+	}
+	if removed, err := repo.UnassignProductCategory(ctx, "p1", "c1"); err != nil || removed {
+		t.Fatalf("expected no-op for a missing relation, got removed=%v err=%v", removed, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_DeleteProductNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectExec(`UPDATE products SET deleted_at = NOW\(\), updated_at = NOW\(\) WHERE id = \$1 AND deleted_at IS NULL`).WithArgs("missing").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	repo := &CatalogRepository{pool: mock}
+	if err := repo.DeleteProduct(context.Background(), "missing"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+}
+
+func TestCatalogRepository_RestoreProduct(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	cols := []string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}
+	mock.ExpectQuery(`UPDATE products SET deleted_at = NULL, updated_at = NOW\(\)\s+WHERE id = \$1 AND deleted_at IS NOT NULL\s+RETURNING`).WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows(cols).AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+	// segundo intento: el producto ya esta activo, se devuelve sin cambios.
+	mock.ExpectQuery(`UPDATE products SET deleted_at = NULL`).WithArgs("p1").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`FROM products WHERE id = \$1$`).WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows(cols).AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+	mock.ExpectQuery(`UPDATE products SET deleted_at = NULL`).WithArgs("missing").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`FROM products WHERE id = \$1$`).WithArgs("missing").WillReturnError(pgx.ErrNoRows)
+
+	repo := &CatalogRepository{pool: mock}
+	ctx := context.Background()
+	if p, restored, err := repo.RestoreProduct(ctx, "p1"); err != nil || !restored || p.ID != "p1" {
+		t.Fatalf("expected restore, got %+v restored=%v err=%v", p, restored, err)
+	}
+	if p, restored, err := repo.RestoreProduct(ctx, "p1"); err != nil || restored || p.ID != "p1" {
+		t.Fatalf("expected no-op restore, got %+v restored=%v err=%v", p, restored, err)
+	}
+	if _, _, err := repo.RestoreProduct(ctx, "missing"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_RestoreProductDuplicateBarcode(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`UPDATE products SET deleted_at = NULL`).WithArgs("p1").
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "products_barcode_key"})
+
+	repo := &CatalogRepository{pool: mock}
+	if _, _, err := repo.RestoreProduct(context.Background(), "p1"); !errors.Is(err, catalog.ErrDuplicateBarcode) {
+		t.Fatalf("expected ErrDuplicateBarcode, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_PublishProduct(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	cols := []string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}
+	mock.ExpectQuery(`UPDATE products SET draft = false, updated_at = NOW\(\)\s+WHERE id = \$1 AND draft AND deleted_at IS NULL\s+RETURNING`).WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows(cols).AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+	// ya publicado: se devuelve sin cambios.
+	mock.ExpectQuery(`UPDATE products SET draft = false`).WithArgs("p1").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`FROM products WHERE id = \$1 AND deleted_at IS NULL$`).WithArgs("p1").
+		WillReturnRows(pgxmock.NewRows(cols).AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false))
+	mock.ExpectQuery(`UPDATE products SET draft = false`).WithArgs("missing").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`FROM products WHERE id = \$1 AND deleted_at IS NULL$`).WithArgs("missing").WillReturnError(pgx.ErrNoRows)
+
+	repo := &CatalogRepository{pool: mock}
+	ctx := context.Background()
+	if p, published, err := repo.PublishProduct(ctx, "p1"); err != nil || !published || p.Draft {
+		t.Fatalf("expected publish, got %+v published=%v err=%v", p, published, err)
+	}
+	if _, published, err := repo.PublishProduct(ctx, "p1"); err != nil || published {
+		t.Fatalf("expected no-op publish, got published=%v err=%v", published, err)
+	}
+	if _, _, err := repo.PublishProduct(ctx, "missing"); !errors.Is(err, catalog.ErrProductNotFound) {
+		t.Fatalf("expected ErrProductNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCatalogRepository_ReadReplicaRouting(t *testing.T) {
+	primary, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer primary.Close()
+	replica, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer replica.Close()
+
+	now := time.Now()
+	productRows := func() *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"id", "name", "slug", "description", "price", "currency", "barcode", "stock", "created_at", "updated_at", "draft"}).
+			AddRow("p1", "Pen", "pen", "", int64(10), "USD", "", int64(1), now, now, false)
+	}
+	// lectura normal -> replica; lectura marcada y escritura -> primario.
+	replica.ExpectQuery(`FROM products WHERE id = \$1`).WithArgs("p1").WillReturnRows(productRows())
+	replica.ExpectQuery(`SELECT COUNT\(\*\) FROM categories`).WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(3)))
+	primary.ExpectQuery(`FROM products WHERE id = \$1`).WithArgs("p1").WillReturnRows(productRows())
+	primary.ExpectExec(`UPDATE products SET deleted_at = NOW\(\), updated_at = NOW\(\) WHERE id = \$1 AND deleted_at IS NULL`).WithArgs("p1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	ctx := context.Background()
+	repo := NewCatalogRepository(primary, WithReadReplica(replica))
+	if _, err := repo.GetProduct(ctx, "p1"); err != nil {
+		t.Fatalf("replica read: %v", err)
+	}
+	if _, err := repo.CountCategories(ctx); err != nil {
+		t.Fatalf("replica count: %v", err)
+	}
+	if _, err := repo.GetProduct(catalog.WithPrimaryRead(ctx), "p1"); err != nil {
+		t.Fatalf("primary read: %v", err)
+	}
+	if err := repo.DeleteProduct(ctx, "p1"); err != nil {
+		t.Fatalf("primary write: %v", err)
+	}
+	if err := replica.ExpectationsWereMet(); err != nil {
+		t.Fatalf("replica expectations: %v", err)
+	}
+	if err := primary.ExpectationsWereMet(); err != nil {
+		t.Fatalf("primary expectations: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Codigos SQLSTATE que se traducen a errores de dominio.
+const (
+	uniqueViolation           = "23505"
+	foreignKeyViolation       = "23503"
+	invalidTextRepresentation = "22P02"
+)
+
+// errorMapping define que error de dominio corresponde a cada falla de Postgres.
+// Un campo nil deja pasar el error original.
+type errorMapping struct {
+	notFound   error
+	conflict   error
+	foreignKey error
+}
+
+// translate convierte errores de pgx en errores de dominio, conservando la causa
+// original para que errors.Is/As sigan funcionando sobre ambos.
+func (m errorMapping) translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return wrapDomain(m.notFound, err)
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return wrapDomain(m.conflict, err)
+	case errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation:
+		return wrapDomain(m.foreignKey, err)
+	case errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentation:
+		// un id que no es UUID valido no puede existir.
+		return wrapDomain(m.notFound, err)
+	}
+	return err
+}
+
+// domainError expone el mensaje de dominio y envuelve ambos errores.
+type domainError struct {
+	domain error
+	cause  error
+}
+
+func (e *domainError) Error() string {
+	return e.domain.Error()
+}
+
+func (e *domainError) Unwrap() []error {
+	return []error{e.domain, e.cause}
+}
+
+func wrapDomain(domain, cause error) error {
+	if domain == nil {
+		return cause
+	}
+	if errors.Is(cause, domain) {
+		return cause
+	}
+	return &domainError{domain: domain, cause: cause}
+}
+
+// isPrimaryKeyViolation distingue un choque de id generado (constraint *_pkey) de un
+// duplicado de negocio como el email o el codigo de barras, que no se reintenta.
+func isPrimaryKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && strings.HasSuffix(pgErr.ConstraintName, "_pkey")
+}
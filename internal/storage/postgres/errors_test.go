@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"catalog-api/internal/catalog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestErrorMapping_Translate(t *testing.T) {
+	errRef := errors.New("missing reference")
+	mapping := errorMapping{notFound: catalog.ErrProductNotFound, conflict: catalog.ErrCategoryConflict, foreignKey: errRef}
+	cases := []struct {
+		name string
+		in   error
+		want error
+	}{
+		{"no rows", pgx.ErrNoRows, catalog.ErrProductNotFound},
+		{"unique", &pgconn.PgError{Code: "23505"}, catalog.ErrCategoryConflict},
+		{"foreign key", &pgconn.PgError{Code: "23503"}, errRef},
+		{"invalid uuid", &pgconn.PgError{Code: "22P02"}, catalog.ErrProductNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := mapping.translate(tc.in)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if !errors.Is(err, tc.in) && !errors.As(err, new(*pgconn.PgError)) {
+				t.Fatalf("expected original cause to be preserved, got %v", err)
+			}
+			if err.Error() != tc.want.Error() {
+				t.Fatalf("expected domain message %q, got %q", tc.want.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestErrorMapping_PassesThroughUnmapped(t *testing.T) {
+	connErr := errors.New("connection refused")
+	if err := (errorMapping{notFound: catalog.ErrProductNotFound}).translate(connErr); err != connErr {
+		t.Fatalf("expected original error, got %v", err)
+	}
+	if err := (errorMapping{}).translate(pgx.ErrNoRows); err != pgx.ErrNoRows {
+		t.Fatalf("expected unmapped ErrNoRows to pass through, got %v", err)
+	}
+	if err := (errorMapping{}).translate(nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestIsPrimaryKeyViolation(t *testing.T) {
+	if !isPrimaryKeyViolation(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "products_pkey"}) {
+		t.Fatalf("expected products_pkey to be a primary key collision")
+	}
+	if isPrimaryKeyViolation(&pgconn.PgError{Code: uniqueViolation, ConstraintName: "users_email_key"}) {
+		t.Fatalf("business-key duplicates must not be retried")
+	}
+	if isPrimaryKeyViolation(errors.New("boom")) {
+		t.Fatalf("non-postgres errors are not collisions")
+	}
+}
@@ -3,22 +3,29 @@ package postgres
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"catalog-api/internal/identity"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// userColumns es la proyeccion que espera scanUser.
+const userColumns = `id, email, COALESCE(username, ''), full_name, password_hash, role, status, is_verified, created_at, updated_at`
+
+var (
+	userErrors         = errorMapping{notFound: identity.ErrUserNotFound, conflict: identity.ErrEmailAlreadyRegistered}
+	verificationErrors = errorMapping{notFound: identity.ErrInvalidVerificationCode, foreignKey: identity.ErrUserNotFound}
+	sessionErrors      = errorMapping{notFound: identity.ErrSessionNotFound, foreignKey: identity.ErrUserNotFound}
 )
 
 // IdentityRepository persists identity data in Postgres.
 type IdentityRepository struct {
-	pool *pgxpool.Pool
+	pool pgxPool
 }
 
-func NewIdentityRepository(pool *pgxpool.Pool) *IdentityRepository {
+func NewIdentityRepository(pool pgxPool) *IdentityRepository {
 	return &IdentityRepository{pool: pool}
 }
 
@@ -29,10 +36,9 @@ type identityTx struct {
 
 func (t *identityTx) CreateUser(ctx context.Context, user identity.User) (identity.User, error) {
 	query := `
-		INSERT INTO users (email, full_name, password_hash, role, status, is_verified)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, email, full_name, password_hash, role, status, is_verified, created_at, updated_at
-	`
+		INSERT INTO users (email, full_name, password_hash, role, status, is_verified, username)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING ` + userColumns
 	row := t.tx.QueryRow(ctx, query,
 		user.Email,
 		user.FullName,
@@ -40,14 +46,11 @@ func (t *identityTx) CreateUser(ctx context.Context, user identity.User) (identi
 		user.Role,
 		user.Status,
 		user.IsVerified,
+		user.Username,
 	)
 	created, err := scanUser(row)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return identity.User{}, identity.ErrEmailAlreadyRegistered
-		}
-		return identity.User{}, err
+		return identity.User{}, translateUserInsertError(err)
 	}
 	return created, nil
 }
@@ -58,7 +61,7 @@ func (t *identityTx) SaveVerificationCode(ctx context.Context, userID identity.U
 		VALUES ($1, $2, $3)
 		ON CONFLICT (user_id) DO UPDATE SET code = EXCLUDED.code, expires_at = EXCLUDED.expires_at, updated_at = NOW()
 	`, userID, code, expiresAt)
-	return err
+	return verificationErrors.translate(err)
 }
 
 func (t *identityTx) Commit(ctx context.Context) error {
@@ -83,7 +86,7 @@ func (r *IdentityRepository) BeginTx(ctx context.Context) (identity.UserTx, erro
 	}
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return nil, err
+		return nil, userErrors.translate(err)
 	}
 	return &identityTx{tx: tx}, nil
 }
@@ -93,10 +96,9 @@ func (r *IdentityRepository) CreateUser(ctx context.Context, user identity.User)
 		return identity.User{}, identity.ErrRepositoryNotConfigured
 	}
 	query := `
-		INSERT INTO users (email, full_name, password_hash, role, status, is_verified)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, email, full_name, password_hash, role, status, is_verified, created_at, updated_at
-	`
+		INSERT INTO users (email, full_name, password_hash, role, status, is_verified, username)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING ` + userColumns
 	row := r.pool.QueryRow(ctx, query,
 		user.Email,
 		user.FullName,
@@ -104,14 +106,11 @@ func (r *IdentityRepository) CreateUser(ctx context.Context, user identity.User)
 		user.Role,
 		user.Status,
 		user.IsVerified,
+		user.Username,
 	)
 	created, err := scanUser(row)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return identity.User{}, identity.ErrEmailAlreadyRegistered
-		}
-		return identity.User{}, err
+		return identity.User{}, translateUserInsertError(err)
 	}
 	return created, nil
 }
@@ -120,28 +119,92 @@ func (r *IdentityRepository) GetByEmail(ctx context.Context, email string) (iden
 	if r.pool == nil {
 		return identity.User{}, identity.ErrRepositoryNotConfigured
 	}
-	query := `
-		SELECT id, email, full_name, password_hash, role, status, is_verified, created_at, updated_at
+	query := `SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1
 		LIMIT 1
 	`
 	row := r.pool.QueryRow(ctx, query, email)
-	return scanUser(row)
+	u, err := scanUser(row)
+	return u, userErrors.translate(err)
+}
+
+// GetByIdentifier acepta email o username; los usernames no llevan '@', asi que
+// un identificador nunca puede coincidir con ambos campos de usuarios distintos.
+func (r *IdentityRepository) GetByIdentifier(ctx context.Context, identifier string) (identity.User, error) {
+	if r.pool == nil {
+		return identity.User{}, identity.ErrRepositoryNotConfigured
+	}
+	query := `SELECT ` + userColumns + `
+		FROM users
+		WHERE email = $1 OR lower(username) = lower($1)
+		LIMIT 1
+	`
+	row := r.pool.QueryRow(ctx, query, identifier)
+	u, err := scanUser(row)
+	return u, userErrors.translate(err)
 }
 
 func (r *IdentityRepository) GetByID(ctx context.Context, id identity.UserID) (identity.User, error) {
 	if r.pool == nil {
 		return identity.User{}, identity.ErrRepositoryNotConfigured
 	}
-	query := `
-		SELECT id, email, full_name, password_hash, role, status, is_verified, created_at, updated_at
+	query := `SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 		LIMIT 1
 	`
 	row := r.pool.QueryRow(ctx, query, id)
-	return scanUser(row)
+	u, err := scanUser(row)
+	return u, userErrors.translate(err)
+}
+
+// GetVerificationStatuses resuelve el lote en una sola consulta sin leer password_hash.
+func (r *IdentityRepository) GetVerificationStatuses(ctx context.Context, ids []identity.UserID) ([]identity.VerificationStatus, error) {
+	if r.pool == nil {
+		return nil, identity.ErrRepositoryNotConfigured
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, is_verified, status
+		FROM users
+		WHERE id = ANY($1::uuid[])
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return nil, userErrors.translate(err)
+	}
+	defer rows.Close()
+	items := make([]identity.VerificationStatus, 0, len(ids))
+	for rows.Next() {
+		var item identity.VerificationStatus
+		if err := rows.Scan(&item.UserID, &item.IsVerified, &item.Status); err != nil {
+			return nil, userErrors.translate(err)
+		}
+		items = append(items, item)
+	}
+	return items, userErrors.translate(rows.Err())
+}
+
+// CountUsersByStatus cuenta con un unico GROUP BY sobre users.
+func (r *IdentityRepository) CountUsersByStatus(ctx context.Context) (map[identity.UserStatus]int64, error) {
+	if r.pool == nil {
+		return nil, identity.ErrRepositoryNotConfigured
+	}
+	rows, err := r.pool.Query(ctx, `SELECT status, COUNT(*) FROM users GROUP BY status`)
+	if err != nil {
+		return nil, userErrors.translate(err)
+	}
+	defer rows.Close()
+	counts := make(map[identity.UserStatus]int64)
+	for rows.Next() {
+		var status identity.UserStatus
+		var total int64
+		if err := rows.Scan(&status, &total); err != nil {
+			return nil, userErrors.translate(err)
+		}
+		counts[status] = total
+	}
+	return counts, userErrors.translate(rows.Err())
 }
 
 func (r *IdentityRepository) SetVerification(ctx context.Context, userID identity.UserID, verified bool) error {
@@ -150,10 +213,10 @@ func (r *IdentityRepository) SetVerification(ctx context.Context, userID identit
 	}
 	cmdTag, err := r.pool.Exec(ctx, `UPDATE users SET is_verified = $1 WHERE id = $2`, verified, userID)
 	if err != nil {
-		return err
+		return userErrors.translate(err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("no rows updated for verification user_id=%s", userID)
+		return identity.ErrUserNotFound
 	}
 	return nil
 }
@@ -164,10 +227,10 @@ func (r *IdentityRepository) UpdateStatus(ctx context.Context, userID identity.U
 	}
 	cmdTag, err := r.pool.Exec(ctx, `UPDATE users SET status = $1 WHERE id = $2`, status, userID)
 	if err != nil {
-		return err
+		return userErrors.translate(err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("no rows updated for status user_id=%s", userID)
+		return identity.ErrUserNotFound
 	}
 	return nil
 }
@@ -177,7 +240,7 @@ func (r *IdentityRepository) EnsureRole(ctx context.Context, role identity.RoleN
 		return identity.ErrRepositoryNotConfigured
 	}
 	_, err := r.pool.Exec(ctx, `INSERT INTO roles (name) VALUES ($1) ON CONFLICT DO NOTHING`, role)
-	return err
+	return userErrors.translate(err)
 }
 
 func (r *IdentityRepository) AssignRole(ctx context.Context, userID identity.UserID, role identity.RoleName) error {
@@ -186,10 +249,10 @@ func (r *IdentityRepository) AssignRole(ctx context.Context, userID identity.Use
 	}
 	cmdTag, err := r.pool.Exec(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, userID)
 	if err != nil {
-		return err
+		return userErrors.translate(err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("no rows updated when assigning role user_id=%s", userID)
+		return identity.ErrUserNotFound
 	}
 	return nil
 }
@@ -198,17 +261,24 @@ func (r *IdentityRepository) UpdateUserProfile(ctx context.Context, user identit
 	if r.pool == nil {
 		return identity.User{}, identity.ErrRepositoryNotConfigured
 	}
-	query := `UPDATE users SET full_name = $1, updated_at = NOW() WHERE id = $2 RETURNING id, email, full_name, password_hash, role, status, is_verified, created_at, updated_at`
+	query := `UPDATE users SET full_name = $1, updated_at = NOW() WHERE id = $2 RETURNING ` + userColumns
 	row := r.pool.QueryRow(ctx, query, user.FullName, user.ID)
-	return scanUser(row)
+	u, err := scanUser(row)
+	return u, userErrors.translate(err)
 }
 
 func (r *IdentityRepository) DeleteUser(ctx context.Context, userID identity.UserID) error {
 	if r.pool == nil {
 		return identity.ErrRepositoryNotConfigured
 	}
-	_, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
-	return err
+	tag, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return userErrors.translate(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return identity.ErrUserNotFound
+	}
+	return nil
 }
 
 func (r *IdentityRepository) SaveVerificationCode(ctx context.Context, userID identity.UserID, code string, expiresAt time.Time) error {
@@ -220,7 +290,7 @@ func (r *IdentityRepository) SaveVerificationCode(ctx context.Context, userID id
 		VALUES ($1, $2, $3)
 		ON CONFLICT (user_id) DO UPDATE SET code = EXCLUDED.code, expires_at = EXCLUDED.expires_at, updated_at = NOW()
 	`, userID, code, expiresAt)
-	return err
+	return verificationErrors.translate(err)
 }
 
 func (r *IdentityRepository) GetVerificationCode(ctx context.Context, userID identity.UserID) (string, time.Time, error) {
@@ -233,7 +303,7 @@ func (r *IdentityRepository) GetVerificationCode(ctx context.Context, userID ide
 		SELECT code, expires_at FROM verification_codes WHERE user_id = $1
 	`, userID).Scan(&code, &expires)
 	if err != nil {
-		return "", time.Time{}, err
+		return "", time.Time{}, verificationErrors.translate(err)
 	}
 	return code, expires, nil
 }
@@ -243,7 +313,141 @@ func (r *IdentityRepository) DeleteVerificationCode(ctx context.Context, userID
 		return identity.ErrRepositoryNotConfigured
 	}
 	_, err := r.pool.Exec(ctx, `DELETE FROM verification_codes WHERE user_id = $1`, userID)
-	return err
+	return verificationErrors.translate(err)
+}
+
+// DeleteExpiredVerificationCodes borra los codigos vencidos antes de now y devuelve cuantos elimino.
+func (r *IdentityRepository) DeleteExpiredVerificationCodes(ctx context.Context, now time.Time) (int64, error) {
+	if r.pool == nil {
+		return 0, identity.ErrRepositoryNotConfigured
+	}
+	tag, err := r.pool.Exec(ctx, `DELETE FROM verification_codes WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, verificationErrors.translate(err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CreateSession guarda un refresh token por su hash.
+func (r *IdentityRepository) CreateSession(ctx context.Context, session identity.Session, tokenHash string) (identity.Session, error) {
+	if r.pool == nil {
+		return identity.Session{}, identity.ErrRepositoryNotConfigured
+	}
+	row := r.pool.QueryRow(ctx, `
+		INSERT INTO refresh_tokens (user_id, token_hash, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+	`, session.UserID, tokenHash, session.UserAgent, session.IP, session.ExpiresAt)
+	out, err := scanSession(row)
+	return out, sessionErrors.translate(err)
+}
+
+// GetSessionByTokenHash busca una sesion vigente (no revocada ni vencida) por hash.
+func (r *IdentityRepository) GetSessionByTokenHash(ctx context.Context, tokenHash string, now time.Time) (identity.Session, error) {
+	if r.pool == nil {
+		return identity.Session{}, identity.ErrRepositoryNotConfigured
+	}
+	row := r.pool.QueryRow(ctx, `
+		SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+		FROM refresh_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > $2
+	`, tokenHash, now)
+	out, err := scanSession(row)
+	return out, sessionErrors.translate(err)
+}
+
+// TouchSession actualiza la marca de ultimo uso.
+func (r *IdentityRepository) TouchSession(ctx context.Context, id string, now time.Time) error {
+	if r.pool == nil {
+		return identity.ErrRepositoryNotConfigured
+	}
+	_, err := r.pool.Exec(ctx, `UPDATE refresh_tokens SET last_used_at = $1 WHERE id = $2`, now, id)
+	return sessionErrors.translate(err)
+}
+
+// ListActiveSessions devuelve las sesiones vigentes del usuario, la mas reciente primero.
+func (r *IdentityRepository) ListActiveSessions(ctx context.Context, userID identity.UserID, now time.Time) ([]identity.Session, error) {
+	if r.pool == nil {
+		return nil, identity.ErrRepositoryNotConfigured
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, user_agent, ip, created_at, last_used_at, expires_at
+		FROM refresh_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC
+	`, userID, now)
+	if err != nil {
+		return nil, sessionErrors.translate(err)
+	}
+	defer rows.Close()
+	var items []identity.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, sessionErrors.translate(err)
+		}
+		items = append(items, s)
+	}
+	return items, sessionErrors.translate(rows.Err())
+}
+
+// RevokeSession marca como revocada una sesion del usuario.
+func (r *IdentityRepository) RevokeSession(ctx context.Context, userID identity.UserID, id string) error {
+	if r.pool == nil {
+		return identity.ErrRepositoryNotConfigured
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, id, userID)
+	if err != nil {
+		return sessionErrors.translate(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return identity.ErrSessionNotFound
+	}
+	return nil
+}
+
+// RememberDevice registra la huella del dispositivo; devuelve true si es la primera vez.
+func (r *IdentityRepository) RememberDevice(ctx context.Context, userID identity.UserID, fingerprint string) (bool, error) {
+	if r.pool == nil {
+		return false, identity.ErrRepositoryNotConfigured
+	}
+	// xmax = 0 solo en filas recien insertadas; en conflicto se actualiza last_seen_at.
+	var inserted bool
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO known_devices (user_id, fingerprint)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, fingerprint) DO UPDATE SET last_seen_at = NOW()
+		RETURNING (xmax = 0)
+	`, userID, fingerprint).Scan(&inserted)
+	if err != nil {
+		return false, userErrors.translate(err)
+	}
+	return inserted, nil
+}
+
+func scanSession(row pgx.Row) (identity.Session, error) {
+	var s identity.Session
+	if err := row.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+		return identity.Session{}, err
+	}
+	return s, nil
+}
+
+// usersUsernameKey es el indice unico de 014_user_usernames.sql; cualquier otro
+// duplicado en users es el email.
+const usersUsernameKey = "users_username_key"
+
+// translateUserInsertError distingue por constraint el username duplicado del email
+// duplicado, p.ej. cuando dos registros simultaneos pasan el chequeo previo del servicio.
+func translateUserInsertError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == usersUsernameKey {
+		return wrapDomain(identity.ErrUsernameTaken, err)
+	}
+	return userErrors.translate(err)
 }
 
 func scanUser(row pgx.Row) (identity.User, error) {
@@ -251,6 +455,7 @@ func scanUser(row pgx.Row) (identity.User, error) {
 	if err := row.Scan(
 		&u.ID,
 		&u.Email,
+		&u.Username,
 		&u.FullName,
 		&u.PasswordHash,
 		&u.Role,
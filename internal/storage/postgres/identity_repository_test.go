@@ -1,8 +1,347 @@
 package postgres
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
 
-// Marcador de posicion hasta implementar tests reales del repositorio de identidad.
-func TestIdentityRepository_Placeholder(t *testing.T) {
-	t.Skip("identity repository tests pending")
+	"catalog-api/internal/identity"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+)
+
+func TestIdentityRepository_DeleteExpiredVerificationCodes(t *testing.T) {
+	ctx := context.Background()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectExec(`DELETE FROM verification_codes WHERE expires_at < \$1`).
+		WithArgs(now).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	repo := NewIdentityRepository(mock)
+	removed, err := repo.DeleteExpiredVerificationCodes(ctx, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 3 {
+		t.Fatalf("expected 3 rows removed, got %d", removed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_GetByEmailNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`FROM users`).WithArgs("ghost@example.com").WillReturnError(pgx.ErrNoRows)
+
+	repo := NewIdentityRepository(mock)
+	if _, err := repo.GetByEmail(context.Background(), "ghost@example.com"); !errors.Is(err, identity.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_CreateUserConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`INSERT INTO users`).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	repo := NewIdentityRepository(mock)
+	if _, err := repo.CreateUser(context.Background(), identity.User{Email: "a@example.com"}); !errors.Is(err, identity.ErrEmailAlreadyRegistered) {
+		t.Fatalf("expected ErrEmailAlreadyRegistered, got %v", err)
+	}
+}
+
+func TestIdentityRepository_TxCreateUserConflictRollsBack(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO users`).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	tx, err := NewIdentityRepository(mock).BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if _, err := tx.CreateUser(ctx, identity.User{Email: "a@example.com"}); !errors.Is(err, identity.ErrEmailAlreadyRegistered) {
+		t.Fatalf("expected ErrEmailAlreadyRegistered, got %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_CreateUserUsernameConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`INSERT INTO users`).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "ana").
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_username_key"})
+
+	repo := NewIdentityRepository(mock)
+	if _, err := repo.CreateUser(context.Background(), identity.User{Email: "a@example.com", Username: "ana"}); !errors.Is(err, identity.ErrUsernameTaken) {
+		t.Fatalf("expected ErrUsernameTaken, got %v", err)
+	}
+}
+
+func TestIdentityRepository_TxCreateUserUsernameRace(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	// el registro con verificacion inserta dentro de una tx; otro registro gano el username.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO users`).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "ana").
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_username_key"})
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	tx, err := NewIdentityRepository(mock).BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	_, err = tx.CreateUser(ctx, identity.User{Email: "a@example.com", Username: "ana"})
+	if !errors.Is(err, identity.ErrUsernameTaken) || errors.Is(err, identity.ErrEmailAlreadyRegistered) {
+		t.Fatalf("expected only ErrUsernameTaken, got %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_GetByIdentifierMatchesUsername(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`WHERE email = \$1 OR lower\(username\) = lower\(\$1\)`).
+		WithArgs("Ana").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "email", "username", "full_name", "password_hash", "role", "status", "is_verified", "created_at", "updated_at"}).
+			AddRow("u1", "ana@example.com", "ana", "Ana", "hash", identity.RoleClient, identity.UserStatusActive, true, now, now))
+
+	repo := NewIdentityRepository(mock)
+	user, err := repo.GetByIdentifier(context.Background(), "Ana")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != "u1" || user.Username != "ana" || user.Email != "ana@example.com" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_SaveVerificationCodeUnknownUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	expires := time.Now().Add(time.Hour)
+	mock.ExpectExec(`INSERT INTO verification_codes`).WithArgs(identity.UserID("u1"), "123456", expires).
+		WillReturnError(&pgconn.PgError{Code: "23503"})
+
+	repo := NewIdentityRepository(mock)
+	if err := repo.SaveVerificationCode(context.Background(), "u1", "123456", expires); !errors.Is(err, identity.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestIdentityRepository_SetVerificationNoRows(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectExec(`UPDATE users SET is_verified`).WithArgs(true, identity.UserID("u1")).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	repo := NewIdentityRepository(mock)
+	if err := repo.SetVerification(context.Background(), "u1", true); !errors.Is(err, identity.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestIdentityRepository_ListActiveSessions(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`FROM refresh_tokens\s+WHERE user_id = \$1 AND revoked_at IS NULL AND expires_at > \$2`).
+		WithArgs(identity.UserID("u1"), now).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "user_agent", "ip", "created_at", "last_used_at", "expires_at"}).
+			AddRow("s1", "u1", "curl/8.0", "203.0.113.7", now, now, now.Add(time.Hour)))
+
+	repo := NewIdentityRepository(mock)
+	sessions, err := repo.ListActiveSessions(context.Background(), "u1", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "s1" || sessions[0].UserAgent != "curl/8.0" || sessions[0].IP != "203.0.113.7" {
+		t.Fatalf("unexpected sessions %+v", sessions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_GetVerificationStatuses(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	ids := []identity.UserID{"u1", "u2", "u3"}
+	mock.ExpectQuery(`SELECT id, is_verified, status\s+FROM users\s+WHERE id = ANY\(\$1::uuid\[\]\)`).
+		WithArgs(ids).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "is_verified", "status"}).
+			AddRow("u1", true, identity.UserStatusActive).
+			AddRow("u3", false, identity.UserStatusPendingVerification))
+
+	repo := NewIdentityRepository(mock)
+	statuses, err := repo.GetVerificationStatuses(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(statuses) != 2 || !statuses[0].IsVerified || statuses[1].UserID != "u3" || statuses[1].Status != identity.UserStatusPendingVerification {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_RevokeSession(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = NOW\(\)`).
+		WithArgs("s1", identity.UserID("u1")).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = NOW\(\)`).
+		WithArgs("s1", identity.UserID("u2")).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	repo := NewIdentityRepository(mock)
+	if err := repo.RevokeSession(context.Background(), "u1", "s1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.RevokeSession(context.Background(), "u2", "s1"); !errors.Is(err, identity.ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound for foreign session, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_CreateSessionPersistsDevice(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	mock.ExpectQuery(`INSERT INTO refresh_tokens \(user_id, token_hash, user_agent, ip, expires_at\)`).
+		WithArgs(identity.UserID("u1"), "hash", "curl/8.0", "203.0.113.7", expires).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "user_agent", "ip", "created_at", "last_used_at", "expires_at"}).
+			AddRow("s1", "u1", "curl/8.0", "203.0.113.7", now, now, expires))
+
+	repo := NewIdentityRepository(mock)
+	created, err := repo.CreateSession(context.Background(), identity.Session{
+		UserID:    "u1",
+		UserAgent: "curl/8.0",
+		IP:        "203.0.113.7",
+		ExpiresAt: expires,
+	}, "hash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.UserAgent != "curl/8.0" || created.IP != "203.0.113.7" {
+		t.Fatalf("unexpected session %+v", created)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIdentityRepository_CountUsersByStatus(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`SELECT status, COUNT\(\*\) FROM users GROUP BY status`).
+		WillReturnRows(pgxmock.NewRows([]string{"status", "count"}).
+			AddRow(identity.UserStatusActive, int64(7)).
+			AddRow(identity.UserStatusBlocked, int64(1)))
+
+	repo := NewIdentityRepository(mock)
+	counts, err := repo.CountUsersByStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts[identity.UserStatusActive] != 7 || counts[identity.UserStatusBlocked] != 1 || len(counts) != 2 {
+		t.Fatalf("unexpected counts %+v", counts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
 }
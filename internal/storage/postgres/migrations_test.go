@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// migrationTx abre una transaccion contra TEST_DATABASE_URL con un schema propio; el
+// rollback final deshace la migracion. Sin la variable el test se omite.
+func migrationTx(t *testing.T) pgx.Tx {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close(context.Background()) })
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	t.Cleanup(func() { _ = tx.Rollback(context.Background()) })
+	if _, err := tx.Exec(ctx, "CREATE SCHEMA migration_test; SET LOCAL search_path TO migration_test"); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	return tx
+}
+
+func runMigration(t *testing.T, tx pgx.Tx, name string) {
+	t.Helper()
+	sql, err := os.ReadFile(filepath.Join("..", "..", "..", "migrations", name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	if _, err := tx.Exec(context.Background(), string(sql)); err != nil {
+		t.Fatalf("apply %s: %v", name, err)
+	}
+}
+
+// assertSlugs compara "nombre=slug" de cada fila en orden de alta.
+func assertSlugs(t *testing.T, tx pgx.Tx, table string, want []string) {
+	t.Helper()
+	rows, err := tx.Query(context.Background(), "SELECT name || '=' || slug FROM "+table+" ORDER BY created_at")
+	if err != nil {
+		t.Fatalf("query %s: %v", table, err)
+	}
+	got, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatalf("scan %s: %v", table, err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestMigration_CategorySlugsSkipTakenSuffixes(t *testing.T) {
+	tx := migrationTx(t)
+	ctx := context.Background()
+	if _, err := tx.Exec(ctx, `
+		CREATE TABLE categories (id UUID PRIMARY KEY, name TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL);
+		INSERT INTO categories VALUES
+			('00000000-0000-0000-0000-000000000001', 'Item', '2024-01-01'),
+			('00000000-0000-0000-0000-000000000002', 'Item', '2024-01-02'),
+			('00000000-0000-0000-0000-000000000003', 'Item 2', '2024-01-03')`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	runMigration(t, tx, "011_category_slugs.sql")
+
+	assertSlugs(t, tx, "categories", []string{"Item=item", "Item=item-3", "Item 2=item-2"})
+}
+
+func TestMigration_ProductSlugsSkipTakenSuffixes(t *testing.T) {
+	tx := migrationTx(t)
+	ctx := context.Background()
+	if _, err := tx.Exec(ctx, `
+		CREATE TABLE products (id UUID PRIMARY KEY, name TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL);
+		INSERT INTO products VALUES
+			('00000000-0000-0000-0000-000000000001', 'Item', '2024-01-01'),
+			('00000000-0000-0000-0000-000000000002', 'Item', '2024-01-02'),
+			('00000000-0000-0000-0000-000000000003', 'Item 2', '2024-01-03'),
+			('00000000-0000-0000-0000-000000000004', 'Item', '2024-01-04')`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	runMigration(t, tx, "012_product_slugs.sql")
+
+	assertSlugs(t, tx, "products", []string{"Item=item", "Item=item-3", "Item 2=item-2", "Item=item-4"})
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"catalog-api/internal/catalog"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var priceChangeErrors = errorMapping{notFound: catalog.ErrPriceChangeNotFound, foreignKey: catalog.ErrProductNotFound}
+
+const priceChangeColumns = `id, product_id, old_price, new_price, status, created_at, decided_at`
+
+// RequestPriceChange aplica los demas campos del producto y crea la solicitud pendiente
+// en la misma transaccion.
+func (r *CatalogRepository) RequestPriceChange(ctx context.Context, p catalog.Product, req catalog.PriceChangeRequest) (catalog.PriceChangeRequest, catalog.Product, error) {
+	if r.pool == nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, catalog.ErrRepositoryNotConfigured
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, productErrors.translate(err)
+	}
+	defer tx.Rollback(ctx)
+
+	product, err := updateProduct(ctx, tx, p)
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, err
+	}
+	out, err := scanPriceChange(tx.QueryRow(ctx, `
+		INSERT INTO price_change_requests (product_id, old_price, new_price)
+		VALUES ($1, $2, $3)
+		RETURNING `+priceChangeColumns, product.ID, req.OldPrice, req.NewPrice))
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, priceChangeErrors.translate(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, priceChangeErrors.translate(err)
+	}
+	return out, product, nil
+}
+
+// ListPriceChangeRequests lee del primario: el panel de aprobacion no tolera atrasos de replica.
+func (r *CatalogRepository) ListPriceChangeRequests(ctx context.Context, status catalog.PriceChangeStatus) ([]catalog.PriceChangeRequest, error) {
+	if r.pool == nil {
+		return nil, catalog.ErrRepositoryNotConfigured
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+priceChangeColumns+`
+		FROM price_change_requests
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id`, string(status))
+	if err != nil {
+		return nil, priceChangeErrors.translate(err)
+	}
+	defer rows.Close()
+	items := []catalog.PriceChangeRequest{}
+	for rows.Next() {
+		req, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, priceChangeErrors.translate(err)
+		}
+		items = append(items, req)
+	}
+	return items, priceChangeErrors.translate(rows.Err())
+}
+
+// ApprovePriceChangeRequest bloquea la solicitud y el producto, aplica el precio con su
+// historial y marca la solicitud en una sola transaccion. Si el precio ya no es OldPrice
+// (otra aprobacion o una edicion posterior) devuelve ErrPriceChangeStale sin tocar nada.
+func (r *CatalogRepository) ApprovePriceChangeRequest(ctx context.Context, id string) (catalog.PriceChangeRequest, catalog.Product, error) {
+	if r.pool == nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, catalog.ErrRepositoryNotConfigured
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, priceChangeErrors.translate(err)
+	}
+	defer tx.Rollback(ctx)
+
+	req, err := lockPendingPriceChange(ctx, tx, id)
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, err
+	}
+	var currentPrice int64
+	if err := tx.QueryRow(ctx, `SELECT price::bigint FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, req.ProductID).Scan(&currentPrice); err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, productErrors.translate(err)
+	}
+	if currentPrice != req.OldPrice {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, catalog.ErrPriceChangeStale
+	}
+	product, err := scanProduct(tx.QueryRow(ctx, `
+		UPDATE products SET price = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING `+productColumns, req.NewPrice, req.ProductID))
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, productErrors.translate(err)
+	}
+	if currentPrice != product.Price {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO product_history (product_id, price, stock)
+			VALUES ($1, $2, $3)
+		`, product.ID, product.Price, product.Stock); err != nil {
+			return catalog.PriceChangeRequest{}, catalog.Product{}, productErrors.translate(err)
+		}
+	}
+	req, err = decidePriceChange(ctx, tx, id, catalog.PriceChangeApproved)
+	if err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return catalog.PriceChangeRequest{}, catalog.Product{}, priceChangeErrors.translate(err)
+	}
+	return req, product, nil
+}
+
+func (r *CatalogRepository) RejectPriceChangeRequest(ctx context.Context, id string) (catalog.PriceChangeRequest, error) {
+	if r.pool == nil {
+		return catalog.PriceChangeRequest{}, catalog.ErrRepositoryNotConfigured
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return catalog.PriceChangeRequest{}, priceChangeErrors.translate(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockPendingPriceChange(ctx, tx, id); err != nil {
+		return catalog.PriceChangeRequest{}, err
+	}
+	req, err := decidePriceChange(ctx, tx, id, catalog.PriceChangeRejected)
+	if err != nil {
+		return catalog.PriceChangeRequest{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return catalog.PriceChangeRequest{}, priceChangeErrors.translate(err)
+	}
+	return req, nil
+}
+
+// lockPendingPriceChange toma la fila con FOR UPDATE para que dos decisiones concurrentes
+// no apliquen la misma solicitud.
+func lockPendingPriceChange(ctx context.Context, tx pgx.Tx, id string) (catalog.PriceChangeRequest, error) {
+	req, err := scanPriceChange(tx.QueryRow(ctx, `
+		SELECT `+priceChangeColumns+`
+		FROM price_change_requests
+		WHERE id = $1
+		FOR UPDATE`, id))
+	if err != nil {
+		return catalog.PriceChangeRequest{}, priceChangeErrors.translate(err)
+	}
+	if req.Status != catalog.PriceChangePending {
+		return catalog.PriceChangeRequest{}, catalog.ErrPriceChangeDecided
+	}
+	return req, nil
+}
+
+func decidePriceChange(ctx context.Context, tx pgx.Tx, id string, status catalog.PriceChangeStatus) (catalog.PriceChangeRequest, error) {
+	req, err := scanPriceChange(tx.QueryRow(ctx, `
+		UPDATE price_change_requests SET status = $1, decided_at = NOW()
+		WHERE id = $2
+		RETURNING `+priceChangeColumns, string(status), id))
+	return req, priceChangeErrors.translate(err)
+}
+
+func scanPriceChange(row pgx.Row) (catalog.PriceChangeRequest, error) {
+	var (
+		req       catalog.PriceChangeRequest
+		status    string
+		decidedAt *time.Time
+	)
+	if err := row.Scan(&req.ID, &req.ProductID, &req.OldPrice, &req.NewPrice, &status, &req.CreatedAt, &decidedAt); err != nil {
+		return catalog.PriceChangeRequest{}, err
+	}
+	req.Status = catalog.PriceChangeStatus(status)
+	if decidedAt != nil {
+		req.DecidedAt = *decidedAt
+	}
+	return req, nil
+}
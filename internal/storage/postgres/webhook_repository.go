@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"catalog-api/internal/webhook"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var webhookErrors = errorMapping{notFound: webhook.ErrSubscriptionNotFound}
+
+// WebhookRepository persiste suscripciones de webhooks en Postgres.
+type WebhookRepository struct {
+	pool pgxPool
+}
+
+func NewWebhookRepository(pool pgxPool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub webhook.Subscription) (webhook.Subscription, error) {
+	if r.pool == nil {
+		return webhook.Subscription{}, webhook.ErrRepositoryNotConfigured
+	}
+	out, err := scanSubscription(r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (url, events, secret)
+		VALUES ($1, $2, $3)
+		RETURNING id, url, events, secret, created_at`, sub.URL, sub.Events, sub.Secret))
+	return out, webhookErrors.translate(err)
+}
+
+// ListSubscriptions devuelve todas las suscripciones, las mas antiguas primero.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	if r.pool == nil {
+		return nil, webhook.ErrRepositoryNotConfigured
+	}
+	rows, err := r.pool.Query(ctx, `SELECT id, url, events, secret, created_at FROM webhook_subscriptions ORDER BY created_at, id`)
+	if err != nil {
+		return nil, webhookErrors.translate(err)
+	}
+	defer rows.Close()
+	var subs []webhook.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, webhookErrors.translate(err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, webhookErrors.translate(rows.Err())
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	if r.pool == nil {
+		return webhook.ErrRepositoryNotConfigured
+	}
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return webhookErrors.translate(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return webhook.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// EnqueueDeliveries guarda el lote en el outbox con un solo INSERT.
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []webhook.Delivery) error {
+	if r.pool == nil {
+		return webhook.ErrRepositoryNotConfigured
+	}
+	ids := make([]string, 0, len(deliveries))
+	subs := make([]string, 0, len(deliveries))
+	events := make([]string, 0, len(deliveries))
+	bodies := make([][]byte, 0, len(deliveries))
+	for _, dl := range deliveries {
+		ids = append(ids, dl.ID)
+		subs = append(subs, dl.SubscriptionID)
+		events = append(events, dl.Event)
+		bodies = append(bodies, dl.Body)
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (id, subscription_id, event, body)
+		SELECT * FROM unnest($1::text[], $2::uuid[], $3::text[], $4::bytea[])`, ids, subs, events, bodies)
+	return webhookErrors.translate(err)
+}
+
+// ClaimDeliveries toma las entregas vencidas con SKIP LOCKED, asi varias instancias
+// pueden drenar el outbox sin repartirse la misma entrega.
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	if r.pool == nil {
+		return nil, webhook.ErrRepositoryNotConfigured
+	}
+	rows, err := r.pool.Query(ctx, `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, locked_until = NOW() + $2 * INTERVAL '1 millisecond'
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE failed_at IS NULL AND next_attempt_at <= NOW()
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING d.id, d.subscription_id, s.url, s.secret, d.event, d.body, d.attempts`, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, webhookErrors.translate(err)
+	}
+	defer rows.Close()
+	var out []webhook.Delivery
+	for rows.Next() {
+		var dl webhook.Delivery
+		if err := rows.Scan(&dl.ID, &dl.SubscriptionID, &dl.URL, &dl.Secret, &dl.Event, &dl.Body, &dl.Attempts); err != nil {
+			return nil, webhookErrors.translate(err)
+		}
+		out = append(out, dl)
+	}
+	return out, webhookErrors.translate(rows.Err())
+}
+
+func (r *WebhookRepository) CompleteDelivery(ctx context.Context, id string) error {
+	if r.pool == nil {
+		return webhook.ErrRepositoryNotConfigured
+	}
+	_, err := r.pool.Exec(ctx, `DELETE FROM webhook_deliveries WHERE id = $1`, id)
+	return webhookErrors.translate(err)
+}
+
+func (r *WebhookRepository) RetryDelivery(ctx context.Context, id string, next time.Time, lastErr string) error {
+	if r.pool == nil {
+		return webhook.ErrRepositoryNotConfigured
+	}
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries SET next_attempt_at = $2, locked_until = NULL, last_error = $3
+		WHERE id = $1`, id, next, lastErr)
+	return webhookErrors.translate(err)
+}
+
+func (r *WebhookRepository) FailDelivery(ctx context.Context, id string, lastErr string) error {
+	if r.pool == nil {
+		return webhook.ErrRepositoryNotConfigured
+	}
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries SET failed_at = NOW(), locked_until = NULL, last_error = $2
+		WHERE id = $1`, id, lastErr)
+	return webhookErrors.translate(err)
+}
+
+func scanSubscription(row pgx.Row) (webhook.Subscription, error) {
+	var sub webhook.Subscription
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Events, &sub.Secret, &sub.CreatedAt)
+	return sub, err
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"catalog-api/internal/webhook"
+
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+)
+
+func TestWebhookRepository_CreateSubscription(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Now()
+	events := []string{"product.created"}
+	mock.ExpectQuery(`INSERT INTO webhook_subscriptions`).
+		WithArgs("https://example.com/hook", events, "s3cret").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "url", "events", "secret", "created_at"}).
+			AddRow("w1", "https://example.com/hook", events, "s3cret", now))
+
+	repo := NewWebhookRepository(mock)
+	sub, err := repo.CreateSubscription(context.Background(), webhook.Subscription{URL: "https://example.com/hook", Events: events, Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.ID != "w1" || !sub.Wants("product.created") {
+		t.Fatalf("unexpected subscription %+v", sub)
+	}
+}
+
+func TestWebhookRepository_DeleteSubscriptionNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectExec(`DELETE FROM webhook_subscriptions WHERE id = \$1`).WithArgs("missing").
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	repo := NewWebhookRepository(mock)
+	if err := repo.DeleteSubscription(context.Background(), "missing"); !errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		t.Fatalf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+}
+
+func TestWebhookRepository_EnqueueDeliveries(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectExec(`INSERT INTO webhook_deliveries \(id, subscription_id, event, body\)\s+SELECT \* FROM unnest`).
+		WithArgs([]string{"d1", "d2"}, []string{"w1", "w2"}, []string{"product.created", "product.created"}, [][]byte{[]byte(`{}`), []byte(`{}`)}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+	repo := NewWebhookRepository(mock)
+	err = repo.EnqueueDeliveries(context.Background(), []webhook.Delivery{
+		{ID: "d1", SubscriptionID: "w1", Event: "product.created", Body: []byte(`{}`)},
+		{ID: "d2", SubscriptionID: "w2", Event: "product.created", Body: []byte(`{}`)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhookRepository_ClaimDeliveries(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery(`UPDATE webhook_deliveries d\s+SET attempts = d.attempts \+ 1.*FOR UPDATE SKIP LOCKED`).
+		WithArgs(16, int64(30000)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "subscription_id", "url", "secret", "event", "body", "attempts"}).
+			AddRow("d1", "w1", "https://example.com/hook", "s3cret", "product.created", []byte(`{}`), 2))
+
+	repo := NewWebhookRepository(mock)
+	got, err := repo.ClaimDeliveries(context.Background(), 16, 30*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].URL != "https://example.com/hook" || got[0].Secret != "s3cret" || got[0].Attempts != 2 {
+		t.Fatalf("unexpected deliveries %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
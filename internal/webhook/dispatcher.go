@@ -0,0 +1,332 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Headers enviados en cada entrega.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Valores por defecto del dispatcher.
+const (
+	DefaultMaxAttempts  = 5
+	DefaultBackoff      = time.Second
+	DefaultTimeout      = 10 * time.Second
+	DefaultPollInterval = time.Second
+	defaultWorkers      = 4
+	defaultClaimBatch   = 16
+	// subscriptionsTTL acota cuanto tarda una instancia en ver altas y bajas hechas en otra.
+	subscriptionsTTL = 30 * time.Second
+)
+
+// SubscriptionLister es lo que el dispatcher necesita del repositorio.
+type SubscriptionLister interface {
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+}
+
+// Delivery es una entrega persistida en el outbox; URL y Secret vienen de la suscripcion.
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	URL            string
+	Secret         string
+	Event          string
+	Body           []byte
+	// Attempts cuenta los intentos ya reclamados, incluido el actual.
+	Attempts int
+}
+
+// DeliveryStore es el outbox: Publish guarda las entregas y los workers las reclaman.
+type DeliveryStore interface {
+	EnqueueDeliveries(ctx context.Context, deliveries []Delivery) error
+	// ClaimDeliveries reserva hasta limit entregas vencidas por lease y suma un intento;
+	// otro worker u otra instancia no las toma hasta que el lease vence.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	// CompleteDelivery borra la entrega aceptada por el endpoint.
+	CompleteDelivery(ctx context.Context, id string) error
+	// RetryDelivery libera la entrega para un nuevo intento en next.
+	RetryDelivery(ctx context.Context, id string, next time.Time, lastErr string) error
+	// FailDelivery la descarta tras agotar los intentos; queda guardada para inspeccion.
+	FailDelivery(ctx context.Context, id string, lastErr string) error
+}
+
+// Event es un evento de catalogo a entregar.
+type Event struct {
+	Name          string
+	Data          any
+	CorrelationID string
+}
+
+// payload es el cuerpo JSON de cada entrega; el id se repite en DeliveryHeader.
+type payload struct {
+	ID            string    `json:"id"`
+	Event         string    `json:"event"`
+	Data          any       `json:"data"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// DispatcherConfig ajusta reintentos y tiempos; los ceros usan los valores por defecto.
+type DispatcherConfig struct {
+	MaxAttempts int
+	// Backoff es la espera antes del primer reintento; se duplica en cada intento.
+	Backoff time.Duration
+	Timeout time.Duration
+	// PollInterval es cada cuanto se revisa el outbox sin eventos nuevos (reintentos,
+	// entregas de otra instancia o pendientes de antes de un reinicio).
+	PollInterval time.Duration
+	Client       *http.Client
+}
+
+// Dispatcher entrega eventos a las suscripciones por POST firmado con HMAC-SHA256.
+// Cada entrega se guarda en el outbox antes de enviarse, asi que sobrevive reinicios.
+type Dispatcher struct {
+	subs         SubscriptionLister
+	store        DeliveryStore
+	client       *http.Client
+	maxAttempts  int
+	backoff      time.Duration
+	timeout      time.Duration
+	pollInterval time.Duration
+	wake         chan struct{}
+	logr         *slog.Logger
+
+	mu        sync.Mutex
+	cached    []Subscription
+	cachedAt  time.Time
+	cacheWarm bool
+}
+
+// NewDispatcher construye un dispatcher; Run debe estar corriendo para que entregue.
+func NewDispatcher(subs SubscriptionLister, store DeliveryStore, cfg DispatcherConfig, logr *slog.Logger) *Dispatcher {
+	if logr == nil {
+		logr = slog.Default()
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Dispatcher{
+		subs:         subs,
+		store:        store,
+		client:       client,
+		maxAttempts:  cfg.MaxAttempts,
+		backoff:      cfg.Backoff,
+		timeout:      cfg.Timeout,
+		pollInterval: cfg.PollInterval,
+		wake:         make(chan struct{}, 1),
+		logr:         logr,
+	}
+}
+
+// Publish guarda en el outbox una entrega por cada suscripcion interesada y despierta
+// a los workers. Las suscripciones salen de un cache, asi que un evento sin
+// interesados no toca la base.
+func (d *Dispatcher) Publish(ctx context.Context, ev Event) {
+	if d == nil || d.subs == nil || d.store == nil {
+		return
+	}
+	subs, err := d.subscriptions(ctx)
+	if err != nil {
+		d.logr.Warn("webhook subscriptions lookup failed", "event", ev.Name, "error", err)
+		return
+	}
+	var deliveries []Delivery
+	for _, sub := range subs {
+		if !sub.Wants(ev.Name) {
+			continue
+		}
+		id, err := newDeliveryID()
+		if err != nil {
+			d.logr.Warn("webhook delivery id failed", "error", err)
+			return
+		}
+		body, err := json.Marshal(payload{ID: id, Event: ev.Name, Data: ev.Data, CorrelationID: ev.CorrelationID, OccurredAt: time.Now().UTC()})
+		if err != nil {
+			d.logr.Warn("webhook payload encode failed", "event", ev.Name, "error", err)
+			return
+		}
+		deliveries = append(deliveries, Delivery{ID: id, SubscriptionID: sub.ID, Event: ev.Name, Body: body})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := d.store.EnqueueDeliveries(ctx, deliveries); err != nil {
+		d.logr.Warn("webhook deliveries enqueue failed", "event", ev.Name, "error", err)
+		return
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// InvalidateSubscriptions descarta el cache; el servicio lo llama en cada alta o baja.
+func (d *Dispatcher) InvalidateSubscriptions() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.cacheWarm = false
+	d.mu.Unlock()
+}
+
+func (d *Dispatcher) subscriptions(ctx context.Context) ([]Subscription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cacheWarm && time.Since(d.cachedAt) < subscriptionsTTL {
+		return d.cached, nil
+	}
+	subs, err := d.subs.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.cached, d.cachedAt, d.cacheWarm = subs, time.Now(), true
+	return subs, nil
+}
+
+// Run drena el outbox hasta que ctx se cancele: reclama un lote, lo envia con varios
+// workers y repite; sin trabajo espera un Publish o el PollInterval.
+func (d *Dispatcher) Run(ctx context.Context) {
+	if d.store == nil {
+		return
+	}
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		full := d.drain(ctx)
+		if full {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain procesa un lote y devuelve true si vino completo (puede haber mas).
+func (d *Dispatcher) drain(ctx context.Context) bool {
+	// el lease cubre el peor caso del lote: cada worker envia sus entregas en serie.
+	lease := time.Duration(defaultClaimBatch/defaultWorkers+1) * d.timeout
+	batch, err := d.store.ClaimDeliveries(ctx, defaultClaimBatch, lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logr.Warn("webhook deliveries claim failed", "error", err)
+		}
+		return false
+	}
+	jobs := make(chan Delivery)
+	var wg sync.WaitGroup
+	for i := 0; i < defaultWorkers && i < len(batch); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dl := range jobs {
+				d.process(ctx, dl)
+			}
+		}()
+	}
+	for _, dl := range batch {
+		jobs <- dl
+	}
+	close(jobs)
+	wg.Wait()
+	return len(batch) == defaultClaimBatch
+}
+
+// process hace un intento y registra el resultado; los reintentos esperan backoff
+// exponencial en el outbox en lugar de bloquear al worker.
+func (d *Dispatcher) process(ctx context.Context, dl Delivery) {
+	sendErr := d.send(ctx, dl)
+	var err error
+	switch {
+	case sendErr == nil:
+		err = d.store.CompleteDelivery(ctx, dl.ID)
+	case dl.Attempts >= d.maxAttempts:
+		d.logr.Warn("webhook delivery failed", "event", dl.Event, "subscription_id", dl.SubscriptionID, "delivery_id", dl.ID, "attempts", dl.Attempts, "error", sendErr)
+		err = d.store.FailDelivery(ctx, dl.ID, sendErr.Error())
+	default:
+		err = d.store.RetryDelivery(ctx, dl.ID, time.Now().Add(d.retryDelay(dl.Attempts)), sendErr.Error())
+	}
+	if err != nil && ctx.Err() == nil {
+		// el lease vence y la entrega se reintenta; a lo sumo llega duplicada.
+		d.logr.Warn("webhook delivery state update failed", "delivery_id", dl.ID, "error", err)
+	}
+}
+
+// retryDelay es Backoff tras el primer intento y se duplica en cada uno siguiente.
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	wait := d.backoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+	}
+	return wait
+}
+
+func (d *Dispatcher) send(ctx context.Context, dl Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(dl.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, dl.Event)
+	req.Header.Set(DeliveryHeader, dl.ID)
+	req.Header.Set(SignatureHeader, Sign(dl.Secret, dl.Body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign devuelve "sha256=<hex>" con el HMAC-SHA256 del cuerpo; el receptor lo
+// recalcula con el mismo secreto y compara en tiempo constante.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,244 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type stubLister struct {
+	subs  []Subscription
+	calls atomic.Int32
+}
+
+func (s *stubLister) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	s.calls.Add(1)
+	return s.subs, nil
+}
+
+// memStore es un outbox en memoria que resuelve URL y secreto como el JOIN de Postgres.
+type memStore struct {
+	mu      sync.Mutex
+	subs    map[string]Subscription
+	pending map[string]*storedDelivery
+	failed  map[string]string
+}
+
+type storedDelivery struct {
+	Delivery
+	next   time.Time
+	locked bool
+}
+
+func newMemStore(subs ...Subscription) *memStore {
+	m := &memStore{subs: map[string]Subscription{}, pending: map[string]*storedDelivery{}, failed: map[string]string{}}
+	for _, s := range subs {
+		m.subs[s.ID] = s
+	}
+	return m
+}
+
+func (m *memStore) EnqueueDeliveries(ctx context.Context, deliveries []Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, dl := range deliveries {
+		m.pending[dl.ID] = &storedDelivery{Delivery: dl, next: time.Now()}
+	}
+	return nil
+}
+
+func (m *memStore) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Delivery
+	for _, dl := range m.pending {
+		if len(out) == limit || dl.locked || dl.next.After(time.Now()) {
+			continue
+		}
+		dl.locked = true
+		dl.Attempts++
+		sub := m.subs[dl.SubscriptionID]
+		dl.URL, dl.Secret = sub.URL, sub.Secret
+		out = append(out, dl.Delivery)
+	}
+	return out, nil
+}
+
+func (m *memStore) CompleteDelivery(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, id)
+	return nil
+}
+
+func (m *memStore) RetryDelivery(ctx context.Context, id string, next time.Time, lastErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[id].locked = false
+	m.pending[id].next = next
+	return nil
+}
+
+func (m *memStore) FailDelivery(ctx context.Context, id string, lastErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, id)
+	m.failed[id] = lastErr
+	return nil
+}
+
+func (m *memStore) counts() (pending, failed int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending), len(m.failed)
+}
+
+func TestSign_KnownVector(t *testing.T) {
+	got := Sign("key", []byte("The quick brown fox jumps over the lazy dog"))
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestDispatcher_RetriesFailingEndpointAndSigns(t *testing.T) {
+	var calls atomic.Int32
+	done := make(chan *http.Request, 1)
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// los dos primeros intentos fallan; el tercero se acepta.
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+		done <- r
+	}))
+	defer srv.Close()
+
+	subs := []Subscription{
+		{ID: "s1", URL: srv.URL, Events: []string{"product.created"}, Secret: "topsecret"},
+		{ID: "s2", URL: srv.URL, Events: []string{"category.deleted"}, Secret: "other"},
+	}
+	store := newMemStore(subs...)
+	d := NewDispatcher(&stubLister{subs: subs}, store, DispatcherConfig{MaxAttempts: 3, Backoff: time.Millisecond, PollInterval: 5 * time.Millisecond}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Publish(ctx, Event{Name: "product.created", Data: map[string]string{"id": "p1"}, CorrelationID: "corr-1"})
+
+	select {
+	case r := <-done:
+		if got := r.Header.Get(SignatureHeader); got != Sign("topsecret", body) {
+			t.Fatalf("signature mismatch: %s", got)
+		}
+		if r.Header.Get(EventHeader) != "product.created" {
+			t.Fatalf("unexpected event header %q", r.Header.Get(EventHeader))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("delivery not completed, %d calls", calls.Load())
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if p.Event != "product.created" || p.CorrelationID != "corr-1" || p.ID == "" {
+		t.Fatalf("unexpected payload %+v", p)
+	}
+	waitFor(t, func() bool { pending, _ := store.counts(); return pending == 0 })
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	sub := Subscription{ID: "s1", URL: srv.URL, Events: []string{"product.created"}, Secret: "k"}
+	store := newMemStore(sub)
+	d := NewDispatcher(&stubLister{subs: []Subscription{sub}}, store, DispatcherConfig{MaxAttempts: 2, Backoff: time.Millisecond, PollInterval: 5 * time.Millisecond}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Publish(ctx, Event{Name: "product.created"})
+
+	waitFor(t, func() bool { _, failed := store.counts(); return failed == 1 })
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 attempts, got %d", calls.Load())
+	}
+}
+
+func TestDispatcher_DeliversPendingFromPreviousRun(t *testing.T) {
+	got := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Get(DeliveryHeader)
+	}))
+	defer srv.Close()
+
+	// una entrega que quedo en el outbox antes de un reinicio, sin Publish nuevo.
+	store := newMemStore(Subscription{ID: "s1", URL: srv.URL, Secret: "k"})
+	_ = store.EnqueueDeliveries(context.Background(), []Delivery{{ID: "d1", SubscriptionID: "s1", Event: "product.created", Body: []byte(`{}`)}})
+
+	d := NewDispatcher(&stubLister{}, store, DispatcherConfig{PollInterval: 5 * time.Millisecond}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	select {
+	case id := <-got:
+		if id != "d1" {
+			t.Fatalf("unexpected delivery id %q", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("pending delivery was not drained")
+	}
+}
+
+func TestDispatcher_CachesSubscriptionsUntilInvalidated(t *testing.T) {
+	lister := &stubLister{subs: []Subscription{{ID: "s1", URL: "https://example.com", Events: []string{"product.created"}}}}
+	store := newMemStore(lister.subs...)
+	d := NewDispatcher(lister, store, DispatcherConfig{}, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		d.Publish(ctx, Event{Name: "product.created"})
+		d.Publish(ctx, Event{Name: "category.deleted"})
+	}
+	if lister.calls.Load() != 1 {
+		t.Fatalf("expected one subscriptions lookup, got %d", lister.calls.Load())
+	}
+	if pending, _ := store.counts(); pending != 3 {
+		t.Fatalf("expected 3 queued deliveries, got %d", pending)
+	}
+
+	d.InvalidateSubscriptions()
+	d.Publish(ctx, Event{Name: "product.created"})
+	if lister.calls.Load() != 2 {
+		t.Fatalf("expected a fresh lookup after invalidation, got %d", lister.calls.Load())
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
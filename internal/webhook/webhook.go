@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRepositoryNotConfigured = errors.New("repository not configured")
+	ErrInvalidSubscription     = errors.New("invalid webhook subscription")
+	ErrUnknownEvent            = errors.New("unknown webhook event")
+	ErrSubscriptionNotFound    = errors.New("webhook subscription not found")
+)
+
+// Subscription es un endpoint externo que recibe por POST los eventos listados.
+type Subscription struct {
+	ID        string
+	URL       string
+	Events    []string
+	Secret    string // firma HMAC de cada entrega; solo se expone al crearla
+	CreatedAt time.Time
+}
+
+// Wants indica si la suscripcion pidio el evento.
+func (s Subscription) Wants(event string) bool {
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Repository persiste las suscripciones.
+type Repository interface {
+	CreateSubscription(ctx context.Context, sub Subscription) (Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+}
+
+// Service expone el alta, listado y baja de suscripciones.
+type Service interface {
+	CreateSubscription(ctx context.Context, input CreateSubscriptionInput) (Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+}
+
+// CreateSubscriptionInput encapsula el alta; Secret vacio genera uno aleatorio.
+type CreateSubscriptionInput struct {
+	URL    string
+	Events []string
+	Secret string
+}
+
+// ServiceDeps cablea las dependencias del servicio de webhooks.
+type ServiceDeps struct {
+	Repo Repository
+	// Events son los nombres de evento aceptados en una suscripcion.
+	Events []string
+	// OnChange se llama tras cada alta o baja, p.ej. Dispatcher.InvalidateSubscriptions.
+	OnChange func()
+}
+
+type service struct {
+	deps  ServiceDeps
+	known map[string]struct{}
+}
+
+// NewService construye el servicio de suscripciones.
+func NewService(deps ServiceDeps) Service {
+	known := make(map[string]struct{}, len(deps.Events))
+	for _, e := range deps.Events {
+		known[e] = struct{}{}
+	}
+	return &service{deps: deps, known: known}
+}
+
+func (s *service) CreateSubscription(ctx context.Context, input CreateSubscriptionInput) (Subscription, error) {
+	target, err := url.Parse(strings.TrimSpace(input.URL))
+	// solo HTTPS: el payload y la firma no deben viajar en claro.
+	if err != nil || target.Scheme != "https" || target.Host == "" {
+		return Subscription{}, ErrInvalidSubscription
+	}
+	events, err := s.normalizeEvents(input.Events)
+	if err != nil {
+		return Subscription{}, err
+	}
+	secret := strings.TrimSpace(input.Secret)
+	if secret == "" {
+		if secret, err = newSecret(); err != nil {
+			return Subscription{}, err
+		}
+	}
+	sub, err := s.deps.Repo.CreateSubscription(ctx, Subscription{URL: target.String(), Events: events, Secret: secret})
+	if err != nil {
+		return Subscription{}, err
+	}
+	s.changed()
+	return sub, nil
+}
+
+func (s *service) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	return s.deps.Repo.ListSubscriptions(ctx)
+}
+
+func (s *service) DeleteSubscription(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return ErrSubscriptionNotFound
+	}
+	if err := s.deps.Repo.DeleteSubscription(ctx, id); err != nil {
+		return err
+	}
+	s.changed()
+	return nil
+}
+
+func (s *service) changed() {
+	if s.deps.OnChange != nil {
+		s.deps.OnChange()
+	}
+}
+
+// normalizeEvents exige al menos un evento conocido y descarta repetidos.
+func (s *service) normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, ErrInvalidSubscription
+	}
+	out := make([]string, 0, len(events))
+	seen := make(map[string]struct{}, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if _, ok := s.known[e]; !ok {
+			return nil, ErrUnknownEvent
+		}
+		if _, dup := seen[e]; dup {
+			continue
+		}
+		seen[e] = struct{}{}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func newSecret() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
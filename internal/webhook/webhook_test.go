@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_CreateSubscriptionValidates(t *testing.T) {
+	svc := NewService(ServiceDeps{Repo: &memoryRepo{}, Events: []string{"product.created"}})
+	ctx := context.Background()
+	for _, in := range []CreateSubscriptionInput{
+		{URL: "http://example.com/hook", Events: []string{"product.created"}},
+		{URL: "https://example.com/hook"},
+		{URL: "https://example.com/hook", Events: []string{"product.exploded"}},
+	} {
+		if _, err := svc.CreateSubscription(ctx, in); err == nil {
+			t.Fatalf("expected %+v to be rejected", in)
+		}
+	}
+	sub, err := svc.CreateSubscription(ctx, CreateSubscriptionInput{URL: "https://example.com/hook", Events: []string{"product.created", "product.created"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.Secret == "" || len(sub.Events) != 1 {
+		t.Fatalf("expected generated secret and deduplicated events, got %+v", sub)
+	}
+}
+
+func TestService_NotifiesChanges(t *testing.T) {
+	var changes int
+	svc := NewService(ServiceDeps{Repo: &memoryRepo{}, Events: []string{"product.created"}, OnChange: func() { changes++ }})
+	ctx := context.Background()
+	if _, err := svc.CreateSubscription(ctx, CreateSubscriptionInput{URL: "https://example.com/hook", Events: []string{"product.created"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.DeleteSubscription(ctx, "s1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changes != 2 {
+		t.Fatalf("expected create and delete to notify, got %d", changes)
+	}
+}
+
+type memoryRepo struct {
+	subs []Subscription
+}
+
+func (r *memoryRepo) CreateSubscription(ctx context.Context, sub Subscription) (Subscription, error) {
+	sub.ID = "s1"
+	r.subs = append(r.subs, sub)
+	return sub, nil
+}
+
+func (r *memoryRepo) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	return r.subs, nil
+}
+
+func (r *memoryRepo) DeleteSubscription(ctx context.Context, id string) error {
+	return nil
+}
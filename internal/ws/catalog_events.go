@@ -2,13 +2,28 @@ package ws
 
 // Event names for catalog notifications.
 const (
-	EventConnected               = "socket.connected"
-	EventDisconnected            = "socket.disconnected"
-	EventCategoryCreated         = "category.created"
-	EventCategoryUpdated         = "category.updated"
-	EventCategoryDeleted         = "category.deleted"
-	EventProductCreated          = "product.created"
-	EventProductUpdated          = "product.updated"
-	EventProductDeleted          = "product.deleted"
-	EventProductCategoryAssigned = "product.category_assigned"
+	EventConnected                 = "socket.connected"
+	EventDisconnected              = "socket.disconnected"
+	EventCategoryCreated           = "category.created"
+	EventCategoryUpdated           = "category.updated"
+	EventCategoryDeleted           = "category.deleted"
+	EventCategoryReordered         = "category.reordered"
+	EventProductCreated            = "product.created"
+	EventProductUpdated            = "product.updated"
+	EventProductDeleted            = "product.deleted"
+	EventProductRestored           = "product.restored"
+	EventProductPublished          = "product.published"
+	EventProductCategoryAssigned   = "product.category_assigned"
+	EventProductCategoryUnassigned = "product.category_unassigned"
+	EventProductLowStock           = "product.low_stock"
+)
+
+// Visibility define que conexiones reciben un evento.
+type Visibility string
+
+const (
+	// VisibilityPublic llega a todas las conexiones autenticadas (valor por defecto).
+	VisibilityPublic Visibility = "public"
+	// VisibilityAdmin solo llega a conexiones cuyo rol es admin.
+	VisibilityAdmin Visibility = "admin"
 )
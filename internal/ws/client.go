@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -10,30 +11,79 @@ const (
 	writeWait  = 10 * time.Second
 	pongWait   = 60 * time.Second
 	pingPeriod = (pongWait * 9) / 10
+
+	// sendBuffer es la cola de salida por cliente; al llenarse el hub lo desconecta.
+	sendBuffer = 64
+	// sendHighWaterMark es la profundidad a partir de la cual se avisa que el cliente
+	// se esta atrasando, antes de que lo desconecten.
+	sendHighWaterMark = sendBuffer * 3 / 4
 )
 
 // Client envuelve una conexion WebSocket registrada en el hub.
 type Client struct {
-	hub  *Hub
-	conn *websocket.Conn
-	send chan []byte
+	hub      *Hub
+	conn     *websocket.Conn
+	send     chan []byte
+	identity Identity
+
+	unregisterOnce sync.Once
+
+	// metricas de backpressure; solo las toca la goroutine de Hub.Run.
+	connectedAt    time.Time
+	highWater      int
+	aboveHighWater bool
 }
 
-func newClient(hub *Hub, conn *websocket.Conn) *Client {
+func newClient(hub *Hub, conn *websocket.Conn, identity Identity) *Client {
 	return &Client{
-		hub:  hub,
-		conn: conn,
-		send: make(chan []byte, 64),
+		hub:         hub,
+		conn:        conn,
+		send:        make(chan []byte, sendBuffer),
+		identity:    identity,
+		connectedAt: time.Now(),
 	}
 }
 
-// readPump consume mensajes entrantes (solo se usa para ciclo de vida) y sale ante error.
-func (c *Client) readPump() {
-	defer func() {
+// trackQueueDepth actualiza el high-water mark tras encolar y devuelve true la primera
+// vez que la cola cruza sendHighWaterMark (se rearma cuando vuelve a bajar).
+func (c *Client) trackQueueDepth() bool {
+	depth := len(c.send)
+	if depth > c.highWater {
+		c.highWater = depth
+	}
+	if depth < sendHighWaterMark {
+		c.aboveHighWater = false
+		return false
+	}
+	crossed := !c.aboveHighWater
+	c.aboveHighWater = true
+	return crossed
+}
+
+// canReceive indica si la conexion puede recibir eventos con la visibilidad dada.
+func (c *Client) canReceive(v Visibility) bool {
+	if v == VisibilityAdmin {
+		return c.identity.Role == adminRole
+	}
+	return true
+}
+
+// requestUnregister pide al hub que quite al cliente, una sola vez y sin bloquear. La
+// llaman ambos pumps: el primero que detecta la conexion muerta la da de baja.
+func (c *Client) requestUnregister() {
+	c.unregisterOnce.Do(func() {
 		select {
 		case c.hub.unregister <- c:
 		default:
+			// cola llena: el broadcast lo descartara cuando se llene su buffer de envio.
 		}
+	})
+}
+
+// readPump consume mensajes entrantes (solo se usa para ciclo de vida) y sale ante error.
+func (c *Client) readPump() {
+	defer func() {
+		c.requestUnregister()
 		_ = c.conn.Close()
 	}()
 	c.conn.SetReadLimit(1024)
@@ -49,11 +99,13 @@ func (c *Client) readPump() {
 	}
 }
 
-// writePump envia eventos de salida y mantiene viva la conexion con pings.
+// writePump envia eventos de salida y mantiene viva la conexion con pings. Un error de
+// escritura da de baja al cliente en el acto, sin esperar a que readPump falle.
 func (c *Client) writePump() {
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
+		c.requestUnregister()
 		_ = c.conn.Close()
 	}()
 	for {
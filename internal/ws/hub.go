@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -17,6 +19,53 @@ import (
 type EventMessage struct {
 	Event string      `json:"event"`
 	Data  interface{} `json:"data"`
+	// CorrelationID repite el X-Correlation-ID de la peticion que causo el evento.
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+const adminRole = "admin"
+
+// Identity describe al usuario autenticado detras de una conexion.
+type Identity struct {
+	UserID string
+	Role   string
+}
+
+type identityKey struct{}
+
+// WithIdentity adjunta la identidad autenticada al contexto de la peticion de upgrade.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+func identityFromContext(ctx context.Context) Identity {
+	identity, _ := ctx.Value(identityKey{}).(Identity)
+	return identity
+}
+
+type outboundMessage struct {
+	payload    []byte
+	visibility Visibility
+}
+
+// ClientStats describe la cola de salida de una conexion.
+type ClientStats struct {
+	RemoteAddr    string    `json:"remote_addr"`
+	UserID        string    `json:"user_id,omitempty"`
+	Role          string    `json:"role,omitempty"`
+	QueueDepth    int       `json:"queue_depth"`
+	QueueCapacity int       `json:"queue_capacity"`
+	HighWater     int       `json:"high_water"`
+	ConnectedAt   time.Time `json:"connected_at"`
+}
+
+// HubStats resume el backpressure del hub. SlowClientDrops cuenta mensajes descartados
+// porque la cola de un cliente estaba llena (y el cliente fue desconectado);
+// BroadcastDrops, eventos perdidos porque la cola del hub estaba llena.
+type HubStats struct {
+	Clients         []ClientStats `json:"clients"`
+	SlowClientDrops int64         `json:"slow_client_drops"`
+	BroadcastDrops  int64         `json:"broadcast_drops"`
 }
 
 // Hub registra los clientes conectados y les difunde eventos.
@@ -24,7 +73,11 @@ type Hub struct {
 	clients    map[*Client]bool
 	register   chan *Client
 	unregister chan *Client
-	broadcast  chan []byte
+	broadcast  chan outboundMessage
+	stats      chan chan HubStats
+
+	slowClientDrops atomic.Int64
+	broadcastDrops  atomic.Int64
 
 	upgrader       websocket.Upgrader
 	allowedOrigins map[string]struct{}
@@ -43,10 +96,13 @@ func NewHub(allowedOrigins []string, logr *slog.Logger) *Hub {
 		logr = slog.Default()
 	}
 	h := &Hub{
-		clients:        make(map[*Client]bool),
-		register:       make(chan *Client),
-		unregister:     make(chan *Client),
-		broadcast:      make(chan []byte, 64),
+		clients:  make(map[*Client]bool),
+		register: make(chan *Client),
+		// con buffer para que los pumps puedan avisar su baja sin bloquear ni perderla
+		// mientras el hub esta ocupado difundiendo.
+		unregister:     make(chan *Client, 64),
+		broadcast:      make(chan outboundMessage, 64),
+		stats:          make(chan chan HubStats),
 		allowedOrigins: originSet,
 		logr:           logr,
 	}
@@ -74,15 +130,32 @@ func (h *Hub) Run(ctx context.Context) {
 			}
 		case message := <-h.broadcast:
 			for client := range h.clients {
+				if !client.canReceive(message.visibility) {
+					continue
+				}
 				select {
-				case client.send <- message:
+				case client.send <- message.payload:
+					if client.trackQueueDepth() {
+						h.logr.Warn("websocket client falling behind",
+							"remote_addr", client.conn.RemoteAddr().String(),
+							"user_id", client.identity.UserID,
+							"queue_depth", len(client.send),
+							"queue_capacity", cap(client.send))
+					}
 				default:
 					// el cliente no esta leyendo; lo descartamos para no bloquear el hub
+					h.slowClientDrops.Add(1)
+					h.logr.Warn("websocket client disconnected: send queue full",
+						"remote_addr", client.conn.RemoteAddr().String(),
+						"user_id", client.identity.UserID,
+						"high_water", client.highWater)
 					delete(h.clients, client)
 					close(client.send)
 					_ = client.conn.Close()
 				}
 			}
+		case reply := <-h.stats:
+			reply <- h.snapshot()
 		case <-ctx.Done():
 			h.shutdownClients()
 			return
@@ -90,15 +163,19 @@ func (h *Hub) Run(ctx context.Context) {
 	}
 }
 
-// ServeHTTP actualiza la conexion y registra un cliente WebSocket.
+// ServeHTTP actualiza la conexion y registra un cliente WebSocket. La identidad
+// se toma del contexto de la peticion (ver WithIdentity).
 func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// el socket es de larga duracion: el WriteTimeout del servidor no debe cortarlo.
+	// Los writes del cliente usan su propio deadline (writeWait).
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		http.Error(w, "websocket upgrade failed", http.StatusBadRequest)
 		return
 	}
 
-	client := newClient(h, conn)
+	client := newClient(h, conn, identityFromContext(r.Context()))
 	select {
 	case h.register <- client:
 	default:
@@ -114,29 +191,75 @@ func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	_ = h.Publish(EventConnected, map[string]string{"id": conn.RemoteAddr().String()})
 }
 
-// Publish envia un evento a cada cliente conectado. Es best-effort y descarta
+// Publish envia un evento publico a cada cliente conectado. Es best-effort y descarta
 // el payload si falla la serializacion o el hub esta congestionado.
 func (h *Hub) Publish(event string, data interface{}) error {
-	payload, err := json.Marshal(EventMessage{
-		Event: event,
-		Data:  data,
-	})
+	return h.PublishWithVisibility(event, data, VisibilityPublic)
+}
+
+// PublishWithVisibility envia un evento solo a los clientes que pueden verlo.
+func (h *Hub) PublishWithVisibility(event string, data interface{}, visibility Visibility) error {
+	return h.PublishMessage(EventMessage{Event: event, Data: data}, visibility)
+}
+
+// PublishMessage difunde un sobre ya armado, p. ej. con correlation id.
+func (h *Hub) PublishMessage(msg EventMessage, visibility Visibility) error {
+	payload, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
 	select {
-	case h.broadcast <- payload:
+	case h.broadcast <- outboundMessage{payload: payload, visibility: visibility}:
 	default:
 		// no bloqueamos peticion, pero avisamos si el buffer esta lleno y se pierde el evento
+		h.broadcastDrops.Add(1)
 		if h.logr != nil {
-			h.logr.Warn("websocket event dropped: broadcast queue full", "event", event)
+			h.logr.Warn("websocket event dropped: broadcast queue full", "event", msg.Event)
 		}
 		return errors.New("broadcast queue full")
 	}
 	return nil
 }
 
+// Stats devuelve la profundidad de cola de cada cliente y los contadores de descarte.
+// La foto la arma la goroutine de Run, asi que falla con ctx si el hub no esta corriendo.
+func (h *Hub) Stats(ctx context.Context) (HubStats, error) {
+	reply := make(chan HubStats, 1)
+	select {
+	case h.stats <- reply:
+	case <-ctx.Done():
+		return HubStats{}, ctx.Err()
+	}
+	select {
+	case s := <-reply:
+		return s, nil
+	case <-ctx.Done():
+		return HubStats{}, ctx.Err()
+	}
+}
+
+func (h *Hub) snapshot() HubStats {
+	out := HubStats{
+		Clients:         make([]ClientStats, 0, len(h.clients)),
+		SlowClientDrops: h.slowClientDrops.Load(),
+		BroadcastDrops:  h.broadcastDrops.Load(),
+	}
+	for client := range h.clients {
+		out.Clients = append(out.Clients, ClientStats{
+			RemoteAddr:    client.conn.RemoteAddr().String(),
+			UserID:        client.identity.UserID,
+			Role:          client.identity.Role,
+			QueueDepth:    len(client.send),
+			QueueCapacity: cap(client.send),
+			HighWater:     client.highWater,
+			ConnectedAt:   client.connectedAt,
+		})
+	}
+	sort.Slice(out.Clients, func(i, j int) bool { return out.Clients[i].QueueDepth > out.Clients[j].QueueDepth })
+	return out
+}
+
 func (h *Hub) shutdownClients() {
 	for client := range h.clients {
 		close(client.send)
@@ -0,0 +1,248 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startTestHub levanta un hub con un servidor que toma el rol del query string.
+func startTestHub(t *testing.T) (*Hub, string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	hub := NewHub(nil, nil)
+	go hub.Run(ctx)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := Identity{UserID: r.URL.Query().Get("user"), Role: r.URL.Query().Get("role")}
+		hub.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+	})
+	return hub, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func dialTestClient(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// readEventsUntil lee eventos hasta encontrar stop y devuelve los nombres vistos.
+func readEventsUntil(t *testing.T, conn *websocket.Conn, stop string) []string {
+	t.Helper()
+	var seen []string
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read failed before %q (seen %v): %v", stop, seen, err)
+		}
+		var msg EventMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			t.Fatalf("invalid event payload: %v", err)
+		}
+		seen = append(seen, msg.Event)
+		if msg.Event == stop {
+			return seen
+		}
+	}
+}
+
+func TestHub_AdminEventsOnlyReachAdmins(t *testing.T) {
+	hub, url := startTestHub(t)
+	// cada cliente recibe su propio socket.connected una vez registrado en el hub.
+	admin := dialTestClient(t, url+"?user=a1&role=admin")
+	readEventsUntil(t, admin, EventConnected)
+	user := dialTestClient(t, url+"?user=u1&role=user")
+	readEventsUntil(t, user, EventConnected)
+
+	if err := hub.PublishWithVisibility("test.admin_only", map[string]string{"id": "1"}, VisibilityAdmin); err != nil {
+		t.Fatalf("publish admin event: %v", err)
+	}
+	if err := hub.Publish("test.public", map[string]string{"id": "2"}); err != nil {
+		t.Fatalf("publish public event: %v", err)
+	}
+
+	adminSeen := readEventsUntil(t, admin, "test.public")
+	if !containsEvent(adminSeen, "test.admin_only") {
+		t.Fatalf("expected admin to receive admin-only event, got %v", adminSeen)
+	}
+	userSeen := readEventsUntil(t, user, "test.public")
+	if containsEvent(userSeen, "test.admin_only") {
+		t.Fatalf("non-admin client should not receive admin-only event, got %v", userSeen)
+	}
+}
+
+func containsEvent(events []string, name string) bool {
+	for _, e := range events {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHub_PublishMessageIncludesCorrelationID(t *testing.T) {
+	hub, url := startTestHub(t)
+	conn := dialTestClient(t, url+"?user=u1&role=user")
+	readEventsUntil(t, conn, EventConnected)
+
+	if err := hub.PublishMessage(EventMessage{Event: "test.correlated", Data: map[string]string{"id": "1"}, CorrelationID: "checkout-42"}, VisibilityPublic); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		var msg EventMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			t.Fatalf("invalid event payload: %v", err)
+		}
+		if msg.Event != "test.correlated" {
+			continue
+		}
+		if msg.CorrelationID != "checkout-42" {
+			t.Fatalf("expected correlation id in envelope, got %q", msg.CorrelationID)
+		}
+		return
+	}
+}
+
+func TestHub_ConnectionOutlivesServerWriteTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	hub := NewHub(nil, nil)
+	go hub.Run(ctx)
+	srv := httptest.NewUnstartedServer(hub)
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+	})
+
+	conn := dialTestClient(t, "ws"+strings.TrimPrefix(srv.URL, "http"))
+	readEventsUntil(t, conn, EventConnected)
+	time.Sleep(200 * time.Millisecond)
+
+	if err := hub.Publish("test.late", map[string]string{"id": "1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	readEventsUntil(t, conn, "test.late")
+}
+
+func TestHub_WriteErrorUnregistersClient(t *testing.T) {
+	hub, url := startTestHub(t)
+	observer := dialTestClient(t, url+"?user=o1&role=user")
+	readEventsUntil(t, observer, EventConnected)
+
+	// cliente registrado solo con writePump: sin readPump la baja solo puede venir del write.
+	conns := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := hub.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(srv.Close)
+	dialTestClient(t, "ws"+strings.TrimPrefix(srv.URL, "http"))
+	serverConn := <-conns
+	dead := newClient(hub, serverConn, Identity{UserID: "d1"})
+	hub.register <- dead
+	go dead.writePump()
+
+	// la conexion muere del lado del servidor; el siguiente write falla.
+	_ = serverConn.Close()
+	if err := hub.Publish("test.after_close", map[string]string{"id": "1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	_ = observer.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, raw, err := observer.ReadMessage()
+		if err != nil {
+			t.Fatalf("expected socket.disconnected for the dead client: %v", err)
+		}
+		var msg struct {
+			Event string            `json:"event"`
+			Data  map[string]string `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			t.Fatalf("invalid event payload: %v", err)
+		}
+		if msg.Event == EventDisconnected && msg.Data["id"] == serverConn.RemoteAddr().String() {
+			return
+		}
+	}
+}
+
+func TestHub_StatsTrackHighWaterAndSlowClientDrops(t *testing.T) {
+	hub, _ := startTestHub(t)
+
+	// cliente sin writePump: nadie drena su cola, como un consumidor lento.
+	conns := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := hub.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(srv.Close)
+	dialTestClient(t, "ws"+strings.TrimPrefix(srv.URL, "http"))
+	slow := newClient(hub, <-conns, Identity{UserID: "slow"})
+	hub.register <- slow
+
+	publish := func(n int) {
+		for i := 0; i < n; {
+			if err := hub.Publish("test.fill", i); err == nil {
+				i++
+			} else {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+	waitStats := func(ok func(HubStats) bool) HubStats {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			stats, err := hub.Stats(context.Background())
+			if err != nil {
+				t.Fatalf("stats: %v", err)
+			}
+			if ok(stats) || time.Now().After(deadline) {
+				return stats
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	publish(sendHighWaterMark)
+	stats := waitStats(func(s HubStats) bool { return len(s.Clients) == 1 && s.Clients[0].HighWater == sendHighWaterMark })
+	if len(stats.Clients) != 1 || stats.Clients[0].QueueDepth != sendHighWaterMark || stats.Clients[0].QueueCapacity != sendBuffer || stats.Clients[0].UserID != "slow" {
+		t.Fatalf("expected a queue at the high-water mark, got %+v", stats)
+	}
+	if stats.SlowClientDrops != 0 {
+		t.Fatalf("expected no drops before the queue fills, got %d", stats.SlowClientDrops)
+	}
+
+	publish(sendBuffer - sendHighWaterMark + 1)
+	stats = waitStats(func(s HubStats) bool { return s.SlowClientDrops == 1 })
+	if stats.SlowClientDrops != 1 || len(stats.Clients) != 0 {
+		t.Fatalf("expected the overflowing client to be dropped and counted, got %+v", stats)
+	}
+}
@@ -0,0 +1,4 @@
+-- Moneda ISO 4217 por producto; NULL hereda DEFAULT_CURRENCY.
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS currency CHAR(3);
@@ -0,0 +1,4 @@
+-- IP de origen del login que emitio cada refresh token.
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS ip TEXT NOT NULL DEFAULT '';
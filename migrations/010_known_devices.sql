@@ -0,0 +1,9 @@
+-- Huellas (user-agent + IP) de dispositivos desde los que cada usuario ya inicio sesion.
+
+CREATE TABLE IF NOT EXISTS known_devices (
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint   TEXT NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, fingerprint)
+);
@@ -0,0 +1,57 @@
+-- Slug legible por categoria para URLs amigables (/categories/slug/:slug).
+-- Las filas existentes se completan con el nombre normalizado; los duplicados
+-- reciben el mismo sufijo numerico que aplica el repositorio (-2, -3, ...), saltando
+-- los que ya usa otra fila.
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug TEXT;
+
+CREATE TEMP TABLE category_slug_bases AS
+SELECT id,
+       COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(
+           lower(translate(name, 'ÁÀÄÂÉÈËÊÍÌÏÎÓÒÖÔÚÙÜÛÑÇáàäâéèëêíìïîóòöôúùüûñç',
+                                 'AAAAEEEEIIIIOOOOUUUUNCaaaaeeeeiiiioooouuuunc')),
+           '[^a-z0-9]+', '-', 'g')), ''), 'item') AS base,
+       created_at
+FROM categories
+WHERE slug IS NULL;
+
+-- La fila mas antigua de cada base se queda con el slug sin sufijo si esta libre.
+UPDATE categories c
+SET slug = b.base
+FROM (
+    SELECT DISTINCT ON (base) id, base
+    FROM category_slug_bases
+    ORDER BY base, created_at, id
+) b
+WHERE c.id = b.id
+  AND NOT EXISTS (SELECT 1 FROM categories t WHERE t.slug = b.base);
+
+-- El resto prueba -2, -3, ... contra todos los slugs ya asignados, asi "Item" repetido
+-- no choca con el slug propio de "Item 2".
+DO $$
+DECLARE
+    r RECORD;
+    n INT;
+    candidate TEXT;
+BEGIN
+    FOR r IN
+        SELECT b.id, b.base
+        FROM category_slug_bases b
+        JOIN categories t ON t.id = b.id
+        WHERE t.slug IS NULL
+        ORDER BY b.created_at, b.id
+    LOOP
+        n := 2;
+        candidate := r.base || '-' || n;
+        WHILE EXISTS (SELECT 1 FROM categories WHERE slug = candidate) LOOP
+            n := n + 1;
+            candidate := r.base || '-' || n;
+        END LOOP;
+        UPDATE categories SET slug = candidate WHERE id = r.id;
+    END LOOP;
+END $$;
+
+DROP TABLE category_slug_bases;
+
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories (slug);
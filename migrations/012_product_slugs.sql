@@ -0,0 +1,63 @@
+-- Slug canonico por producto y registro de slugs anteriores para que los
+-- enlaces viejos sigan resolviendo (301) tras un renombre.
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug TEXT;
+
+CREATE TEMP TABLE product_slug_bases AS
+SELECT id,
+       COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(
+           lower(translate(name, 'ÁÀÄÂÉÈËÊÍÌÏÎÓÒÖÔÚÙÜÛÑÇáàäâéèëêíìïîóòöôúùüûñç',
+                                 'AAAAEEEEIIIIOOOOUUUUNCaaaaeeeeiiiioooouuuunc')),
+           '[^a-z0-9]+', '-', 'g')), ''), 'item') AS base,
+       created_at
+FROM products
+WHERE slug IS NULL;
+
+-- La fila mas antigua de cada base se queda con el slug sin sufijo si esta libre.
+UPDATE products p
+SET slug = b.base
+FROM (
+    SELECT DISTINCT ON (base) id, base
+    FROM product_slug_bases
+    ORDER BY base, created_at, id
+) b
+WHERE p.id = b.id
+  AND NOT EXISTS (SELECT 1 FROM products t WHERE t.slug = b.base);
+
+-- El resto prueba -2, -3, ... contra todos los slugs ya asignados, asi "Item" repetido
+-- no choca con el slug propio de "Item 2".
+DO $$
+DECLARE
+    r RECORD;
+    n INT;
+    candidate TEXT;
+BEGIN
+    FOR r IN
+        SELECT b.id, b.base
+        FROM product_slug_bases b
+        JOIN products t ON t.id = b.id
+        WHERE t.slug IS NULL
+        ORDER BY b.created_at, b.id
+    LOOP
+        n := 2;
+        candidate := r.base || '-' || n;
+        WHILE EXISTS (SELECT 1 FROM products WHERE slug = candidate) LOOP
+            n := n + 1;
+            candidate := r.base || '-' || n;
+        END LOOP;
+        UPDATE products SET slug = candidate WHERE id = r.id;
+    END LOOP;
+END $$;
+
+DROP TABLE product_slug_bases;
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug ON products (slug);
+
+CREATE TABLE IF NOT EXISTS product_slug_history (
+    slug       TEXT PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_slug_history_product ON product_slug_history (product_id);
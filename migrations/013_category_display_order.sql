@@ -0,0 +1,4 @@
+-- Orden de visualizacion elegido por un admin; 0 = sin orden explicito.
+
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS display_order INT NOT NULL DEFAULT 0;
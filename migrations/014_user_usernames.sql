@@ -0,0 +1,8 @@
+-- Username opcional para login; unico sin distinguir mayusculas.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS username TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_key
+    ON users (lower(username))
+    WHERE username IS NOT NULL;
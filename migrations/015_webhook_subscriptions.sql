@@ -0,0 +1,9 @@
+-- Endpoints externos que reciben eventos de catalogo firmados con HMAC.
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url        TEXT NOT NULL,
+    events     TEXT[] NOT NULL,
+    secret     TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
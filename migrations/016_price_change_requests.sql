@@ -0,0 +1,13 @@
+-- Cambios de precio que superan PRICE_APPROVAL_THRESHOLD y esperan aprobacion de un admin.
+
+CREATE TABLE IF NOT EXISTS price_change_requests (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id  UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    old_price   BIGINT NOT NULL,
+    new_price   BIGINT NOT NULL,
+    status      TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_change_requests_status ON price_change_requests (status, created_at DESC);
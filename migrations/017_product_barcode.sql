@@ -0,0 +1,9 @@
+-- Codigo de barras opcional por producto, guardado como EAN-13 (los UPC-A llevan
+-- un 0 adelante). Unico entre los productos que lo tienen.
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS barcode CHAR(13);
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_barcode_key
+    ON products (barcode)
+    WHERE barcode IS NOT NULL;
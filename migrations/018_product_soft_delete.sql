@@ -0,0 +1,17 @@
+-- Soft-delete de productos: DELETE marca deleted_at en lugar de borrar la fila, asi el
+-- historial y las relaciones sobreviven y el producto se puede restaurar.
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- el barcode solo es unico entre productos vigentes: borrar uno lo libera para un alta
+-- nueva, y restaurarlo falla si otro lo tomo mientras tanto.
+DROP INDEX IF EXISTS products_barcode_key;
+CREATE UNIQUE INDEX products_barcode_key
+    ON products (barcode)
+    WHERE barcode IS NOT NULL AND deleted_at IS NULL;
+
+-- los listados solo recorren productos vigentes.
+CREATE INDEX IF NOT EXISTS idx_products_active_created_at
+    ON products (created_at DESC)
+    WHERE deleted_at IS NULL;
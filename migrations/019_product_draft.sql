@@ -0,0 +1,5 @@
+-- Borradores: un producto incompleto no aparece en la vitrina hasta que se publica.
+-- Los productos existentes quedan publicados.
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS draft BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,20 @@
+-- Outbox de entregas de webhooks: cada evento se guarda por suscripcion antes de
+-- enviarse, asi un reinicio no pierde las entregas pendientes ni sus reintentos.
+-- Las entregadas se borran; las que agotan los intentos quedan con failed_at.
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              TEXT PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event           TEXT NOT NULL,
+    body            BYTEA NOT NULL,
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until    TIMESTAMPTZ,
+    last_error      TEXT,
+    failed_at       TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries (next_attempt_at)
+    WHERE failed_at IS NULL;
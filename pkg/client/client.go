@@ -0,0 +1,312 @@
+// Package client es un cliente Go tipado para la API del catalogo. Vive en el mismo
+// modulo que los handlers y reutiliza sus DTOs, asi que no puede desincronizarse.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	httpapi "catalog-api/internal/http"
+)
+
+// APIError es una respuesta no exitosa decodificada de httpapi.ErrorResponse.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	// Details trae, p. ej., los campos invalidos de un validation_error.
+	Details any
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("catalog api: %d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("catalog api: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// IsCode indica si err es un APIError con el codigo estable code.
+func IsCode(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// PriceChangePendingError se devuelve cuando UpdateProduct responde 202: los demas
+// campos se guardaron pero el precio espera aprobacion de un admin.
+type PriceChangePendingError struct {
+	Response httpapi.PriceChangePendingResponse
+}
+
+func (e *PriceChangePendingError) Error() string {
+	return "catalog api: price change pending approval (request " + e.Response.PriceChangeRequest.ID + ")"
+}
+
+// Client llama a la API con un bearer token opcional; es seguro para uso concurrente.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option ajusta parametros opcionales del cliente.
+type Option func(*Client)
+
+// WithHTTPClient reemplaza el http.Client por defecto (timeouts, transport, etc.).
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithToken arranca el cliente con un access token ya emitido.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New crea un cliente contra baseURL (por ejemplo "https://api.example.com").
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetToken reemplaza el access token usado en Authorization; "" lo quita.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+// Token devuelve el access token actual.
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// Identidad
+
+// RegisterClient da de alta un usuario cliente; queda pendiente de verificacion.
+func (c *Client) RegisterClient(ctx context.Context, req httpapi.RegisterClientRequest) (httpapi.IdentityResponse, error) {
+	var out httpapi.IdentityResponse
+	err := c.do(ctx, http.MethodPost, "/identity/users/client", nil, req, &out)
+	return out, err
+}
+
+// VerifyUser confirma el codigo de verificacion enviado por email.
+func (c *Client) VerifyUser(ctx context.Context, req httpapi.VerifyUserRequest) error {
+	return c.do(ctx, http.MethodPost, "/identity/verify", nil, req, nil)
+}
+
+// Login autentica y guarda el access token para las llamadas siguientes.
+func (c *Client) Login(ctx context.Context, req httpapi.LoginRequest) (httpapi.LoginResponse, error) {
+	var out httpapi.LoginResponse
+	if err := c.do(ctx, http.MethodPost, "/identity/login", nil, req, &out); err != nil {
+		return out, err
+	}
+	c.SetToken(out.Token)
+	return out, nil
+}
+
+// Refresh canjea un refresh token por un access token nuevo y lo guarda.
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (httpapi.LoginResponse, error) {
+	var out httpapi.LoginResponse
+	if err := c.do(ctx, http.MethodPost, "/identity/refresh", nil, httpapi.RefreshRequest{RefreshToken: refreshToken}, &out); err != nil {
+		return out, err
+	}
+	c.SetToken(out.Token)
+	return out, nil
+}
+
+// Catalogo
+
+// ListOptions pagina los listados; los ceros usan los defaults del servidor.
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return q
+}
+
+// ProductListOptions agrega el filtro de stock ("out", "in" o "low") y de categoria a la paginacion.
+type ProductListOptions struct {
+	ListOptions
+	Stock      string
+	CategoryID string
+	// SkipCount pide ?count=false: Total vuelve nil y solo HasMore indica otra pagina.
+	SkipCount bool
+}
+
+// ListCategories devuelve una pagina de categorias con el total.
+func (c *Client) ListCategories(ctx context.Context, opts ListOptions) (httpapi.CategoryListResponse, error) {
+	q := opts.query()
+	// sin limit ni offset el servidor responde el arreglo plano legacy.
+	q.Set("offset", strconv.Itoa(opts.Offset))
+	var out httpapi.CategoryListResponse
+	err := c.do(ctx, http.MethodGet, "/categories", q, nil, &out)
+	return out, err
+}
+
+// CreateCategory requiere un token de admin.
+func (c *Client) CreateCategory(ctx context.Context, req httpapi.CreateCategoryRequest) (httpapi.CategoryResponse, error) {
+	var out httpapi.CategoryResponse
+	err := c.do(ctx, http.MethodPost, "/categories", nil, req, &out)
+	return out, err
+}
+
+// ListProducts devuelve una pagina de productos con el total.
+func (c *Client) ListProducts(ctx context.Context, opts ProductListOptions) (httpapi.ProductListResponse, error) {
+	q := opts.query()
+	if opts.Stock != "" {
+		q.Set("stock", opts.Stock)
+	}
+	if opts.CategoryID != "" {
+		q.Set("category_id", opts.CategoryID)
+	}
+	if opts.SkipCount {
+		q.Set("count", "false")
+	}
+	var out httpapi.ProductListResponse
+	err := c.do(ctx, http.MethodGet, "/products", q, nil, &out)
+	return out, err
+}
+
+// SearchProducts busca productos por texto; un query vacio lista todos.
+func (c *Client) SearchProducts(ctx context.Context, query string, opts ListOptions) (httpapi.ProductListResponse, error) {
+	q := opts.query()
+	q.Set("type", "product")
+	q.Set("q", query)
+	var out httpapi.ProductListResponse
+	err := c.do(ctx, http.MethodGet, "/search", q, nil, &out)
+	return out, err
+}
+
+// GetProduct obtiene un producto por id.
+func (c *Client) GetProduct(ctx context.Context, id string) (httpapi.ProductResponse, error) {
+	var out httpapi.ProductResponse
+	err := c.do(ctx, http.MethodGet, "/products/"+url.PathEscape(id), nil, nil, &out)
+	return out, err
+}
+
+// CreateProduct requiere un token de admin.
+func (c *Client) CreateProduct(ctx context.Context, req httpapi.CreateProductRequest) (httpapi.ProductResponse, error) {
+	var out httpapi.ProductResponse
+	err := c.do(ctx, http.MethodPost, "/products", nil, req, &out)
+	return out, err
+}
+
+// UpdateProduct aplica una actualizacion parcial. Si el precio queda pendiente de
+// aprobacion devuelve el producto guardado junto con un *PriceChangePendingError.
+func (c *Client) UpdateProduct(ctx context.Context, id string, req httpapi.UpdateProductRequest) (httpapi.ProductResponse, error) {
+	resp, err := c.send(ctx, http.MethodPut, "/products/"+url.PathEscape(id), nil, req)
+	if err != nil {
+		return httpapi.ProductResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusAccepted {
+		var pending httpapi.PriceChangePendingResponse
+		if err := json.NewDecoder(resp.Body).Decode(&pending); err != nil {
+			return httpapi.ProductResponse{}, err
+		}
+		return pending.Product, &PriceChangePendingError{Response: pending}
+	}
+	var out httpapi.ProductResponse
+	err = decodeResponse(resp, &out)
+	return out, err
+}
+
+// DeleteProduct requiere un token de admin.
+func (c *Client) DeleteProduct(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/products/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// do envia el request y decodifica la respuesta en out (nil la descarta).
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	resp, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeResponse(resp, out)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	target := c.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := c.Token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(req)
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeAPIError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// decodeAPIError lee el envelope estandar; si el body no lo respeta usa el texto crudo.
+func decodeAPIError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	apiErr := &APIError{Status: resp.StatusCode}
+	var envelope httpapi.ErrorResponse
+	if err := json.Unmarshal(raw, &envelope); err == nil && envelope.Message != "" {
+		apiErr.Message, apiErr.Code, apiErr.Details = envelope.Message, envelope.Code, envelope.Details
+		return apiErr
+	}
+	apiErr.Message = strings.TrimSpace(string(raw))
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
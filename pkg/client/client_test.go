@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"catalog-api/internal/catalog"
+	httpapi "catalog-api/internal/http"
+	"catalog-api/internal/identity"
+	"catalog-api/internal/storage/memory"
+	"catalog-api/pkg/crypto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loginOnlyIdentity implementa solo Login; el resto del servicio no se usa en estos tests.
+type loginOnlyIdentity struct {
+	identity.Service
+	jwt crypto.JWTProvider
+}
+
+func (s loginOnlyIdentity) Login(ctx context.Context, input identity.LoginInput) (identity.AuthToken, error) {
+	if input.Email != "admin@example.com" || input.Password != "secret-pass" {
+		return identity.AuthToken{}, identity.ErrInvalidCredentials
+	}
+	token, err := s.jwt.Generate(ctx, identity.User{ID: "admin-1", Role: "admin"})
+	return identity.AuthToken{Token: token}, err
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	repo := memory.NewCatalogRepository()
+	svc, err := catalog.NewService(catalog.ServiceDeps{CategoryRepo: repo, ProductRepo: repo})
+	if err != nil {
+		t.Fatalf("unexpected error building service: %v", err)
+	}
+	jwt := crypto.JWTProvider{Secret: "test-secret", Issuer: "test", TTL: time.Hour}
+	router := (&httpapi.RouterFactory{
+		CatalogHandler:  httpapi.NewCatalogHandler(svc, nil),
+		IdentityHandler: httpapi.NewIdentityHandler(loginOnlyIdentity{jwt: jwt}),
+		TokenValidator:  httpapi.JWTValidatorAdapter{Provider: jwt},
+	}).Build()
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_ProductLifecycle(t *testing.T) {
+	ctx := context.Background()
+	c := New(newTestServer(t).URL)
+
+	if _, err := c.CreateProduct(ctx, httpapi.CreateProductRequest{Name: "Pen"}); !errors.As(err, new(*APIError)) {
+		t.Fatalf("expected an APIError without token, got %v", err)
+	}
+
+	if _, err := c.Login(ctx, httpapi.LoginRequest{Email: "admin@example.com", Password: "wrong"}); !IsCode(err, "invalid_credentials") {
+		t.Fatalf("expected invalid_credentials, got %v", err)
+	}
+	if _, err := c.Login(ctx, httpapi.LoginRequest{Email: "admin@example.com", Password: "secret-pass"}); err != nil {
+		t.Fatalf("unexpected login error: %v", err)
+	}
+	if c.Token() == "" {
+		t.Fatalf("expected login to store the access token")
+	}
+
+	price, stock := httpapi.FlexInt64(150), httpapi.FlexInt64(3)
+	created, err := c.CreateProduct(ctx, httpapi.CreateProductRequest{Name: "Pen", Price: &price, Currency: "usd", Stock: &stock})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.ID == "" || created.Price != 150 || created.Currency != "USD" {
+		t.Fatalf("unexpected product %+v", created)
+	}
+
+	name := "Blue pen"
+	updated, err := c.UpdateProduct(ctx, created.ID, httpapi.UpdateProductRequest{Name: &name})
+	if err != nil || updated.Name != "Blue pen" {
+		t.Fatalf("expected rename, got %+v (%v)", updated, err)
+	}
+
+	got, err := c.GetProduct(ctx, created.ID)
+	if err != nil || got.Name != "Blue pen" {
+		t.Fatalf("expected to read back the product, got %+v (%v)", got, err)
+	}
+
+	list, err := c.ListProducts(ctx, ProductListOptions{Stock: "in"})
+	if err != nil || list.Total == nil || *list.Total != 1 || len(list.Products) != 1 {
+		t.Fatalf("expected one listed product, got %+v (%v)", list, err)
+	}
+	found, err := c.SearchProducts(ctx, "blue", ListOptions{})
+	if err != nil || found.Total == nil || *found.Total != 1 {
+		t.Fatalf("expected search to find the product, got %+v (%v)", found, err)
+	}
+
+	if err := c.DeleteProduct(ctx, created.ID); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+	_, err = c.GetProduct(ctx, created.ID)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || apiErr.Code != "product_not_found" {
+		t.Fatalf("expected product_not_found, got %v", err)
+	}
+}
+
+func TestClient_Categories(t *testing.T) {
+	ctx := context.Background()
+	c := New(newTestServer(t).URL)
+	if _, err := c.Login(ctx, httpapi.LoginRequest{Email: "admin@example.com", Password: "secret-pass"}); err != nil {
+		t.Fatalf("unexpected login error: %v", err)
+	}
+
+	if _, err := c.CreateCategory(ctx, httpapi.CreateCategoryRequest{Name: "Books"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.SetToken("")
+	if _, err := c.CreateCategory(ctx, httpapi.CreateCategoryRequest{Name: "Audio"}); !errors.As(err, new(*APIError)) {
+		t.Fatalf("expected an APIError after clearing the token, got %v", err)
+	}
+	list, err := c.ListCategories(ctx, ListOptions{})
+	if err != nil || list.Total != 1 || list.Categories[0].Slug != "books" {
+		t.Fatalf("expected one paginated category, got %+v (%v)", list, err)
+	}
+}
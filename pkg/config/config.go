@@ -2,33 +2,210 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"math"
+	"mime"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"errors"
 	"net/url"
+
+	"catalog-api/pkg/locale"
 )
 
-// AdminSeed contiene las credenciales de arranque para el usuario admin inicial.
+// AdminSeed contiene las credenciales de arranque de un usuario admin inicial.
 type AdminSeed struct {
 	Email    string
 	Password string
 	FullName string
 }
 
+// Backends de almacenamiento soportados para el catalogo.
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Formatos de codigo de verificacion.
+const (
+	CodeFormatDigits       = "digits"
+	CodeFormatAlphanumeric = "alphanumeric"
+)
+
+// Politicas aceptadas para sanear la descripcion de productos.
+const (
+	DescriptionPlain = "plain"
+	DescriptionBasic = "basic"
+	DescriptionRaw   = "raw"
+)
+
+// Formatos aceptados para los ids de las rutas.
+const (
+	IDFormatUUID = "uuid"
+	IDFormatFree = "free"
+)
+
 // Config centraliza la configuracion de runtime.
 type Config struct {
-	HTTPPort         string
-	DatabaseURL      string
-	AdminSeed        AdminSeed
-	SMTP             SMTPConfig
-	JWTSecret        string
-	JWTIssuer        string
-	JWTTTL           time.Duration
-	WSAllowedOrigins []string
-	ShutdownTimeout  time.Duration
+	HTTPPort    string
+	DatabaseURL string
+	// DatabaseReplicaURL apunta a una replica de solo lectura para el catalogo; vacio usa DatabaseURL.
+	DatabaseReplicaURL string
+	Storage            string
+	DefaultCurrency    string
+	// LowStockThreshold es el limite de stock para ?stock=low en el listado de productos.
+	LowStockThreshold int
+	// LowStockAlertThreshold emite product.low_stock cuando una edicion cruza este stock; 0 deshabilita.
+	LowStockAlertThreshold int64
+	// PriceApprovalThreshold es el porcentaje de cambio de precio que requiere aprobacion; 0 deshabilita.
+	PriceApprovalThreshold float64
+	// IDCollisionRetries reintenta un alta cuyo id generado choca con la clave primaria.
+	IDCollisionRetries int
+	// ProductDescriptionPolicy sanea descripciones de productos: plain, basic o raw.
+	ProductDescriptionPolicy string
+	// ProductDefaultPrice y ProductDefaultStock completan un alta de producto sin price/stock.
+	ProductDefaultPrice int64
+	ProductDefaultStock int64
+	// PublishRequiresCategory exige al menos una categoria para publicar un borrador.
+	PublishRequiresCategory bool
+	// SearchDefaultLimit es el limite de /search sin ?limit; el listado de productos conserva 20.
+	SearchDefaultLimit int
+	// MaxPageOffset es el ?offset= maximo de los listados paginados; 0 no limita.
+	MaxPageOffset int
+	AdminSeeds    []AdminSeed
+	SMTP          SMTPConfig
+	JWTSecret     string
+	JWTIssuer     string
+	JWTTTL        time.Duration
+	// JWTRoleTTLs sobreescribe JWTTTL para roles puntuales (p.ej. admins con tokens mas cortos).
+	JWTRoleTTLs     map[string]time.Duration
+	RefreshTokenTTL time.Duration
+	// RememberMeTTL es la vigencia de las sesiones con remember_me; 0 deshabilita la opcion.
+	RememberMeTTL time.Duration
+	AuthCookie    AuthCookieConfig
+	// NewDeviceAlerts envia un correo cuando un usuario entra desde un dispositivo no visto.
+	NewDeviceAlerts bool
+	// UsernameLogin habilita el username opcional como identificador de login.
+	UsernameLogin bool
+	// VerificationCode define formato, largo y alfabeto de los codigos de verificacion.
+	VerificationCode VerificationCodeConfig
+	// RequireVerificationSender impide arrancar sin SMTP en lugar de usar el sender noop.
+	RequireVerificationSender bool
+	// DevExposeVerificationCodes hace que el sender noop loguee y guarde los codigos.
+	// Solo para desarrollo sin SMTP; es incompatible con REQUIRE_VERIFICATION_SENDER.
+	DevExposeVerificationCodes bool
+	WSAllowedOrigins           []string
+	WSAllowAnonymous           bool
+	// StrictJSON rechaza campos desconocidos en los cuerpos JSON de altas y ediciones.
+	StrictJSON bool
+	// LocaleHeader es el header del que se negocia el locale; un proxy puede fijar uno propio.
+	LocaleHeader string
+	// DefaultLocale se usa cuando el header no pide un locale soportado (en, es).
+	DefaultLocale string
+	// JSONSchemaValidation valida altas y ediciones del catalogo contra los JSON Schema embebidos.
+	JSONSchemaValidation bool
+	// IDFormat valida los ids de ruta: "uuid" responde 400 a ids malformados, "free" no valida.
+	IDFormat string
+	// JSONInt64AsString serializa price y stock como strings para clientes JavaScript.
+	JSONInt64AsString bool
+	// Compression habilita gzip para respuestas de al menos CompressionMinSize bytes.
+	Compression        bool
+	CompressionMinSize int
+	// CacheControl mapea rutas GET a su directiva Cache-Control (CACHE_CONTROL).
+	CacheControl map[string]string
+	// Storefront oculta productos agotados en listados y busquedas de no-admins.
+	Storefront bool
+	// GlobalRateLimit es el maximo de peticiones por segundo de todo el servidor, con
+	// rafagas de GlobalRateBurst (0 usa el mismo valor); 0 deshabilita el limite.
+	GlobalRateLimit float64
+	GlobalRateBurst int
+	// LoginRatePerMin y LoginBurst limitan por IP las rutas de identity (login, alta,
+	// verificacion); 0 conserva el default del router.
+	LoginRatePerMin float64
+	LoginBurst      int
+	// RegistrationRatePerHour y RegistrationBurst limitan las altas por email sin importar
+	// la IP; 0 conserva el default del router.
+	RegistrationRatePerHour float64
+	RegistrationBurst       int
+	// DebugErrors incluye el error original (y el stack de los panics) en los 500.
+	// Solo para desarrollo; por defecto apagado.
+	DebugErrors bool
+	// CORSAllowedOrigins habilita CORS para esos origenes (coma, o "*"); vacio lo deshabilita.
+	CORSAllowedOrigins []string
+	// CORSAllowCredentials permite cookies cross-origin; exige origenes explicitos.
+	CORSAllowCredentials bool
+	// CORSMaxAge cachea los preflight en el navegador; 0 no envia Access-Control-Max-Age.
+	CORSMaxAge time.Duration
+	// AllowedContentTypes son los Content-Type aceptados en bodies de la API (coma);
+	// los parametros como charset no cuentan.
+	AllowedContentTypes []string
+	// SlowRequestThreshold loguea en warn las peticiones que lo superan; 0 deshabilita.
+	SlowRequestThreshold time.Duration
+	ShutdownTimeout      time.Duration
+	// HTTPServer acota lecturas, escrituras y conexiones ociosas del servidor HTTP.
+	HTTPServer HTTPServerConfig
+	// VerificationCleanupInterval define cada cuanto se purgan codigos vencidos; 0 deshabilita.
+	VerificationCleanupInterval time.Duration
+	// ProductHistoryRetention borra historial mas viejo que este periodo; 0 lo conserva todo.
+	// ProductHistoryKeep entradas por producto se conservan siempre.
+	ProductHistoryRetention     time.Duration
+	ProductHistoryKeep          int
+	ProductHistoryPruneInterval time.Duration
+	// WebhookMaxAttempts y WebhookRetryBackoff controlan los reintentos de cada entrega;
+	// el backoff se duplica tras cada fallo.
+	WebhookMaxAttempts  int
+	WebhookRetryBackoff time.Duration
+	WebhookTimeout      time.Duration
+	// WebhookPollInterval es cada cuanto se revisa el outbox de entregas pendientes.
+	WebhookPollInterval time.Duration
+	// AdminStatsCacheTTL es cuanto se reutiliza el resumen de /admin/stats.
+	AdminStatsCacheTTL time.Duration
+
+	adminSeedsErr   error
+	jwtRoleTTLsErr  error
+	cacheControlErr error
+}
+
+// VerificationCodeConfig describe los codigos OTP enviados por email.
+type VerificationCodeConfig struct {
+	Format   string // digits o alphanumeric
+	Length   int
+	Alphabet string // solo alphanumeric; vacio usa el alfabeto sin caracteres ambiguos
+	// MinBits rechaza al arrancar una combinacion de largo y alfabeto con menos
+	// entropia; 0 no valida.
+	MinBits float64
+}
+
+// Bits estima la entropia de un codigo: Length * log2(simbolos posibles).
+func (v VerificationCodeConfig) Bits(defaultAlphabetSize int) float64 {
+	symbols := 10
+	if v.Format == CodeFormatAlphanumeric {
+		symbols = defaultAlphabetSize
+		if v.Alphabet != "" {
+			symbols = len(v.Alphabet)
+		}
+	}
+	return float64(v.length()) * math.Log2(float64(symbols))
+}
+
+// length trata 0 como el largo por defecto de los generadores.
+func (v VerificationCodeConfig) length() int {
+	if v.Length == 0 {
+		return 6
+	}
+	return v.Length
+}
+
+// AuthCookieConfig controla la entrega del JWT en cookie HttpOnly al hacer login.
+type AuthCookieConfig struct {
+	Always   bool // setea la cookie en todo login; si no, solo con ?cookie=true
+	Secure   bool
+	SameSite string // strict, lax o none
+	Domain   string
 }
 
 // SMTPConfig contiene las credenciales SMTP para el envio de correo.
@@ -39,42 +216,385 @@ type SMTPConfig struct {
 	Password string
 	From     string
 	SkipTLS  bool
+	CABundle string
+	// MessageIDDomain es el dominio de los Message-ID; vacio usa el de From.
+	MessageIDDomain string
+	// PoolSize reutiliza hasta N conexiones SMTP; 0 abre una por envio.
+	PoolSize        int
+	PoolIdleTimeout time.Duration
+}
+
+// HTTPServerConfig agrupa los timeouts del http.Server; 0 deja el timeout sin limite.
+// WriteTimeout no aplica a /ws, que es de larga duracion.
+type HTTPServerConfig struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// KeepAlive mantiene conexiones HTTP/1.1 abiertas entre peticiones hasta IdleTimeout.
+	KeepAlive bool
 }
 
 // Load lee configuracion desde variables de entorno con valores por defecto.
 func Load() Config {
+	seeds, seedsErr := loadAdminSeeds()
+	roleTTLs, roleTTLsErr := parseRoleTTLs(os.Getenv("JWT_ROLE_TTLS"))
+	cacheControl, cacheControlErr := parseCacheControl(os.Getenv("CACHE_CONTROL"))
 	return Config{
-		HTTPPort:         envOrDefault("HTTP_PORT", "8080"),
-		DatabaseURL:      envOrDefault("DATABASE_URL", defaultDatabaseURL()),
-		JWTSecret:        os.Getenv("JWT_SECRET"),
-		JWTIssuer:        envOrDefault("JWT_ISSUER", "catalog-api"),
-		JWTTTL:           durationOrDefault("JWT_TTL", 15*time.Minute),
-		WSAllowedOrigins: splitAndTrim(os.Getenv("WS_ALLOWED_ORIGINS")),
-		ShutdownTimeout:  durationOrDefault("SHUTDOWN_TIMEOUT", 10*time.Second),
-		SMTP: SMTPConfig{
-			Host:     os.Getenv("SMTP_HOST"),
-			Port:     intOrDefault("SMTP_PORT", 587),
-			Username: os.Getenv("SMTP_USERNAME"),
-			Password: os.Getenv("SMTP_PASSWORD"),
-			From:     os.Getenv("SMTP_FROM"),
-			SkipTLS:  boolOrDefault("SMTP_TLS_SKIP_VERIFY", false),
+		HTTPPort:                 envOrDefault("HTTP_PORT", "8080"),
+		DatabaseURL:              envOrDefault("DATABASE_URL", defaultDatabaseURL()),
+		DatabaseReplicaURL:       strings.TrimSpace(os.Getenv("DATABASE_REPLICA_URL")),
+		Storage:                  strings.ToLower(envOrDefault("STORAGE", StoragePostgres)),
+		DefaultCurrency:          strings.ToUpper(envOrDefault("DEFAULT_CURRENCY", "USD")),
+		LowStockThreshold:        intOrDefault("LOW_STOCK_THRESHOLD", 5),
+		LowStockAlertThreshold:   int64OrDefault("LOW_STOCK_ALERT_THRESHOLD", 0),
+		PriceApprovalThreshold:   floatOrDefault("PRICE_APPROVAL_THRESHOLD", 0),
+		ProductDescriptionPolicy: strings.ToLower(envOrDefault("PRODUCT_DESCRIPTION_POLICY", DescriptionPlain)),
+		ProductDefaultPrice:      int64OrDefault("PRODUCT_DEFAULT_PRICE", 0),
+		ProductDefaultStock:      int64OrDefault("PRODUCT_DEFAULT_STOCK", 0),
+		PublishRequiresCategory:  boolOrDefault("PUBLISH_REQUIRES_CATEGORY", false),
+		IDCollisionRetries:       intOrDefault("ID_COLLISION_RETRIES", 3),
+		SearchDefaultLimit:       intOrDefault("SEARCH_DEFAULT_LIMIT", 20),
+		MaxPageOffset:            intOrDefault("MAX_PAGE_OFFSET", 10000),
+		JWTSecret:                os.Getenv("JWT_SECRET"),
+		JWTIssuer:                envOrDefault("JWT_ISSUER", "catalog-api"),
+		JWTTTL:                   durationOrDefault("JWT_TTL", 15*time.Minute),
+		JWTRoleTTLs:              roleTTLs,
+		RefreshTokenTTL:          durationOrDefault("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		RememberMeTTL:            durationOrDefault("REMEMBER_ME_TTL", 0),
+		NewDeviceAlerts:          boolOrDefault("NEW_DEVICE_ALERTS", false),
+		UsernameLogin:            boolOrDefault("USERNAME_LOGIN", false),
+		VerificationCode: VerificationCodeConfig{
+			Format:   strings.ToLower(envOrDefault("VERIFICATION_CODE_FORMAT", CodeFormatDigits)),
+			Length:   intOrDefault("VERIFICATION_CODE_LENGTH", 6),
+			Alphabet: os.Getenv("VERIFICATION_CODE_ALPHABET"),
+			MinBits:  floatOrDefault("VERIFICATION_CODE_MIN_BITS", 0),
+		},
+		RequireVerificationSender:  boolOrDefault("REQUIRE_VERIFICATION_SENDER", false),
+		DevExposeVerificationCodes: boolOrDefault("DEV_EXPOSE_VERIFICATION_CODES", false),
+		AuthCookie: AuthCookieConfig{
+			Always:   boolOrDefault("AUTH_COOKIE", false),
+			Secure:   boolOrDefault("AUTH_COOKIE_SECURE", true),
+			SameSite: strings.ToLower(envOrDefault("AUTH_COOKIE_SAMESITE", "lax")),
+			Domain:   os.Getenv("AUTH_COOKIE_DOMAIN"),
 		},
-		AdminSeed: AdminSeed{
-			Email:    os.Getenv("ADMIN_EMAIL"),
-			Password: os.Getenv("ADMIN_PASSWORD"),
-			FullName: envOrDefault("ADMIN_FULL_NAME", "Catalog Admin"),
+		WSAllowedOrigins:        splitAndTrim(os.Getenv("WS_ALLOWED_ORIGINS")),
+		CORSAllowedOrigins:      splitAndTrim(os.Getenv("CORS_ALLOWED_ORIGINS")),
+		CORSAllowCredentials:    boolOrDefault("CORS_ALLOW_CREDENTIALS", false),
+		CORSMaxAge:              durationOrDefault("CORS_MAX_AGE", 0),
+		AllowedContentTypes:     splitAndTrim(envOrDefault("ALLOWED_CONTENT_TYPES", "application/json")),
+		WSAllowAnonymous:        boolOrDefault("WS_ALLOW_ANONYMOUS", false),
+		StrictJSON:              boolOrDefault("STRICT_JSON", false),
+		LocaleHeader:            envOrDefault("LOCALE_HEADER", "Accept-Language"),
+		DefaultLocale:           strings.ToLower(envOrDefault("DEFAULT_LOCALE", locale.Default)),
+		JSONSchemaValidation:    boolOrDefault("JSON_SCHEMA_VALIDATION", false),
+		IDFormat:                strings.ToLower(envOrDefault("ID_FORMAT", IDFormatUUID)),
+		JSONInt64AsString:       boolOrDefault("JSON_INT64_AS_STRING", false),
+		Compression:             boolOrDefault("COMPRESSION", false),
+		CompressionMinSize:      intOrDefault("COMPRESSION_MIN_SIZE", 1024),
+		CacheControl:            cacheControl,
+		Storefront:              boolOrDefault("STOREFRONT_MODE", false),
+		LoginRatePerMin:         floatOrDefault("LOGIN_RATE_PER_MIN", 5),
+		LoginBurst:              intOrDefault("LOGIN_BURST", 5),
+		RegistrationRatePerHour: floatOrDefault("REGISTRATION_RATE_PER_HOUR", 12),
+		RegistrationBurst:       intOrDefault("REGISTRATION_BURST", 3),
+		SlowRequestThreshold:    durationOrDefault("SLOW_REQUEST_THRESHOLD", time.Second),
+		ShutdownTimeout:         durationOrDefault("SHUTDOWN_TIMEOUT", 10*time.Second),
+		HTTPServer: HTTPServerConfig{
+			ReadTimeout:       durationOrDefault("HTTP_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout: durationOrDefault("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:      durationOrDefault("HTTP_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:       durationOrDefault("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+			KeepAlive:         boolOrDefault("HTTP_KEEP_ALIVE", true),
+		},
+		VerificationCleanupInterval: durationOrDefault("VERIFICATION_CLEANUP_INTERVAL", time.Hour),
+		ProductHistoryRetention:     durationOrDefault("PRODUCT_HISTORY_RETENTION", 0),
+		ProductHistoryKeep:          intOrDefault("PRODUCT_HISTORY_KEEP", 10),
+		ProductHistoryPruneInterval: durationOrDefault("PRODUCT_HISTORY_PRUNE_INTERVAL", 24*time.Hour),
+		WebhookMaxAttempts:          intOrDefault("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookRetryBackoff:         durationOrDefault("WEBHOOK_RETRY_BACKOFF", time.Second),
+		WebhookTimeout:              durationOrDefault("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookPollInterval:         durationOrDefault("WEBHOOK_POLL_INTERVAL", time.Second),
+		AdminStatsCacheTTL:          durationOrDefault("ADMIN_STATS_CACHE_TTL", 30*time.Second),
+		SMTP: SMTPConfig{
+			Host:            os.Getenv("SMTP_HOST"),
+			Port:            intOrDefault("SMTP_PORT", 587),
+			Username:        os.Getenv("SMTP_USERNAME"),
+			Password:        os.Getenv("SMTP_PASSWORD"),
+			From:            os.Getenv("SMTP_FROM"),
+			SkipTLS:         boolOrDefault("SMTP_TLS_SKIP_VERIFY", false),
+			CABundle:        os.Getenv("SMTP_CA_BUNDLE"),
+			MessageIDDomain: os.Getenv("SMTP_MESSAGE_ID_DOMAIN"),
+			PoolSize:        intOrDefault("SMTP_POOL_SIZE", 0),
+			PoolIdleTimeout: durationOrDefault("SMTP_POOL_IDLE_TIMEOUT", 30*time.Second),
 		},
+		AdminSeeds:      seeds,
+		adminSeedsErr:   seedsErr,
+		jwtRoleTTLsErr:  roleTTLsErr,
+		cacheControlErr: cacheControlErr,
+	}
+}
+
+// loadAdminSeeds prioriza ADMIN_SEEDS y cae a ADMIN_EMAIL/ADMIN_PASSWORD si no esta definido.
+func loadAdminSeeds() ([]AdminSeed, error) {
+	defaultName := envOrDefault("ADMIN_FULL_NAME", "Catalog Admin")
+	if raw := strings.TrimSpace(os.Getenv("ADMIN_SEEDS")); raw != "" {
+		return parseAdminSeeds(raw, defaultName)
+	}
+	email := os.Getenv("ADMIN_EMAIL")
+	if email == "" {
+		return nil, nil
+	}
+	return []AdminSeed{{
+		Email:    email,
+		Password: os.Getenv("ADMIN_PASSWORD"),
+		FullName: defaultName,
+	}}, nil
+}
+
+// parseAdminSeeds interpreta entradas email:password:name separadas por coma o punto y coma.
+// El password puede contener ':' porque se toma todo lo que hay entre el primer y el ultimo.
+func parseAdminSeeds(raw, defaultName string) ([]AdminSeed, error) {
+	entries := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ';' })
+	seeds := make([]AdminSeed, 0, len(entries))
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		first := strings.Index(entry, ":")
+		last := strings.LastIndex(entry, ":")
+		if first <= 0 || first == last {
+			return nil, fmt.Errorf("ADMIN_SEEDS entry %d must be email:password:name", i)
+		}
+		seed := AdminSeed{
+			Email:    strings.TrimSpace(entry[:first]),
+			Password: entry[first+1 : last],
+			FullName: strings.TrimSpace(entry[last+1:]),
+		}
+		if seed.Password == "" {
+			return nil, fmt.Errorf("ADMIN_SEEDS entry %d has an empty password", i)
+		}
+		if seed.FullName == "" {
+			seed.FullName = defaultName
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, nil
+}
+
+// jwtRoles son los roles que existen en identity; un typo en JWT_ROLE_TTLS no debe pasar inadvertido.
+var jwtRoles = map[string]struct{}{"admin": {}, "user": {}, "client": {}}
+
+// parseRoleTTLs interpreta entradas rol=duracion separadas por coma, p.ej. "admin=5m,client=24h".
+func parseRoleTTLs(raw string) (map[string]time.Duration, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	out := make(map[string]time.Duration)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		role, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("JWT_ROLE_TTLS entry %q must be role=duration", entry)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("JWT_ROLE_TTLS entry %q: %w", entry, err)
+		}
+		out[strings.ToLower(strings.TrimSpace(role))] = ttl
+	}
+	return out, nil
+}
+
+// defaultCacheControl cachea por poco tiempo el listado de categorias, que cambia rara vez.
+var defaultCacheControl = map[string]string{
+	"/api/v1/categories": "public, max-age=30",
+}
+
+// parseCacheControl interpreta entradas ruta=directiva separadas por ';' (la directiva
+// puede llevar comas), p.ej. "/api/v1/categories=public, max-age=60;/api/v1/products=no-cache".
+// Sin valor se usa defaultCacheControl.
+func parseCacheControl(raw string) (map[string]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return maps.Clone(defaultCacheControl), nil
+	}
+	out := make(map[string]string)
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, directive, ok := strings.Cut(entry, "=")
+		route, directive = strings.TrimSpace(route), strings.TrimSpace(directive)
+		if !ok || !strings.HasPrefix(route, "/") || directive == "" {
+			return nil, fmt.Errorf("CACHE_CONTROL entry %q must be /route=directive", entry)
+		}
+		out[route] = directive
+	}
+	return out, nil
+}
+
+// LongestJWTTTL es el mayor TTL posible entre el default y los de cada rol.
+func (c Config) LongestJWTTTL() time.Duration {
+	longest := c.JWTTTL
+	for _, ttl := range c.JWTRoleTTLs {
+		if ttl > longest {
+			longest = ttl
+		}
 	}
+	return longest
 }
 
 // Validate asegura que existan los parametros criticos de configuracion.
 func (c Config) Validate() error {
+	if c.adminSeedsErr != nil {
+		return c.adminSeedsErr
+	}
+	if c.jwtRoleTTLsErr != nil {
+		return c.jwtRoleTTLsErr
+	}
+	if c.cacheControlErr != nil {
+		return c.cacheControlErr
+	}
+	for role, ttl := range c.JWTRoleTTLs {
+		if _, ok := jwtRoles[role]; !ok {
+			return fmt.Errorf("JWT_ROLE_TTLS has unknown role %q", role)
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("JWT_ROLE_TTLS ttl for %q must be positive", role)
+		}
+	}
 	if c.DatabaseURL == "" {
 		return errors.New("DATABASE_URL is required")
 	}
 	if strings.TrimSpace(c.JWTSecret) == "" {
 		return errors.New("JWT_SECRET is required")
 	}
+	if c.Storage != StoragePostgres && c.Storage != StorageMemory {
+		return fmt.Errorf("STORAGE must be %q or %q", StoragePostgres, StorageMemory)
+	}
+	switch c.IDFormat {
+	case "", IDFormatUUID, IDFormatFree:
+	default:
+		return fmt.Errorf("ID_FORMAT must be %q or %q", IDFormatUUID, IDFormatFree)
+	}
+	if c.DefaultLocale != "" && !locale.Supported(c.DefaultLocale) {
+		return fmt.Errorf("DEFAULT_LOCALE must be %q or %q", locale.English, locale.Spanish)
+	}
+	if c.GlobalRateLimit < 0 || c.GlobalRateBurst < 0 {
+		return errors.New("GLOBAL_RATE_LIMIT and GLOBAL_RATE_BURST cannot be negative")
+	}
+	if c.LoginRatePerMin < 0 || c.LoginBurst < 0 {
+		return errors.New("LOGIN_RATE_PER_MIN and LOGIN_BURST cannot be negative (0 keeps the default)")
+	}
+	if c.RegistrationRatePerHour < 0 || c.RegistrationBurst < 0 {
+		return errors.New("REGISTRATION_RATE_PER_HOUR and REGISTRATION_BURST cannot be negative (0 keeps the default)")
+	}
+	if c.PriceApprovalThreshold < 0 {
+		return errors.New("PRICE_APPROVAL_THRESHOLD must not be negative")
+	}
+	if c.IDCollisionRetries < 0 {
+		return errors.New("ID_COLLISION_RETRIES must not be negative")
+	}
+	if c.CORSAllowCredentials && slices.Contains(c.CORSAllowedOrigins, "*") {
+		return errors.New("CORS_ALLOW_CREDENTIALS requires explicit CORS_ALLOWED_ORIGINS, not *")
+	}
+	if c.CORSMaxAge < 0 {
+		return errors.New("CORS_MAX_AGE must not be negative")
+	}
+	for _, ct := range c.AllowedContentTypes {
+		if mediaType, _, err := mime.ParseMediaType(ct); err != nil || !strings.Contains(mediaType, "/") {
+			return fmt.Errorf("ALLOWED_CONTENT_TYPES: invalid media type %q", ct)
+		}
+	}
+	if c.ProductDefaultPrice < 0 || c.ProductDefaultStock < 0 {
+		return errors.New("PRODUCT_DEFAULT_PRICE and PRODUCT_DEFAULT_STOCK must not be negative")
+	}
+	if c.MaxPageOffset < 0 {
+		return errors.New("MAX_PAGE_OFFSET must not be negative (0 disables the limit)")
+	}
+	if c.LowStockAlertThreshold < 0 {
+		return errors.New("LOW_STOCK_ALERT_THRESHOLD must not be negative (0 disables the alert)")
+	}
+	switch c.ProductDescriptionPolicy {
+	case "", DescriptionPlain, DescriptionBasic, DescriptionRaw:
+	default:
+		return fmt.Errorf("PRODUCT_DESCRIPTION_POLICY must be %q, %q or %q", DescriptionPlain, DescriptionBasic, DescriptionRaw)
+	}
+	if c.RememberMeTTL < 0 || c.RememberMeTTL > maxRememberMeTTL {
+		return errors.New("REMEMBER_ME_TTL must be between 0 and 8760h")
+	}
+	if err := c.VerificationCode.validate(); err != nil {
+		return err
+	}
+	if err := c.HTTPServer.validate(); err != nil {
+		return err
+	}
+	if c.ProductHistoryKeep < 0 {
+		return errors.New("PRODUCT_HISTORY_KEEP cannot be negative")
+	}
+	if c.DevExposeVerificationCodes && c.RequireVerificationSender {
+		return errors.New("DEV_EXPOSE_VERIFICATION_CODES cannot be used with REQUIRE_VERIFICATION_SENDER")
+	}
+	if c.SMTP.SkipTLS && c.SMTP.CABundle != "" {
+		return errors.New("SMTP_CA_BUNDLE and SMTP_TLS_SKIP_VERIFY cannot be used together")
+	}
+	switch c.AuthCookie.SameSite {
+	case "", "strict", "lax":
+	case "none":
+		if !c.AuthCookie.Secure {
+			return errors.New("AUTH_COOKIE_SAMESITE=none requires AUTH_COOKIE_SECURE=true")
+		}
+	default:
+		return fmt.Errorf("AUTH_COOKIE_SAMESITE must be strict, lax or none, got %q", c.AuthCookie.SameSite)
+	}
+	return nil
+}
+
+func (h HTTPServerConfig) validate() error {
+	if h.ReadTimeout < 0 || h.ReadHeaderTimeout < 0 || h.WriteTimeout < 0 || h.IdleTimeout < 0 {
+		return errors.New("HTTP_READ_TIMEOUT, HTTP_READ_HEADER_TIMEOUT, HTTP_WRITE_TIMEOUT and HTTP_IDLE_TIMEOUT cannot be negative")
+	}
+	return nil
+}
+
+// maxRememberMeTTL coincide con identity.MaxRememberMeTTL.
+const maxRememberMeTTL = 365 * 24 * time.Hour
+
+// defaultCodeAlphabetSize coincide con crypto.DefaultCodeAlphabet.
+const defaultCodeAlphabetSize = 31
+
+func (v VerificationCodeConfig) validate() error {
+	switch v.Format {
+	case "", CodeFormatDigits:
+		if v.Alphabet != "" {
+			return errors.New("VERIFICATION_CODE_ALPHABET requires VERIFICATION_CODE_FORMAT=alphanumeric")
+		}
+	case CodeFormatAlphanumeric:
+		seen := make(map[rune]struct{}, len(v.Alphabet))
+		for _, r := range v.Alphabet {
+			isAlnum := r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+			if _, dup := seen[r]; dup || !isAlnum {
+				return errors.New("VERIFICATION_CODE_ALPHABET must be unique ASCII letters or digits")
+			}
+			seen[r] = struct{}{}
+		}
+		if v.Alphabet != "" && len(v.Alphabet) < 2 {
+			return errors.New("VERIFICATION_CODE_ALPHABET needs at least 2 symbols")
+		}
+	default:
+		return fmt.Errorf("VERIFICATION_CODE_FORMAT must be %q or %q", CodeFormatDigits, CodeFormatAlphanumeric)
+	}
+	if n := v.length(); n < 4 || n > 32 {
+		return errors.New("VERIFICATION_CODE_LENGTH must be between 4 and 32")
+	}
+	if bits := v.Bits(defaultCodeAlphabetSize); v.MinBits > 0 && bits < v.MinBits {
+		return fmt.Errorf("verification codes carry %.1f bits, below VERIFICATION_CODE_MIN_BITS=%.1f", bits, v.MinBits)
+	}
 	return nil
 }
 
@@ -122,6 +642,24 @@ func intOrDefault(key string, fallback int) int {
 	return fallback
 }
 
+func int64OrDefault(key string, fallback int64) int64 {
+	if v := os.Getenv(key); v != "" {
+		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+func floatOrDefault(key string, fallback float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
 func boolOrDefault(key string, fallback bool) bool {
 	if v := os.Getenv(key); v != "" {
 		switch v {
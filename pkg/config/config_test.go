@@ -0,0 +1,354 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAdminSeeds(t *testing.T) {
+	seeds, err := parseAdminSeeds("a@example.com:secret1:Alice; b@example.com:pa:ss:, c@example.com:x:Carol", "Default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seeds) != 3 {
+		t.Fatalf("expected 3 seeds, got %+v", seeds)
+	}
+	if seeds[0] != (AdminSeed{Email: "a@example.com", Password: "secret1", FullName: "Alice"}) {
+		t.Fatalf("unexpected first seed %+v", seeds[0])
+	}
+	if seeds[1].Password != "pa:ss" || seeds[1].FullName != "Default" {
+		t.Fatalf("expected password with colon and default name, got %+v", seeds[1])
+	}
+	if seeds[2].Email != "c@example.com" {
+		t.Fatalf("unexpected third seed %+v", seeds[2])
+	}
+}
+
+func TestParseAdminSeeds_RejectsMalformedEntries(t *testing.T) {
+	for _, raw := range []string{"a@example.com", "a@example.com:secret", ":secret:Name", "a@example.com::Name"} {
+		if _, err := parseAdminSeeds(raw, "Default"); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestLoad_AdminSeedsFallbackToSingleAdmin(t *testing.T) {
+	t.Setenv("ADMIN_SEEDS", "")
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "changeme")
+	t.Setenv("ADMIN_FULL_NAME", "Root")
+
+	cfg := Load()
+	if len(cfg.AdminSeeds) != 1 || cfg.AdminSeeds[0].Email != "admin@example.com" || cfg.AdminSeeds[0].FullName != "Root" {
+		t.Fatalf("expected single admin fallback, got %+v", cfg.AdminSeeds)
+	}
+
+	t.Setenv("ADMIN_SEEDS", "x@example.com:pw:X;y@example.com:pw:Y")
+	cfg = Load()
+	if len(cfg.AdminSeeds) != 2 || cfg.AdminSeeds[1].Email != "y@example.com" {
+		t.Fatalf("expected ADMIN_SEEDS to take precedence, got %+v", cfg.AdminSeeds)
+	}
+}
+
+func TestValidate_ReportsInvalidAdminSeeds(t *testing.T) {
+	t.Setenv("ADMIN_SEEDS", "broken")
+	t.Setenv("JWT_SECRET", "secret")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected invalid ADMIN_SEEDS to fail validation")
+	}
+}
+
+func TestValidate_AuthCookieSameSite(t *testing.T) {
+	base := Config{DatabaseURL: "postgres://x", JWTSecret: "s", Storage: StorageMemory}
+
+	cfg := base
+	cfg.AuthCookie = AuthCookieConfig{SameSite: "none", Secure: false}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected SameSite=none without Secure to be rejected")
+	}
+	cfg.AuthCookie = AuthCookieConfig{SameSite: "sideways", Secure: true}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected unknown SameSite to be rejected")
+	}
+	cfg.AuthCookie = AuthCookieConfig{SameSite: "none", Secure: true}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoad_JWTRoleTTLs(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("JWT_TTL", "1h")
+	t.Setenv("JWT_ROLE_TTLS", "admin=5m, Client=24h")
+	cfg := Load()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.JWTRoleTTLs["admin"] != 5*time.Minute || cfg.JWTRoleTTLs["client"] != 24*time.Hour {
+		t.Fatalf("unexpected role ttls %+v", cfg.JWTRoleTTLs)
+	}
+	if cfg.LongestJWTTTL() != 24*time.Hour {
+		t.Fatalf("expected longest ttl 24h, got %v", cfg.LongestJWTTTL())
+	}
+}
+
+func TestValidate_JWTRoleTTLs(t *testing.T) {
+	for _, raw := range []string{"admin", "admin=soon", "admni=5m", "admin=0s"} {
+		t.Setenv("JWT_SECRET", "secret")
+		t.Setenv("JWT_ROLE_TTLS", raw)
+		if err := Load().Validate(); err == nil {
+			t.Fatalf("expected JWT_ROLE_TTLS=%q to fail validation", raw)
+		}
+	}
+}
+
+func TestParseCacheControl(t *testing.T) {
+	rules, err := parseCacheControl("")
+	if err != nil || rules["/api/v1/categories"] != "public, max-age=30" {
+		t.Fatalf("expected default rules, got %+v (%v)", rules, err)
+	}
+	rules, err = parseCacheControl("/api/v1/categories=public, max-age=60; /api/v1/products=no-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules["/api/v1/categories"] != "public, max-age=60" || rules["/api/v1/products"] != "no-cache" {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+	if _, err := parseCacheControl("categories=public"); err == nil {
+		t.Fatalf("expected error for route without leading slash")
+	}
+}
+
+func TestValidate_IDFormat(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	if cfg := Load(); cfg.IDFormat != IDFormatUUID {
+		t.Fatalf("expected uuid by default, got %q", cfg.IDFormat)
+	}
+	t.Setenv("ID_FORMAT", "Free")
+	if err := Load().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("ID_FORMAT", "int")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected unknown ID_FORMAT to fail validation")
+	}
+}
+
+func TestValidate_VerificationCode(t *testing.T) {
+	base := Config{DatabaseURL: "postgres://x", JWTSecret: "s", Storage: StorageMemory}
+	for _, tc := range []struct {
+		code VerificationCodeConfig
+		ok   bool
+	}{
+		{code: VerificationCodeConfig{}, ok: true},
+		{code: VerificationCodeConfig{Format: CodeFormatAlphanumeric, Length: 8}, ok: true},
+		{code: VerificationCodeConfig{Format: "emoji", Length: 6}},
+		{code: VerificationCodeConfig{Format: CodeFormatDigits, Length: 6, Alphabet: "AB"}},
+		{code: VerificationCodeConfig{Format: CodeFormatAlphanumeric, Length: 6, Alphabet: "AAB"}},
+		{code: VerificationCodeConfig{Format: CodeFormatDigits, Length: 2}},
+		// 6 digitos son ~19.9 bits; 6 alfanumericos ~29.7.
+		{code: VerificationCodeConfig{Format: CodeFormatDigits, Length: 6, MinBits: 24}},
+		{code: VerificationCodeConfig{Format: CodeFormatAlphanumeric, Length: 6, MinBits: 24}, ok: true},
+	} {
+		cfg := base
+		cfg.VerificationCode = tc.code
+		if err := cfg.Validate(); (err == nil) != tc.ok {
+			t.Fatalf("%+v: expected ok=%v, got %v", tc.code, tc.ok, err)
+		}
+	}
+}
+
+func TestValidate_RememberMeTTL(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("REMEMBER_ME_TTL", "2160h")
+	if err := Load().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("REMEMBER_ME_TTL", "9000h")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected REMEMBER_ME_TTL above one year to fail validation")
+	}
+}
+
+func TestValidate_DefaultLocale(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("DEFAULT_LOCALE", "ES")
+	if cfg := Load(); cfg.DefaultLocale != "es" || cfg.Validate() != nil {
+		t.Fatalf("expected es to be accepted, got %q", cfg.DefaultLocale)
+	}
+	t.Setenv("DEFAULT_LOCALE", "fr")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected unsupported DEFAULT_LOCALE to fail validation")
+	}
+}
+
+func TestLoad_HTTPServerTimeouts(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "45s")
+	cfg := Load()
+	if cfg.HTTPServer.WriteTimeout != 45*time.Second || cfg.HTTPServer.ReadHeaderTimeout != 5*time.Second || !cfg.HTTPServer.KeepAlive {
+		t.Fatalf("unexpected http server config %+v", cfg.HTTPServer)
+	}
+	t.Setenv("HTTP_IDLE_TIMEOUT", "-1s")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected negative HTTP_IDLE_TIMEOUT to fail validation")
+	}
+}
+
+func TestValidate_DevExposeVerificationCodes(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("DEV_EXPOSE_VERIFICATION_CODES", "true")
+	if err := Load().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("REQUIRE_VERIFICATION_SENDER", "true")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected DEV_EXPOSE_VERIFICATION_CODES with REQUIRE_VERIFICATION_SENDER to fail validation")
+	}
+}
+
+func TestValidate_ProductDescriptionPolicy(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	if cfg := Load(); cfg.ProductDescriptionPolicy != DescriptionPlain || cfg.Validate() != nil {
+		t.Fatalf("expected plain by default, got %q", cfg.ProductDescriptionPolicy)
+	}
+	t.Setenv("PRODUCT_DESCRIPTION_POLICY", "Basic")
+	if cfg := Load(); cfg.ProductDescriptionPolicy != DescriptionBasic || cfg.Validate() != nil {
+		t.Fatalf("expected basic to be accepted, got %q", cfg.ProductDescriptionPolicy)
+	}
+	t.Setenv("PRODUCT_DESCRIPTION_POLICY", "markdown")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected unknown policy to fail validation")
+	}
+}
+
+func TestLoad_LoginRateLimit(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	cfg := Load()
+	if cfg.LoginRatePerMin != 5 || cfg.LoginBurst != 5 {
+		t.Fatalf("expected default 5/5, got %v/%d", cfg.LoginRatePerMin, cfg.LoginBurst)
+	}
+	t.Setenv("LOGIN_RATE_PER_MIN", "20")
+	t.Setenv("LOGIN_BURST", "10")
+	cfg = Load()
+	if err := cfg.Validate(); err != nil || cfg.LoginRatePerMin != 20 || cfg.LoginBurst != 10 {
+		t.Fatalf("expected 20/10, got %v/%d (%v)", cfg.LoginRatePerMin, cfg.LoginBurst, err)
+	}
+	t.Setenv("LOGIN_BURST", "-1")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected negative LOGIN_BURST to be rejected")
+	}
+}
+
+func TestLoad_RegistrationRateLimit(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	cfg := Load()
+	if cfg.RegistrationRatePerHour != 12 || cfg.RegistrationBurst != 3 {
+		t.Fatalf("expected default 12/3, got %v/%d", cfg.RegistrationRatePerHour, cfg.RegistrationBurst)
+	}
+	t.Setenv("REGISTRATION_RATE_PER_HOUR", "60")
+	t.Setenv("REGISTRATION_BURST", "5")
+	cfg = Load()
+	if err := cfg.Validate(); err != nil || cfg.RegistrationRatePerHour != 60 || cfg.RegistrationBurst != 5 {
+		t.Fatalf("expected 60/5, got %v/%d (%v)", cfg.RegistrationRatePerHour, cfg.RegistrationBurst, err)
+	}
+	t.Setenv("REGISTRATION_RATE_PER_HOUR", "-1")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected negative REGISTRATION_RATE_PER_HOUR to be rejected")
+	}
+}
+
+func TestLoad_StorefrontMode(t *testing.T) {
+	if Load().Storefront {
+		t.Fatalf("expected storefront mode off by default")
+	}
+	t.Setenv("STOREFRONT_MODE", "true")
+	if !Load().Storefront {
+		t.Fatalf("expected STOREFRONT_MODE=true to enable storefront mode")
+	}
+}
+
+func TestLoad_ProductDefaults(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("PRODUCT_DEFAULT_PRICE", "990")
+	t.Setenv("PRODUCT_DEFAULT_STOCK", "1")
+	cfg := Load()
+	if err := cfg.Validate(); err != nil || cfg.ProductDefaultPrice != 990 || cfg.ProductDefaultStock != 1 {
+		t.Fatalf("expected 990/1, got %d/%d (%v)", cfg.ProductDefaultPrice, cfg.ProductDefaultStock, err)
+	}
+	if cfg.PublishRequiresCategory {
+		t.Fatalf("expected PUBLISH_REQUIRES_CATEGORY to default to false")
+	}
+	t.Setenv("PUBLISH_REQUIRES_CATEGORY", "true")
+	if !Load().PublishRequiresCategory {
+		t.Fatalf("expected PUBLISH_REQUIRES_CATEGORY=true to be read")
+	}
+	t.Setenv("PRODUCT_DEFAULT_STOCK", "-3")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected negative PRODUCT_DEFAULT_STOCK to be rejected")
+	}
+}
+
+func TestLoad_LowStockAlertThreshold(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	if cfg := Load(); cfg.LowStockAlertThreshold != 0 {
+		t.Fatalf("expected the alert to be disabled by default, got %d", cfg.LowStockAlertThreshold)
+	}
+	t.Setenv("LOW_STOCK_ALERT_THRESHOLD", "3")
+	if cfg := Load(); cfg.Validate() != nil || cfg.LowStockAlertThreshold != 3 {
+		t.Fatalf("expected threshold 3, got %d", cfg.LowStockAlertThreshold)
+	}
+	t.Setenv("LOW_STOCK_ALERT_THRESHOLD", "-1")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected negative LOW_STOCK_ALERT_THRESHOLD to be rejected")
+	}
+}
+
+func TestLoad_MaxPageOffset(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	if cfg := Load(); cfg.MaxPageOffset != 10000 {
+		t.Fatalf("expected default 10000, got %d", cfg.MaxPageOffset)
+	}
+	t.Setenv("MAX_PAGE_OFFSET", "0")
+	if cfg := Load(); cfg.Validate() != nil || cfg.MaxPageOffset != 0 {
+		t.Fatalf("expected 0 to disable the limit, got %d", cfg.MaxPageOffset)
+	}
+	t.Setenv("MAX_PAGE_OFFSET", "-1")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected negative MAX_PAGE_OFFSET to be rejected")
+	}
+}
+
+func TestLoad_CORS(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://shop.example.com, https://admin.example.com")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("CORS_MAX_AGE", "10m")
+	cfg := Load()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.CORSAllowedOrigins) != 2 || !cfg.CORSAllowCredentials || cfg.CORSMaxAge != 10*time.Minute {
+		t.Fatalf("unexpected CORS config: %v %v %v", cfg.CORSAllowedOrigins, cfg.CORSAllowCredentials, cfg.CORSMaxAge)
+	}
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected wildcard origin with credentials to be rejected")
+	}
+}
+
+func TestLoad_AllowedContentTypes(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	if got := Load().AllowedContentTypes; len(got) != 1 || got[0] != "application/json" {
+		t.Fatalf("expected application/json by default, got %v", got)
+	}
+	t.Setenv("ALLOWED_CONTENT_TYPES", "application/json, application/merge-patch+json")
+	cfg := Load()
+	if err := cfg.Validate(); err != nil || len(cfg.AllowedContentTypes) != 2 {
+		t.Fatalf("expected two content types, got %v (%v)", cfg.AllowedContentTypes, err)
+	}
+	t.Setenv("ALLOWED_CONTENT_TYPES", "json")
+	if err := Load().Validate(); err == nil {
+		t.Fatalf("expected an invalid media type to be rejected")
+	}
+}
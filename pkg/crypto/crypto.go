@@ -3,6 +3,7 @@ package crypto
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"catalog-api/internal/identity"
@@ -29,11 +30,17 @@ func (h BcryptHasher) Compare(hash, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
+// ErrMissingSubject se devuelve al validar un token firmado pero sin sub: sin el no
+// hay usuario al que atribuir el request.
+var ErrMissingSubject = errors.New("token has no subject")
+
 // JWTProvider emite tokens JWT; el secreto/issuer/ttl viene por config.
 type JWTProvider struct {
 	Secret string
 	Issuer string
 	TTL    time.Duration
+	// RoleTTL sobreescribe TTL segun el rol del usuario; roles ausentes usan TTL.
+	RoleTTL map[string]time.Duration
 }
 
 // AuthClaims extiende los claims estandar con metadata de rol.
@@ -52,13 +59,20 @@ func (p JWTProvider) Generate(ctx context.Context, user identity.User) (string,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   user.ID,
 			Issuer:    p.Issuer,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(p.TTL)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(p.ttlFor(string(user.Role)))),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(p.Secret))
 }
 
+func (p JWTProvider) ttlFor(role string) time.Duration {
+	if ttl, ok := p.RoleTTL[role]; ok {
+		return ttl
+	}
+	return p.TTL
+}
+
 // Validate parsea y valida un token JWT y retorna sus claims.
 func (p JWTProvider) Validate(token string) (AuthClaims, error) {
 	var claims AuthClaims
@@ -74,5 +88,8 @@ func (p JWTProvider) Validate(token string) (AuthClaims, error) {
 	if !parsed.Valid {
 		return claims, errors.New("invalid token")
 	}
+	if strings.TrimSpace(claims.Subject) == "" {
+		return claims, ErrMissingSubject
+	}
 	return claims, nil
 }
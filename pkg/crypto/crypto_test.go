@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"catalog-api/internal/identity"
+)
+
+func TestJWTProvider_RoleTTL(t *testing.T) {
+	p := JWTProvider{
+		Secret:  "secret",
+		Issuer:  "test",
+		TTL:     time.Hour,
+		RoleTTL: map[string]time.Duration{"admin": 5 * time.Minute, "client": 24 * time.Hour},
+	}
+
+	expiry := func(role identity.RoleName) time.Time {
+		t.Helper()
+		token, err := p.Generate(context.Background(), identity.User{ID: "u1", Role: role})
+		if err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		claims, err := p.Validate(token)
+		if err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		return claims.ExpiresAt.Time
+	}
+
+	now := time.Now()
+	admin := expiry(identity.RoleAdmin)
+	client := expiry(identity.RoleClient)
+	user := expiry(identity.RoleUser)
+
+	if !admin.Before(client) {
+		t.Fatalf("expected admin token (%v) to expire before client token (%v)", admin, client)
+	}
+	if d := admin.Sub(now); d > 5*time.Minute+time.Second {
+		t.Fatalf("expected admin ttl around 5m, got %v", d)
+	}
+	if d := client.Sub(now); d < 24*time.Hour-time.Minute {
+		t.Fatalf("expected client ttl around 24h, got %v", d)
+	}
+	// sin override el rol usa el TTL por defecto.
+	if d := user.Sub(now); d < time.Hour-time.Minute || d > time.Hour+time.Second {
+		t.Fatalf("expected default ttl for user, got %v", d)
+	}
+}
+
+func TestAlphaNumericGenerator_LengthAndAlphabet(t *testing.T) {
+	for _, g := range []AlphaNumericGenerator{
+		{Length: 8},
+		{Length: 5, Alphabet: "XYZ9"},
+	} {
+		alphabet := g.Alphabet
+		if alphabet == "" {
+			alphabet = DefaultCodeAlphabet
+		}
+		for i := 0; i < 50; i++ {
+			code, err := g.Generate(context.Background(), "u1")
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			if len(code) != g.Length {
+				t.Fatalf("expected length %d, got %q", g.Length, code)
+			}
+			for _, r := range code {
+				if !strings.ContainsRune(alphabet, r) {
+					t.Fatalf("code %q has %q outside alphabet %q", code, r, alphabet)
+				}
+			}
+		}
+	}
+	if strings.ContainsAny(DefaultCodeAlphabet, "0O1IL") {
+		t.Fatalf("default alphabet must not include ambiguous characters")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"math/big"
 )
 
 // RandomDigitsGenerator produce codigos numericos de la longitud indicada.
@@ -31,3 +32,35 @@ func (g RandomDigitsGenerator) Generate(ctx context.Context, userID string) (str
 	format := fmt.Sprintf("%%0%dd", n)
 	return fmt.Sprintf(format, val), nil
 }
+
+// DefaultCodeAlphabet omite caracteres ambiguos al leerlos o copiarlos (0/O, 1/I/L).
+const DefaultCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// AlphaNumericGenerator produce codigos alfanumericos: con 31 simbolos, 6
+// caracteres dan ~29 bits frente a ~20 de 6 digitos.
+type AlphaNumericGenerator struct {
+	Length   int
+	Alphabet string // vacio usa DefaultCodeAlphabet
+}
+
+func (g AlphaNumericGenerator) Generate(ctx context.Context, userID string) (string, error) {
+	n := g.Length
+	if n <= 0 {
+		n = 6
+	}
+	alphabet := g.Alphabet
+	if alphabet == "" {
+		alphabet = DefaultCodeAlphabet
+	}
+	// rand.Int descarta los valores que sesgarian el modulo, asi cada simbolo es equiprobable.
+	size := big.NewInt(int64(len(alphabet)))
+	out := make([]byte, n)
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		out[i] = alphabet[idx.Int64()]
+	}
+	return string(out), nil
+}
@@ -0,0 +1,87 @@
+package locale
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locales soportados por los mensajes de error y los correos.
+const (
+	English = "en"
+	Spanish = "es"
+)
+
+// Default es el locale usado cuando no se configura otro.
+const Default = English
+
+// Supported indica si tag es uno de los locales con traducciones.
+func Supported(tag string) bool {
+	switch tag {
+	case English, Spanish:
+		return true
+	}
+	return false
+}
+
+// Negotiate elige el locale soportado de mayor calidad en un header tipo
+// Accept-Language ("es-AR,es;q=0.9,en;q=0.5"). Solo se compara el subtag primario;
+// entradas con q=0 se descartan y "*" equivale a fallback.
+func Negotiate(header, fallback string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q == 0 {
+			continue
+		}
+		if primary, _, found := strings.Cut(tag, "-"); found {
+			tag = primary
+		}
+		candidates = append(candidates, candidate{tag: tag, q: q})
+	}
+	// estable: a igual calidad gana el orden del header.
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if c.tag == "*" {
+			return fallback
+		}
+		if Supported(c.tag) {
+			return c.tag
+		}
+	}
+	return fallback
+}
+
+type contextKey struct{}
+
+// WithLocale guarda el locale negociado en el contexto.
+func WithLocale(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tag)
+}
+
+// FromContext devuelve el locale negociado, o "" si la peticion no paso por la negociacion.
+func FromContext(ctx context.Context) string {
+	tag, _ := ctx.Value(contextKey{}).(string)
+	return tag
+}
@@ -0,0 +1,40 @@
+package locale
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		header string
+		want   string
+	}{
+		{"", English},
+		{"es", Spanish},
+		{"es-AR,es;q=0.9", Spanish},
+		{"fr;q=1, es;q=0.8, en;q=0.5", Spanish},
+		{"en;q=0.4, es;q=0.6", Spanish},
+		{"es;q=0, en", English},
+		{"de, *;q=0.5", English},
+		{"ES-mx", Spanish},
+		{"es;q=abc, en;q=0.1", English},
+	}
+	for _, tc := range cases {
+		if got := Negotiate(tc.header, English); got != tc.want {
+			t.Fatalf("Negotiate(%q) = %q, want %q", tc.header, got, tc.want)
+		}
+	}
+	if got := Negotiate("pt-BR", Spanish); got != Spanish {
+		t.Fatalf("expected fallback for unsupported locale, got %q", got)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != "" {
+		t.Fatalf("expected empty locale, got %q", got)
+	}
+	if got := FromContext(WithLocale(context.Background(), Spanish)); got != Spanish {
+		t.Fatalf("expected es, got %q", got)
+	}
+}
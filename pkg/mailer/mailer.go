@@ -2,24 +2,83 @@ package mailer
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	mail "github.com/wneessen/go-mail"
 )
 
 // MailVerificationSender implementa identity.VerificationSender usando SMTP.
 type MailVerificationSender struct {
-	client *mail.Client
-	from   string
+	client          *mail.Client
+	pool            *connPool // nil: una conexion nueva por envio
+	from            string
+	messageIDDomain string
+}
+
+// Option ajusta parametros opcionales del sender.
+type Option func(*senderOptions)
+
+type senderOptions struct {
+	rootCAs         *x509.CertPool
+	messageIDDomain string
+	poolSize        int
+	poolIdleTimeout time.Duration
+}
+
+// WithRootCAs define las CAs de confianza para validar el certificado del servidor SMTP.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(o *senderOptions) {
+		o.rootCAs = pool
+	}
+}
+
+// WithMessageIDDomain define el dominio usado en el Message-ID; por defecto el del remitente.
+func WithMessageIDDomain(domain string) Option {
+	return func(o *senderOptions) {
+		o.messageIDDomain = strings.TrimSpace(domain)
+	}
+}
+
+// WithPool reutiliza hasta size conexiones SMTP; las ociosas por mas de idleTimeout
+// se descartan (0 las conserva). size <= 0 mantiene una conexion por envio.
+func WithPool(size int, idleTimeout time.Duration) Option {
+	return func(o *senderOptions) {
+		o.poolSize = size
+		o.poolIdleTimeout = idleTimeout
+	}
+}
+
+// LoadCABundle lee un archivo PEM con uno o mas certificados de CA.
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	pemData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read smtp ca bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("smtp ca bundle %s has no valid certificates", path)
+	}
+	return pool, nil
 }
 
 // NewMailVerificationSender construye un sender de verificacion; devuelve nil si falta host.
-func NewMailVerificationSender(host string, port int, username, password, from string, skipTLSVerify bool) *MailVerificationSender {
+// skipTLSVerify desactiva TLS y solo debe usarse en desarrollo.
+func NewMailVerificationSender(host string, port int, username, password, from string, skipTLSVerify bool, options ...Option) *MailVerificationSender {
 	if host == "" || from == "" {
 		return nil
 	}
+	var cfg senderOptions
+	for _, opt := range options {
+		opt(&cfg)
+	}
 	opts := []mail.Option{
 		mail.WithPort(port),
 		mail.WithTLSPolicy(mail.TLSOpportunistic),
@@ -31,6 +90,13 @@ func NewMailVerificationSender(host string, port int, username, password, from s
 			mail.WithSMTPAuth(mail.SMTPAuthPlain),
 		)
 	}
+	if cfg.rootCAs != nil {
+		opts = append(opts, mail.WithTLSConfig(&tls.Config{
+			ServerName: host,
+			RootCAs:    cfg.rootCAs,
+			MinVersion: mail.DefaultTLSMinVersion,
+		}))
+	}
 	if skipTLSVerify {
 		opts = append(opts,
 			mail.WithTLSPolicy(mail.NoTLS),
@@ -42,11 +108,46 @@ func NewMailVerificationSender(host string, port int, username, password, from s
 	if err != nil {
 		return nil
 	}
-	return &MailVerificationSender{client: c, from: from}
+	domain := cfg.messageIDDomain
+	if domain == "" {
+		domain = domainOf(from)
+	}
+	sender := &MailVerificationSender{client: c, from: from, messageIDDomain: domain}
+	if cfg.poolSize > 0 {
+		sender.pool = newConnPool(c, cfg.poolSize, cfg.poolIdleTimeout)
+	}
+	return sender
 }
 
-// SendVerification envia un correo de texto plano con el codigo de verificacion.
+// Close libera las conexiones ociosas del pool; sin pool no hace nada.
+func (s *MailVerificationSender) Close() error {
+	if s == nil || s.pool == nil {
+		return nil
+	}
+	return s.pool.close()
+}
+
+// SendVerification envia un correo de texto plano con el codigo de verificacion, en el
+// locale negociado del request.
 func (s *MailVerificationSender) SendVerification(ctx context.Context, email, code string) error {
+	t := templateFor(ctx, verificationTemplates)
+	return s.send(ctx, email, t.subject, fmt.Sprintf(t.body, code))
+}
+
+// SendNewDeviceLogin avisa al usuario de un inicio de sesion desde un dispositivo no reconocido.
+func (s *MailVerificationSender) SendNewDeviceLogin(ctx context.Context, email, userAgent, ip string) error {
+	t := templateFor(ctx, newDeviceTemplates)
+	return s.send(ctx, email, t.subject, fmt.Sprintf(t.body, userAgent, ip))
+}
+
+// SendTestEmail envia un correo fijo para comprobar la configuracion SMTP.
+func (s *MailVerificationSender) SendTestEmail(ctx context.Context, email string) error {
+	t := templateFor(ctx, testTemplates)
+	return s.send(ctx, email, t.subject, t.body)
+}
+
+// send arma y envia un mensaje de texto plano con Message-ID y envelope-from propios.
+func (s *MailVerificationSender) send(ctx context.Context, to, subject, body string) error {
 	if s == nil || s.client == nil {
 		return errors.New("mail sender no configurado")
 	}
@@ -54,10 +155,39 @@ func (s *MailVerificationSender) SendVerification(ctx context.Context, email, co
 	if err := msg.From(s.from); err != nil {
 		return err
 	}
-	if err := msg.To(email); err != nil {
+	if err := msg.To(to); err != nil {
+		return err
+	}
+	// el envelope-from es el que el servidor receptor usa como Return-Path.
+	if err := msg.EnvelopeFrom(s.from); err != nil {
 		return err
 	}
-	msg.Subject("Verifica tu cuenta")
-	msg.SetBodyString(mail.TypeTextPlain, fmt.Sprintf("Tu codigo de verificacion es: %s", code))
+	messageID, err := newMessageID(s.messageIDDomain)
+	if err != nil {
+		return err
+	}
+	msg.SetMessageIDWithValue(messageID)
+	msg.Subject(subject)
+	msg.SetBodyString(mail.TypeTextPlain, body)
+	if s.pool != nil {
+		return s.pool.send(ctx, msg)
+	}
 	return s.client.DialAndSendWithContext(ctx, msg)
 }
+
+// newMessageID genera un id unico con formato local@dominio.
+func newMessageID(domain string) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]) + "@" + domain, nil
+}
+
+func domainOf(address string) string {
+	address = strings.TrimSuffix(strings.TrimSpace(address), ">")
+	if i := strings.LastIndex(address, "@"); i >= 0 && i < len(address)-1 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
@@ -3,15 +3,28 @@ package mailer
 import (
 	"bufio"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"net"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"catalog-api/pkg/locale"
 )
 
-// servidor SMTP minimo para asegurar que el sender envia mail.
-func startTestSMTPServer(t *testing.T) (addr string, stop func(), received chan string) {
+// servidor SMTP minimo para asegurar que el sender envia mail; con tlsCfg anuncia STARTTLS.
+func startTestSMTPServer(t *testing.T, tlsCfg *tls.Config) (addr string, stop func(), received chan string) {
 	t.Helper()
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -42,7 +55,21 @@ func startTestSMTPServer(t *testing.T) (addr string, stop func(), received chan
 			l := strings.TrimSpace(line)
 			switch {
 			case strings.HasPrefix(l, "EHLO") || strings.HasPrefix(l, "HELO"):
+				if tlsCfg != nil {
+					if _, secured := conn.(*tls.Conn); !secured {
+						write("250-localhost\r\n250-STARTTLS\r\n250 OK\r\n")
+						continue
+					}
+				}
 				write("250-localhost\r\n250 OK\r\n")
+			case l == "STARTTLS" && tlsCfg != nil:
+				write("220 Ready to start TLS\r\n")
+				tlsConn := tls.Server(conn, tlsCfg)
+				if err := tlsConn.Handshake(); err != nil {
+					return
+				}
+				conn = tlsConn
+				reader = bufio.NewReader(conn)
 			case strings.HasPrefix(l, "MAIL FROM:"):
 				write("250 OK\r\n")
 			case strings.HasPrefix(l, "RCPT TO:"):
@@ -74,7 +101,7 @@ func startTestSMTPServer(t *testing.T) (addr string, stop func(), received chan
 }
 
 func TestMailVerificationSender_SendsMail(t *testing.T) {
-	addr, stop, received := startTestSMTPServer(t)
+	addr, stop, received := startTestSMTPServer(t, nil)
 	defer stop()
 
 	host, portStr, _ := net.SplitHostPort(addr)
@@ -99,3 +126,224 @@ func TestMailVerificationSender_SendsMail(t *testing.T) {
 		t.Fatalf("timed out waiting for email body")
 	}
 }
+
+func TestMailVerificationSender_UsesContextLocale(t *testing.T) {
+	addr, stop, received := startTestSMTPServer(t, nil)
+	defer stop()
+
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	sender := NewMailVerificationSender(host, port, "", "", "from@example.com", true)
+	ctx := locale.WithLocale(context.Background(), locale.English)
+	if err := sender.SendVerification(ctx, "to@example.com", "424242"); err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+
+	select {
+	case body := <-received:
+		if !strings.Contains(body, "Verify your account") || !strings.Contains(body, "Your verification code is: 424242") {
+			t.Fatalf("expected english email, got %s", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for email body")
+	}
+}
+
+func TestMailVerificationSender_SendTestEmail(t *testing.T) {
+	addr, stop, received := startTestSMTPServer(t, nil)
+	defer stop()
+
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	sender := NewMailVerificationSender(host, port, "", "", "from@example.com", true)
+	ctx := locale.WithLocale(context.Background(), locale.English)
+	if err := sender.SendTestEmail(ctx, "admin@example.com"); err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+
+	select {
+	case body := <-received:
+		if !strings.Contains(body, "Test email") {
+			t.Fatalf("expected test email, got %s", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for email body")
+	}
+}
+
+func TestMailVerificationSender_PoolReusesConnection(t *testing.T) {
+	// el servidor de prueba acepta una sola conexion: sin reuso el segundo envio no tendria a quien hablarle.
+	addr, stop, received := startTestSMTPServer(t, nil)
+	defer stop()
+
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+	sender := NewMailVerificationSender(host, port, "", "", "from@example.com", true, WithPool(1, time.Minute))
+	if sender == nil {
+		t.Fatalf("expected sender to be created")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	codes := []string{"100001", "100002", "100003", "100004"}
+	errs := make(chan error, len(codes))
+	for _, code := range codes {
+		go func(code string) {
+			errs <- sender.SendVerification(ctx, "to@example.com", code)
+		}(code)
+	}
+	for range codes {
+		if err := <-errs; err != nil {
+			t.Fatalf("unexpected send error: %v", err)
+		}
+	}
+	if err := sender.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	select {
+	case body := <-received:
+		for _, code := range codes {
+			if !strings.Contains(body, code) {
+				t.Fatalf("expected code %s to go through the pooled connection, got %s", code, body)
+			}
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for pooled connection to quit")
+	}
+}
+
+// selfSignedCert genera un certificado para 127.0.0.1 y devuelve el par TLS y su PEM.
+func selfSignedCert(t *testing.T) (tls.Certificate, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test smtp"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("load key pair: %v", err)
+	}
+	return pair, certPEM
+}
+
+func TestMailVerificationSender_TrustsConfiguredCABundle(t *testing.T) {
+	pair, certPEM := selfSignedCert(t)
+	bundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(bundlePath, certPEM, 0o600); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	pool, err := LoadCABundle(bundlePath)
+	if err != nil {
+		t.Fatalf("unexpected error loading bundle: %v", err)
+	}
+
+	addr, stop, received := startTestSMTPServer(t, &tls.Config{Certificates: []tls.Certificate{pair}})
+	defer stop()
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	sender := NewMailVerificationSender(host, port, "", "", "from@example.com", false, WithRootCAs(pool))
+	if err := sender.SendVerification(context.Background(), "to@example.com", "123456"); err != nil {
+		t.Fatalf("expected send over trusted TLS to succeed, got %v", err)
+	}
+	select {
+	case body := <-received:
+		if !strings.Contains(body, "123456") {
+			t.Fatalf("expected body to contain code, got %s", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for email body")
+	}
+}
+
+func TestMailVerificationSender_RejectsUntrustedCertificate(t *testing.T) {
+	pair, _ := selfSignedCert(t)
+	addr, stop, _ := startTestSMTPServer(t, &tls.Config{Certificates: []tls.Certificate{pair}})
+	defer stop()
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	sender := NewMailVerificationSender(host, port, "", "", "from@example.com", false)
+	if err := sender.SendVerification(context.Background(), "to@example.com", "123456"); err == nil {
+		t.Fatalf("expected send to fail without the CA bundle")
+	}
+}
+
+func TestLoadCABundle_RejectsInvalidPEM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(path, []byte("not a cert"), 0o600); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	if _, err := LoadCABundle(path); err == nil {
+		t.Fatalf("expected error for bundle without certificates")
+	}
+}
+
+func TestMailVerificationSender_SetsMessageID(t *testing.T) {
+	addr, stop, received := startTestSMTPServer(t, nil)
+	defer stop()
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	sender := NewMailVerificationSender(host, port, "", "", "no-reply@example.com", true, WithMessageIDDomain("mail.example.com"))
+	if err := sender.SendVerification(context.Background(), "to@example.com", "123456"); err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+
+	var body string
+	select {
+	case body = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for email body")
+	}
+	messageID := regexp.MustCompile(`(?m)^Message-ID: <[0-9a-f]{32}@mail\.example\.com>\r?$`)
+	if !messageID.MatchString(body) {
+		t.Fatalf("expected well-formed Message-ID header, got %s", body)
+	}
+}
+
+func TestMailVerificationSender_SendsNewDeviceLogin(t *testing.T) {
+	addr, stop, received := startTestSMTPServer(t, nil)
+	defer stop()
+
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	sender := NewMailVerificationSender(host, port, "", "", "from@example.com", true)
+	if err := sender.SendNewDeviceLogin(context.Background(), "to@example.com", "curl/8.0", "203.0.113.7"); err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+
+	select {
+	case body := <-received:
+		if !strings.Contains(body, "curl/8.0") || !strings.Contains(body, "203.0.113.7") {
+			t.Fatalf("expected body to mention device and ip, got %s", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for email body")
+	}
+}
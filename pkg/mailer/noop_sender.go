@@ -2,17 +2,61 @@ package mailer
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"strings"
+	"sync"
 )
 
+// ErrNoopSender indica que no hay SMTP configurado, asi que ningun correo se entrega.
+var ErrNoopSender = errors.New("smtp is not configured: the noop sender does not deliver email")
+
 // NoopVerificationSender registra el intento de envio pero no envia correo.
+// Con ExposeCodes (solo desarrollo) loguea el codigo en debug y lo guarda para LastCode,
+// lo que permite completar la verificacion sin SMTP.
 type NoopVerificationSender struct {
-	Logr *slog.Logger
+	Logr        *slog.Logger
+	ExposeCodes bool
+
+	mu    sync.Mutex
+	codes map[string]string
 }
 
 func (s *NoopVerificationSender) SendVerification(ctx context.Context, email, code string) error {
 	if s.Logr != nil {
 		s.Logr.Info("verification email noop sender", "email", email)
 	}
+	if !s.ExposeCodes {
+		return nil
+	}
+	if s.Logr != nil {
+		s.Logr.Debug("verification code (dev only)", "email", email, "code", code)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.codes == nil {
+		s.codes = make(map[string]string)
+	}
+	s.codes[strings.ToLower(email)] = code
 	return nil
 }
+
+// LastCode devuelve el ultimo codigo enviado a email; sin ExposeCodes nunca hay codigo.
+func (s *NoopVerificationSender) LastCode(email string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code, ok := s.codes[strings.ToLower(email)]
+	return code, ok
+}
+
+func (s *NoopVerificationSender) SendNewDeviceLogin(ctx context.Context, email, userAgent, ip string) error {
+	if s.Logr != nil {
+		s.Logr.Info("new device login noop sender", "email", email, "ip", ip)
+	}
+	return nil
+}
+
+// SendTestEmail falla siempre: un correo de prueba debe revelar que no hay SMTP.
+func (s *NoopVerificationSender) SendTestEmail(ctx context.Context, email string) error {
+	return ErrNoopSender
+}
@@ -0,0 +1,26 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNoopVerificationSender_LastCode(t *testing.T) {
+	ctx := context.Background()
+	hidden := &NoopVerificationSender{}
+	_ = hidden.SendVerification(ctx, "user@example.com", "123456")
+	if _, ok := hidden.LastCode("user@example.com"); ok {
+		t.Fatalf("codes must not be captured without ExposeCodes")
+	}
+
+	sender := &NoopVerificationSender{ExposeCodes: true}
+	_ = sender.SendVerification(ctx, "User@Example.com", "111111")
+	_ = sender.SendVerification(ctx, "user@example.com", "222222")
+	code, ok := sender.LastCode("USER@example.com")
+	if !ok || code != "222222" {
+		t.Fatalf("expected last code 222222, got %q ok=%v", code, ok)
+	}
+	if _, ok := sender.LastCode("other@example.com"); ok {
+		t.Fatalf("unexpected code for unknown email")
+	}
+}
@@ -0,0 +1,115 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	mail "github.com/wneessen/go-mail"
+	"github.com/wneessen/go-mail/smtp"
+)
+
+// connPool reutiliza conexiones SMTP abiertas entre envios. slots limita cuantas
+// conexiones hay a la vez; cada conexion la usa un solo envio por vez, asi que los
+// envios concurrentes no comparten estado de sesion.
+type connPool struct {
+	client      *mail.Client
+	slots       chan struct{}
+	idle        chan *pooledConn
+	idleTimeout time.Duration
+}
+
+type pooledConn struct {
+	smtp     *smtp.Client
+	lastUsed time.Time
+}
+
+func newConnPool(client *mail.Client, size int, idleTimeout time.Duration) *connPool {
+	return &connPool{
+		client:      client,
+		slots:       make(chan struct{}, size),
+		idle:        make(chan *pooledConn, size),
+		idleTimeout: idleTimeout,
+	}
+}
+
+// send entrega msg por una conexion ociosa o por una nueva si no hay ninguna vigente.
+func (p *connPool) send(ctx context.Context, msg *mail.Msg) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	conn := p.takeIdle()
+	reused := conn != nil
+	if !reused {
+		var err error
+		if conn, err = p.dial(ctx); err != nil {
+			return err
+		}
+	}
+	err := p.client.SendWithSMTPClient(conn.smtp, msg)
+	if err != nil && reused && isConnCheckError(err) {
+		// el servidor cerro la conexion ociosa; el mensaje no llego a salir, se reintenta una vez.
+		_ = p.client.CloseWithSMTPClient(conn.smtp)
+		if conn, err = p.dial(ctx); err != nil {
+			return err
+		}
+		err = p.client.SendWithSMTPClient(conn.smtp, msg)
+	}
+	if err != nil {
+		_ = p.client.CloseWithSMTPClient(conn.smtp)
+		return err
+	}
+	conn.lastUsed = time.Now()
+	select {
+	case p.idle <- conn:
+	default:
+		_ = p.client.CloseWithSMTPClient(conn.smtp)
+	}
+	return nil
+}
+
+// takeIdle devuelve una conexion ociosa reciente; las vencidas se cierran en el camino.
+func (p *connPool) takeIdle() *pooledConn {
+	for {
+		select {
+		case conn := <-p.idle:
+			if p.idleTimeout > 0 && time.Since(conn.lastUsed) > p.idleTimeout {
+				_ = p.client.CloseWithSMTPClient(conn.smtp)
+				continue
+			}
+			return conn
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *connPool) dial(ctx context.Context) (*pooledConn, error) {
+	c, err := p.client.DialToSMTPClientWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledConn{smtp: c}, nil
+}
+
+// close cierra las conexiones ociosas; las que estan en uso se cierran al volver.
+func (p *connPool) close() error {
+	var errs []error
+	for {
+		select {
+		case conn := <-p.idle:
+			errs = append(errs, p.client.CloseWithSMTPClient(conn.smtp))
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+func isConnCheckError(err error) bool {
+	var sendErr *mail.SendError
+	return errors.As(err, &sendErr) && sendErr.Reason == mail.ErrConnCheck
+}
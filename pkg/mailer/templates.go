@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"context"
+
+	"catalog-api/pkg/locale"
+)
+
+// template es un correo de texto plano; body se formatea con fmt.Sprintf.
+type template struct {
+	subject string
+	body    string
+}
+
+var verificationTemplates = map[string]template{
+	locale.Spanish: {subject: "Verifica tu cuenta", body: "Tu codigo de verificacion es: %s"},
+	locale.English: {subject: "Verify your account", body: "Your verification code is: %s"},
+}
+
+var newDeviceTemplates = map[string]template{
+	locale.Spanish: {
+		subject: "Nuevo inicio de sesion",
+		body:    "Detectamos un inicio de sesion desde un dispositivo nuevo.\n\nDispositivo: %s\nIP: %s\n\nSi no fuiste tu, cambia tu password y revoca la sesion.",
+	},
+	locale.English: {
+		subject: "New sign-in",
+		body:    "We detected a sign-in from a new device.\n\nDevice: %s\nIP: %s\n\nIf this wasn't you, change your password and revoke the session.",
+	},
+}
+
+var testTemplates = map[string]template{
+	locale.Spanish: {subject: "Correo de prueba", body: "Este es un correo de prueba: la configuracion SMTP funciona."},
+	locale.English: {subject: "Test email", body: "This is a test email: the SMTP configuration works."},
+}
+
+// templateFor elige la plantilla del locale negociado; sin locale en el contexto (p. ej.
+// tareas en segundo plano) se mantiene el espanol, el idioma original de los correos.
+func templateFor(ctx context.Context, templates map[string]template) template {
+	if t, ok := templates[locale.FromContext(ctx)]; ok {
+		return t
+	}
+	return templates[locale.Spanish]
+}